@@ -0,0 +1,449 @@
+package goja
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/token"
+)
+
+// DiagnosticKind identifies the type of a Diagnostic.
+type DiagnosticKind int
+
+const (
+	// DiagnosticUnused is reported for a variable, function or parameter that is never read.
+	DiagnosticUnused DiagnosticKind = iota
+	// DiagnosticUnreachable is reported for a statement that follows return, throw, break or continue.
+	DiagnosticUnreachable
+	// DiagnosticConstantComparison is reported for a comparison whose result is known without running the code.
+	DiagnosticConstantComparison
+)
+
+func (k DiagnosticKind) String() string {
+	switch k {
+	case DiagnosticUnused:
+		return "unused"
+	case DiagnosticUnreachable:
+		return "unreachable"
+	case DiagnosticConstantComparison:
+		return "constant comparison"
+	}
+	return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+}
+
+// Diagnostic is a single finding of Analyze().
+type Diagnostic struct {
+	Kind     DiagnosticKind
+	Message  string
+	Offset   int
+	Position Position
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Position.Line, d.Position.Col, d.Message)
+}
+
+var analysisTypeofResults = map[string]bool{
+	"undefined": true,
+	"object":    true,
+	"boolean":   true,
+	"number":    true,
+	"string":    true,
+	"function":  true,
+}
+
+// analysisBinding is a binding declared in a scope, as recorded by the compiler.
+type analysisBinding struct {
+	name   string
+	idx    file.Idx
+	param  int // parameter index or -1
+	fn     bool
+	used   bool
+	silent bool
+}
+
+// analysisScope records the bindings the compiler declares in a scope and whether they are read.
+type analysisScope struct {
+	bindings map[string]*analysisBinding
+	order    []*analysisBinding
+
+	// dynamic is set when a direct eval() or a with statement in the scope or in a nested one may reference
+	// bindings by name at run time.
+	dynamic bool
+	// argsNeeded is set when the function uses the arguments object, which gives access to all the parameters.
+	argsNeeded bool
+}
+
+type analyzer struct {
+	src         *SrcFile
+	diagnostics []Diagnostic
+	// functions are the scopes of the functions in the order the compiler has left them.
+	functions []*analysisScope
+}
+
+// Analyze parses and compiles the source and statically checks it for bindings (variables, functions and
+// parameters) that are never read, statements that can never be reached and comparisons which are always true or
+// always false. The bindings and their uses are the ones resolved by the compiler. Top-level declarations are
+// never reported as unused because they become properties of the global object, and functions that contain a
+// direct eval() or a with statement are not checked for unused bindings either.
+// Returns a *CompilerSyntaxError (or *CompilerReferenceError) if the source cannot be compiled. The diagnostics
+// are ordered by position.
+func Analyze(name, src string) ([]Diagnostic, error) {
+	prg, err := parse(name, src)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzer{
+		src: NewSrcFile(name, src),
+	}
+	c := newCompiler()
+	c.analysis = a
+	c.scope.analysis = newAnalysisScope()
+	if err := c.compileProgram(prg); err != nil {
+		return nil, err
+	}
+	for _, s := range a.functions {
+		a.unused(s)
+	}
+	a.declarations(prg.DeclarationList)
+	a.statements(prg.Body)
+
+	sort.SliceStable(a.diagnostics, func(i, j int) bool {
+		return a.diagnostics[i].Offset < a.diagnostics[j].Offset
+	})
+	return a.diagnostics, nil
+}
+
+func (a *analyzer) report(kind DiagnosticKind, idx file.Idx, format string, args ...interface{}) {
+	offset := int(idx) - 1
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Kind:     kind,
+		Message:  fmt.Sprintf(format, args...),
+		Offset:   offset,
+		Position: a.src.Position(offset),
+	})
+}
+
+func newAnalysisScope() *analysisScope {
+	return &analysisScope{
+		bindings: make(map[string]*analysisBinding),
+	}
+}
+
+// declare records a declaration, a name which is already bound keeps its first declaration.
+func (s *analysisScope) declare(b analysisBinding) {
+	if _, exists := s.bindings[b.name]; exists {
+		return
+	}
+	s.bindings[b.name] = &b
+	s.order = append(s.order, &b)
+}
+
+// use records a read of a name bound in the scope.
+func (s *analysisScope) use(name string) {
+	if b := s.bindings[name]; b != nil {
+		b.used = true
+	}
+}
+
+// leaveScope is called by the compiler when it's done with a scope.
+func (a *analyzer) leaveScope(s *scope) {
+	as := s.analysis
+	if s.dynamic {
+		as.dynamic = true
+	}
+	if as.dynamic && s.outer != nil {
+		s.outer.analysis.dynamic = true
+	}
+	if !s.lexical {
+		as.argsNeeded = s.argsNeeded
+		a.functions = append(a.functions, as)
+	}
+}
+
+// unused reports the bindings of a function which are never read.
+func (a *analyzer) unused(s *analysisScope) {
+	if s.dynamic {
+		return
+	}
+	// Only the parameters after the last used one are reported, the others are needed to keep the positions.
+	lastUsedParam := -1
+	for _, b := range s.order {
+		if b.param > lastUsedParam && b.used {
+			lastUsedParam = b.param
+		}
+	}
+	for _, b := range s.order {
+		if b.used || b.silent {
+			continue
+		}
+		switch {
+		case b.param >= 0:
+			if b.param > lastUsedParam && !s.argsNeeded {
+				a.report(DiagnosticUnused, b.idx, "Parameter '%s' is never used", b.name)
+			}
+		case b.fn:
+			a.report(DiagnosticUnused, b.idx, "Function '%s' is declared but never used", b.name)
+		default:
+			a.report(DiagnosticUnused, b.idx, "Variable '%s' is declared but its value is never read", b.name)
+		}
+	}
+}
+
+func (a *analyzer) declarations(list []ast.Declaration) {
+	for _, decl := range list {
+		if decl, ok := decl.(*ast.FunctionDeclaration); ok {
+			a.function(decl.Function)
+		}
+	}
+}
+
+func (a *analyzer) function(fn *ast.FunctionLiteral) {
+	a.declarations(fn.DeclarationList)
+	if body, ok := fn.Body.(*ast.BlockStatement); ok {
+		a.statements(body.List)
+	} else {
+		a.statement(fn.Body)
+	}
+}
+
+func (a *analyzer) statements(list []ast.Statement) {
+	terminated, reported := false, false
+	for _, st := range list {
+		if terminated && !reported && isReachableCode(st) {
+			a.report(DiagnosticUnreachable, st.Idx0(), "Unreachable code")
+			reported = true
+		}
+		a.statement(st)
+		if !terminated && statementTerminates(st) {
+			terminated = true
+		}
+	}
+}
+
+// isReachableCode returns false for statements that produce no code, i.e. empty statements (function
+// declarations are replaced by those too) and var statements without initialisers which are hoisted.
+func isReachableCode(st ast.Statement) bool {
+	switch st := st.(type) {
+	case *ast.EmptyStatement:
+		return false
+	case *ast.VariableStatement:
+		for _, v := range st.List {
+			if v, ok := v.(*ast.VariableExpression); !ok || v.Initializer != nil {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// statementTerminates returns true if the control never passes to the statement following st.
+func statementTerminates(st ast.Statement) bool {
+	switch st := st.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement, *ast.BranchStatement:
+		return true
+	case *ast.BlockStatement:
+		for _, s := range st.List {
+			if statementTerminates(s) {
+				return true
+			}
+		}
+	case *ast.IfStatement:
+		return st.Alternate != nil && statementTerminates(st.Consequent) && statementTerminates(st.Alternate)
+	case *ast.TryStatement:
+		if st.Finally != nil && statementTerminates(st.Finally) {
+			return true
+		}
+		return statementTerminates(st.Body) && (st.Catch == nil || statementTerminates(st.Catch.Body))
+	}
+	return false
+}
+
+func (a *analyzer) statement(st ast.Statement) {
+	switch st := st.(type) {
+	case *ast.BlockStatement:
+		a.statements(st.List)
+	case *ast.ExpressionStatement:
+		a.expression(st.Expression)
+	case *ast.VariableStatement:
+		for _, e := range st.List {
+			a.expression(e)
+		}
+	case *ast.IfStatement:
+		a.expression(st.Test)
+		a.statement(st.Consequent)
+		if st.Alternate != nil {
+			a.statement(st.Alternate)
+		}
+	case *ast.ForStatement:
+		a.expression(st.Initializer)
+		a.expression(st.Test)
+		a.expression(st.Update)
+		a.statement(st.Body)
+	case *ast.ForInStatement:
+		a.expression(st.Into)
+		a.expression(st.Source)
+		a.statement(st.Body)
+	case *ast.WhileStatement:
+		a.expression(st.Test)
+		a.statement(st.Body)
+	case *ast.DoWhileStatement:
+		a.statement(st.Body)
+		a.expression(st.Test)
+	case *ast.LabelledStatement:
+		a.statement(st.Statement)
+	case *ast.ReturnStatement:
+		a.expression(st.Argument)
+	case *ast.ThrowStatement:
+		a.expression(st.Argument)
+	case *ast.SwitchStatement:
+		a.expression(st.Discriminant)
+		for _, c := range st.Body {
+			a.expression(c.Test)
+			a.statements(c.Consequent)
+		}
+	case *ast.TryStatement:
+		a.statement(st.Body)
+		if st.Catch != nil {
+			a.statement(st.Catch.Body)
+		}
+		if st.Finally != nil {
+			a.statement(st.Finally)
+		}
+	case *ast.WithStatement:
+		a.expression(st.Object)
+		a.statement(st.Body)
+	}
+}
+
+func (a *analyzer) expression(e ast.Expression) {
+	switch e := e.(type) {
+	case nil:
+	case *ast.VariableExpression:
+		a.expression(e.Initializer)
+	case *ast.AssignExpression:
+		a.expression(e.Left)
+		a.expression(e.Right)
+	case *ast.BinaryExpression:
+		a.expression(e.Left)
+		a.expression(e.Right)
+		if e.Comparison {
+			a.comparison(e)
+		}
+	case *ast.UnaryExpression:
+		a.expression(e.Operand)
+	case *ast.ConditionalExpression:
+		a.expression(e.Test)
+		a.expression(e.Consequent)
+		a.expression(e.Alternate)
+	case *ast.SequenceExpression:
+		for _, item := range e.Sequence {
+			a.expression(item)
+		}
+	case *ast.CallExpression:
+		a.expression(e.Callee)
+		for _, arg := range e.ArgumentList {
+			a.expression(arg)
+		}
+	case *ast.NewExpression:
+		a.expression(e.Callee)
+		for _, arg := range e.ArgumentList {
+			a.expression(arg)
+		}
+	case *ast.DotExpression:
+		a.expression(e.Left)
+	case *ast.BracketExpression:
+		a.expression(e.Left)
+		a.expression(e.Member)
+	case *ast.ArrayLiteral:
+		for _, item := range e.Value {
+			a.expression(item)
+		}
+	case *ast.ObjectLiteral:
+		for _, prop := range e.Value {
+			a.expression(prop.Value)
+		}
+	case *ast.FunctionLiteral:
+		a.function(e)
+	}
+}
+
+func analysisLiteralValue(e ast.Expression) Value {
+	switch e := e.(type) {
+	case *ast.NumberLiteral:
+		switch num := e.Value.(type) {
+		case int64:
+			return intToValue(num)
+		case float64:
+			return floatToValue(num)
+		}
+	case *ast.StringLiteral:
+		return newStringValue(e.Value)
+	case *ast.BooleanLiteral:
+		if e.Value {
+			return valueTrue
+		}
+		return valueFalse
+	case *ast.NullLiteral:
+		return _null
+	}
+	return nil
+}
+
+func (a *analyzer) comparison(e *ast.BinaryExpression) {
+	left, right := analysisLiteralValue(e.Left), analysisLiteralValue(e.Right)
+	if left == nil || right == nil {
+		a.typeofComparison(e)
+		return
+	}
+
+	var res bool
+	switch e.Operator {
+	case token.EQUAL:
+		res = left.Equals(right)
+	case token.NOT_EQUAL:
+		res = !left.Equals(right)
+	case token.STRICT_EQUAL:
+		res = left.StrictEquals(right)
+	case token.STRICT_NOT_EQUAL:
+		res = !left.StrictEquals(right)
+	case token.LESS:
+		res = cmp(left, right) == valueTrue
+	case token.GREATER:
+		res = cmp(right, left) == valueTrue
+	case token.LESS_OR_EQUAL:
+		res = cmp(right, left) == valueFalse
+	case token.GREATER_OR_EQUAL:
+		res = cmp(left, right) == valueFalse
+	default:
+		return
+	}
+	a.report(DiagnosticConstantComparison, e.Idx0(), "Comparison is always %t", res)
+}
+
+// typeofComparison checks for comparing the result of typeof with a string it can never return.
+func (a *analyzer) typeofComparison(e *ast.BinaryExpression) {
+	var res bool
+	switch e.Operator {
+	case token.EQUAL, token.STRICT_EQUAL:
+		res = false
+	case token.NOT_EQUAL, token.STRICT_NOT_EQUAL:
+		res = true
+	default:
+		return
+	}
+	operand, str := e.Left, e.Right
+	if _, ok := operand.(*ast.StringLiteral); ok {
+		operand, str = str, operand
+	}
+	if u, ok := operand.(*ast.UnaryExpression); !ok || u.Operator != token.TYPEOF {
+		return
+	}
+	if s, ok := str.(*ast.StringLiteral); ok && !analysisTypeofResults[s.Value] {
+		a.report(DiagnosticConstantComparison, e.Idx0(), "Comparison is always %t, typeof never returns '%s'", res, s.Value)
+	}
+}
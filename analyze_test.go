@@ -0,0 +1,124 @@
+package goja
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	const SCRIPT = `
+var global = 1;
+function f(a, b, c) {
+	var unused = 1, used = 2, written;
+	written = 3;
+	function inner() {}
+	return a + b + used;
+	used += 1;
+}
+function g() {
+	if (1 < 2) {
+		throw new Error();
+	} else {
+		return;
+	}
+	var hoisted;
+	function declared() {}
+	f();
+}
+if (typeof global === "strnig" || "1" == 1) {
+	g(function named(x) { return named; });
+}
+`
+	diags, err := Analyze("test.js", SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type diag struct {
+		kind      DiagnosticKind
+		line, col int
+	}
+	var actual []diag
+	for _, d := range diags {
+		actual = append(actual, diag{d.Kind, d.Position.Line, d.Position.Col})
+	}
+	expected := []diag{
+		{DiagnosticUnused, 3, 18},
+		{DiagnosticUnused, 4, 6},
+		{DiagnosticUnused, 4, 28},
+		{DiagnosticUnused, 6, 11},
+		{DiagnosticUnreachable, 8, 2},
+		{DiagnosticConstantComparison, 11, 6},
+		{DiagnosticUnused, 16, 6},
+		{DiagnosticUnused, 17, 11},
+		{DiagnosticUnreachable, 18, 2},
+		{DiagnosticConstantComparison, 20, 5},
+		{DiagnosticConstantComparison, 20, 35},
+		{DiagnosticUnused, 21, 19},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestAnalyzeDynamicScope(t *testing.T) {
+	const SCRIPT = `
+function f(s) {
+	var x = 1;
+	return eval(s);
+}
+function g(o) {
+	var y;
+	with (o) {
+		y = z;
+	}
+}
+`
+	diags, err := Analyze("test.js", SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Fatalf("Unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestAnalyzeSyntaxError(t *testing.T) {
+	_, err := Analyze("test.js", "var 1;")
+	if _, ok := err.(*CompilerSyntaxError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestAnalyzeCompilerScopes(t *testing.T) {
+	const SCRIPT = `
+function f(a, b) {
+	return arguments.length;
+}
+function g() {
+	var x = 1, y;
+	function inner() {
+		return x;
+	}
+	try {
+		y = inner();
+	} catch (e) {
+	}
+	return y;
+}
+`
+	diags, err := Analyze("test.js", SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Fatalf("Unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestAnalyzeCompilerSyntaxError(t *testing.T) {
+	_, err := Analyze("test.js", "'use strict'; var eval;")
+	if _, ok := err.(*CompilerSyntaxError); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
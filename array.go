@@ -129,22 +129,23 @@ func (a *arrayObject) swap(i, j int64) {
 }
 
 func toIdx(v Value) (idx int64) {
-	idx = -1
 	if idxVal, ok1 := v.(valueInt); ok1 {
 		idx = int64(idxVal)
-	} else {
-		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
-			idx = i
+		if idx >= 0 && idx < math.MaxUint32 {
+			return
 		}
+		return -1
 	}
-	if idx >= 0 && idx < math.MaxUint32 {
-		return
-	}
-	return -1
+	return strToIdx(v.String())
 }
 
+// strToIdx returns the array index represented by s or -1. Only the canonical form is an index, so "01", "+1"
+// and "-0" are ordinary property names.
 func strToIdx(s string) (idx int64) {
 	idx = -1
+	if s == "" || s[0] == '+' || s[0] == '-' || s[0] == '0' && len(s) > 1 {
+		return
+	}
 	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
 		idx = i
 	}
@@ -355,6 +356,7 @@ func (a *arrayObject) expand(idx int64) bool {
 						}
 					}
 				}
+				a.val.runtime.reserveMemory((newcap - int64(cap(a.values))) * memValueSize)
 				newValues := make([]Value, targetLen, newcap)
 				copy(newValues, a.values)
 				a.values = newValues
@@ -492,7 +494,9 @@ func (a *arrayObject) exportType() reflect.Type {
 }
 
 func (a *arrayObject) setValuesFromSparse(items []sparseArrayItem) {
-	a.values = make([]Value, int(items[len(items)-1].idx+1))
+	l := items[len(items)-1].idx + 1
+	a.val.runtime.reserveMemory(l * memValueSize)
+	a.values = make([]Value, int(l))
 	for _, item := range items {
 		a.values[item.idx] = item.value
 	}
@@ -198,6 +198,7 @@ func (a *sparseArrayObject) putIdx(idx int64, val Value, throw bool, origNameStr
 		}
 
 		if a.expand() {
+			a.reserveItem()
 			a.items = append(a.items, sparseArrayItem{})
 			copy(a.items[i+1:], a.items[i:])
 			a.items[i] = sparseArrayItem{
@@ -317,6 +318,13 @@ func (a *sparseArrayObject) hasOwnPropertyStr(name string) bool {
 	}
 }
 
+// reserveItem reserves the memory for the growth of the items when one more is about to be appended.
+func (a *sparseArrayObject) reserveItem() {
+	if len(a.items) == cap(a.items) {
+		a.val.runtime.reserveMemory(int64(cap(a.items)+1) * (memValueSize + 8))
+	}
+}
+
 func (a *sparseArrayObject) expand() bool {
 	if l := len(a.items); l >= 1024 {
 		if int(a.items[l-1].idx)/l < 8 {
@@ -352,6 +360,7 @@ func (a *sparseArrayObject) defineOwnProperty(n Value, descr objectImpl, throw b
 			}
 			if i >= len(a.items) || a.items[i].idx != idx {
 				if a.expand() {
+					a.reserveItem()
 					a.items = append(a.items, sparseArrayItem{})
 					copy(a.items[i+1:], a.items[i:])
 					a.items[i] = sparseArrayItem{
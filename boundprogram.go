@@ -0,0 +1,187 @@
+package goja
+
+// BoundProgram is a Program with some of its free variables bound to fixed values, see Program.Bind().
+// Unlike a Program it may only be run in the Runtime the bound values belong to (unless all of them are primitives).
+type BoundProgram struct {
+	prg *Program
+}
+
+// Bind returns a copy of the Program in which the free identifiers named in vars (i.e. the ones that would otherwise
+// be looked up in the global object) are resolved to the given values. The values are fixed at bind time: they
+// are not affected by later changes to the global object, assigning to a bound identifier throws a TypeError and
+// deleting it returns false. Declarations of the same name inside the code shadow the bound value as usual, so a
+// name declared at the top level of the program (with var or a function declaration) is not bound at all. Code
+// compiled at run time (eval() and the Function constructor) is not bound.
+// This makes it possible to compile shared code once and inject per-tenant objects into it at a lower cost
+// than defining them as globals.
+func (p *Program) Bind(vars map[string]Value) *BoundProgram {
+	// the names declared at the top level are global variables, which the whole program refers to
+	copied := false
+	for _, ins := range p.code {
+		if name, ok := ins.(bindName); ok {
+			if _, exists := vars[string(name)]; exists {
+				if !copied {
+					vars1 := make(map[string]Value, len(vars))
+					for k, v := range vars {
+						vars1[k] = v
+					}
+					vars = vars1
+					copied = true
+				}
+				delete(vars, string(name))
+			}
+		}
+	}
+	return &BoundProgram{
+		prg: p.bind(vars),
+	}
+}
+
+func (p *Program) bind(vars map[string]Value) *Program {
+	p1 := *p
+	p1.code = make([]instruction, len(p.code))
+	for i, ins := range p.code {
+		switch ins := ins.(type) {
+		case getVar1:
+			if v, exists := vars[string(ins)]; exists {
+				p1.code[i] = getBound{name: string(ins), value: v}
+				continue
+			}
+		case getVar1Callee:
+			if v, exists := vars[string(ins)]; exists {
+				p1.code[i] = getBound{name: string(ins), value: v}
+				continue
+			}
+		case resolveVar1:
+			if v, exists := vars[string(ins)]; exists {
+				p1.code[i] = resolveBound{name: string(ins), value: v}
+				continue
+			}
+		case resolveVar1Strict:
+			if v, exists := vars[string(ins)]; exists {
+				p1.code[i] = resolveBound{name: string(ins), value: v, strict: true}
+				continue
+			}
+		case deleteVar:
+			if _, exists := vars[string(ins)]; exists {
+				p1.code[i] = deleteBound(ins)
+				continue
+			}
+		case deleteGlobal:
+			if _, exists := vars[string(ins)]; exists {
+				p1.code[i] = deleteBound(ins)
+				continue
+			}
+		case *newFunc:
+			n := *ins
+			n.prg = ins.prg.bind(vars)
+			p1.code[i] = &n
+			continue
+		}
+		p1.code[i] = ins
+	}
+	return &p1
+}
+
+// RunBoundProgram executes a Program bound with Program.Bind() in the global context.
+func (r *Runtime) RunBoundProgram(p *BoundProgram) (result Value, err error) {
+	return r.RunProgram(p.prg)
+}
+
+// getBound replaces getVar1 and getVar1Callee for a bound name: the scopes are searched first (they may contain
+// a binding created by eval or a with statement), the bound value is used instead of the global object.
+type getBound struct {
+	name  string
+	value Value
+}
+
+func (g getBound) exec(vm *vm) {
+	val := g.value
+	for stash := vm.stash; stash != nil; stash = stash.outer {
+		if v, exists := stash.getByName(g.name, vm); exists {
+			val = v
+			break
+		}
+	}
+	vm.push(val)
+	vm.pc++
+}
+
+// resolveBound replaces resolveVar1 and resolveVar1Strict for a bound name.
+type resolveBound struct {
+	name   string
+	value  Value
+	strict bool
+}
+
+func (r resolveBound) exec(vm *vm) {
+	var ref ref
+	for stash := vm.stash; stash != nil; stash = stash.outer {
+		if stash.obj != nil {
+			if stash.obj.hasPropertyStr(r.name) {
+				ref = &objRef{
+					base:   stash.obj,
+					name:   r.name,
+					strict: r.strict,
+				}
+				goto end
+			}
+		} else {
+			if idx, exists := stash.names[r.name]; exists {
+				ref = &stashRef{
+					v: &stash.values[idx],
+				}
+				goto end
+			}
+		}
+	}
+
+	ref = &boundRef{
+		runtime: vm.r,
+		name:    r.name,
+		value:   r.value,
+	}
+
+end:
+	vm.refStack = append(vm.refStack, ref)
+	vm.pc++
+}
+
+type boundRef struct {
+	runtime *Runtime
+	name    string
+	value   Value
+}
+
+func (r *boundRef) get() Value {
+	return r.value
+}
+
+func (r *boundRef) set(v Value) {
+	r.runtime.typeErrorResult(true, "Assignment to bound variable '%s'", r.name)
+}
+
+func (r *boundRef) refname() string {
+	return r.name
+}
+
+// deleteBound replaces deleteVar and deleteGlobal for a bound name, which cannot be deleted, like a declared variable.
+type deleteBound string
+
+func (d deleteBound) exec(vm *vm) {
+	name := string(d)
+	for stash := vm.stash; stash != nil; stash = stash.outer {
+		var exists bool
+		if stash.obj != nil {
+			exists = stash.obj.hasPropertyStr(name)
+		} else {
+			_, exists = stash.names[name]
+		}
+		if exists {
+			deleteVar(d).exec(vm)
+			return
+		}
+	}
+	vm.push(valueFalse)
+	vm.pc++
+}
@@ -0,0 +1,110 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestProgramBind(t *testing.T) {
+	const SCRIPT = `
+	function get() {
+		return sdk.name;
+	}
+	function shadowed(sdk) {
+		return sdk;
+	}
+	var res = [get(), (function() { return typeof sdk; })(), shadowed(42), limit];
+	sdk = null;
+	`
+
+	prg, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := New()
+	sdk := vm.NewObject()
+	sdk.Set("name", "tenant1")
+	vm.Set("sdk", "global")
+	bound := prg.Bind(map[string]Value{
+		"sdk":   sdk,
+		"limit": vm.ToValue(10),
+	})
+	_, err = vm.RunBoundProgram(bound)
+	if ex, ok := err.(*Exception); !ok || !ex.Value().ToObject(vm).self.getStr("message").SameAs(newStringValue("Assignment to bound variable 'sdk'")) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res := vm.Get("res").Export().([]interface{})
+	if res[0] != "tenant1" || res[1] != "object" || res[2] != int64(42) || res[3] != int64(10) {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+	if v := vm.Get("sdk"); !v.SameAs(newStringValue("global")) {
+		t.Fatalf("Global is modified: %v", v)
+	}
+
+	// The original Program is not affected
+	_, err = vm.RunProgram(prg)
+	if ex, ok := err.(*Exception); !ok || !ex.Value().ToObject(vm).self.getStr("message").SameAs(newStringValue("limit is not defined")) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestProgramBindWith(t *testing.T) {
+	const SCRIPT = `
+	var o = {sdk: 1};
+	var res;
+	with (o) {
+		res = sdk;
+	}
+	res;
+	`
+
+	prg, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := New()
+	v, err := vm.RunBoundProgram(prg.Bind(map[string]Value{"sdk": vm.ToValue(2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.SameAs(intToValue(1)) {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestProgramBindDeclared(t *testing.T) {
+	const SCRIPT = `
+	var sdk = "decl";
+	function f() {
+		return sdk;
+	}
+	var res = [f(), limit, delete limit, limit];
+	(function() {
+		var o = {limit: 1};
+		with (o) {
+			res.push(delete limit, typeof limit);
+		}
+	})();
+	res;
+	`
+
+	prg, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := New()
+	v, err := vm.RunBoundProgram(prg.Bind(map[string]Value{
+		"sdk":   vm.ToValue("bound"),
+		"limit": vm.ToValue(10),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := v.Export().([]interface{})
+	if len(res) != 6 || res[0] != "decl" || res[1] != int64(10) || res[2] != false || res[3] != int64(10) || res[4] != true || res[5] != "number" {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+	if s := vm.Get("sdk"); s == nil || s.String() != "decl" {
+		t.Fatalf("Unexpected global: %v", s)
+	}
+}
@@ -0,0 +1,199 @@
+package goja
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// The web compatibility features of Annex B which are not installed by default, see Runtime.SetAnnexB().
+
+// escapeUnescaped is the set of the characters escape() leaves as they are.
+var escapeUnescaped = func() (set [128]bool) {
+	for _, c := range "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789@*_+-./" {
+		set[c] = true
+	}
+	return
+}()
+
+func (r *Runtime) builtin_escape(call FunctionCall) Value {
+	s := call.Argument(0).ToString()
+	const hex = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i, l := int64(0), s.length(); i < l; i++ {
+		c := s.charAt(i)
+		switch {
+		case c < 128 && escapeUnescaped[c]:
+			buf.WriteByte(byte(c))
+		case c < 256:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&15])
+		default:
+			buf.WriteString("%u")
+			buf.WriteByte(hex[c>>12])
+			buf.WriteByte(hex[c>>8&15])
+			buf.WriteByte(hex[c>>4&15])
+			buf.WriteByte(hex[c&15])
+		}
+	}
+	return asciiString(buf.String())
+}
+
+// unhexUnits returns the value of the hex digits in s[start:start+n] or -1 if they are not all hex digits.
+func unhexUnits(s valueString, start, n int64) rune {
+	if start+n > s.length() {
+		return -1
+	}
+	var v rune
+	for i := start; i < start+n; i++ {
+		c := s.charAt(i)
+		if c >= 128 || !ishex(byte(c)) {
+			return -1
+		}
+		v = v<<4 | rune(unhex(byte(c)))
+	}
+	return v
+}
+
+func (r *Runtime) builtin_unescape(call FunctionCall) Value {
+	s := call.Argument(0).ToString()
+	l := s.length()
+	units := make([]uint16, 0, l)
+	isUnicode := false
+	for i := int64(0); i < l; i++ {
+		c := s.charAt(i)
+		if c == '%' {
+			if i+1 < l && s.charAt(i+1) == 'u' {
+				if v := unhexUnits(s, i+2, 4); v >= 0 {
+					c = v
+					i += 5
+				}
+			} else if v := unhexUnits(s, i+1, 2); v >= 0 {
+				c = v
+				i += 2
+			}
+		}
+		if c >= 128 {
+			isUnicode = true
+		}
+		units = append(units, uint16(c))
+	}
+	if isUnicode {
+		return unicodeString(units)
+	}
+	return asciiString(string(utf16.Decode(units)))
+}
+
+// createHTML implements CreateHTML() of the String.prototype HTML methods.
+func (r *Runtime) createHTML(call FunctionCall, tag, attribute string) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	var buf strings.Builder
+	buf.WriteByte('<')
+	buf.WriteString(tag)
+	if attribute != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(attribute)
+		buf.WriteString(`="`)
+		buf.WriteString(strings.Replace(call.Argument(0).String(), `"`, "&quot;", -1))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+	prefix := newStringValue(buf.String())
+	return prefix.concat(s).concat(asciiString("</" + tag + ">"))
+}
+
+// stringHTMLMethods lists the String.prototype HTML methods with their tags and attributes.
+var stringHTMLMethods = []struct {
+	name, tag, attribute string
+}{
+	{"anchor", "a", "name"},
+	{"big", "big", ""},
+	{"blink", "blink", ""},
+	{"bold", "b", ""},
+	{"fixed", "tt", ""},
+	{"fontcolor", "font", "color"},
+	{"fontsize", "font", "size"},
+	{"italics", "i", ""},
+	{"link", "a", "href"},
+	{"small", "small", ""},
+	{"strike", "strike", ""},
+	{"sub", "sub", ""},
+	{"sup", "sup", ""},
+}
+
+func (r *Runtime) regexpproto_compile(call FunctionCall) Value {
+	this, ok := r.toObject(call.This).self.(*regexpObject)
+	if !ok {
+		panic(r.NewTypeError("Method RegExp.prototype.compile called on incompatible receiver %s", call.This.String()))
+	}
+	var pattern valueString
+	var flags string
+	patternArg, flagsArg := call.Argument(0), call.Argument(1)
+	if obj, ok := patternArg.(*Object); ok {
+		if re, ok := obj.self.(*regexpObject); ok {
+			if flagsArg != _undefined {
+				panic(r.NewTypeError("Cannot supply flags when constructing one RegExp from another"))
+			}
+			pattern, flags = re.source, re.flags()
+		}
+	}
+	if pattern == nil {
+		if patternArg != _undefined {
+			pattern = patternArg.ToString()
+		} else {
+			pattern = stringEmpty
+		}
+		if flagsArg != _undefined {
+			flags = flagsArg.String()
+		}
+	}
+	re := r.newRegExp(pattern, flags, r.global.RegExpPrototype).self.(*regexpObject)
+	this.pattern = re.pattern
+	this.groupNames = re.groupNames
+	this.lookbehindGroups = re.lookbehindGroups
+	this.source = re.source
+	this.regexpFlagSet = re.regexpFlagSet
+	this.putStr("lastIndex", intToValue(0), true)
+	return this.val
+}
+
+// SetAnnexB enables (or disables) the web compatibility features of Annex B of the specification which are not
+// available by default: the global escape() and unescape() functions, the String.prototype HTML methods (anchor(),
+// big(), blink(), bold(), fixed(), fontcolor(), fontsize(), italics(), link(), small(), strike(), sub() and sup())
+// and RegExp.prototype.compile(). String.prototype.substr() is always available.
+// Function declarations in blocks don't need this mode: as there is no block scope, they are always bound in the
+// enclosing function (or the global scope), as it has been done by the browsers before ES2015.
+// Disabling deletes the properties, so it should be done before running any scripts.
+func (r *Runtime) SetAnnexB(enabled bool) {
+	if enabled == r.annexB {
+		return
+	}
+	r.annexB = enabled
+	global := r.globalObject.self
+	stringProto := r.global.StringPrototype.self
+	regexpProto := r.global.RegExpPrototype.self
+	if !enabled {
+		global.deleteStr("escape", false)
+		global.deleteStr("unescape", false)
+		for _, m := range stringHTMLMethods {
+			stringProto.deleteStr(m.name, false)
+		}
+		regexpProto.deleteStr("compile", false)
+		return
+	}
+
+	global._putProp("escape", r.newNativeFunc(r.builtin_escape, nil, "escape", nil, 1), true, false, true)
+	global._putProp("unescape", r.newNativeFunc(r.builtin_unescape, nil, "unescape", nil, 1), true, false, true)
+	for _, m := range stringHTMLMethods {
+		m := m
+		length := 0
+		if m.attribute != "" {
+			length = 1
+		}
+		stringProto._putProp(m.name, r.newNativeFunc(func(call FunctionCall) Value {
+			return r.createHTML(call, m.tag, m.attribute)
+		}, nil, m.name, nil, length), true, false, true)
+	}
+	regexpProto._putProp("compile", r.newNativeFunc(r.regexpproto_compile, nil, "compile", nil, 2), true, false, true)
+}
@@ -0,0 +1,50 @@
+package goja
+
+import "testing"
+
+func TestAnnexB(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(escape("a b+é€/"), "a%20b+%E9%u20AC/", "escape");
+	assert.sameValue(unescape("a%20b+%E9%u20AC/%zz%u12"), "a b+é€/%zz%u12", "unescape");
+	assert.sameValue(unescape(escape("😀")), "😀", "surrogates");
+
+	assert.sameValue("x".anchor('a"b'), '<a name="a&quot;b">x</a>', "anchor");
+	assert.sameValue("x".bold(), "<b>x</b>", "bold");
+	assert.sameValue("x".link("http://example.com"), '<a href="http://example.com">x</a>', "link");
+	assert.sameValue(String.prototype.fontsize.length, 1, "length");
+
+	var re = /a/g;
+	re.lastIndex = 3;
+	assert.sameValue(re.compile("b+", "i"), re, "compile returns this");
+	assert.sameValue(re.source, "b+", "source");
+	assert.sameValue(re.global, false, "global");
+	assert.sameValue(re.ignoreCase, true, "ignoreCase");
+	assert.sameValue(re.lastIndex, 0, "lastIndex");
+	assert(re.test("BB"), "test");
+	re.compile(/c/m);
+	assert.sameValue(re.multiline, true, "from regexp");
+
+	var thrown = false;
+	try {
+		re.compile(/c/, "g");
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	assert(thrown, "flags with a regexp");
+	`
+
+	r := New()
+	r.SetAnnexB(true)
+	if _, err := r.RunString(TESTLIB + SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+
+	r.SetAnnexB(false)
+	v, err := r.RunString(`typeof escape + typeof "".bold + typeof /a/.compile + typeof "".substr`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "undefinedundefinedundefinedfunction" {
+		t.Fatal(s)
+	}
+}
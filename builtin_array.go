@@ -3,7 +3,6 @@ package goja
 import (
 	"bytes"
 	"sort"
-	"strings"
 )
 
 func (r *Runtime) builtin_newArray(args []Value, proto *Object) *Object {
@@ -104,6 +103,7 @@ func (r *Runtime) arrayproto_join(call FunctionCall) Value {
 	}
 
 	var buf bytes.Buffer
+	mem := memoryCharger{r: r}
 
 	element0 := o.self.get(intToValue(0))
 	if element0 != nil && element0 != _undefined && element0 != _null {
@@ -116,6 +116,7 @@ func (r *Runtime) arrayproto_join(call FunctionCall) Value {
 		if element != nil && element != _undefined && element != _null {
 			buf.WriteString(element.String())
 		}
+		mem.charge(buf.Len())
 	}
 
 	return newStringValue(buf.String())
@@ -191,7 +192,9 @@ func (r *Runtime) arrayproto_toLocaleString(call FunctionCall) Value {
 
 }
 
-func (r *Runtime) arrayproto_concat_append(a *Object, item Value) {
+// arrayproto_concat_append appends item (or its elements if it's an array) to a, the elements which are set are
+// charged to mem.
+func (r *Runtime) arrayproto_concat_append(a *Object, item Value, mem *memoryCharger) {
 	descr := r.NewObject().self
 	descr.putStr("writable", valueTrue, false)
 	descr.putStr("enumerable", valueTrue, false)
@@ -203,6 +206,7 @@ func (r *Runtime) arrayproto_concat_append(a *Object, item Value) {
 			for i := int64(0); i < length; i++ {
 				v := obj.self.get(intToValue(i))
 				if v != nil {
+					mem.charge(mem.charged + memValueSize)
 					descr.putStr("value", v, false)
 					a.self.defineOwnProperty(intToValue(aLength), descr, false)
 					aLength++
@@ -214,15 +218,18 @@ func (r *Runtime) arrayproto_concat_append(a *Object, item Value) {
 			return
 		}
 	}
+	mem.charge(mem.charged + memValueSize)
 	descr.putStr("value", item, false)
 	a.self.defineOwnProperty(intToValue(aLength), descr, false)
 }
 
 func (r *Runtime) arrayproto_concat(call FunctionCall) Value {
 	a := r.newArrayValues(nil)
-	r.arrayproto_concat_append(a, call.This.ToObject(r))
+	// the result is not reachable by the scripts until it's returned, so the memory measurements don't see it
+	mem := memoryCharger{r: r}
+	r.arrayproto_concat_append(a, call.This.ToObject(r), &mem)
 	for _, item := range call.Arguments {
-		r.arrayproto_concat_append(a, item)
+		r.arrayproto_concat_append(a, item, &mem)
 	}
 	return a
 }
@@ -317,9 +324,12 @@ func (r *Runtime) arrayproto_splice(call FunctionCall) Value {
 
 	actualDeleteCount := min(max(call.Argument(1).ToInteger(), 0), length-actualStart)
 
+	// the result is not reachable by the scripts until it's returned, so the memory measurements don't see it
+	mem := memoryCharger{r: r}
 	for k := int64(0); k < actualDeleteCount; k++ {
 		from := intToValue(k + actualStart)
 		if o.self.hasProperty(from) {
+			mem.charge(mem.charged + memValueSize)
 			a.self.put(intToValue(k), o.self.get(from), false)
 		}
 	}
@@ -533,6 +543,7 @@ func (r *Runtime) arrayproto_map(call FunctionCall) Value {
 		}
 		a := r.newArrayObject()
 		a._setLengthInt(length, true)
+		r.reserveMemory(length * memValueSize)
 		a.values = make([]Value, length)
 		for k := int64(0); k < length; k++ {
 			idx := intToValue(k)
@@ -738,6 +749,9 @@ func (r *Runtime) arrayproto_shift(call FunctionCall) Value {
 		o.self.putStr("length", intToValue(0), true)
 		return _undefined
 	}
+	if r.perfWarnings != nil {
+		r.checkShiftPerf(length)
+	}
 	first := o.self.get(intToValue(0))
 	for i := int64(1); i < length; i++ {
 		v := o.self.get(intToValue(i))
@@ -755,6 +769,183 @@ func (r *Runtime) arrayproto_shift(call FunctionCall) Value {
 	return first
 }
 
+// relToIdx converts a relative index argument (negative values count from the end) to an index in [0, length].
+func relToIdx(rel, length int64) int64 {
+	if rel < 0 {
+		return max(length+rel, 0)
+	}
+	return min(rel, length)
+}
+
+// arrayFind returns the first element (the last one if fromEnd is true) of this for which the predicate returns
+// true and its index, or undefined and -1.
+func (r *Runtime) arrayFind(call FunctionCall, fromEnd bool) (Value, int64) {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	predicate := r.toCallable(call.Argument(0))
+	fc := FunctionCall{
+		This:      call.Argument(1),
+		Arguments: []Value{nil, nil, o},
+	}
+	k, end, step := int64(0), length, int64(1)
+	if fromEnd {
+		k, end, step = length-1, -1, -1
+	}
+	for ; k != end; k += step {
+		idx := intToValue(k)
+		val := nilSafe(o.self.get(idx))
+		fc.Arguments[0] = val
+		fc.Arguments[1] = idx
+		if predicate(fc).ToBoolean() {
+			return val, k
+		}
+	}
+	return _undefined, -1
+}
+
+func (r *Runtime) arrayproto_find(call FunctionCall) Value {
+	val, _ := r.arrayFind(call, false)
+	return val
+}
+
+func (r *Runtime) arrayproto_findIndex(call FunctionCall) Value {
+	_, idx := r.arrayFind(call, false)
+	return intToValue(idx)
+}
+
+func (r *Runtime) arrayproto_findLast(call FunctionCall) Value {
+	val, _ := r.arrayFind(call, true)
+	return val
+}
+
+func (r *Runtime) arrayproto_findLastIndex(call FunctionCall) Value {
+	_, idx := r.arrayFind(call, true)
+	return intToValue(idx)
+}
+
+func (r *Runtime) arrayproto_at(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	k := call.Argument(0).ToInteger()
+	if k < 0 {
+		k += length
+	}
+	if k < 0 || k >= length {
+		return _undefined
+	}
+	return nilSafe(o.self.get(intToValue(k)))
+}
+
+func (r *Runtime) arrayproto_includes(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	if length == 0 {
+		return valueFalse
+	}
+	searchElement := call.Argument(0)
+	for n := relToIdx(call.Argument(1).ToInteger(), length); n < length; n++ {
+		val := nilSafe(o.self.get(intToValue(n)))
+		// SameValueZero
+		if searchElement.StrictEquals(val) || searchElement.SameAs(val) {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+
+func (r *Runtime) arrayproto_fill(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	value := call.Argument(0)
+	start := relToIdx(call.Argument(1).ToInteger(), length)
+	end := length
+	if endArg := call.Argument(2); endArg != _undefined {
+		end = relToIdx(endArg.ToInteger(), length)
+	}
+	for ; start < end; start++ {
+		o.self.put(intToValue(start), value, true)
+	}
+	return o
+}
+
+func (r *Runtime) arrayproto_copyWithin(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	length := toLength(o.self.getStr("length"))
+	to := relToIdx(call.Argument(0).ToInteger(), length)
+	from := relToIdx(call.Argument(1).ToInteger(), length)
+	end := length
+	if endArg := call.Argument(2); endArg != _undefined {
+		end = relToIdx(endArg.ToInteger(), length)
+	}
+	count := min(end-from, length-to)
+	dir := int64(1)
+	if from < to && to < from+count {
+		// the ranges overlap, copy backwards
+		dir = -1
+		from += count - 1
+		to += count - 1
+	}
+	for ; count > 0; count-- {
+		fromKey := intToValue(from)
+		toKey := intToValue(to)
+		if o.self.hasProperty(fromKey) {
+			o.self.put(toKey, nilSafe(o.self.get(fromKey)), true)
+		} else {
+			o.self.delete(toKey, true)
+		}
+		from += dir
+		to += dir
+	}
+	return o
+}
+
+// maxFlattenDepth limits the nesting flat() descends into, so that flattening a cyclic array with an infinite
+// depth throws instead of overflowing the Go stack.
+const maxFlattenDepth = 10000
+
+// flattenIntoArray appends the elements of source to target, flattening the nested arrays up to depth levels.
+// If mapFn is not nil the top level elements are mapped first.
+func (r *Runtime) flattenIntoArray(target []Value, source *Object, depth int64, level int, mapFn func(FunctionCall) Value, thisArg Value) []Value {
+	if level > maxFlattenDepth {
+		panic(r.newError(r.global.RangeError, "Maximum array nesting depth exceeded"))
+	}
+	length := toLength(source.self.getStr("length"))
+	for k := int64(0); k < length; k++ {
+		idx := intToValue(k)
+		if !source.self.hasProperty(idx) {
+			continue
+		}
+		val := nilSafe(source.self.get(idx))
+		if mapFn != nil {
+			val = mapFn(FunctionCall{
+				This:      thisArg,
+				Arguments: []Value{val, idx, source},
+			})
+		}
+		if obj, ok := val.(*Object); ok && depth > 0 && isArray(obj) {
+			target = r.flattenIntoArray(target, obj, depth-1, level+1, nil, nil)
+		} else {
+			target = append(target, val)
+		}
+	}
+	return target
+}
+
+func (r *Runtime) arrayproto_flat(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	depth := int64(1)
+	if depthArg := call.Argument(0); depthArg != _undefined {
+		depth = depthArg.ToInteger()
+	}
+	return r.newArrayValues(r.flattenIntoArray(nil, o, depth, 0, nil, nil))
+}
+
+func (r *Runtime) arrayproto_flatMap(call FunctionCall) Value {
+	o := call.This.ToObject(r)
+	mapFn := r.toCallable(call.Argument(0))
+	return r.newArrayValues(r.flattenIntoArray(nil, o, 1, 0, mapFn, call.Argument(1)))
+}
+
 func (r *Runtime) array_isArray(call FunctionCall) Value {
 	if o, ok := call.Argument(0).(*Object); ok {
 		if isArray(o) {
@@ -764,6 +955,93 @@ func (r *Runtime) array_isArray(call FunctionCall) Value {
 	return valueFalse
 }
 
+func (r *Runtime) array_from(call FunctionCall) Value {
+	items := call.Argument(0)
+	var mapFn func(FunctionCall) Value
+	if fn := call.Argument(1); fn != _undefined {
+		mapFn = r.toCallable(fn)
+	}
+	fc := FunctionCall{
+		This:      call.Argument(2),
+		Arguments: []Value{nil, nil},
+	}
+	ctor := r.arrayThisConstructor(call.This)
+	var a *Object
+	var values []Value
+	// the values are not reachable by the scripts until they're returned, so the memory measurements don't see them
+	mem := memoryCharger{r: r}
+	add := func(val Value) {
+		if mapFn != nil {
+			fc.Arguments[0] = val
+			fc.Arguments[1] = intToValue(int64(len(values)))
+			val = mapFn(fc)
+		}
+		mem.charge((len(values) + 1) * memValueSize)
+		values = append(values, val)
+	}
+	// Symbol.iterator is not supported, so strings are the only iterables, the other values are treated as
+	// array-likes (this includes arrays and wrapped Go slices).
+	if s, ok := items.assertString(); ok {
+		if ctor != nil {
+			a = ctor(nil)
+		}
+		next := stringCodePoints(s)
+		for val, ok := next(); ok; val, ok = next() {
+			add(val)
+		}
+	} else {
+		o := items.ToObject(r)
+		length := toLength(o.self.getStr("length"))
+		if ctor != nil {
+			a = ctor([]Value{intToValue(length)})
+		}
+		for k := int64(0); k < length; k++ {
+			val := o.self.get(intToValue(k))
+			if val == nil {
+				val = _undefined
+			}
+			add(val)
+		}
+	}
+	if a != nil {
+		return r.setArrayLikeValues(a, values)
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) array_of(call FunctionCall) Value {
+	if ctor := r.arrayThisConstructor(call.This); ctor != nil {
+		a := ctor([]Value{intToValue(int64(len(call.Arguments)))})
+		return r.setArrayLikeValues(a, call.Arguments)
+	}
+	values := make([]Value, len(call.Arguments))
+	copy(values, call.Arguments)
+	return r.newArrayValues(values)
+}
+
+// arrayThisConstructor returns the constructor Array.from() and Array.of() have been called on, or nil if they
+// create a plain array (when called on Array itself or on a value that is not a constructor).
+func (r *Runtime) arrayThisConstructor(this Value) func(args []Value) *Object {
+	if obj, ok := this.(*Object); ok && obj != r.global.Array {
+		return r.toConstructor(obj)
+	}
+	return nil
+}
+
+// setArrayLikeValues defines the values as the elements of a and sets its length.
+func (r *Runtime) setArrayLikeValues(a *Object, values []Value) *Object {
+	for i, val := range values {
+		descr := r.NewObject().self
+		descr.putStr("value", val, false)
+		descr.putStr("writable", valueTrue, false)
+		descr.putStr("enumerable", valueTrue, false)
+		descr.putStr("configurable", valueTrue, false)
+		a.self.defineOwnProperty(intToValue(int64(i)), descr, true)
+	}
+	a.self.putStr("length", intToValue(int64(len(values))), true)
+	return a
+}
+
 func (r *Runtime) createArrayProto(val *Object) objectImpl {
 	o := &arrayObject{
 		baseObject: baseObject{
@@ -797,6 +1075,16 @@ func (r *Runtime) createArrayProto(val *Object) objectImpl {
 	o._putProp("filter", r.newNativeFunc(r.arrayproto_filter, nil, "filter", nil, 1), true, false, true)
 	o._putProp("reduce", r.newNativeFunc(r.arrayproto_reduce, nil, "reduce", nil, 1), true, false, true)
 	o._putProp("reduceRight", r.newNativeFunc(r.arrayproto_reduceRight, nil, "reduceRight", nil, 1), true, false, true)
+	o._putProp("find", r.newNativeFunc(r.arrayproto_find, nil, "find", nil, 1), true, false, true)
+	o._putProp("findIndex", r.newNativeFunc(r.arrayproto_findIndex, nil, "findIndex", nil, 1), true, false, true)
+	o._putProp("findLast", r.newNativeFunc(r.arrayproto_findLast, nil, "findLast", nil, 1), true, false, true)
+	o._putProp("findLastIndex", r.newNativeFunc(r.arrayproto_findLastIndex, nil, "findLastIndex", nil, 1), true, false, true)
+	o._putProp("includes", r.newNativeFunc(r.arrayproto_includes, nil, "includes", nil, 1), true, false, true)
+	o._putProp("fill", r.newNativeFunc(r.arrayproto_fill, nil, "fill", nil, 1), true, false, true)
+	o._putProp("copyWithin", r.newNativeFunc(r.arrayproto_copyWithin, nil, "copyWithin", nil, 2), true, false, true)
+	o._putProp("flat", r.newNativeFunc(r.arrayproto_flat, nil, "flat", nil, 0), true, false, true)
+	o._putProp("flatMap", r.newNativeFunc(r.arrayproto_flatMap, nil, "flatMap", nil, 1), true, false, true)
+	o._putProp("at", r.newNativeFunc(r.arrayproto_at, nil, "at", nil, 1), true, false, true)
 
 	return o
 }
@@ -804,6 +1092,8 @@ func (r *Runtime) createArrayProto(val *Object) objectImpl {
 func (r *Runtime) createArray(val *Object) objectImpl {
 	o := r.newNativeFuncConstructObj(val, r.builtin_newArray, "Array", r.global.ArrayPrototype, 1)
 	o._putProp("isArray", r.newNativeFunc(r.array_isArray, nil, "isArray", nil, 1), true, false, true)
+	o._putProp("from", r.newNativeFunc(r.array_from, nil, "from", nil, 1), true, false, true)
+	o._putProp("of", r.newNativeFunc(r.array_of, nil, "of", nil, 0), true, false, true)
 	return o
 }
 
@@ -862,7 +1152,7 @@ func (ctx *arraySortCtx) sortCompare(x, y Value) int {
 			Arguments: []Value{x, y},
 		}).ToInteger())
 	}
-	return strings.Compare(x.String(), y.String())
+	return x.ToString().compareTo(y.ToString())
 }
 
 // sort.Interface
@@ -0,0 +1,167 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestArrayFrom(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Array.from({length: 3, 0: "a", 2: "c"}).join(), "a,,c", "array-like");
+	assert.sameValue(Array.from({length: 2})[1], undefined, "holes");
+	assert.sameValue(Array.from([1, 2, 3], function(v, i) { return v * this.m + i; }, {m: 10}).join(), "10,21,32", "mapFn");
+	var s = Array.from("a😀b");
+	assert.sameValue(s.length, 3, "string length");
+	assert.sameValue(s[1], "😀", "surrogate pair");
+	assert.sameValue(Array.from(5).length, 0, "number");
+	assert(Array.isArray((function() { return Array.from(arguments); })(1)), "arguments");
+	function throwsTypeError(f) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof TypeError;
+		}
+		return false;
+	}
+	assert(throwsTypeError(function() { Array.from(null); }), "null");
+	assert(throwsTypeError(function() { Array.from([], {}); }), "mapFn not callable");
+	assert.sameValue(Array.from.length, 1, "length");
+
+	assert.sameValue(Array.of(7).length, 1, "of single");
+	assert.sameValue(Array.of(1, "a", undefined).length, 3, "of length");
+	assert.sameValue(Array.of().length, 0, "of empty");
+	assert.sameValue(Array.of.length, 0, "of.length");
+
+	function C(n) {
+		this.args = arguments.length;
+		this.n = n;
+	}
+	var c = Array.from.call(C, [1, 2]);
+	assert(c instanceof C, "from constructor");
+	assert.sameValue(c.n, 2, "from constructor argument");
+	assert.sameValue(c[1], 2, "from constructor element");
+	assert.sameValue(c.length, 2, "from constructor length");
+	assert.sameValue(Array.from.call(C, "ab").args, 0, "from constructor with a string");
+	c = Array.of.call(C, 1);
+	assert(c instanceof C, "of constructor");
+	assert.sameValue(c[0], 1, "of constructor element");
+	assert.sameValue(c.length, 1, "of constructor length");
+	assert(Array.isArray(Array.of.call(Math.max, 1)), "of non-constructor");
+	assert(Array.isArray(Array.from.call(undefined, [1])), "from without this");
+
+	assert.sameValue(Array.from(goSlice, function(v) { return v * 2; }).join(), "2,4,6", "Go slice");
+	assert.sameValue(Array.from(goStrings).join("-"), "x-y", "Go reflect slice");
+	`
+
+	vm := New()
+	vm.Set("goSlice", []interface{}{1, 2, 3})
+	vm.Set("goStrings", []string{"x", "y"})
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArrayFindIncludes(t *testing.T) {
+	const SCRIPT = `
+	var a = [1, , 3, NaN, -0];
+	var visited = [];
+	assert.sameValue(a.find(function(v, i) { visited.push(i); return v > 1; }), 3, "find");
+	assert.sameValue(visited.join(), "0,1,2", "holes are visited");
+	assert.sameValue(a.findIndex(function(v) { return v === undefined; }), 1, "findIndex hole");
+	assert.sameValue(a.find(function() { return false; }), undefined, "find not found");
+	assert.sameValue(a.findIndex(function() { return false; }), -1, "findIndex not found");
+	assert.sameValue([1].find(function() { return this.x; }, {x: true}), 1, "thisArg");
+
+	assert(a.includes(NaN), "NaN");
+	assert(a.includes(0), "-0");
+	assert(a.includes(undefined), "hole");
+	assert(!a.includes(1, 1), "fromIndex");
+	assert(a.includes(-0, -1), "negative fromIndex");
+	assert(a.indexOf(NaN) === -1, "indexOf NaN");
+	assert(Array.prototype.includes.call("abc", "b"), "generic");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrayFillCopyWithin(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue([1, 2, 3, 4].fill(0).join(), "0,0,0,0", "fill");
+	assert.sameValue([1, 2, 3, 4].fill(0, 1, -1).join(), "1,0,0,4", "fill range");
+	assert.sameValue([1, 2, 3].fill(0, -5, 10).join(), "0,0,0", "fill clamped");
+	assert.sameValue(new Array(3).fill(7).join(), "7,7,7", "fill sparse");
+	var o = {length: 2};
+	assert.sameValue(Array.prototype.fill.call(o, "x"), o, "fill returns this");
+	assert.sameValue(o[1], "x", "fill array-like");
+
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(0, 3).join(), "4,5,3,4,5", "copyWithin");
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(1, 0).join(), "1,1,2,3,4", "overlapping forwards");
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(0, 1, 3).join(), "2,3,3,4,5", "overlapping backwards");
+	assert.sameValue([1, 2, 3, 4, 5].copyWithin(-2, -4, -3).join(), "1,2,3,2,5", "negative");
+	var s = [1, , 3].copyWithin(0, 1);
+	assert(!s.hasOwnProperty(0), "hole is copied");
+	assert.sameValue(s[1], 3, "copied");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrayFlat(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(JSON.stringify([1, [2, [3, [4]]]].flat()), "[1,2,[3,[4]]]", "default depth");
+	assert.sameValue(JSON.stringify([1, [2, [3, [4]]]].flat(2)), "[1,2,3,[4]]", "depth 2");
+	assert.sameValue(JSON.stringify([1, [2, [3, [4]]]].flat(Infinity)), "[1,2,3,4]", "Infinity");
+	assert.sameValue(JSON.stringify([1, [2]].flat(0)), "[1,[2]]", "depth 0");
+	assert.sameValue(JSON.stringify([1, [2]].flat(-1)), "[1,[2]]", "negative depth");
+	var sparse = [1, , [2, , 3]].flat();
+	assert.sameValue(sparse.length, 3, "holes are skipped");
+	assert.sameValue(sparse.join(), "1,2,3", "holes are skipped");
+	assert.sameValue([{length: 1, 0: "x"}, "ab"].flat().length, 2, "non-arrays are not flattened");
+	var cyclic = [1];
+	cyclic.push(cyclic);
+	assert.sameValue(cyclic.flat(2).length, 4, "cyclic with finite depth");
+	var thrown = false;
+	try {
+		cyclic.flat(Infinity);
+	} catch (e) {
+		thrown = e instanceof RangeError;
+	}
+	assert(thrown, "cyclic with infinite depth");
+
+	assert.sameValue(JSON.stringify([1, 2].flatMap(function(v, i) { return [v, [i * this.m]]; }, {m: 10})), "[1,[0],2,[10]]", "flatMap");
+	assert.sameValue([1, , 2].flatMap(function(v) { return v; }).join(), "1,2", "flatMap holes");
+	thrown = false;
+	try {
+		[1].flatMap();
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	assert(thrown, "flatMap without callback");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestArrayAtFindLast(t *testing.T) {
+	const SCRIPT = `
+	var a = [1, 2, , 4];
+	assert.sameValue(a.at(0), 1, "0");
+	assert.sameValue(a.at(-1), 4, "-1");
+	assert.sameValue(a.at(-4), 1, "-4");
+	assert.sameValue(a.at(2), undefined, "hole");
+	assert.sameValue(a.at(4), undefined, "4");
+	assert.sameValue(a.at(-5), undefined, "-5");
+	assert.sameValue(a.at(1.7), 2, "fraction");
+	assert.sameValue(Array.prototype.at.call({length: 2, 1: "x"}, -1), "x", "array-like");
+
+	var visited = [];
+	assert.sameValue(a.findLast(function(v, i) { visited.push(i); return v < 3; }), 2, "findLast");
+	assert.sameValue(visited.join(), "3,2,1", "visited backwards");
+	assert.sameValue(a.findLastIndex(function(v) { return v === undefined; }), 2, "findLastIndex hole");
+	assert.sameValue(a.findLastIndex(function(v) { return v === 1; }), 0, "findLastIndex");
+	assert.sameValue(a.findLast(function() { return false; }), undefined, "findLast not found");
+	assert.sameValue([].findLastIndex(function() { return true; }), -1, "empty");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
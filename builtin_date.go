@@ -218,7 +218,7 @@ func (r *Runtime) dateproto_toLocaleString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(datetimeLayout_en_GB))
+			return r.localeFormatDate(d.time, call.Argument(0), call.Argument(1), "any", "all")
 		} else {
 			return stringInvalidDate
 		}
@@ -231,7 +231,7 @@ func (r *Runtime) dateproto_toLocaleDateString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(dateLayout_en_GB))
+			return r.localeFormatDate(d.time, call.Argument(0), call.Argument(1), "date", "date")
 		} else {
 			return stringInvalidDate
 		}
@@ -244,7 +244,7 @@ func (r *Runtime) dateproto_toLocaleTimeString(call FunctionCall) Value {
 	obj := r.toObject(call.This)
 	if d, ok := obj.self.(*dateObject); ok {
 		if d.isSet {
-			return asciiString(d.time.Format(timeLayout_en_GB))
+			return r.localeFormatDate(d.time, call.Argument(0), call.Argument(1), "time", "time")
 		} else {
 			return stringInvalidDate
 		}
@@ -1,5 +1,49 @@
 package goja
 
+import "strings"
+
+// ErrorStackFormatter returns the value of the 'stack' property of a newly created error object, given the frames
+// of the call stack at the time of its creation (the innermost first), see Runtime.SetErrorStackFormatter().
+type ErrorStackFormatter func(err *Object, frames []StackFrame) string
+
+// SetErrorStackFormatter sets the function that formats the 'stack' property of the error objects. By default, it
+// is formatted like in V8: the result of the error's toString() followed by a line for each frame:
+//
+//	TypeError: x is not a function
+//	    at f (test.js:2:5)
+//	    at test.js:4:1
+//
+// The StackFrameFilter (see SetStackFrameFilter()) is applied to the frames first. Setting nil restores the
+// default format.
+func (r *Runtime) SetErrorStackFormatter(f ErrorStackFormatter) {
+	r.errorStackFormatter = f
+}
+
+func (r *Runtime) formatErrorStack(err *Object, frames []StackFrame) string {
+	var b strings.Builder
+	b.WriteString(r.error_toString(FunctionCall{This: err}).String())
+	for _, frame := range frames {
+		b.WriteString("\n    at ")
+		b.WriteString(frame.String())
+	}
+	return b.String()
+}
+
+// installErrorStack sets the 'stack' property of a new error object to the current call stack.
+func (r *Runtime) installErrorStack(obj *baseObject) {
+	frames := r.CaptureCallStack(0)
+	if r.stackFrameFilter != nil {
+		frames = r.stackFrameFilter(frames)
+	}
+	var stack string
+	if r.errorStackFormatter != nil {
+		stack = r.errorStackFormatter(obj.val, frames)
+	} else {
+		stack = r.formatErrorStack(obj.val, frames)
+	}
+	obj._putProp("stack", newStringValue(stack), true, false, true)
+}
+
 func (r *Runtime) initErrors() {
 	r.global.ErrorPrototype = r.NewObject()
 	o := r.global.ErrorPrototype.self
@@ -11,49 +55,56 @@ func (r *Runtime) initErrors() {
 	o = r.global.Error.self
 	r.addToGlobal("Error", r.global.Error)
 
-	r.global.TypeErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.TypeErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.TypeErrorPrototype.self
 	o._putProp("name", stringTypeError, true, false, true)
 
 	r.global.TypeError = r.newNativeFuncConstructProto(r.builtin_Error, "TypeError", r.global.TypeErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("TypeError", r.global.TypeError)
 
-	r.global.ReferenceErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.ReferenceErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.ReferenceErrorPrototype.self
 	o._putProp("name", stringReferenceError, true, false, true)
 
 	r.global.ReferenceError = r.newNativeFuncConstructProto(r.builtin_Error, "ReferenceError", r.global.ReferenceErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("ReferenceError", r.global.ReferenceError)
 
-	r.global.SyntaxErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.SyntaxErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.SyntaxErrorPrototype.self
 	o._putProp("name", stringSyntaxError, true, false, true)
 
 	r.global.SyntaxError = r.newNativeFuncConstructProto(r.builtin_Error, "SyntaxError", r.global.SyntaxErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("SyntaxError", r.global.SyntaxError)
 
-	r.global.RangeErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.RangeErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.RangeErrorPrototype.self
 	o._putProp("name", stringRangeError, true, false, true)
 
 	r.global.RangeError = r.newNativeFuncConstructProto(r.builtin_Error, "RangeError", r.global.RangeErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("RangeError", r.global.RangeError)
 
-	r.global.EvalErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.EvalErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.EvalErrorPrototype.self
 	o._putProp("name", stringEvalError, true, false, true)
 
 	r.global.EvalError = r.newNativeFuncConstructProto(r.builtin_Error, "EvalError", r.global.EvalErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("EvalError", r.global.EvalError)
 
-	r.global.URIErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.URIErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.URIErrorPrototype.self
 	o._putProp("name", stringURIError, true, false, true)
 
 	r.global.URIError = r.newNativeFuncConstructProto(r.builtin_Error, "URIError", r.global.URIErrorPrototype, r.global.Error, 1)
 	r.addToGlobal("URIError", r.global.URIError)
 
-	r.global.GoErrorPrototype = r.builtin_new(r.global.Error, []Value{})
+	r.global.AggregateErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
+	o = r.global.AggregateErrorPrototype.self
+	o._putProp("name", stringAggregateError, true, false, true)
+
+	r.global.AggregateError = r.newNativeFuncConstructProto(r.builtin_AggregateError, "AggregateError", r.global.AggregateErrorPrototype, r.global.Error, 2)
+	r.addToGlobal("AggregateError", r.global.AggregateError)
+
+	r.global.GoErrorPrototype = r.newBaseObject(r.global.ErrorPrototype, classError).val
 	o = r.global.GoErrorPrototype.self
 	o._putProp("name", stringGoError, true, false, true)
 
@@ -0,0 +1,71 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorStack(t *testing.T) {
+	vm := New()
+	_, err := vm.RunScript("test.js", `
+	function f() {
+		return new TypeError("test");
+	}
+	var e = f();
+	var e1;
+	try {
+		null.x;
+	} catch (ex) {
+		e1 = ex;
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack := vm.Get("e").ToObject(vm).Get("stack").String()
+	const expected = "TypeError: test\n    at f (test.js:3:10)\n    at test.js:5:11"
+	if stack != expected {
+		t.Fatalf("Unexpected stack: %q", stack)
+	}
+	if s := vm.Get("e1").ToObject(vm).Get("stack").String(); !strings.HasPrefix(s, "TypeError: ") || !strings.Contains(s, "\n    at test.js:8:") {
+		t.Fatalf("Unexpected stack: %q", s)
+	}
+
+	_, err = vm.RunString(`
+	var desc = Object.getOwnPropertyDescriptor(e, "stack");
+	if (desc.enumerable || !desc.writable || !desc.configurable) {
+		throw new Error("Unexpected descriptor");
+	}
+	if (Error.prototype.hasOwnProperty("stack") || TypeError.prototype.hasOwnProperty("stack")) {
+		throw new Error("Prototype has a stack");
+	}
+	if (JSON.stringify(e) !== "{}") {
+		throw new Error("stack is enumerable");
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorStackFormatter(t *testing.T) {
+	vm := New()
+	vm.SetErrorStackFormatter(func(err *Object, frames []StackFrame) string {
+		names := make([]string, len(frames))
+		for i, frame := range frames {
+			names[i] = frame.FuncName()
+		}
+		return err.Get("message").String() + "@" + strings.Join(names, "<")
+	})
+	v, err := vm.RunString(`
+	function inner() { return new Error("x"); }
+	function outer() { return inner(); }
+	outer().stack;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "x@inner<outer<" {
+		t.Fatalf("Unexpected stack: %q", s)
+	}
+}
@@ -2,23 +2,28 @@ package goja
 
 import (
 	"fmt"
+	"strings"
 )
 
 func (r *Runtime) builtin_Function(args []Value, proto *Object) *Object {
 	src := "(function anonymous("
 	if len(args) > 1 {
 		for _, arg := range args[:len(args)-1] {
-			src += arg.String() + ","
+			src += arg.ToString().String() + ","
 		}
 		src = src[:len(src)-1]
 	}
 	body := ""
 	if len(args) > 0 {
-		body = args[len(args)-1].String()
+		body = args[len(args)-1].ToString().String()
+	}
+	if strings.Contains(body[strings.LastIndexByte(body, '\n')+1:], "//") {
+		// the last line may end with a comment (e.g. "//# sourceURL=")
+		body += "\n"
 	}
 	src += "){" + body + "})"
 
-	return r.toObject(r.eval(src, false, false, _undefined))
+	return r.toObject(r.eval("Function", src, false, false, _undefined))
 }
 
 func (r *Runtime) functionproto_toString(call FunctionCall) Value {
@@ -129,7 +134,12 @@ repeat:
 		f = ff.create(obj)
 		goto repeat
 	default:
-		r.typeErrorResult(true, "Value is not callable: %s", obj.ToString())
+		// e.g. a proxy, which can be called but not constructed
+		if call, ok := f.assertCallable(); ok {
+			fcall = call
+		} else {
+			r.typeErrorResult(true, "Value is not callable: %s", obj.ToString())
+		}
 	}
 
 	l := int(toUInt32(obj.self.getStr("length")))
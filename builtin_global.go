@@ -238,22 +238,28 @@ func (r *Runtime) initGlobalObject() {
 	o._putProp("NaN", _NaN, false, false, false)
 	o._putProp("undefined", _undefined, false, false, false)
 	o._putProp("Infinity", _positiveInf, false, false, false)
+	o._putProp("globalThis", r.globalObject, true, false, true)
 
 	o._putProp("isNaN", r.newNativeFunc(r.builtin_isNaN, nil, "isNaN", nil, 1), true, false, true)
-	o._putProp("parseInt", r.newNativeFunc(r.builtin_parseInt, nil, "parseInt", nil, 2), true, false, true)
-	o._putProp("parseFloat", r.newNativeFunc(r.builtin_parseFloat, nil, "parseFloat", nil, 1), true, false, true)
+	parseIntFunc := r.newNativeFunc(r.builtin_parseInt, nil, "parseInt", nil, 2)
+	parseFloatFunc := r.newNativeFunc(r.builtin_parseFloat, nil, "parseFloat", nil, 1)
+	o._putProp("parseInt", parseIntFunc, true, false, true)
+	o._putProp("parseFloat", parseFloatFunc, true, false, true)
+	// Number.parseInt and Number.parseFloat are the same functions
+	r.global.Number.self._putProp("parseInt", parseIntFunc, true, false, true)
+	r.global.Number.self._putProp("parseFloat", parseFloatFunc, true, false, true)
 	o._putProp("isFinite", r.newNativeFunc(r.builtin_isFinite, nil, "isFinite", nil, 1), true, false, true)
 	o._putProp("decodeURI", r.newNativeFunc(r.builtin_decodeURI, nil, "decodeURI", nil, 1), true, false, true)
 	o._putProp("decodeURIComponent", r.newNativeFunc(r.builtin_decodeURIComponent, nil, "decodeURIComponent", nil, 1), true, false, true)
 	o._putProp("encodeURI", r.newNativeFunc(r.builtin_encodeURI, nil, "encodeURI", nil, 1), true, false, true)
 	o._putProp("encodeURIComponent", r.newNativeFunc(r.builtin_encodeURIComponent, nil, "encodeURIComponent", nil, 1), true, false, true)
+	o._putProp("structuredClone", r.newNativeFunc(r.builtin_structuredClone, nil, "structuredClone", nil, 1), true, false, true)
 
 	o._putProp("toString", r.newNativeFunc(func(FunctionCall) Value {
 		return stringGlobalObject
 	}, nil, "toString", nil, 0), false, false, false)
 
-	// TODO: Annex B
-
+	// escape() and unescape() are installed by SetAnnexB()
 }
 
 func digitVal(d byte) int {
@@ -19,3 +19,13 @@ func TestDecodeURI(t *testing.T) {
 
 	testScript1(SCRIPT, newStringValue("http://ru.wikipedia.org/wiki/Юникод"), t)
 }
+
+func TestGlobalThis(t *testing.T) {
+	const SCRIPT = `
+	var x = 1;
+	var desc = Object.getOwnPropertyDescriptor(globalThis, "globalThis");
+	globalThis === this && globalThis.x === 1 && desc.writable && !desc.enumerable && desc.configurable;
+	`
+
+	testScript1(SCRIPT, valueTrue, t)
+}
@@ -0,0 +1,1032 @@
+package goja
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// NumberFormatOptions are the resolved options of an Intl.NumberFormat.
+type NumberFormatOptions struct {
+	// Locale is the BCP 47 language tag, "und" if neither the locales argument nor the Runtime specify one.
+	Locale string
+	// Style is "decimal", "percent" or "currency".
+	Style string
+	// Currency is the upper case ISO 4217 currency code if Style is "currency".
+	Currency string
+	// CurrencyDisplay is "symbol", "narrowSymbol", "code" or "name" if Style is "currency".
+	CurrencyDisplay string
+	UseGrouping     bool
+
+	MinimumIntegerDigits  int
+	MinimumFractionDigits int
+	MaximumFractionDigits int
+}
+
+// NumberFormatProvider formats the numbers for Intl.NumberFormat and for Number.prototype.toLocaleString() when it
+// is called with locales or options, see Runtime.SetNumberFormatProvider().
+type NumberFormatProvider interface {
+	// FormatNumber formats x, which may be NaN or infinite. For the "percent" style x is the fraction (0.5 for 50%).
+	FormatNumber(x float64, options *NumberFormatOptions) string
+}
+
+// SetNumberFormatProvider sets the provider of the locale data for Intl.NumberFormat. If not set (or if p is nil),
+// the numbers are formatted with golang.org/x/text. Note that its data doesn't include the currency patterns and
+// names, so the currency symbol (or code) is put after the number for a fixed list of the locales which do that and
+// before it otherwise, and the "name" display uses the code.
+func (r *Runtime) SetNumberFormatProvider(p NumberFormatProvider) {
+	r.numberFormatProvider = p
+}
+
+type textNumberFormatProvider struct{}
+
+// currencyAfterNumber lists the languages whose currency pattern puts the currency after the number, and
+// currencyBeforeNumber the regional variants of them which put it before.
+var (
+	currencyAfterNumber = map[string]bool{
+		"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "et": true, "fi": true, "fr": true,
+		"hr": true, "hu": true, "is": true, "it": true, "lt": true, "lv": true, "nb": true, "nn": true, "no": true,
+		"pl": true, "ro": true, "ru": true, "sk": true, "sl": true, "sr": true, "sv": true, "uk": true,
+	}
+	currencyBeforeNumber = map[string]bool{
+		"de-AT": true, "de-CH": true, "de-LI": true, "it-CH": true,
+	}
+
+	regionAmericas = language.MustParseRegion("019")
+)
+
+// isCurrencyAfterNumber reports whether the locale puts the currency after the number. Note that "pt" is Brazilian
+// Portuguese, which puts it before, like the Spanish of the Americas.
+func isCurrencyAfterNumber(tag language.Tag) bool {
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	switch base.String() {
+	case "pt":
+		return region.String() == "PT"
+	case "es":
+		if region.String() == "419" || regionAmericas.Contains(region) {
+			return false
+		}
+	}
+	return currencyAfterNumber[base.String()] && !currencyBeforeNumber[base.String()+"-"+region.String()]
+}
+
+// roundHalfExpand rounds the non-negative x to the given number of fraction digits with the ties away from zero,
+// which is the default rounding mode of ECMA-402 (x/text rounds them to even). Like in ICU, the shortest decimal
+// representation of x is rounded, so 1.005 becomes 1.01.
+func roundHalfExpand(x float64, digits int) float64 {
+	s := strconv.FormatFloat(x, 'f', -1, 64)
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 || len(s)-dot-1 <= digits {
+		return x
+	}
+	buf := []byte(s[:dot] + s[dot+1:dot+1+digits])
+	if s[dot+1+digits] >= '5' {
+		i := len(buf) - 1
+		for ; i >= 0 && buf[i] == '9'; i-- {
+			buf[i] = '0'
+		}
+		if i < 0 {
+			buf = append([]byte{'1'}, buf...)
+		} else {
+			buf[i]++
+		}
+	}
+	if digits > 0 {
+		intLen := len(buf) - digits
+		buf = append(buf[:intLen], append([]byte{'.'}, buf[intLen:]...)...)
+	}
+	res, _ := strconv.ParseFloat(string(buf), 64)
+	return res
+}
+
+func (textNumberFormatProvider) FormatNumber(x float64, options *NumberFormatOptions) string {
+	var sign string
+	if x < 0 || x == 0 && math.Signbit(x) {
+		sign = "-"
+		x = -x
+	}
+	if math.IsNaN(x) {
+		return "NaN"
+	}
+	if math.IsInf(x, 0) {
+		return sign + "∞"
+	}
+
+	tag, err := language.Parse(options.Locale)
+	if err != nil {
+		tag = language.Und
+	}
+	p := message.NewPrinter(tag)
+	opts := []number.Option{
+		number.MinIntegerDigits(options.MinimumIntegerDigits),
+		number.MinFractionDigits(options.MinimumFractionDigits),
+		number.MaxFractionDigits(options.MaximumFractionDigits),
+	}
+	if !options.UseGrouping {
+		opts = append(opts, number.NoSeparator())
+	}
+
+	switch options.Style {
+	case "percent":
+		return sign + p.Sprint(number.Percent(roundHalfExpand(x, options.MaximumFractionDigits+2), opts...))
+	case "currency":
+		s := p.Sprint(number.Decimal(roundHalfExpand(x, options.MaximumFractionDigits), opts...))
+		cur, symbol := options.Currency, false
+		if unit, err := currency.ParseISO(options.Currency); err == nil {
+			cur = unit.String()
+			switch options.CurrencyDisplay {
+			case "symbol":
+				cur, symbol = p.Sprint(currency.Symbol(unit)), true
+			case "narrowSymbol":
+				cur, symbol = p.Sprint(currency.NarrowSymbol(unit)), true
+			}
+		}
+		if isCurrencyAfterNumber(tag) {
+			return sign + s + "\u00a0" + cur
+		}
+		if symbol {
+			return sign + cur + s
+		}
+		return sign + cur + "\u00a0" + s
+	}
+	return sign + p.Sprint(number.Decimal(roundHalfExpand(x, options.MaximumFractionDigits), opts...))
+}
+
+func (r *Runtime) getNumberFormatProvider() NumberFormatProvider {
+	if r.numberFormatProvider != nil {
+		return r.numberFormatProvider
+	}
+	return textNumberFormatProvider{}
+}
+
+type numberFormatObject struct {
+	baseObject
+	options     NumberFormatOptions
+	boundFormat *Object
+}
+
+// getStringOption implements GetOption() for a string option. If allowed is not nil the value must be one of them.
+func (r *Runtime) getStringOption(options *Object, name string, allowed []string, fallback string) string {
+	if options == nil {
+		return fallback
+	}
+	v := options.self.getStr(name)
+	if v == nil || v == _undefined {
+		return fallback
+	}
+	s := v.String()
+	if allowed == nil {
+		return s
+	}
+	for _, a := range allowed {
+		if s == a {
+			return s
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Value %s out of range for options property %s", s, name))
+}
+
+// getNumberOption implements GetNumberOption().
+func (r *Runtime) getNumberOption(options *Object, name string, min, max, fallback int) int {
+	if options == nil {
+		return fallback
+	}
+	v := options.self.getStr(name)
+	if v == nil || v == _undefined {
+		return fallback
+	}
+	f := v.ToFloat()
+	if math.IsNaN(f) || f < float64(min) || f > float64(max) {
+		panic(r.newError(r.global.RangeError, "%s value is out of range.", name))
+	}
+	return int(math.Floor(f))
+}
+
+// resolveNumberFormatOptions implements the part of InitializeNumberFormat() that resolves the options.
+func (r *Runtime) resolveNumberFormatOptions(locales, optionsArg Value) NumberFormatOptions {
+	tag, ok := r.requestedLocale(locales)
+	if !ok {
+		tag = r.locale.tag
+	}
+	var options *Object
+	if optionsArg != _undefined {
+		options = optionsArg.ToObject(r)
+	}
+
+	res := NumberFormatOptions{
+		Locale: tag.String(),
+		Style:  r.getStringOption(options, "style", []string{"decimal", "percent", "currency"}, "decimal"),
+	}
+	cur := r.getStringOption(options, "currency", nil, "")
+	if cur != "" {
+		if len(cur) != 3 || strings.Trim(strings.ToUpper(cur), "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			panic(r.newError(r.global.RangeError, "Invalid currency code : %s", cur))
+		}
+		cur = strings.ToUpper(cur)
+	}
+	currencyDisplay := r.getStringOption(options, "currencyDisplay", []string{"code", "symbol", "narrowSymbol", "name"}, "symbol")
+	if res.Style == "currency" {
+		if cur == "" {
+			panic(r.NewTypeError("Currency code is required with currency style."))
+		}
+		res.Currency = cur
+		res.CurrencyDisplay = currencyDisplay
+	}
+
+	res.MinimumIntegerDigits = r.getNumberOption(options, "minimumIntegerDigits", 1, 21, 1)
+	minDefault, maxDefault := 0, 3
+	switch res.Style {
+	case "percent":
+		maxDefault = 0
+	case "currency":
+		minDefault = 2
+		if unit, err := currency.ParseISO(cur); err == nil {
+			minDefault, _ = currency.Standard.Rounding(unit)
+		}
+		maxDefault = minDefault
+	}
+	res.MinimumFractionDigits = r.getNumberOption(options, "minimumFractionDigits", 0, 20, minDefault)
+	if maxDefault < res.MinimumFractionDigits {
+		maxDefault = res.MinimumFractionDigits
+	}
+	res.MaximumFractionDigits = r.getNumberOption(options, "maximumFractionDigits", res.MinimumFractionDigits, 20, maxDefault)
+
+	res.UseGrouping = true
+	if options != nil {
+		if v := options.self.getStr("useGrouping"); v != nil && v != _undefined {
+			res.UseGrouping = v.ToBoolean()
+		}
+	}
+	return res
+}
+
+func (r *Runtime) formatNumber(x float64, options *NumberFormatOptions) valueString {
+	return newStringValue(r.getNumberFormatProvider().FormatNumber(x, options))
+}
+
+func (r *Runtime) builtin_NumberFormat(args []Value, proto *Object) *Object {
+	o := &Object{runtime: r}
+	nf := &numberFormatObject{
+		baseObject: baseObject{
+			class:      classObject,
+			val:        o,
+			prototype:  proto,
+			extensible: true,
+		},
+	}
+	o.self = nf
+	nf.init()
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	nf.options = r.resolveNumberFormatOptions(locales, options)
+	return o
+}
+
+func (r *Runtime) thisNumberFormat(v Value, method string) *numberFormatObject {
+	if o, ok := v.(*Object); ok {
+		if nf, ok := o.self.(*numberFormatObject); ok {
+			return nf
+		}
+	}
+	panic(r.NewTypeError("Method Intl.NumberFormat.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) numberFormatProto_getFormat(call FunctionCall) Value {
+	nf := r.thisNumberFormat(call.This, "format")
+	if nf.boundFormat == nil {
+		nf.boundFormat = r.newNativeFunc(func(call FunctionCall) Value {
+			return r.formatNumber(call.Argument(0).ToFloat(), &nf.options)
+		}, nil, "", nil, 1)
+	}
+	return nf.boundFormat
+}
+
+func (r *Runtime) numberFormatProto_resolvedOptions(call FunctionCall) Value {
+	nf := r.thisNumberFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	opts := &nf.options
+	o.self.putStr("locale", newStringValue(opts.Locale), false)
+	o.self.putStr("numberingSystem", asciiString("latn"), false)
+	o.self.putStr("style", newStringValue(opts.Style), false)
+	if opts.Style == "currency" {
+		o.self.putStr("currency", newStringValue(opts.Currency), false)
+		o.self.putStr("currencyDisplay", newStringValue(opts.CurrencyDisplay), false)
+	}
+	o.self.putStr("minimumIntegerDigits", intToValue(int64(opts.MinimumIntegerDigits)), false)
+	o.self.putStr("minimumFractionDigits", intToValue(int64(opts.MinimumFractionDigits)), false)
+	o.self.putStr("maximumFractionDigits", intToValue(int64(opts.MaximumFractionDigits)), false)
+	o.self.putStr("useGrouping", r.toBoolean(opts.UseGrouping), false)
+	return o
+}
+
+type dateTimeFormatObject struct {
+	baseObject
+	format      *dateTimeFormat
+	boundFormat *Object
+}
+
+func (r *Runtime) builtin_DateTimeFormat(args []Value, proto *Object) *Object {
+	o := &Object{runtime: r}
+	df := &dateTimeFormatObject{
+		baseObject: baseObject{
+			class:      classObject,
+			val:        o,
+			prototype:  proto,
+			extensible: true,
+		},
+	}
+	o.self = df
+	df.init()
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	df.format = r.resolveDateTimeFormat(locales, options, "any", "date")
+	return o
+}
+
+func (r *Runtime) thisDateTimeFormat(v Value, method string) *dateTimeFormatObject {
+	if o, ok := v.(*Object); ok {
+		if df, ok := o.self.(*dateTimeFormatObject); ok {
+			return df
+		}
+	}
+	panic(r.NewTypeError("Method Intl.DateTimeFormat.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+// dateTimeFormatArg converts the argument of format() and formatToParts() to a time, the current time if it is
+// undefined.
+func (r *Runtime) dateTimeFormatArg(v Value) time.Time {
+	if v == _undefined {
+		return time.Now()
+	}
+	x := v.ToFloat()
+	if math.IsNaN(x) || math.Abs(x) > maxTime {
+		panic(r.newError(r.global.RangeError, "Invalid time value"))
+	}
+	return timeFromMsec(int64(x))
+}
+
+func (r *Runtime) dateTimeFormatProto_getFormat(call FunctionCall) Value {
+	df := r.thisDateTimeFormat(call.This, "format")
+	if df.boundFormat == nil {
+		df.boundFormat = r.newNativeFunc(func(call FunctionCall) Value {
+			return newStringValue(df.format.format(r.dateTimeFormatArg(call.Argument(0))))
+		}, nil, "", nil, 1)
+	}
+	return df.boundFormat
+}
+
+func (r *Runtime) dateTimeFormatProto_formatToParts(call FunctionCall) Value {
+	df := r.thisDateTimeFormat(call.This, "formatToParts")
+	parts := df.format.formatToParts(r.dateTimeFormatArg(call.Argument(0)))
+	values := make([]Value, len(parts))
+	for i, p := range parts {
+		o := r.NewObject()
+		o.self.putStr("type", newStringValue(p.typ), false)
+		o.self.putStr("value", newStringValue(p.value), false)
+		values[i] = o
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) dateTimeFormatProto_resolvedOptions(call FunctionCall) Value {
+	df := r.thisDateTimeFormat(call.This, "resolvedOptions")
+	f := df.format
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(f.locale.String()), false)
+	o.self.putStr("calendar", asciiString("gregory"), false)
+	o.self.putStr("numberingSystem", asciiString("latn"), false)
+	o.self.putStr("timeZone", newStringValue(f.timeZone()), false)
+	if f.hasTime {
+		o.self.putStr("hour12", r.toBoolean(f.hour12()), false)
+	}
+	for _, p := range f.parts {
+		if p.typ == "literal" || p.typ == "dayPeriod" {
+			continue
+		}
+		var style string
+		switch {
+		case p.narrow:
+			style = "narrow"
+		case p.value == "January" || p.value == "Monday":
+			style = "long"
+		case p.value == "Jan" || p.value == "Mon":
+			style = "short"
+		case len(p.value) == 2:
+			style = "2-digit"
+		default:
+			style = "numeric"
+		}
+		o.self.putStr(p.typ, asciiString(style), false)
+	}
+	return o
+}
+
+type collatorObject struct {
+	baseObject
+	locale       language.Tag
+	usage        string
+	sensitivity  string
+	numeric      bool
+	collator     *collate.Collator
+	boundCompare *Object
+}
+
+// newCollator creates a collator for the locales and options arguments of Intl.Collator (and
+// String.prototype.localeCompare). The sensitivity and numeric options are mapped to the options of
+// golang.org/x/text/collate, ignorePunctuation and caseFirst are not supported.
+func (r *Runtime) newCollator(locales, optionsArg Value, proto *Object) *collatorObject {
+	v := &Object{runtime: r}
+	c := &collatorObject{
+		baseObject: baseObject{
+			class:      classObject,
+			val:        v,
+			prototype:  proto,
+			extensible: true,
+		},
+		locale: r.locale.tag,
+	}
+	v.self = c
+	c.init()
+	if tag, ok := r.requestedLocale(locales); ok {
+		c.locale = tag
+	}
+	var options *Object
+	if optionsArg != _undefined {
+		options = optionsArg.ToObject(r)
+	}
+	c.usage = r.getStringOption(options, "usage", []string{"sort", "search"}, "sort")
+	if options != nil {
+		if v := options.self.getStr("numeric"); v != nil && v != _undefined {
+			c.numeric = v.ToBoolean()
+		}
+	}
+	c.sensitivity = r.getStringOption(options, "sensitivity", []string{"base", "accent", "case", "variant"}, "variant")
+
+	var opts []collate.Option
+	switch c.sensitivity {
+	case "base":
+		opts = append(opts, collate.IgnoreCase, collate.IgnoreDiacritics)
+	case "accent":
+		opts = append(opts, collate.IgnoreCase)
+	case "case":
+		opts = append(opts, collate.IgnoreDiacritics)
+	}
+	if c.numeric {
+		opts = append(opts, collate.Numeric)
+	}
+	c.collator = collate.New(c.locale, opts...)
+	return c
+}
+
+func (r *Runtime) builtin_Collator(args []Value, proto *Object) *Object {
+	var locales, options Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+	return r.newCollator(locales, options, proto).val
+}
+
+func (r *Runtime) thisCollator(v Value, method string) *collatorObject {
+	if o, ok := v.(*Object); ok {
+		if c, ok := o.self.(*collatorObject); ok {
+			return c
+		}
+	}
+	panic(r.NewTypeError("Method Intl.Collator.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) collatorProto_getCompare(call FunctionCall) Value {
+	c := r.thisCollator(call.This, "compare")
+	if c.boundCompare == nil {
+		c.boundCompare = r.newNativeFunc(func(call FunctionCall) Value {
+			return intToValue(int64(localeCompareStrings(c.collator, call.Argument(0).String(), call.Argument(1).String())))
+		}, nil, "", nil, 2)
+	}
+	return c.boundCompare
+}
+
+func (r *Runtime) collatorProto_resolvedOptions(call FunctionCall) Value {
+	c := r.thisCollator(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(c.locale.String()), false)
+	o.self.putStr("usage", newStringValue(c.usage), false)
+	o.self.putStr("sensitivity", newStringValue(c.sensitivity), false)
+	o.self.putStr("ignorePunctuation", valueFalse, false)
+	o.self.putStr("collation", asciiString("default"), false)
+	o.self.putStr("numeric", r.toBoolean(c.numeric), false)
+	o.self.putStr("caseFirst", asciiString("false"), false)
+	return o
+}
+
+// PluralRulesProvider selects the plural categories for Intl.PluralRules, see Runtime.SetPluralRulesProvider().
+type PluralRulesProvider interface {
+	// PluralCategory returns the category ("zero", "one", "two", "few", "many" or "other") of n, which is the
+	// absolute value of a number formatted as a decimal with the resolved fraction digits (e.g. "1" or "1.50"),
+	// in the locale. If ordinal is true the ordinal rules are used, otherwise the cardinal ones.
+	PluralCategory(locale string, n string, ordinal bool) string
+}
+
+// SetPluralRulesProvider sets the provider of the plural rules for Intl.PluralRules. If not set (or if p is nil),
+// the rules of golang.org/x/text/feature/plural are used.
+func (r *Runtime) SetPluralRulesProvider(p PluralRulesProvider) {
+	r.pluralRulesProvider = p
+}
+
+type textPluralRulesProvider struct{}
+
+// pluralOperand parses digits as an integer. Only the last 9 digits of larger numbers are kept, the rules are
+// concerned with the lower digits.
+func pluralOperand(digits string) int {
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+func (textPluralRulesProvider) PluralCategory(locale string, n string, ordinal bool) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		tag = language.Und
+	}
+	intPart, fracPart := n, ""
+	if p := strings.IndexByte(n, '.'); p != -1 {
+		intPart, fracPart = n[:p], n[p+1:]
+	}
+	trimmed := strings.TrimRight(fracPart, "0")
+	rules := plural.Cardinal
+	if ordinal {
+		rules = plural.Ordinal
+	}
+	switch rules.MatchPlural(tag, pluralOperand(intPart), len(fracPart), len(trimmed), pluralOperand(fracPart), pluralOperand(trimmed)) {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	}
+	return "other"
+}
+
+func (r *Runtime) getPluralRulesProvider() PluralRulesProvider {
+	if r.pluralRulesProvider != nil {
+		return r.pluralRulesProvider
+	}
+	return textPluralRulesProvider{}
+}
+
+type pluralRulesObject struct {
+	baseObject
+	locale                string
+	ordinal               bool
+	minimumIntegerDigits  int
+	minimumFractionDigits int
+	maximumFractionDigits int
+}
+
+func (r *Runtime) builtin_PluralRules(args []Value, proto *Object) *Object {
+	v := &Object{runtime: r}
+	p := &pluralRulesObject{
+		baseObject: baseObject{
+			class:      classObject,
+			val:        v,
+			prototype:  proto,
+			extensible: true,
+		},
+	}
+	v.self = p
+	p.init()
+	var locales, optionsArg Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		optionsArg = args[1]
+	}
+	tag, ok := r.requestedLocale(locales)
+	if !ok {
+		tag = r.locale.tag
+	}
+	p.locale = tag.String()
+	var options *Object
+	if optionsArg != _undefined {
+		options = optionsArg.ToObject(r)
+	}
+	p.ordinal = r.getStringOption(options, "type", []string{"cardinal", "ordinal"}, "cardinal") == "ordinal"
+	p.minimumIntegerDigits = r.getNumberOption(options, "minimumIntegerDigits", 1, 21, 1)
+	p.minimumFractionDigits = r.getNumberOption(options, "minimumFractionDigits", 0, 20, 0)
+	maxDefault := 3
+	if maxDefault < p.minimumFractionDigits {
+		maxDefault = p.minimumFractionDigits
+	}
+	p.maximumFractionDigits = r.getNumberOption(options, "maximumFractionDigits", p.minimumFractionDigits, 20, maxDefault)
+	return v
+}
+
+func (r *Runtime) thisPluralRules(v Value, method string) *pluralRulesObject {
+	if o, ok := v.(*Object); ok {
+		if p, ok := o.self.(*pluralRulesObject); ok {
+			return p
+		}
+	}
+	panic(r.NewTypeError("Method Intl.PluralRules.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) pluralRulesProto_select(call FunctionCall) Value {
+	p := r.thisPluralRules(call.This, "select")
+	x := math.Abs(call.Argument(0).ToFloat())
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return asciiString("other")
+	}
+	var n string
+	if x >= 1e21 {
+		n = strconv.FormatFloat(x, 'f', -1, 64)
+	} else {
+		n = numberToFixed(x, p.maximumFractionDigits)
+		if p.maximumFractionDigits > p.minimumFractionDigits {
+			n = strings.TrimRight(n, "0")
+			if dot := strings.IndexByte(n, '.'); len(n)-dot-1 < p.minimumFractionDigits {
+				n += strings.Repeat("0", p.minimumFractionDigits-(len(n)-dot-1))
+			}
+			n = strings.TrimSuffix(n, ".")
+		}
+	}
+	return newStringValue(r.getPluralRulesProvider().PluralCategory(p.locale, n, p.ordinal))
+}
+
+func (r *Runtime) pluralRulesProto_resolvedOptions(call FunctionCall) Value {
+	p := r.thisPluralRules(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(p.locale), false)
+	if p.ordinal {
+		o.self.putStr("type", asciiString("ordinal"), false)
+	} else {
+		o.self.putStr("type", asciiString("cardinal"), false)
+	}
+	o.self.putStr("minimumIntegerDigits", intToValue(int64(p.minimumIntegerDigits)), false)
+	o.self.putStr("minimumFractionDigits", intToValue(int64(p.minimumFractionDigits)), false)
+	o.self.putStr("maximumFractionDigits", intToValue(int64(p.maximumFractionDigits)), false)
+	return o
+}
+
+// RelativeTimeFormatOptions are the resolved options of an Intl.RelativeTimeFormat.
+type RelativeTimeFormatOptions struct {
+	Locale string
+	// Style is "long", "short" or "narrow".
+	Style string
+	// Numeric is "always" or "auto".
+	Numeric string
+}
+
+// RelativeTimeFormatProvider formats the relative times for Intl.RelativeTimeFormat, see
+// Runtime.SetRelativeTimeFormatProvider().
+type RelativeTimeFormatProvider interface {
+	// FormatRelativeTime formats value (which is finite, negative values and -0 are in the past) of the unit, which
+	// is one of "second", "minute", "hour", "day", "week", "month", "quarter" or "year".
+	FormatRelativeTime(value float64, unit string, options *RelativeTimeFormatOptions) string
+}
+
+// SetRelativeTimeFormatProvider sets the provider of the locale data for Intl.RelativeTimeFormat. If not set (or
+// if p is nil), the relative times are formatted in English regardless of the requested locale, and the resolved
+// locale is "en".
+func (r *Runtime) SetRelativeTimeFormatProvider(p RelativeTimeFormatProvider) {
+	r.relativeTimeFormatProvider = p
+}
+
+var (
+	relativeTimeUnits = []string{"second", "minute", "hour", "day", "week", "month", "quarter", "year"}
+
+	relativeTimeShortUnits = map[string][2]string{
+		"second":  {"sec.", "sec."},
+		"minute":  {"min.", "min."},
+		"hour":    {"hr.", "hr."},
+		"day":     {"day", "days"},
+		"week":    {"wk.", "wk."},
+		"month":   {"mo.", "mo."},
+		"quarter": {"qtr.", "qtrs."},
+		"year":    {"yr.", "yr."},
+	}
+
+	// the phrases for -1, 0 and 1 with numeric: "auto"
+	relativeTimeAuto = map[string][3]string{
+		"second":  {"", "now", ""},
+		"minute":  {"", "this minute", ""},
+		"hour":    {"", "this hour", ""},
+		"day":     {"yesterday", "today", "tomorrow"},
+		"week":    {"last week", "this week", "next week"},
+		"month":   {"last month", "this month", "next month"},
+		"quarter": {"last quarter", "this quarter", "next quarter"},
+		"year":    {"last year", "this year", "next year"},
+	}
+)
+
+type englishRelativeTimeFormatProvider struct{}
+
+func (englishRelativeTimeFormatProvider) FormatRelativeTime(value float64, unit string, options *RelativeTimeFormatOptions) string {
+	past := value < 0 || value == 0 && math.Signbit(value)
+	abs := math.Abs(value)
+	if options.Numeric == "auto" && (abs == 0 || abs == 1) {
+		idx := 1
+		if abs == 1 {
+			if past {
+				idx = 0
+			} else {
+				idx = 2
+			}
+		}
+		if s := relativeTimeAuto[unit][idx]; s != "" {
+			return s
+		}
+	}
+
+	var name string
+	if options.Style == "long" {
+		name = unit
+		if abs != 1 {
+			name += "s"
+		}
+	} else {
+		names := relativeTimeShortUnits[unit]
+		if abs == 1 {
+			name = names[0]
+		} else {
+			name = names[1]
+		}
+	}
+	n := message.NewPrinter(language.English).Sprint(number.Decimal(abs, number.MaxFractionDigits(3)))
+	if past {
+		return n + " " + name + " ago"
+	}
+	return "in " + n + " " + name
+}
+
+func (r *Runtime) getRelativeTimeFormatProvider() RelativeTimeFormatProvider {
+	if r.relativeTimeFormatProvider != nil {
+		return r.relativeTimeFormatProvider
+	}
+	return englishRelativeTimeFormatProvider{}
+}
+
+type relativeTimeFormatObject struct {
+	baseObject
+	options RelativeTimeFormatOptions
+}
+
+func (r *Runtime) builtin_RelativeTimeFormat(args []Value, proto *Object) *Object {
+	v := &Object{runtime: r}
+	f := &relativeTimeFormatObject{
+		baseObject: baseObject{
+			class:      classObject,
+			val:        v,
+			prototype:  proto,
+			extensible: true,
+		},
+	}
+	v.self = f
+	f.init()
+	var locales, optionsArg Value = _undefined, _undefined
+	if len(args) > 0 {
+		locales = args[0]
+	}
+	if len(args) > 1 {
+		optionsArg = args[1]
+	}
+	tag, ok := r.requestedLocale(locales)
+	if !ok {
+		tag = r.locale.tag
+	}
+	if r.relativeTimeFormatProvider == nil {
+		// the locale that is actually used
+		tag = language.English
+	}
+	var options *Object
+	if optionsArg != _undefined {
+		options = optionsArg.ToObject(r)
+	}
+	f.options = RelativeTimeFormatOptions{
+		Locale:  tag.String(),
+		Style:   r.getStringOption(options, "style", []string{"long", "short", "narrow"}, "long"),
+		Numeric: r.getStringOption(options, "numeric", []string{"always", "auto"}, "always"),
+	}
+	return v
+}
+
+func (r *Runtime) thisRelativeTimeFormat(v Value, method string) *relativeTimeFormatObject {
+	if o, ok := v.(*Object); ok {
+		if f, ok := o.self.(*relativeTimeFormatObject); ok {
+			return f
+		}
+	}
+	panic(r.NewTypeError("Method Intl.RelativeTimeFormat.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+// relativeTimeFormatArgs validates the arguments of format() and formatToParts() and returns the value and the
+// singular unit.
+func (r *Runtime) relativeTimeFormatArgs(call FunctionCall, method string) (float64, string) {
+	value := call.Argument(0).ToFloat()
+	unit := strings.TrimSuffix(call.Argument(1).String(), "s")
+	valid := false
+	for _, u := range relativeTimeUnits {
+		if unit == u {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		panic(r.newError(r.global.RangeError, "Invalid unit argument for %s() '%s'", method, call.Argument(1).String()))
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		panic(r.newError(r.global.RangeError, "Invalid value for %s(): %s", method, call.Argument(0).String()))
+	}
+	return value, unit
+}
+
+func (r *Runtime) relativeTimeFormatProto_format(call FunctionCall) Value {
+	f := r.thisRelativeTimeFormat(call.This, "format")
+	value, unit := r.relativeTimeFormatArgs(call, "format")
+	return newStringValue(r.getRelativeTimeFormatProvider().FormatRelativeTime(value, unit, &f.options))
+}
+
+// relativeTimeFormatProto_formatToParts splits the formatted string around its first number (the digits with the
+// single separators between them). As the provider only returns a string, the separators are told apart by the
+// value: the last one is the decimal separator if the value has a fraction, the others are group separators.
+func (r *Runtime) relativeTimeFormatProto_formatToParts(call FunctionCall) Value {
+	f := r.thisRelativeTimeFormat(call.This, "formatToParts")
+	value, unit := r.relativeTimeFormatArgs(call, "formatToParts")
+	s := r.getRelativeTimeFormatProvider().FormatRelativeTime(value, unit, &f.options)
+
+	var values []Value
+	addPart := func(typ, value string, withUnit bool) {
+		o := r.NewObject()
+		o.self.putStr("type", newStringValue(typ), false)
+		o.self.putStr("value", newStringValue(value), false)
+		if withUnit {
+			o.self.putStr("unit", newStringValue(unit), false)
+		}
+		values = append(values, o)
+	}
+
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	start := strings.IndexAny(s, "0123456789")
+	if start == -1 {
+		addPart("literal", s, false)
+		return r.newArrayValues(values)
+	}
+	// the offsets of the digit runs
+	var runs [][2]int
+	end := start
+	for {
+		runStart := end
+		for end < len(s) && isDigit(s[end]) {
+			end++
+		}
+		runs = append(runs, [2]int{runStart, end})
+		sep, size := utf8.DecodeRuneInString(s[end:])
+		if size == 0 || unicode.IsLetter(sep) || unicode.IsDigit(sep) || end+size >= len(s) || !isDigit(s[end+size]) {
+			break
+		}
+		end += size
+	}
+
+	if start > 0 {
+		addPart("literal", s[:start], false)
+	}
+	hasFraction := value != math.Trunc(value)
+	for i, run := range runs {
+		if i > 0 {
+			sep := s[runs[i-1][1]:run[0]]
+			if hasFraction && i == len(runs)-1 {
+				addPart("decimal", sep, true)
+			} else {
+				addPart("group", sep, true)
+			}
+		}
+		if hasFraction && i == len(runs)-1 && i > 0 {
+			addPart("fraction", s[run[0]:run[1]], true)
+		} else {
+			addPart("integer", s[run[0]:run[1]], true)
+		}
+	}
+	if end < len(s) {
+		addPart("literal", s[end:], false)
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) relativeTimeFormatProto_resolvedOptions(call FunctionCall) Value {
+	f := r.thisRelativeTimeFormat(call.This, "resolvedOptions")
+	o := r.NewObject()
+	o.self.putStr("locale", newStringValue(f.options.Locale), false)
+	o.self.putStr("style", newStringValue(f.options.Style), false)
+	o.self.putStr("numeric", newStringValue(f.options.Numeric), false)
+	o.self.putStr("numberingSystem", asciiString("latn"), false)
+	return o
+}
+
+func (r *Runtime) intl_supportedLocalesOf(call FunctionCall) Value {
+	tags := r.requestedLocales(call.Argument(0))
+	values := make([]Value, len(tags))
+	for i, tag := range tags {
+		values[i] = newStringValue(tag.String())
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) createIntl(val *Object) objectImpl {
+	o := &baseObject{
+		class:      classObject,
+		val:        val,
+		extensible: true,
+		prototype:  r.global.ObjectPrototype,
+	}
+	o.init()
+
+	proto := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	proto._putProp("resolvedOptions", r.newNativeFunc(r.numberFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	proto._put("format", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.numberFormatProto_getFormat, nil, "get format", nil, 0),
+	})
+
+	numberFormat := r.newNativeFuncConstruct(r.builtin_NumberFormat, "NumberFormat", proto.val, 0)
+	numberFormat.self._putProp("supportedLocalesOf", r.newNativeFunc(r.intl_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	o._putProp("NumberFormat", numberFormat, true, false, true)
+
+	proto = r.newBaseObject(r.global.ObjectPrototype, classObject)
+	proto._putProp("formatToParts", r.newNativeFunc(r.dateTimeFormatProto_formatToParts, nil, "formatToParts", nil, 1), true, false, true)
+	proto._putProp("resolvedOptions", r.newNativeFunc(r.dateTimeFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	proto._put("format", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.dateTimeFormatProto_getFormat, nil, "get format", nil, 0),
+	})
+
+	dateTimeFormat := r.newNativeFuncConstruct(r.builtin_DateTimeFormat, "DateTimeFormat", proto.val, 0)
+	dateTimeFormat.self._putProp("supportedLocalesOf", r.newNativeFunc(r.intl_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	o._putProp("DateTimeFormat", dateTimeFormat, true, false, true)
+
+	proto = r.newBaseObject(r.global.ObjectPrototype, classObject)
+	proto._putProp("resolvedOptions", r.newNativeFunc(r.collatorProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+	proto._put("compare", &valueProperty{
+		accessor:     true,
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.collatorProto_getCompare, nil, "get compare", nil, 0),
+	})
+
+	collator := r.newNativeFuncConstruct(r.builtin_Collator, "Collator", proto.val, 0)
+	collator.self._putProp("supportedLocalesOf", r.newNativeFunc(r.intl_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	o._putProp("Collator", collator, true, false, true)
+
+	proto = r.newBaseObject(r.global.ObjectPrototype, classObject)
+	proto._putProp("select", r.newNativeFunc(r.pluralRulesProto_select, nil, "select", nil, 1), true, false, true)
+	proto._putProp("resolvedOptions", r.newNativeFunc(r.pluralRulesProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+
+	pluralRules := r.newNativeFuncConstruct(r.builtin_PluralRules, "PluralRules", proto.val, 0)
+	pluralRules.self._putProp("supportedLocalesOf", r.newNativeFunc(r.intl_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	o._putProp("PluralRules", pluralRules, true, false, true)
+
+	proto = r.newBaseObject(r.global.ObjectPrototype, classObject)
+	proto._putProp("format", r.newNativeFunc(r.relativeTimeFormatProto_format, nil, "format", nil, 2), true, false, true)
+	proto._putProp("formatToParts", r.newNativeFunc(r.relativeTimeFormatProto_formatToParts, nil, "formatToParts", nil, 2), true, false, true)
+	proto._putProp("resolvedOptions", r.newNativeFunc(r.relativeTimeFormatProto_resolvedOptions, nil, "resolvedOptions", nil, 0), true, false, true)
+
+	relativeTimeFormat := r.newNativeFuncConstruct(r.builtin_RelativeTimeFormat, "RelativeTimeFormat", proto.val, 0)
+	relativeTimeFormat.self._putProp("supportedLocalesOf", r.newNativeFunc(r.intl_supportedLocalesOf, nil, "supportedLocalesOf", nil, 1), true, false, true)
+	o._putProp("RelativeTimeFormat", relativeTimeFormat, true, false, true)
+
+	return o
+}
+
+func (r *Runtime) initIntl() {
+	r.addToGlobal("Intl", r.newLazyObject(r.createIntl))
+}
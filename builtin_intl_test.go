@@ -0,0 +1,320 @@
+package goja
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+type testNumberFormatProvider struct{}
+
+func (testNumberFormatProvider) FormatNumber(x float64, o *NumberFormatOptions) string {
+	return fmt.Sprintf("%v %s %s %s %s %v %d %d %d", x, o.Locale, o.Style, o.Currency, o.CurrencyDisplay, o.UseGrouping,
+		o.MinimumIntegerDigits, o.MinimumFractionDigits, o.MaximumFractionDigits)
+}
+
+func TestIntlNumberFormat(t *testing.T) {
+	const SCRIPT = `
+	var nf = new Intl.NumberFormat("de-DE");
+	assert.sameValue(nf.format(1234.5), "1234.5 de-DE decimal   true 1 0 3", "decimal");
+	assert.sameValue(nf.format, nf.format, "bound format");
+	assert.sameValue([1, 2].map(nf.format).length, 2, "format is bound");
+	assert.sameValue(Intl.NumberFormat("x y", {style: "percent"}).format(0.5), "0.5 en-US percent   true 1 0 0", "percent");
+	assert.sameValue(new Intl.NumberFormat(undefined, {style: "currency", currency: "eur", useGrouping: false}).format("3"),
+		"3 en-US currency EUR symbol false 1 2 2", "currency");
+	assert.sameValue(new Intl.NumberFormat([], {minimumFractionDigits: 5}).format(1), "1 en-US decimal   true 1 5 5", "min > max default");
+	assert.sameValue((1.5).toLocaleString("fr", {maximumFractionDigits: 0, minimumIntegerDigits: 2}), "1.5 fr decimal   true 2 0 0", "toLocaleString");
+
+	var opts = new Intl.NumberFormat("de", {style: "currency", currency: "USD", currencyDisplay: "code"}).resolvedOptions();
+	assert.sameValue(opts.locale, "de", "locale");
+	assert.sameValue(opts.style, "currency", "style");
+	assert.sameValue(opts.currency, "USD", "currency");
+	assert.sameValue(opts.currencyDisplay, "code", "currencyDisplay");
+	assert.sameValue(opts.useGrouping, true, "useGrouping");
+	assert.sameValue(opts.minimumIntegerDigits, 1, "minimumIntegerDigits");
+	assert.sameValue(Intl.NumberFormat.supportedLocalesOf(["de", "x y"]).join(), "de", "supportedLocalesOf");
+
+	function throws(f, type) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof type;
+		}
+		return false;
+	}
+	assert(throws(function() { new Intl.NumberFormat("en", {style: "unit"}); }, RangeError), "style");
+	assert(throws(function() { new Intl.NumberFormat("en", {style: "currency"}); }, TypeError), "no currency");
+	assert(throws(function() { new Intl.NumberFormat("en", {currency: "US"}); }, RangeError), "currency code");
+	assert(throws(function() { new Intl.NumberFormat("en", {maximumFractionDigits: 21}); }, RangeError), "maximumFractionDigits");
+	assert(throws(function() { new Intl.NumberFormat("en", {minimumFractionDigits: 3, maximumFractionDigits: 2}); }, RangeError), "max < min");
+	assert(throws(function() { Intl.NumberFormat.prototype.resolvedOptions.call({}); }, TypeError), "brand check");
+	`
+
+	r := New()
+	r.SetNumberFormatProvider(testNumberFormatProvider{})
+	if err := r.SetLocale("en-US"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIntlNumberFormatDefault(t *testing.T) {
+	p := textNumberFormatProvider{}
+	opts := &NumberFormatOptions{Style: "decimal", MinimumIntegerDigits: 1, MaximumFractionDigits: 3}
+	if s := p.FormatNumber(math.NaN(), opts); s != "NaN" {
+		t.Fatal(s)
+	}
+	if s := p.FormatNumber(math.Inf(-1), opts); s != "-∞" {
+		t.Fatal(s)
+	}
+
+	for _, test := range []struct {
+		x    float64
+		opts NumberFormatOptions
+		res  string
+	}{
+		{2.5, NumberFormatOptions{Locale: "en", Style: "decimal", MinimumIntegerDigits: 1}, "3"},
+		{-2.5, NumberFormatOptions{Locale: "en", Style: "decimal", MinimumIntegerDigits: 1}, "-3"},
+		{0.125, NumberFormatOptions{Locale: "en", Style: "decimal", MinimumIntegerDigits: 1, MaximumFractionDigits: 2}, "0.13"},
+		{1.005, NumberFormatOptions{Locale: "en", Style: "decimal", MinimumIntegerDigits: 1, MaximumFractionDigits: 2}, "1.01"},
+		{9.995, NumberFormatOptions{Locale: "en", Style: "decimal", MinimumIntegerDigits: 1, MaximumFractionDigits: 2}, "10"},
+		{0.125, NumberFormatOptions{Locale: "en", Style: "percent", MinimumIntegerDigits: 1}, "13%"},
+		{12.5, NumberFormatOptions{Locale: "en", Style: "currency", Currency: "JPY", CurrencyDisplay: "symbol", MinimumIntegerDigits: 1}, "¥13"},
+		{12.5, NumberFormatOptions{Locale: "en-US", Style: "currency", Currency: "USD", CurrencyDisplay: "symbol", MinimumIntegerDigits: 1, MinimumFractionDigits: 2, MaximumFractionDigits: 2}, "$12.50"},
+		{12.5, NumberFormatOptions{Locale: "de-DE", Style: "currency", Currency: "EUR", CurrencyDisplay: "symbol", MinimumIntegerDigits: 1, MinimumFractionDigits: 2, MaximumFractionDigits: 2}, "12,50\u00a0€"},
+		{-12.5, NumberFormatOptions{Locale: "fr", Style: "currency", Currency: "EUR", CurrencyDisplay: "code", MinimumIntegerDigits: 1, MinimumFractionDigits: 2, MaximumFractionDigits: 2}, "-12,50\u00a0EUR"},
+		{12.5, NumberFormatOptions{Locale: "de-CH", Style: "currency", Currency: "CHF", CurrencyDisplay: "code", MinimumIntegerDigits: 1, MinimumFractionDigits: 2, MaximumFractionDigits: 2}, "CHF\u00a012.50"},
+		{12.5, NumberFormatOptions{Locale: "es-MX", Style: "currency", Currency: "MXN", CurrencyDisplay: "narrowSymbol", MinimumIntegerDigits: 1, MinimumFractionDigits: 2, MaximumFractionDigits: 2}, "$12.50"},
+	} {
+		if s := p.FormatNumber(test.x, &test.opts); s != test.res {
+			t.Errorf("%v %+v: %q, expected %q", test.x, test.opts, s, test.res)
+		}
+	}
+}
+
+func TestIntlDateTimeFormat(t *testing.T) {
+	const SCRIPT = `
+	var d = Date.UTC(2016, 8, 1, 23, 5, 9);
+	var df = new Intl.DateTimeFormat("en-US", {timeZone: "UTC"});
+	assert.sameValue(df.format(d), "9/1/2016", "date by default");
+	assert.sameValue(df.format, df.format, "bound format");
+	assert.sameValue(new Intl.DateTimeFormat("de", {timeZone: "UTC", hour: "numeric"}).format(new Date(d)), "23", "time");
+	assert.sameValue(Intl.DateTimeFormat("de", {timeZone: "UTC", dateStyle: "short", timeStyle: "short"}).format(d), "1.9.2016, 23:05:09", "styles");
+
+	var parts = new Intl.DateTimeFormat("en-US", {timeZone: "UTC", year: "numeric", hour: "numeric"}).formatToParts(d);
+	assert.sameValue(parts.map(function(p) { return p.type; }).join(),
+		"year,literal,hour,literal,dayPeriod", "part types");
+	assert.sameValue(parts.map(function(p) { return p.value; }).join(""), "2016, 11 PM", "part values");
+	parts = new Intl.DateTimeFormat("en-US", {timeZone: "UTC", hour: "numeric", minute: "2-digit"}).formatToParts(d);
+	assert.sameValue(parts.map(function(p) { return p.type; }).join(), "hour,literal,minute,literal,dayPeriod", "no seconds");
+
+	var epoch = new Date(0);
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", year: "numeric", month: "long", day: "numeric"}).format(epoch),
+		"January 1, 1970", "long month");
+	assert.sameValue(new Intl.DateTimeFormat("en-GB", {timeZone: "UTC", year: "numeric", month: "short", day: "numeric"}).format(epoch),
+		"1 Jan 1970", "short month");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", weekday: "long", month: "numeric", day: "numeric"}).format(epoch),
+		"Thursday, 1/1", "weekday");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", month: "narrow"}).format(epoch), "J", "narrow month");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", month: "2-digit", year: "numeric"}).format(epoch), "01/1970", "2-digit");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", hour: "numeric", hour12: false}).format(d), "23", "hour12");
+	assert.sameValue(new Intl.DateTimeFormat("de", {timeZone: "UTC", timeStyle: "medium", hourCycle: "h12"}).format(d), "11:05:09 PM", "hourCycle");
+	var longOpts = new Intl.DateTimeFormat("en", {month: "long", weekday: "short"}).resolvedOptions();
+	assert.sameValue(longOpts.month, "long", "resolved month");
+	assert.sameValue(longOpts.weekday, "short", "resolved weekday");
+	assert.sameValue(longOpts.year, undefined, "resolved year");
+	assert(longOpts.timeZone !== "Local", "local time zone name");
+
+	var opts = new Intl.DateTimeFormat("en-GB", {timeZone: "UTC", timeStyle: "medium"}).resolvedOptions();
+	assert.sameValue(opts.locale, "en-GB", "locale");
+	assert.sameValue(opts.timeZone, "UTC", "timeZone");
+	assert.sameValue(opts.hour12, false, "hour12");
+	assert.sameValue(opts.hour, "2-digit", "hour");
+	assert.sameValue(opts.year, undefined, "year");
+
+	// the options which cannot be honoured fall back
+	assert.sameValue(new Intl.DateTimeFormat("de", {timeZone: "UTC", month: "long", day: "numeric"}).format(d), "1.9", "month name");
+	assert.sameValue(new Intl.DateTimeFormat("de", {month: "long"}).resolvedOptions().month, "numeric", "resolved month name");
+	assert.sameValue(new Date(d).toLocaleDateString("fr-FR", {timeZone: "UTC", weekday: "long"}), "Thursday", "weekday name");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", hour: "numeric", hourCycle: "h24"}).format(d), "23", "h24");
+	assert.sameValue(new Intl.DateTimeFormat("en-US", {timeZone: "UTC", hour: "numeric", hourCycle: "h11"}).format(d), "11 PM", "h11");
+	var unsupported = new Intl.DateTimeFormat("en-US", {timeZone: "UTC", year: "numeric", era: "long", timeZoneName: "short",
+		dayPeriod: "short", fractionalSecondDigits: 2});
+	assert.sameValue(unsupported.format(d), "2016", "unsupported components");
+	assert.sameValue(unsupported.resolvedOptions().era, undefined, "resolved era");
+	assert.sameValue(unsupported.resolvedOptions().timeZoneName, undefined, "resolved timeZoneName");
+	assert.sameValue(typeof new Date(d).toLocaleString("en-US", {timeZoneName: "short"}), "string", "toLocaleString with timeZoneName");
+
+	var d1 = new Date(d);
+	assert.sameValue(d1.toLocaleDateString("en-US", {timeZone: "UTC", hour: "numeric"}), "9/1/2016, 11 PM", "toLocaleDateString with time");
+	assert.sameValue(d1.toLocaleString("en-US", {timeZone: "UTC", year: "numeric"}), "2016", "toLocaleString date only");
+
+	function throws(f, type) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof type;
+		}
+		return false;
+	}
+	assert(throws(function() { df.format(NaN); }, RangeError), "NaN");
+	assert(throws(function() { new Intl.DateTimeFormat("en", {dateStyle: "short", year: "numeric"}); }, TypeError), "style and component");
+	assert(throws(function() { new Intl.DateTimeFormat("en", {dateStyle: "tiny"}); }, RangeError), "invalid style");
+	assert(throws(function() { Intl.DateTimeFormat.prototype.formatToParts.call({}); }, TypeError), "brand check");
+	assert(throws(function() { new Intl.DateTimeFormat("en", {era: "full"}); }, RangeError), "unsupported option value");
+	assert(throws(function() { new Intl.DateTimeFormat("en", {fractionalSecondDigits: 4}); }, RangeError), "fractionalSecondDigits");
+	assert(throws(function() { new Intl.DateTimeFormat("en", {month: "full"}); }, RangeError), "invalid style value");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestIntlCollator(t *testing.T) {
+	const SCRIPT = `
+	var c = new Intl.Collator("en", {sensitivity: "base", numeric: true});
+	assert.sameValue(c.compare("a", "b"), -1, "compare");
+	assert.sameValue(c.compare, c.compare, "bound compare");
+	assert.sameValue(["c", "a", "b"].sort(c.compare).join(), "a,b,c", "sort");
+	assert.sameValue(Intl.Collator().compare("b", "a"), 1, "call");
+	assert.sameValue("a".localeCompare("b", "de", {usage: "search"}), -1, "localeCompare");
+
+	var opts = c.resolvedOptions();
+	assert.sameValue(opts.locale, "en", "locale");
+	assert.sameValue(opts.usage, "sort", "usage");
+	assert.sameValue(opts.sensitivity, "base", "sensitivity");
+	assert.sameValue(opts.numeric, true, "numeric");
+	assert.sameValue(new Intl.Collator().resolvedOptions().sensitivity, "variant", "default sensitivity");
+
+	var thrown = false;
+	try {
+		new Intl.Collator("en", {sensitivity: "none"});
+	} catch (e) {
+		thrown = e instanceof RangeError;
+	}
+	assert(thrown, "invalid sensitivity");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+type testPluralRulesProvider struct{}
+
+func (testPluralRulesProvider) PluralCategory(locale string, n string, ordinal bool) string {
+	return fmt.Sprintf("%s %s %v", locale, n, ordinal)
+}
+
+func TestIntlPluralRules(t *testing.T) {
+	const SCRIPT = `
+	var pr = new Intl.PluralRules("pl");
+	assert.sameValue(pr.select(1), "pl 1 false", "integer");
+	assert.sameValue(pr.select(-1.5), "pl 1.5 false", "negative");
+	assert.sameValue(pr.select(0.12345), "pl 0.123 false", "maximumFractionDigits");
+	assert.sameValue(pr.select(NaN), "other", "NaN");
+	assert.sameValue(Intl.PluralRules("en", {type: "ordinal", minimumFractionDigits: 2}).select(2), "en 2.00 true", "ordinal");
+	assert.sameValue(new Intl.PluralRules("en", {minimumFractionDigits: 1, maximumFractionDigits: 4}).select(1.25), "en 1.25 false", "trimmed");
+
+	var opts = new Intl.PluralRules("en", {type: "ordinal"}).resolvedOptions();
+	assert.sameValue(opts.locale, "en", "locale");
+	assert.sameValue(opts.type, "ordinal", "type");
+	assert.sameValue(opts.minimumFractionDigits, 0, "minimumFractionDigits");
+	assert.sameValue(opts.maximumFractionDigits, 3, "maximumFractionDigits");
+
+	var thrown = false;
+	try {
+		new Intl.PluralRules("en", {type: "dual"});
+	} catch (e) {
+		thrown = e instanceof RangeError;
+	}
+	assert(thrown, "invalid type");
+	`
+
+	r := New()
+	r.SetPluralRulesProvider(testPluralRulesProvider{})
+	_, err := r.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIntlPluralRulesDefault(t *testing.T) {
+	p := textPluralRulesProvider{}
+	if s := p.PluralCategory("en", "1", false); s != "one" {
+		t.Fatal(s)
+	}
+	if s := p.PluralCategory("en", "2", false); s != "other" {
+		t.Fatal(s)
+	}
+	if s := p.PluralCategory("en", "1.0", false); s != "other" {
+		t.Fatal(s)
+	}
+}
+
+func TestIntlRelativeTimeFormat(t *testing.T) {
+	const SCRIPT = `
+	var rtf = new Intl.RelativeTimeFormat("en");
+	assert.sameValue(rtf.format(3, "days"), "in 3 days", "future");
+	assert.sameValue(rtf.format(-1, "day"), "1 day ago", "past");
+	assert.sameValue(rtf.format(-0, "second"), "0 seconds ago", "negative zero");
+	assert.sameValue(new Intl.RelativeTimeFormat("en", {style: "short"}).format(2, "quarter"), "in 2 qtrs.", "short");
+
+	var auto = new Intl.RelativeTimeFormat("en", {numeric: "auto"});
+	assert.sameValue(auto.format(-1, "day"), "yesterday", "yesterday");
+	assert.sameValue(auto.format(0, "seconds"), "now", "now");
+	assert.sameValue(auto.format(1, "year"), "next year", "next year");
+	assert.sameValue(auto.format(1, "second"), "in 1 second", "no special phrase");
+	assert.sameValue(auto.format(2, "week"), "in 2 weeks", "numeric");
+
+	var opts = new Intl.RelativeTimeFormat("de", {style: "narrow"}).resolvedOptions();
+	assert.sameValue(opts.locale, "en", "locale");
+	assert.sameValue(opts.style, "narrow", "style");
+	assert.sameValue(opts.numeric, "always", "numeric");
+
+	function parts(p) {
+		return p.map(function(part) {
+			return part.type + ":" + part.value + (part.unit ? ":" + part.unit : "");
+		}).join("|");
+	}
+	assert.sameValue(parts(rtf.formatToParts(1234.5, "days")), "literal:in |integer:1:day|group:,:day|integer:234:day|decimal:.:day|fraction:5:day|literal: days", "formatToParts");
+	assert.sameValue(parts(rtf.formatToParts(-2, "hour")), "integer:2:hour|literal: hours ago", "formatToParts past");
+	assert.sameValue(parts(auto.formatToParts(-1, "day")), "literal:yesterday", "formatToParts without a number");
+
+	function throws(f, type) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof type;
+		}
+		return false;
+	}
+	assert(throws(function() { rtf.format(1, "decade"); }, RangeError), "unit");
+	assert(throws(function() { rtf.format(Infinity, "day"); }, RangeError), "value");
+	assert(throws(function() { Intl.RelativeTimeFormat.prototype.format.call({}, 1, "day"); }, TypeError), "brand check");
+	assert(throws(function() { rtf.formatToParts(1, "decade"); }, RangeError), "formatToParts unit");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+type testRelativeTimeFormatProvider struct{}
+
+func (testRelativeTimeFormatProvider) FormatRelativeTime(value float64, unit string, options *RelativeTimeFormatOptions) string {
+	return fmt.Sprintf("%s:%v %s", options.Locale, value, unit)
+}
+
+func TestIntlRelativeTimeFormatProvider(t *testing.T) {
+	r := New()
+	r.SetRelativeTimeFormatProvider(testRelativeTimeFormatProvider{})
+	v, err := r.RunString(`
+	var rtf = new Intl.RelativeTimeFormat("de");
+	rtf.resolvedOptions().locale + "," + rtf.format(2, "days");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "de,de:2 day" {
+		t.Fatal(s)
+	}
+}
@@ -6,15 +6,32 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strconv"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
+const jsonCircularErrorMsg = "Converting circular structure to JSON"
+
 var hex = "0123456789abcdef"
 
 func (r *Runtime) builtinJSON_parse(call FunctionCall) Value {
-	d := json.NewDecoder(bytes.NewBufferString(call.Argument(0).String()))
+	text := call.Argument(0).String()
+	d := json.NewDecoder(bytes.NewBufferString(text))
+
+	var reviver func(FunctionCall) Value
+
+	if arg1 := call.Argument(1); arg1 != _undefined {
+		reviver, _ = arg1.ToObject(r).self.assertCallable()
+	}
+
+	sources := &jsonSources{text: text}
+	if reviver != nil {
+		sources.sources = make(map[*Object]map[string]jsonSource)
+	}
 
-	value, err := r.builtinJSON_decodeValue(d)
+	value, err := r.builtinJSON_decodeValue(d, sources)
 	if err != nil {
 		panic(r.newError(r.global.SyntaxError, err.Error()))
 	}
@@ -23,29 +40,125 @@ func (r *Runtime) builtinJSON_parse(call FunctionCall) Value {
 		panic(r.newError(r.global.SyntaxError, "Unexpected token at the end: %v", tok))
 	}
 
-	var reviver func(FunctionCall) Value
-
-	if arg1 := call.Argument(1); arg1 != _undefined {
-		reviver, _ = arg1.ToObject(r).self.assertCallable()
-	}
-
 	if reviver != nil {
 		root := r.NewObject()
 		root.self.putStr("", value, false)
-		return r.builtinJSON_reviveWalk(reviver, root, stringEmpty)
+		sources.record(root, "", value)
+		return r.builtinJSON_reviveWalk(reviver, root, stringEmpty, sources)
 	}
 
 	return value
 }
 
-func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, tok json.Token) (Value, error) {
+// jsonSources tracks the source text of the tokens parsed by JSON.parse(). If sources is not nil it records the
+// source text of the primitive values which is passed to the reviver in the 'source' property of its context
+// argument.
+type jsonSources struct {
+	text    string
+	offset  int64  // the offset after the last token
+	last    string // the source text of the last token
+	sources map[*Object]map[string]jsonSource
+}
+
+type jsonSource struct {
+	value Value
+	text  string
+}
+
+// token reads the next token and its source text.
+func (s *jsonSources) token(d *json.Decoder) (json.Token, error) {
+	tok, err := d.Token()
+	if err == nil {
+		offset := d.InputOffset()
+		// the token may be preceded by whitespace and a separator
+		s.last = strings.TrimLeft(s.text[s.offset:offset], " \t\r\n,:")
+		s.offset = offset
+		if str, ok := tok.(string); ok && strings.ContainsRune(str, utf8.RuneError) {
+			// encoding/json replaces the unpaired surrogates with U+FFFD
+			tok = unquoteJSONString(s.last)
+		}
+	}
+	return tok, err
+}
+
+// unquoteJSONString decodes a string literal which has been validated by encoding/json. The unpaired surrogates
+// are kept in the WTF-8 encoding which newStringValue() decodes.
+func unquoteJSONString(lit string) string {
+	lit = lit[1 : len(lit)-1]
+	b := make([]byte, 0, len(lit))
+	for i := 0; i < len(lit); i++ {
+		c := lit[i]
+		if c != '\\' {
+			b = append(b, c)
+			continue
+		}
+		i++
+		switch c = lit[i]; c {
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case 'u':
+			r, _ := strconv.ParseUint(lit[i+1:i+5], 16, 16)
+			i += 4
+			if isUTF16FirstSurrogate(rune(r)) && i+6 < len(lit) && lit[i+1] == '\\' && lit[i+2] == 'u' {
+				if r2, err := strconv.ParseUint(lit[i+3:i+7], 16, 16); err == nil && isUTF16SecondSurrogate(rune(r2)) {
+					b = utf8.AppendRune(b, utf16.DecodeRune(rune(r), rune(r2)))
+					i += 6
+					continue
+				}
+			}
+			b = appendWTF8(b, rune(r))
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// record records the source of a primitive value of the property name of holder.
+func (s *jsonSources) record(holder *Object, name string, value Value) {
+	if s.sources == nil {
+		return
+	}
+	if _, isObj := value.(*Object); isObj {
+		return
+	}
+	m := s.sources[holder]
+	if m == nil {
+		m = make(map[string]jsonSource)
+		s.sources[holder] = m
+	}
+	m[name] = jsonSource{value: value, text: s.last}
+}
+
+// source returns the source text of the property name of holder if its value is the one that was parsed.
+func (s *jsonSources) source(holder *Object, name string, value Value) (string, bool) {
+	src, exists := s.sources[holder][name]
+	if !exists {
+		return "", false
+	}
+	if _, ok := src.value.(valueInt); ok {
+		// valueInt.SameAs() doesn't handle the integral floats
+		return src.text, value.SameAs(src.value)
+	}
+	return src.text, src.value.SameAs(value)
+}
+
+func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, tok json.Token, s *jsonSources) (Value, error) {
 	switch tok := tok.(type) {
 	case json.Delim:
 		switch tok {
 		case '{':
-			return r.builtinJSON_decodeObject(d)
+			return r.builtinJSON_decodeObject(d, s)
 		case '[':
-			return r.builtinJSON_decodeArray(d)
+			return r.builtinJSON_decodeArray(d, s)
 		}
 	case nil:
 		return _null, nil
@@ -62,28 +175,36 @@ func (r *Runtime) builtinJSON_decodeToken(d *json.Decoder, tok json.Token) (Valu
 	return nil, fmt.Errorf("Unexpected token (%T): %v", tok, tok)
 }
 
-func (r *Runtime) builtinJSON_decodeValue(d *json.Decoder) (Value, error) {
-	tok, err := d.Token()
+// builtinJSON_decodeValue decodes the next value. If s.sources is not nil the sources of the primitive values are recorded
+// (except the one of the value itself, which has no holder yet).
+func (r *Runtime) builtinJSON_decodeValue(d *json.Decoder, s *jsonSources) (Value, error) {
+	tok, err := s.token(d)
 	if err != nil {
 		return nil, err
 	}
-	return r.builtinJSON_decodeToken(d, tok)
+	return r.builtinJSON_decodeToken(d, tok, s)
 }
 
-func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder) (*Object, error) {
-	object := r.NewObject()
+func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder, s *jsonSources) (*Object, error) {
+	var object *Object
+	if r.jsonParseNullPrototype {
+		object = r.newBaseObject(nil, classObject).val
+	} else {
+		object = r.NewObject()
+	}
 	for {
-		key, end, err := r.builtinJSON_decodeObjectKey(d)
+		key, end, err := r.builtinJSON_decodeObjectKey(d, s)
 		if err != nil {
 			return nil, err
 		}
 		if end {
 			break
 		}
-		value, err := r.builtinJSON_decodeValue(d)
+		value, err := r.builtinJSON_decodeValue(d, s)
 		if err != nil {
 			return nil, err
 		}
+		s.record(object, key, value)
 
 		if key == "__proto__" {
 			descr := r.NewObject().self
@@ -99,8 +220,8 @@ func (r *Runtime) builtinJSON_decodeObject(d *json.Decoder) (*Object, error) {
 	return object, nil
 }
 
-func (r *Runtime) builtinJSON_decodeObjectKey(d *json.Decoder) (string, bool, error) {
-	tok, err := d.Token()
+func (r *Runtime) builtinJSON_decodeObjectKey(d *json.Decoder, s *jsonSources) (string, bool, error) {
+	tok, err := s.token(d)
 	if err != nil {
 		return "", false, err
 	}
@@ -116,10 +237,11 @@ func (r *Runtime) builtinJSON_decodeObjectKey(d *json.Decoder) (string, bool, er
 	return "", false, fmt.Errorf("Unexpected token (%T): %v", tok, tok)
 }
 
-func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder) (*Object, error) {
+func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder, s *jsonSources) (*Object, error) {
 	var arrayValue []Value
+	var texts []string
 	for {
-		tok, err := d.Token()
+		tok, err := s.token(d)
 		if err != nil {
 			return nil, err
 		}
@@ -128,13 +250,23 @@ func (r *Runtime) builtinJSON_decodeArray(d *json.Decoder) (*Object, error) {
 				break
 			}
 		}
-		value, err := r.builtinJSON_decodeToken(d, tok)
+		value, err := r.builtinJSON_decodeToken(d, tok, s)
 		if err != nil {
 			return nil, err
 		}
 		arrayValue = append(arrayValue, value)
+		if s.sources != nil {
+			texts = append(texts, s.last)
+		}
+	}
+	array := r.newArrayValues(arrayValue)
+	if s.sources != nil {
+		for i, value := range arrayValue {
+			s.last = texts[i]
+			s.record(array, strconv.Itoa(i), value)
+		}
 	}
-	return r.newArrayValues(arrayValue), nil
+	return array, nil
 }
 
 func isArray(object *Object) bool {
@@ -146,18 +278,18 @@ func isArray(object *Object) bool {
 	}
 }
 
-func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holder *Object, name Value) Value {
+func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holder *Object, name Value, s *jsonSources) Value {
 	value := holder.self.get(name)
 	if value == nil {
 		value = _undefined
 	}
 
-	if object := value.(*Object); object != nil {
+	if object, ok := value.(*Object); ok {
 		if isArray(object) {
 			length := object.self.getStr("length").ToInteger()
 			for index := int64(0); index < length; index++ {
 				name := intToValue(index)
-				value := r.builtinJSON_reviveWalk(reviver, object, name)
+				value := r.builtinJSON_reviveWalk(reviver, object, name, s)
 				if value == _undefined {
 					object.self.delete(name, false)
 				} else {
@@ -166,7 +298,7 @@ func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holde
 			}
 		} else {
 			for item, f := object.self.enumerate(false, false)(); f != nil; item, f = f() {
-				value := r.builtinJSON_reviveWalk(reviver, object, name)
+				value := r.builtinJSON_reviveWalk(reviver, object, newStringValue(item.name), s)
 				if value == _undefined {
 					object.self.deleteStr(item.name, false)
 				} else {
@@ -175,24 +307,70 @@ func (r *Runtime) builtinJSON_reviveWalk(reviver func(FunctionCall) Value, holde
 			}
 		}
 	}
+	context := r.NewObject()
+	if text, ok := s.source(holder, name.String(), value); ok {
+		context.self.putStr("source", newStringValue(text), false)
+	}
 	return reviver(FunctionCall{
 		This:      holder,
-		Arguments: []Value{name, value},
+		Arguments: []Value{name, value, context},
 	})
 }
 
+// rawJSONObject is an object created by JSON.rawJSON(), it is serialised by JSON.stringify() as its raw text.
+type rawJSONObject struct {
+	baseObject
+	text string
+}
+
+func (r *Runtime) builtinJSON_rawJSON(call FunctionCall) Value {
+	text := call.Argument(0).String()
+	if text == "" || strings.IndexByte("\t\n\r ", text[0]) != -1 || strings.IndexByte("\t\n\r ", text[len(text)-1]) != -1 {
+		panic(r.newError(r.global.SyntaxError, "Invalid value for JSON.rawJSON"))
+	}
+	if !json.Valid([]byte(text)) || text[0] == '{' || text[0] == '[' {
+		panic(r.newError(r.global.SyntaxError, "Invalid value for JSON.rawJSON"))
+	}
+
+	v := &Object{runtime: r}
+	o := &rawJSONObject{
+		baseObject: baseObject{
+			class: classObject,
+			val:   v,
+		},
+		text: text,
+	}
+	v.self = o
+	o.init()
+	o._putProp("rawJSON", newStringValue(text), false, true, false)
+	o.extensible = false
+	return v
+}
+
+func (r *Runtime) builtinJSON_isRawJSON(call FunctionCall) Value {
+	if o, ok := call.Argument(0).(*Object); ok {
+		if _, ok := o.self.(*rawJSONObject); ok {
+			return valueTrue
+		}
+	}
+	return valueFalse
+}
+
 type _builtinJSON_stringifyContext struct {
 	r                *Runtime
 	stack            []*Object
+	keys             []Value // the keys of the objects in stack
 	propertyList     []Value
 	replacerFunction func(FunctionCall) Value
 	gap, indent      string
 	buf              bytes.Buffer
+	mem              memoryCharger
 }
 
 func (r *Runtime) builtinJSON_stringify(call FunctionCall) Value {
 	ctx := _builtinJSON_stringifyContext{
-		r: r,
+		r:   r,
+		mem: memoryCharger{r: r},
 	}
 
 	replacer, _ := call.Argument(1).(*Object)
@@ -269,12 +447,14 @@ func (r *Runtime) builtinJSON_stringify(call FunctionCall) Value {
 	holder := r.NewObject()
 	holder.self.putStr("", call.Argument(0), false)
 	if ctx.str(stringEmpty, holder) {
+		ctx.mem.charge(ctx.buf.Len())
 		return newStringValue(ctx.buf.String())
 	}
 	return _undefined
 }
 
 func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
+	ctx.mem.charge(ctx.buf.Len())
 	value := holder.self.get(key)
 	if value == nil {
 		value = _undefined
@@ -339,18 +519,43 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 		if !math.IsNaN(float64(value1)) && !math.IsInf(float64(value1), 0) {
 			ctx.buf.WriteString(value.String())
 		} else {
-			ctx.buf.WriteString("null")
+			switch ctx.r.jsonNonFiniteNumbers {
+			case JSONNonFiniteAsString:
+				ctx.quote(value1.ToString())
+			case JSONNonFiniteError:
+				ctx.r.typeErrorResult(true, "Cannot convert %s to JSON (key '%s')", value1.String(), key.String())
+			default:
+				ctx.buf.WriteString("null")
+			}
 		}
 	case valueNull:
 		ctx.buf.WriteString("null")
 	case *Object:
-		for _, object := range ctx.stack {
+		if raw, ok := value1.self.(*rawJSONObject); ok {
+			ctx.buf.WriteString(raw.text)
+			return true
+		}
+		for i, object := range ctx.stack {
 			if value1 == object {
-				ctx.r.typeErrorResult(true, "Converting circular structure to JSON")
+				switch ctx.r.jsonCircularReferences {
+				case JSONCircularAsMarker:
+					ctx.quote(asciiString("[Circular]"))
+					return true
+				case JSONCircularErrorWithPath:
+					l := len(ctx.keys)
+					ctx.r.typeErrorResult(true, "%s: property '%s' refers to '%s'", jsonCircularErrorMsg,
+						jsonPath(append(ctx.keys[1:l:l], key)), jsonPath(ctx.keys[1:i+1]))
+				default:
+					ctx.r.typeErrorResult(true, jsonCircularErrorMsg)
+				}
 			}
 		}
 		ctx.stack = append(ctx.stack, value1)
-		defer func() { ctx.stack = ctx.stack[:len(ctx.stack)-1] }()
+		ctx.keys = append(ctx.keys, key)
+		defer func() {
+			ctx.stack = ctx.stack[:len(ctx.stack)-1]
+			ctx.keys = ctx.keys[:len(ctx.keys)-1]
+		}()
 		if _, ok := value1.self.assertCallable(); !ok {
 			if isArray(value1) {
 				ctx.ja(value1)
@@ -366,6 +571,27 @@ func (ctx *_builtinJSON_stringifyContext) str(key Value, holder *Object) bool {
 	return true
 }
 
+// jsonPath returns the property path consisting of the keys, e.g. a.b[0].c
+func jsonPath(keys []Value) string {
+	if len(keys) == 0 {
+		return "(root)"
+	}
+	var buf bytes.Buffer
+	for i, key := range keys {
+		if _, ok := key.(valueInt); ok {
+			buf.WriteByte('[')
+			buf.WriteString(key.String())
+			buf.WriteByte(']')
+		} else {
+			if i > 0 {
+				buf.WriteByte('.')
+			}
+			buf.WriteString(key.String())
+		}
+	}
+	return buf.String()
+}
+
 func (ctx *_builtinJSON_stringifyContext) ja(array *Object) {
 	var stepback string
 	if ctx.gap != "" {
@@ -470,6 +696,15 @@ func (ctx *_builtinJSON_stringifyContext) quote(str valueString) {
 	for {
 		r, _, err := reader.ReadRune()
 		if err != nil {
+			if err == InvalidRuneError {
+				// An unpaired surrogate
+				ctx.buf.WriteString(`\u`)
+				ctx.buf.WriteByte(hex[r>>12])
+				ctx.buf.WriteByte(hex[(r>>8)&0xF])
+				ctx.buf.WriteByte(hex[(r>>4)&0xF])
+				ctx.buf.WriteByte(hex[r&0xF])
+				continue
+			}
 			break
 		}
 		switch r {
@@ -503,6 +738,8 @@ func (r *Runtime) initJSON() {
 	JSON := r.newBaseObject(r.global.ObjectPrototype, "JSON")
 	JSON._putProp("parse", r.newNativeFunc(r.builtinJSON_parse, nil, "parse", nil, 2), true, false, true)
 	JSON._putProp("stringify", r.newNativeFunc(r.builtinJSON_stringify, nil, "stringify", nil, 3), true, false, true)
+	JSON._putProp("rawJSON", r.newNativeFunc(r.builtinJSON_rawJSON, nil, "rawJSON", nil, 1), true, false, true)
+	JSON._putProp("isRawJSON", r.newNativeFunc(r.builtinJSON_isRawJSON, nil, "isRawJSON", nil, 1), true, false, true)
 
 	r.addToGlobal("JSON", JSON.val)
 }
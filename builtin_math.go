@@ -2,6 +2,7 @@ package goja
 
 import (
 	"math"
+	"math/bits"
 )
 
 func (r *Runtime) math_abs(call FunctionCall) Value {
@@ -12,10 +13,18 @@ func (r *Runtime) math_acos(call FunctionCall) Value {
 	return floatToValue(math.Acos(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_acosh(call FunctionCall) Value {
+	return floatToValue(math.Acosh(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_asin(call FunctionCall) Value {
 	return floatToValue(math.Asin(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_asinh(call FunctionCall) Value {
+	return floatToValue(math.Asinh(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_atan(call FunctionCall) Value {
 	return floatToValue(math.Atan(call.Argument(0).ToFloat()))
 }
@@ -27,26 +36,77 @@ func (r *Runtime) math_atan2(call FunctionCall) Value {
 	return floatToValue(math.Atan2(y, x))
 }
 
+func (r *Runtime) math_atanh(call FunctionCall) Value {
+	return floatToValue(math.Atanh(call.Argument(0).ToFloat()))
+}
+
+func (r *Runtime) math_cbrt(call FunctionCall) Value {
+	return floatToValue(math.Cbrt(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_ceil(call FunctionCall) Value {
 	return floatToValue(math.Ceil(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_clz32(call FunctionCall) Value {
+	return intToValue(int64(bits.LeadingZeros32(toUInt32(call.Argument(0)))))
+}
+
 func (r *Runtime) math_cos(call FunctionCall) Value {
 	return floatToValue(math.Cos(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_cosh(call FunctionCall) Value {
+	return floatToValue(math.Cosh(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_exp(call FunctionCall) Value {
 	return floatToValue(math.Exp(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_expm1(call FunctionCall) Value {
+	return floatToValue(math.Expm1(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_floor(call FunctionCall) Value {
 	return floatToValue(math.Floor(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_fround(call FunctionCall) Value {
+	return floatToValue(float64(float32(call.Argument(0).ToFloat())))
+}
+
+func (r *Runtime) math_hypot(call FunctionCall) Value {
+	var result float64
+	for _, arg := range call.Arguments {
+		// math.Hypot() returns Infinity if either argument is infinite, even if the other one is NaN
+		result = math.Hypot(result, arg.ToFloat())
+	}
+	return floatToValue(result)
+}
+
+func (r *Runtime) math_imul(call FunctionCall) Value {
+	x := toUInt32(call.Argument(0))
+	y := toUInt32(call.Argument(1))
+	return intToValue(int64(int32(x * y)))
+}
+
 func (r *Runtime) math_log(call FunctionCall) Value {
 	return floatToValue(math.Log(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_log10(call FunctionCall) Value {
+	return floatToValue(math.Log10(call.Argument(0).ToFloat()))
+}
+
+func (r *Runtime) math_log1p(call FunctionCall) Value {
+	return floatToValue(math.Log1p(call.Argument(0).ToFloat()))
+}
+
+func (r *Runtime) math_log2(call FunctionCall) Value {
+	return floatToValue(math.Log2(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_max(call FunctionCall) Value {
 	if len(call.Arguments) == 0 {
 		return _negativeInf
@@ -135,10 +195,26 @@ func (r *Runtime) math_round(call FunctionCall) Value {
 	return floatToValue(t)
 }
 
+func (r *Runtime) math_sign(call FunctionCall) Value {
+	arg := call.Argument(0)
+	num := arg.ToFloat()
+	if math.IsNaN(num) || num == 0 { // this will match -0 too
+		return arg.ToNumber()
+	}
+	if num > 0 {
+		return intToValue(1)
+	}
+	return intToValue(-1)
+}
+
 func (r *Runtime) math_sin(call FunctionCall) Value {
 	return floatToValue(math.Sin(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_sinh(call FunctionCall) Value {
+	return floatToValue(math.Sinh(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) math_sqrt(call FunctionCall) Value {
 	return floatToValue(math.Sqrt(call.Argument(0).ToFloat()))
 }
@@ -147,6 +223,14 @@ func (r *Runtime) math_tan(call FunctionCall) Value {
 	return floatToValue(math.Tan(call.Argument(0).ToFloat()))
 }
 
+func (r *Runtime) math_tanh(call FunctionCall) Value {
+	return floatToValue(math.Tanh(call.Argument(0).ToFloat()))
+}
+
+func (r *Runtime) math_trunc(call FunctionCall) Value {
+	return floatToValue(math.Trunc(call.Argument(0).ToFloat()))
+}
+
 func (r *Runtime) createMath(val *Object) objectImpl {
 	m := &baseObject{
 		class:      "Math",
@@ -167,22 +251,39 @@ func (r *Runtime) createMath(val *Object) objectImpl {
 
 	m._putProp("abs", r.newNativeFunc(r.math_abs, nil, "abs", nil, 1), true, false, true)
 	m._putProp("acos", r.newNativeFunc(r.math_acos, nil, "acos", nil, 1), true, false, true)
+	m._putProp("acosh", r.newNativeFunc(r.math_acosh, nil, "acosh", nil, 1), true, false, true)
 	m._putProp("asin", r.newNativeFunc(r.math_asin, nil, "asin", nil, 1), true, false, true)
+	m._putProp("asinh", r.newNativeFunc(r.math_asinh, nil, "asinh", nil, 1), true, false, true)
 	m._putProp("atan", r.newNativeFunc(r.math_atan, nil, "atan", nil, 1), true, false, true)
+	m._putProp("atanh", r.newNativeFunc(r.math_atanh, nil, "atanh", nil, 1), true, false, true)
 	m._putProp("atan2", r.newNativeFunc(r.math_atan2, nil, "atan2", nil, 2), true, false, true)
+	m._putProp("cbrt", r.newNativeFunc(r.math_cbrt, nil, "cbrt", nil, 1), true, false, true)
 	m._putProp("ceil", r.newNativeFunc(r.math_ceil, nil, "ceil", nil, 1), true, false, true)
+	m._putProp("clz32", r.newNativeFunc(r.math_clz32, nil, "clz32", nil, 1), true, false, true)
 	m._putProp("cos", r.newNativeFunc(r.math_cos, nil, "cos", nil, 1), true, false, true)
+	m._putProp("cosh", r.newNativeFunc(r.math_cosh, nil, "cosh", nil, 1), true, false, true)
 	m._putProp("exp", r.newNativeFunc(r.math_exp, nil, "exp", nil, 1), true, false, true)
+	m._putProp("expm1", r.newNativeFunc(r.math_expm1, nil, "expm1", nil, 1), true, false, true)
 	m._putProp("floor", r.newNativeFunc(r.math_floor, nil, "floor", nil, 1), true, false, true)
+	m._putProp("fround", r.newNativeFunc(r.math_fround, nil, "fround", nil, 1), true, false, true)
+	m._putProp("hypot", r.newNativeFunc(r.math_hypot, nil, "hypot", nil, 2), true, false, true)
+	m._putProp("imul", r.newNativeFunc(r.math_imul, nil, "imul", nil, 2), true, false, true)
 	m._putProp("log", r.newNativeFunc(r.math_log, nil, "log", nil, 1), true, false, true)
+	m._putProp("log1p", r.newNativeFunc(r.math_log1p, nil, "log1p", nil, 1), true, false, true)
+	m._putProp("log10", r.newNativeFunc(r.math_log10, nil, "log10", nil, 1), true, false, true)
+	m._putProp("log2", r.newNativeFunc(r.math_log2, nil, "log2", nil, 1), true, false, true)
 	m._putProp("max", r.newNativeFunc(r.math_max, nil, "max", nil, 2), true, false, true)
 	m._putProp("min", r.newNativeFunc(r.math_min, nil, "min", nil, 2), true, false, true)
 	m._putProp("pow", r.newNativeFunc(r.math_pow, nil, "pow", nil, 2), true, false, true)
 	m._putProp("random", r.newNativeFunc(r.math_random, nil, "random", nil, 0), true, false, true)
 	m._putProp("round", r.newNativeFunc(r.math_round, nil, "round", nil, 1), true, false, true)
+	m._putProp("sign", r.newNativeFunc(r.math_sign, nil, "sign", nil, 1), true, false, true)
 	m._putProp("sin", r.newNativeFunc(r.math_sin, nil, "sin", nil, 1), true, false, true)
+	m._putProp("sinh", r.newNativeFunc(r.math_sinh, nil, "sinh", nil, 1), true, false, true)
 	m._putProp("sqrt", r.newNativeFunc(r.math_sqrt, nil, "sqrt", nil, 1), true, false, true)
 	m._putProp("tan", r.newNativeFunc(r.math_tan, nil, "tan", nil, 1), true, false, true)
+	m._putProp("tanh", r.newNativeFunc(r.math_tanh, nil, "tanh", nil, 1), true, false, true)
+	m._putProp("trunc", r.newNativeFunc(r.math_trunc, nil, "trunc", nil, 1), true, false, true)
 
 	return m
 }
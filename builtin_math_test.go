@@ -0,0 +1,47 @@
+package goja
+
+import "testing"
+
+func TestMathES6(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(Math.trunc(-1.5), -1, "trunc");
+	assert.sameValue(Math.trunc(-0.5), -0, "trunc -0");
+	assert.sameValue(Math.trunc(Infinity), Infinity, "trunc Infinity");
+	assert.sameValue(Math.sign(-3), -1, "sign");
+	assert.sameValue(Math.sign(0.5), 1, "sign");
+	assert.sameValue(Math.sign(-0), -0, "sign -0");
+	assert.sameValue(Math.sign("x"), NaN, "sign NaN");
+	assert.sameValue(Math.cbrt(-27), -3, "cbrt");
+	assert.sameValue(Math.log2(8), 3, "log2");
+	assert.sameValue(Math.log10(1000), 3, "log10");
+	assert.sameValue(Math.log1p(-1), -Infinity, "log1p");
+	assert.sameValue(Math.log1p(-0), -0, "log1p -0");
+	assert.sameValue(Math.expm1(-0), -0, "expm1 -0");
+	assert.sameValue(Math.expm1(Infinity), Infinity, "expm1 Infinity");
+	assert.sameValue(Math.hypot(), 0, "hypot()");
+	assert.sameValue(Math.hypot(3, 4), 5, "hypot");
+	assert.sameValue(Math.hypot(-0), 0, "hypot -0");
+	assert.sameValue(Math.hypot(NaN, Infinity), Infinity, "hypot NaN, Infinity");
+	assert.sameValue(Math.hypot(NaN, 1), NaN, "hypot NaN");
+	assert.sameValue(Math.clz32(1), 31, "clz32");
+	assert.sameValue(Math.clz32(0), 32, "clz32 0");
+	assert.sameValue(Math.clz32(-1), 0, "clz32 -1");
+	assert.sameValue(Math.fround(5.5), 5.5, "fround");
+	assert.sameValue(Math.fround(5.05), 5.050000190734863, "fround");
+	assert.sameValue(Math.fround(-0), -0, "fround -0");
+	assert.sameValue(Math.imul(3, 4), 12, "imul");
+	assert.sameValue(Math.imul(0xffffffff, 5), -5, "imul overflow");
+	assert.sameValue(Math.imul(0x7fffffff, 2), -2, "imul wrap");
+	assert.sameValue(Math.sinh(-0), -0, "sinh -0");
+	assert.sameValue(Math.cosh(0), 1, "cosh");
+	assert.sameValue(Math.tanh(Infinity), 1, "tanh Infinity");
+	assert.sameValue(Math.asinh(-0), -0, "asinh -0");
+	assert.sameValue(Math.acosh(1), 0, "acosh");
+	assert.sameValue(Math.acosh(0.5), NaN, "acosh < 1");
+	assert.sameValue(Math.atanh(1), Infinity, "atanh 1");
+	assert.sameValue(Math.atanh(-0), -0, "atanh -0");
+	assert.sameValue(Math.hypot.length, 2, "hypot.length");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -2,49 +2,44 @@ package goja
 
 import (
 	"math"
-	"strconv"
 )
 
 func (r *Runtime) numberproto_valueOf(call FunctionCall) Value {
-	this := call.This
-	if !isNumber(this) {
-		r.typeErrorResult(true, "Value is not a number")
-	}
-	if _, ok := this.assertInt(); ok {
-		return this
-	}
-
-	if _, ok := this.assertFloat(); ok {
-		return this
-	}
+	return r.thisNumberValue(call.This)
+}
 
-	if obj, ok := this.(*Object); ok {
-		if v, ok := obj.self.(*primitiveValueObject); ok {
-			return v.pValue
+// thisNumberValue returns the primitive value of a number or a Number object (its [[NumberData]]). Other values
+// throw a TypeError, so that the Number.prototype methods are not generic.
+func (r *Runtime) thisNumberValue(v Value) Value {
+	switch t := v.(type) {
+	case valueInt, valueFloat:
+		return v
+	case *Object:
+		if pvo, ok := t.self.(*primitiveValueObject); ok {
+			switch pvo.pValue.(type) {
+			case valueInt, valueFloat:
+				return pvo.pValue
+			}
 		}
 	}
-
-	r.typeErrorResult(true, "Number.prototype.valueOf is not generic")
+	r.typeErrorResult(true, "Value is not a number")
 	return nil
 }
 
-func isNumber(v Value) bool {
-	switch t := v.(type) {
-	case valueFloat, valueInt:
-		return true
-	case *Object:
-		switch t := t.self.(type) {
-		case *primitiveValueObject:
-			return isNumber(t.pValue)
-		}
+func (r *Runtime) numberproto_toLocaleString(call FunctionCall) Value {
+	num := r.thisNumberValue(call.This)
+	if locales, options := call.Argument(0), call.Argument(1); locales != _undefined || options != _undefined {
+		opts := r.resolveNumberFormatOptions(locales, options)
+		return r.formatNumber(num.ToFloat(), &opts)
+	}
+	if !r.hasLocale() {
+		return r.numberproto_toString(FunctionCall{This: num})
 	}
-	return false
+	return r.localeFormatNumber(num.ToFloat())
 }
 
 func (r *Runtime) numberproto_toString(call FunctionCall) Value {
-	if !isNumber(call.This) {
-		r.typeErrorResult(true, "Value is not a number")
-	}
+	num := r.thisNumberValue(call.This).ToFloat()
 	var radix int
 	if arg := call.Argument(0); arg != _undefined {
 		radix = int(arg.ToInteger())
@@ -56,8 +51,6 @@ func (r *Runtime) numberproto_toString(call FunctionCall) Value {
 		panic(r.newError(r.global.RangeError, "toString() radix argument must be between 2 and 36"))
 	}
 
-	num := call.This.ToFloat()
-
 	if math.IsNaN(num) {
 		return stringNaN
 	}
@@ -71,64 +64,103 @@ func (r *Runtime) numberproto_toString(call FunctionCall) Value {
 	}
 
 	if radix == 10 {
-		var fmt byte
-		if math.Abs(num) >= 1e21 {
-			fmt = 'e'
-		} else {
-			fmt = 'f'
-		}
-		return asciiString(strconv.FormatFloat(num, fmt, -1, 64))
+		return asciiString(numberToString(num))
 	}
 
 	return asciiString(dtobasestr(num, radix))
 }
 
 func (r *Runtime) numberproto_toFixed(call FunctionCall) Value {
+	num := r.thisNumberValue(call.This).ToFloat()
 	prec := call.Argument(0).ToInteger()
 	if prec < 0 || prec > 20 {
 		panic(r.newError(r.global.RangeError, "toFixed() precision must be between 0 and 20"))
 	}
 
-	num := call.This.ToFloat()
 	if math.IsNaN(num) {
 		return stringNaN
 	}
 	if math.Abs(num) >= 1e21 {
-		return asciiString(strconv.FormatFloat(num, 'g', -1, 64))
+		return asciiString(numberToString(num))
 	}
-	return asciiString(strconv.FormatFloat(num, 'f', int(prec), 64))
+	return asciiString(numberToFixed(num, int(prec)))
 }
 
 func (r *Runtime) numberproto_toExponential(call FunctionCall) Value {
-	prec := call.Argument(0).ToInteger()
-	if prec < 0 || prec > 20 {
+	num := r.thisNumberValue(call.This).ToFloat()
+	arg := call.Argument(0)
+	prec := arg.ToInteger()
+
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return asciiString(numberToString(num))
+	}
+	if arg == _undefined {
+		prec = -1
+	} else if prec < 0 || prec > 20 {
 		panic(r.newError(r.global.RangeError, "toExponential() precision must be between 0 and 20"))
 	}
+	return asciiString(numberToExponential(num, int(prec)))
+}
 
-	num := call.This.ToFloat()
-	if math.IsNaN(num) {
-		return stringNaN
+func (r *Runtime) numberproto_toPrecision(call FunctionCall) Value {
+	numVal := r.thisNumberValue(call.This)
+	arg := call.Argument(0)
+	if arg == _undefined {
+		return numVal.ToString()
 	}
-	if math.Abs(num) >= 1e21 {
-		return asciiString(strconv.FormatFloat(num, 'g', -1, 64))
+	num := numVal.ToFloat()
+	prec := arg.ToInteger()
+
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return asciiString(numberToString(num))
+	}
+	if prec < 1 || prec > 21 {
+		panic(r.newError(r.global.RangeError, "toPrecision() precision must be between 1 and 21"))
 	}
-	return asciiString(strconv.FormatFloat(num, 'e', int(prec), 64))
+	return asciiString(numberToPrecision(num, int(prec)))
 }
 
-func (r *Runtime) numberproto_toPrecision(call FunctionCall) Value {
-	prec := call.Argument(0).ToInteger()
-	if prec < 0 || prec > 20 {
-		panic(r.newError(r.global.RangeError, "toPrecision() precision must be between 0 and 20"))
+func (r *Runtime) number_isFinite(call FunctionCall) Value {
+	switch arg := call.Argument(0).(type) {
+	case valueInt:
+		return valueTrue
+	case valueFloat:
+		f := float64(arg)
+		return r.toBoolean(!math.IsInf(f, 0) && !math.IsNaN(f))
+	default:
+		return valueFalse
 	}
+}
 
-	num := call.This.ToFloat()
-	if math.IsNaN(num) {
-		return stringNaN
+func (r *Runtime) number_isInteger(call FunctionCall) Value {
+	switch arg := call.Argument(0).(type) {
+	case valueInt:
+		return valueTrue
+	case valueFloat:
+		f := float64(arg)
+		return r.toBoolean(!math.IsInf(f, 0) && !math.IsNaN(f) && math.Trunc(f) == f)
+	default:
+		return valueFalse
 	}
-	if math.Abs(num) >= 1e21 {
-		return asciiString(strconv.FormatFloat(num, 'g', -1, 64))
+}
+
+func (r *Runtime) number_isNaN(call FunctionCall) Value {
+	if f, ok := call.Argument(0).(valueFloat); ok && math.IsNaN(float64(f)) {
+		return valueTrue
+	}
+	return valueFalse
+}
+
+func (r *Runtime) number_isSafeInteger(call FunctionCall) Value {
+	switch arg := call.Argument(0).(type) {
+	case valueInt:
+		return r.toBoolean(arg >= -(maxInt-1) && arg <= maxInt-1)
+	case valueFloat:
+		f := float64(arg)
+		return r.toBoolean(math.Trunc(f) == f && f >= -(maxInt-1) && f <= maxInt-1)
+	default:
+		return valueFalse
 	}
-	return asciiString(strconv.FormatFloat(num, 'g', int(prec), 64))
 }
 
 func (r *Runtime) initNumber() {
@@ -136,7 +168,7 @@ func (r *Runtime) initNumber() {
 	o := r.global.NumberPrototype.self
 	o._putProp("valueOf", r.newNativeFunc(r.numberproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
 	o._putProp("toString", r.newNativeFunc(r.numberproto_toString, nil, "toString", nil, 0), true, false, true)
-	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toString, nil, "toLocaleString", nil, 0), true, false, true)
+	o._putProp("toLocaleString", r.newNativeFunc(r.numberproto_toLocaleString, nil, "toLocaleString", nil, 0), true, false, true)
 	o._putProp("toFixed", r.newNativeFunc(r.numberproto_toFixed, nil, "toFixed", nil, 1), true, false, true)
 	o._putProp("toExponential", r.newNativeFunc(r.numberproto_toExponential, nil, "toExponential", nil, 1), true, false, true)
 	o._putProp("toPrecision", r.newNativeFunc(r.numberproto_toPrecision, nil, "toPrecision", nil, 1), true, false, true)
@@ -149,6 +181,12 @@ func (r *Runtime) initNumber() {
 	o._putProp("NEGATIVE_INFINITY", _negativeInf, false, false, false)
 	o._putProp("POSITIVE_INFINITY", _positiveInf, false, false, false)
 	o._putProp("EPSILON", _epsilon, false, false, false)
+	o._putProp("MAX_SAFE_INTEGER", valueInt(maxInt-1), false, false, false)
+	o._putProp("MIN_SAFE_INTEGER", valueInt(-(maxInt - 1)), false, false, false)
+	o._putProp("isFinite", r.newNativeFunc(r.number_isFinite, nil, "isFinite", nil, 1), true, false, true)
+	o._putProp("isInteger", r.newNativeFunc(r.number_isInteger, nil, "isInteger", nil, 1), true, false, true)
+	o._putProp("isNaN", r.newNativeFunc(r.number_isNaN, nil, "isNaN", nil, 1), true, false, true)
+	o._putProp("isSafeInteger", r.newNativeFunc(r.number_isSafeInteger, nil, "isSafeInteger", nil, 1), true, false, true)
 	r.addToGlobal("Number", r.global.Number)
 
 }
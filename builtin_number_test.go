@@ -0,0 +1,80 @@
+package goja
+
+import "testing"
+
+func TestNumberES6(t *testing.T) {
+	const SCRIPT = `
+	assert(Number.isInteger(1) && Number.isInteger(-0) && Number.isInteger(1e300), "isInteger");
+	assert(!Number.isInteger(1.5) && !Number.isInteger(Infinity) && !Number.isInteger(NaN) && !Number.isInteger("1"), "!isInteger");
+	assert(Number.isSafeInteger(Number.MAX_SAFE_INTEGER) && Number.isSafeInteger(Number.MIN_SAFE_INTEGER), "isSafeInteger");
+	assert(!Number.isSafeInteger(Number.MAX_SAFE_INTEGER + 1) && !Number.isSafeInteger(1.5) && !Number.isSafeInteger("1"), "!isSafeInteger");
+	assert.sameValue(Number.MAX_SAFE_INTEGER, 9007199254740991, "MAX_SAFE_INTEGER");
+	assert.sameValue(Number.MIN_SAFE_INTEGER, -9007199254740991, "MIN_SAFE_INTEGER");
+	assert(Number.isFinite(1) && Number.isFinite(1.5), "isFinite");
+	assert(!Number.isFinite(Infinity) && !Number.isFinite(NaN) && !Number.isFinite("1"), "!isFinite");
+	assert(Number.isNaN(NaN) && !Number.isNaN("x") && !Number.isNaN(undefined), "isNaN");
+	assert.sameValue(Number.parseInt, parseInt, "parseInt");
+	assert.sameValue(Number.parseFloat, parseFloat, "parseFloat");
+	assert.sameValue(Number.EPSILON, Math.pow(2, -52), "EPSILON");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNumberFormat(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(String(1e21), "1e+21", "1e21");
+	assert.sameValue(String(123456789012345680000), "123456789012345680000", "< 1e21");
+	assert.sameValue(String(1e-7), "1e-7", "1e-7");
+	assert.sameValue(String(1.5e-7), "1.5e-7", "1.5e-7");
+	assert.sameValue(String(0.000001), "0.000001", "1e-6");
+	assert.sameValue(String(-1.5e300), "-1.5e+300", "-1.5e300");
+	assert.sameValue(String(0.1 + 0.2), "0.30000000000000004", "0.1 + 0.2");
+	assert.sameValue(String(-0), "0", "-0");
+	assert.sameValue((255).toString(16), "ff", "radix");
+
+	assert.sameValue((0.5).toFixed(0), "1", "toFixed tie");
+	assert.sameValue((2.5).toFixed(0), "3", "toFixed tie");
+	assert.sameValue((-2.5).toFixed(0), "-3", "toFixed negative tie");
+	assert.sameValue((1.25).toFixed(1), "1.3", "toFixed exact tie");
+	assert.sameValue((1.005).toFixed(2), "1.00", "toFixed below tie");
+	assert.sameValue((0.004).toFixed(2), "0.00", "toFixed round to 0");
+	assert.sameValue((-0.0001).toFixed(2), "-0.00", "toFixed negative round to 0");
+	assert.sameValue((-0).toFixed(2), "0.00", "toFixed -0");
+	assert.sameValue((9.995).toFixed(2), "9.99", "toFixed 9.995");
+	assert.sameValue((99.5).toFixed(0), "100", "toFixed carry");
+	assert.sameValue((1000000000000000128).toFixed(0), "1000000000000000128", "toFixed exact");
+	assert.sameValue((1e21).toFixed(2), "1e+21", "toFixed >= 1e21");
+
+	assert.sameValue((123.456).toExponential(), "1.23456e+2", "toExponential undefined");
+	assert.sameValue((123.456).toExponential(1), "1.2e+2", "toExponential");
+	assert.sameValue((1.25).toExponential(1), "1.3e+0", "toExponential tie");
+	assert.sameValue((0.00015).toExponential(0), "1e-4", "toExponential small");
+	assert.sameValue((0).toExponential(2), "0.00e+0", "toExponential 0");
+	assert.sameValue((0).toExponential(), "0e+0", "toExponential 0 undefined");
+	assert.sameValue((-0).toExponential(), "0e+0", "toExponential -0 undefined");
+	assert.sameValue((-1e21).toExponential(), "-1e+21", "toExponential large");
+	assert.sameValue((Infinity).toExponential(1000), "Infinity", "toExponential Infinity");
+
+	assert.sameValue((123.456).toPrecision(), "123.456", "toPrecision undefined");
+	assert.sameValue((123.456).toPrecision(4), "123.5", "toPrecision");
+	assert.sameValue((123.456).toPrecision(2), "1.2e+2", "toPrecision exponential");
+	assert.sameValue((0.000123).toPrecision(2), "0.00012", "toPrecision small");
+	assert.sameValue((0.0000001).toPrecision(1), "1e-7", "toPrecision e < -6");
+	assert.sameValue((99.99).toPrecision(3), "100", "toPrecision carry");
+	assert.sameValue((999.99).toPrecision(3), "1.00e+3", "toPrecision carry exponential");
+	assert.sameValue((0).toPrecision(3), "0.00", "toPrecision 0");
+	assert.sameValue((2.5).toPrecision(1), "3", "toPrecision tie");
+	assert.sameValue((1).toPrecision(21), "1.00000000000000000000", "toPrecision 21");
+
+	var thrown = false;
+	try {
+		(1).toPrecision(0);
+	} catch (e) {
+		thrown = e instanceof RangeError;
+	}
+	assert(thrown, "toPrecision(0)");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
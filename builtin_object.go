@@ -14,6 +14,16 @@ func (r *Runtime) builtin_Object(args []Value, proto *Object) *Object {
 	return r.NewObject()
 }
 
+// object_is implements Object.is(), i.e. the SameValue algorithm.
+func (r *Runtime) object_is(call FunctionCall) Value {
+	x, y := call.Argument(0), call.Argument(1)
+	if _, ok := x.(valueInt); ok {
+		// valueInt.SameAs() doesn't handle the integral floats
+		x, y = y, x
+	}
+	return r.toBoolean(x.SameAs(y))
+}
+
 func (r *Runtime) object_getPrototypeOf(call FunctionCall) Value {
 	o := call.Argument(0).ToObject(r)
 	p := o.self.proto()
@@ -30,6 +40,22 @@ func (r *Runtime) object_getOwnPropertyDescriptor(call FunctionCall) Value {
 	if desc == nil {
 		return _undefined
 	}
+	return r.fromPropertyDescriptor(desc)
+}
+
+func (r *Runtime) object_getOwnPropertyDescriptors(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	result := r.NewObject()
+	for item, f := obj.self.enumerate(true, false)(); f != nil; item, f = f() {
+		if desc := obj.self.getOwnProp(item.name); desc != nil {
+			result.self._putProp(item.name, r.fromPropertyDescriptor(desc), true, true, true)
+		}
+	}
+	return result
+}
+
+// fromPropertyDescriptor converts the value returned by getOwnProp() into a descriptor object.
+func (r *Runtime) fromPropertyDescriptor(desc Value) *Object {
 	var writable, configurable, enumerable, accessor bool
 	var get, set *Object
 	var value Value
@@ -203,29 +229,39 @@ func (r *Runtime) object_seal(call FunctionCall) Value {
 	return arg
 }
 
+func (r *Runtime) freeze(obj *Object) {
+	r.freezeProperties(obj)
+	obj.self.preventExtensions()
+}
+
+// freezeProperties makes the own properties of obj non-configurable, and the data properties non-writable,
+// without preventing extensions.
+func (r *Runtime) freezeProperties(obj *Object) {
+	var descr objectImpl
+	for item, f := obj.self.enumerate(true, false)(); f != nil; item, f = f() {
+		v := obj.self.getOwnProp(item.name)
+		if prop, ok := v.(*valueProperty); ok {
+			prop.configurable = false
+			if prop.value != nil {
+				prop.writable = false
+			}
+		} else {
+			if descr == nil {
+				descr = r.NewObject().self
+				descr.putStr("writable", valueFalse, false)
+				descr.putStr("enumerable", valueTrue, false)
+				descr.putStr("configurable", valueFalse, false)
+			}
+			descr.putStr("value", v, false)
+			obj.self.defineOwnProperty(newStringValue(item.name), descr, true)
+		}
+	}
+}
+
 func (r *Runtime) object_freeze(call FunctionCall) Value {
 	arg := call.Argument(0)
 	if obj, ok := arg.(*Object); ok {
-		var descr objectImpl
-		for item, f := obj.self.enumerate(true, false)(); f != nil; item, f = f() {
-			v := obj.self.getOwnProp(item.name)
-			if prop, ok := v.(*valueProperty); ok {
-				prop.configurable = false
-				if prop.value != nil {
-					prop.writable = false
-				}
-			} else {
-				if descr == nil {
-					descr = r.NewObject().self
-					descr.putStr("writable", valueFalse, false)
-					descr.putStr("enumerable", valueTrue, false)
-					descr.putStr("configurable", valueFalse, false)
-				}
-				descr.putStr("value", v, false)
-				obj.self.defineOwnProperty(newStringValue(item.name), descr, true)
-			}
-		}
-		obj.self.preventExtensions()
+		r.freeze(obj)
 		return obj
 	} else {
 		// ES6 behavior
@@ -319,6 +355,57 @@ func (r *Runtime) object_keys(call FunctionCall) Value {
 	//return nil
 }
 
+func (r *Runtime) object_values(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	var values []Value
+	for item, f := obj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		values = append(values, nilSafe(obj.self.getStr(item.name)))
+	}
+	return r.newArrayValues(values)
+}
+
+func (r *Runtime) object_entries(call FunctionCall) Value {
+	obj := call.Argument(0).ToObject(r)
+	var entries []Value
+	for item, f := obj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		entries = append(entries, r.newArrayValues([]Value{newStringValue(item.name), nilSafe(obj.self.getStr(item.name))}))
+	}
+	return r.newArrayValues(entries)
+}
+
+func (r *Runtime) object_assign(call FunctionCall) Value {
+	to := call.Argument(0).ToObject(r)
+	if len(call.Arguments) > 1 {
+		for _, arg := range call.Arguments[1:] {
+			if arg == _undefined || arg == _null {
+				continue
+			}
+			source := arg.ToObject(r)
+			for item, f := source.self.enumerate(false, false)(); f != nil; item, f = f() {
+				to.self.putStr(item.name, nilSafe(source.self.getStr(item.name)), true)
+			}
+		}
+	}
+	return to
+}
+
+// object_fromEntries accepts an array-like object of entries, as iterables are not supported.
+func (r *Runtime) object_fromEntries(call FunctionCall) Value {
+	iterable := call.Argument(0)
+	r.checkObjectCoercible(iterable)
+	result := r.NewObject()
+	for _, entry := range r.toValueArray(iterable) {
+		entryObj, ok := entry.(*Object)
+		if !ok {
+			r.typeErrorResult(true, "Iterator value %s is not an entry object", nilSafe(entry).String())
+		}
+		key := nilSafe(entryObj.self.get(intToValue(0))).String()
+		value := nilSafe(entryObj.self.get(intToValue(1)))
+		result.self._putProp(key, value, true, true, true)
+	}
+	return result
+}
+
 func (r *Runtime) objectproto_hasOwnProperty(call FunctionCall) Value {
 	p := call.Argument(0).String()
 	o := call.This.ToObject(r)
@@ -396,6 +483,8 @@ func (r *Runtime) initObject() {
 	o._putProp("defineProperty", r.newNativeFunc(r.object_defineProperty, nil, "defineProperty", nil, 3), true, false, true)
 	o._putProp("defineProperties", r.newNativeFunc(r.object_defineProperties, nil, "defineProperties", nil, 2), true, false, true)
 	o._putProp("getOwnPropertyDescriptor", r.newNativeFunc(r.object_getOwnPropertyDescriptor, nil, "getOwnPropertyDescriptor", nil, 2), true, false, true)
+	o._putProp("getOwnPropertyDescriptors", r.newNativeFunc(r.object_getOwnPropertyDescriptors, nil, "getOwnPropertyDescriptors", nil, 1), true, false, true)
+	o._putProp("is", r.newNativeFunc(r.object_is, nil, "is", nil, 2), true, false, true)
 	o._putProp("getPrototypeOf", r.newNativeFunc(r.object_getPrototypeOf, nil, "getPrototypeOf", nil, 1), true, false, true)
 	o._putProp("getOwnPropertyNames", r.newNativeFunc(r.object_getOwnPropertyNames, nil, "getOwnPropertyNames", nil, 1), true, false, true)
 	o._putProp("create", r.newNativeFunc(r.object_create, nil, "create", nil, 2), true, false, true)
@@ -406,6 +495,10 @@ func (r *Runtime) initObject() {
 	o._putProp("isFrozen", r.newNativeFunc(r.object_isFrozen, nil, "isFrozen", nil, 1), true, false, true)
 	o._putProp("isExtensible", r.newNativeFunc(r.object_isExtensible, nil, "isExtensible", nil, 1), true, false, true)
 	o._putProp("keys", r.newNativeFunc(r.object_keys, nil, "keys", nil, 1), true, false, true)
+	o._putProp("values", r.newNativeFunc(r.object_values, nil, "values", nil, 1), true, false, true)
+	o._putProp("entries", r.newNativeFunc(r.object_entries, nil, "entries", nil, 1), true, false, true)
+	o._putProp("assign", r.newNativeFunc(r.object_assign, nil, "assign", nil, 2), true, false, true)
+	o._putProp("fromEntries", r.newNativeFunc(r.object_fromEntries, nil, "fromEntries", nil, 1), true, false, true)
 
 	r.addToGlobal("Object", r.global.Object)
 }
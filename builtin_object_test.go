@@ -0,0 +1,184 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestObjectValuesEntries(t *testing.T) {
+	const SCRIPT = `
+	var o = {a: 1, b: "x"};
+	Object.defineProperty(o, "hidden", {value: 3, enumerable: false});
+	Object.defineProperty(o, "getter", {get: function() { return 4; }, enumerable: true});
+	assert.sameValue(JSON.stringify(Object.values(o)), '[1,"x",4]', "values");
+	assert.sameValue(JSON.stringify(Object.entries(o)), '[["a",1],["b","x"],["getter",4]]', "entries");
+	assert.sameValue(JSON.stringify(Object.values("ab")), '["a","b"]', "string");
+	assert.sameValue(Object.entries([7])[0][0], "0", "array keys");
+	var thrown = false;
+	try {
+		Object.values(null);
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	assert(thrown, "null");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectAssign(t *testing.T) {
+	const SCRIPT = `
+	var target = {a: 1};
+	var source = {b: 2};
+	Object.defineProperty(source, "hidden", {value: 3, enumerable: false});
+	Object.defineProperty(source, "c", {get: function() { return this.b + 1; }, enumerable: true});
+	var res = Object.assign(target, null, source, undefined, {a: 4});
+	assert.sameValue(res, target, "result");
+	assert.sameValue(JSON.stringify(target), '{"a":4,"b":2,"c":3}', "target");
+	assert(!Object.getOwnPropertyDescriptor(target, "c").get, "getter value is copied");
+
+	var log = [];
+	var setterTarget = {set x(v) { log.push(v); }};
+	Object.assign(setterTarget, {x: 1});
+	assert.sameValue(log.join(), "1", "setter");
+
+	var frozen = Object.freeze({x: 1});
+	var thrown = false;
+	try {
+		Object.assign(frozen, {x: 2});
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	assert(thrown, "frozen target");
+	assert.sameValue(typeof Object.assign(1), "object", "primitive target");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectFromEntries(t *testing.T) {
+	const SCRIPT = `
+	var o = Object.fromEntries([["a", 1], ["b", 2], ["a", 3], [4, "x"]]);
+	assert.sameValue(Object.keys(o).length, 3, "keys");
+	assert(o.a === 3 && o.b === 2 && o[4] === "x", "values");
+	var p = Object.fromEntries([["__proto__", 1]]);
+	assert(Object.getPrototypeOf(p) === Object.prototype && p.hasOwnProperty("__proto__"), "__proto__");
+	assert.sameValue(JSON.stringify(Object.fromEntries(Object.entries({x: 1}))), '{"x":1}', "round trip");
+	var thrown = false;
+	try {
+		Object.fromEntries([1]);
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	assert(thrown, "not an entry");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestPrimitiveWrappers(t *testing.T) {
+	const SCRIPT = `
+	function throwsTypeError(f) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof TypeError;
+		}
+		return false;
+	}
+
+	var s = new String("ab");
+	assert.sameValue(Object.getOwnPropertyNames(s).join(), "0,1,length", "own properties");
+	assert.sameValue(s["01"], undefined, "non-canonical index");
+	Object.defineProperty(s, "0", {value: "a", enumerable: true});
+	assert(throwsTypeError(function() { Object.defineProperty(s, "0", {value: "x"}); }), "redefine index");
+	assert(throwsTypeError(function() { "use strict"; s[1] = "x"; }), "assign index");
+	s["01"] = 1;
+	assert.sameValue(s["01"], 1, "non-canonical index property");
+
+	var a = [1, 2];
+	a["01"] = 3;
+	assert.sameValue(a[1], 2, "array non-canonical index");
+	assert.sameValue(a.length, 2, "array length");
+
+	var n = new Number(1.5);
+	n.valueOf = function() { return 5; };
+	assert.sameValue(n.toString(), "1.5", "toString uses [[NumberData]]");
+	assert.sameValue(n.toFixed(0), "2", "toFixed uses [[NumberData]]");
+	assert(throwsTypeError(function() { Number.prototype.toFixed.call({}, 1); }), "toFixed brand check");
+	assert(throwsTypeError(function() { Number.prototype.toExponential.call("1"); }), "toExponential brand check");
+	assert(throwsTypeError(function() { Number.prototype.toPrecision.call(new String("1")); }), "toPrecision brand check");
+	assert(throwsTypeError(function() { Number.prototype.valueOf.call(new Boolean(true)); }), "valueOf brand check");
+
+	assert.sameValue(Object("ab").length, 2, "Object(string)");
+	assert.sameValue(Object(1).toFixed(1), "1.0", "Object(number)");
+	assert.sameValue(Object.prototype.toString.call(Object(true)), "[object Boolean]", "Object(boolean)");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectGetOwnPropertyDescriptors(t *testing.T) {
+	const SCRIPT = `
+	var o = {a: 1, get b() { return 2; }};
+	Object.defineProperty(o, "hidden", {value: 3});
+	var d = Object.getOwnPropertyDescriptors(o);
+	assert.sameValue(Object.keys(d).join(), "a,b,hidden", "keys");
+	assert(d.a.value === 1 && d.a.writable && d.a.enumerable && d.a.configurable, "data property");
+	assert(typeof d.b.get === "function" && d.b.set === undefined && !("value" in d.b), "accessor");
+	assert(d.hidden.value === 3 && !d.hidden.writable && !d.hidden.enumerable, "non-enumerable");
+
+	// A shallow copy that preserves accessors
+	var copy = Object.create(Object.getPrototypeOf(o), Object.getOwnPropertyDescriptors(o));
+	assert.sameValue(Object.getOwnPropertyDescriptor(copy, "b").get, d.b.get, "copied accessor");
+	assert.sameValue(copy.hidden, 3, "copied non-enumerable");
+
+	assert.sameValue(Object.getOwnPropertyDescriptors("ab")[1].value, "b", "string");
+	assert.sameValue(typeof Object.getOwnPropertySymbols, "undefined", "symbols");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectPropertyOrder(t *testing.T) {
+	const SCRIPT = `
+	var o = {b: 1, 2: 1, a: 1, 0: 1, "01": 1, 10: 1, 4294967295: 1, 1: 1};
+	assert.sameValue(Object.getOwnPropertyNames(o).join(), "0,1,2,10,b,a,01,4294967295", "literal");
+	assert.sameValue(Object.keys(o).join(), "0,1,2,10,b,a,01,4294967295", "keys");
+	var keys = [];
+	for (var k in o) {
+		keys.push(k);
+	}
+	assert.sameValue(keys.join(), "0,1,2,10,b,a,01,4294967295", "for-in");
+	delete o[1];
+	o[5] = 1;
+	assert.sameValue(Object.getOwnPropertyNames(o).join(), "0,2,5,10,b,a,01,4294967295", "after delete");
+	Object.defineProperty(o, "3", {value: 1, enumerable: true});
+	assert.sameValue(Object.keys(o).join(), "0,2,3,5,10,b,a,01,4294967295", "defineProperty");
+
+	var s = new String("abc");
+	s.x = 1;
+	s[5] = 1;
+	assert.sameValue(Object.getOwnPropertyNames(s).join(), "0,1,2,5,length,x", "String object");
+	assert.sameValue(JSON.stringify({z: 1, 1: 2}), '{"1":2,"z":1}', "JSON");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestObjectIs(t *testing.T) {
+	const SCRIPT = `
+	assert(Object.is(NaN, NaN), "NaN");
+	assert(!Object.is(0, -0), "0, -0");
+	assert(!Object.is(-0, 0), "-0, 0");
+	assert(Object.is(-0, -0), "-0, -0");
+	assert(Object.is(1, 1.5 - 0.5), "int, float");
+	assert(Object.is(1.5 - 0.5, 1), "float, int");
+	assert(!Object.is(1, "1"), "number, string");
+	assert(Object.is("a", "a"), "string");
+	var o = {};
+	assert(Object.is(o, o) && !Object.is(o, {}), "object");
+	assert(Object.is(), "undefined");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -1,10 +1,15 @@
 package goja
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/dlclark/regexp2"
 	"github.com/dop251/goja/parser"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 func (r *Runtime) newRegexpObject(proto *Object) *regexpObject {
@@ -20,58 +25,543 @@ func (r *Runtime) newRegexpObject(proto *Object) *regexpObject {
 	return o
 }
 
-func (r *Runtime) newRegExpp(pattern regexpPattern, patternStr valueString, global, ignoreCase, multiline bool, proto *Object) *Object {
+func (r *Runtime) newRegExpp(re *compiledRegexp, patternStr valueString, proto *Object) *Object {
 	o := r.newRegexpObject(proto)
 
-	o.pattern = pattern
+	o.groupNames = re.groupNames
+	o.lookbehindGroups = re.lookbehindGroups
 	o.source = patternStr
-	o.global = global
-	o.ignoreCase = ignoreCase
-	o.multiline = multiline
+	o.regexpFlagSet = re.regexpFlagSet
+	o.pattern = r.regexpEnginePattern(patternStr.String(), o.flags(), re.pattern)
 
 	return o.val
 }
 
-func compileRegexp(patternStr, flags string) (p regexpPattern, global, ignoreCase, multiline bool, err error) {
+// regexpGroupNameRegexp matches valid capture group names (identifiers).
+var regexpGroupNameRegexp = regexp.MustCompile(`^[\p{L}\p{Nl}$_][\p{L}\p{Nl}\p{Mn}\p{Mc}\p{Nd}\p{Pc}$_\x{200C}\x{200D}]*$`)
 
-	if flags != "" {
-		invalidFlags := func() {
-			err = fmt.Errorf("Invalid flags supplied to RegExp constructor '%s'", flags)
+// stripRegexpGroupNames removes the names from named capture groups ((?<name>...)) and replaces named
+// backreferences (\k<name>) with numbered ones, because the engines don't number named groups in the same way as
+// JavaScript does (regexp2 numbers them after the unnamed ones). Returns the transformed pattern and the names of
+// the capture groups by their number (empty for unnamed groups), or nil if there are no named groups.
+func stripRegexpGroupNames(pattern string) (string, []string, error) {
+	if !strings.Contains(pattern, "(?<") {
+		return pattern, nil, nil
+	}
+
+	// groupAt returns the name of the named group starting at pos and the length of its prefix
+	groupAt := func(pos int) (string, int, error) {
+		rest := pattern[pos:]
+		if !strings.HasPrefix(rest, "(?<") || strings.HasPrefix(rest, "(?<=") || strings.HasPrefix(rest, "(?<!") {
+			return "", 0, nil
 		}
-		for _, chr := range flags {
-			switch chr {
-			case 'g':
-				if global {
-					invalidFlags()
-					return
+		end := strings.IndexByte(rest, '>')
+		if end == -1 || !regexpGroupNameRegexp.MatchString(rest[3:end]) {
+			return "", 0, fmt.Errorf("Invalid capture group name")
+		}
+		return rest[3:end], end + 1, nil
+	}
+
+	names := []string{""}
+	named := make(map[string]int)
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if inClass {
+				continue
+			}
+			name, _, err := groupAt(i)
+			if err != nil {
+				return "", nil, err
+			}
+			if name != "" {
+				if _, exists := named[name]; exists {
+					return "", nil, fmt.Errorf("Duplicate capture group name")
+				}
+				named[name] = len(names)
+				names = append(names, name)
+			} else if !strings.HasPrefix(pattern[i+1:], "?") {
+				names = append(names, "")
+			}
+		}
+	}
+	if len(named) == 0 {
+		return pattern, nil, nil
+	}
+
+	var buf bytes.Buffer
+	inClass = false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			if strings.HasPrefix(pattern[i+1:], "k<") {
+				end := strings.IndexByte(pattern[i+3:], '>')
+				if end == -1 {
+					return "", nil, fmt.Errorf("Invalid named reference")
 				}
-				global = true
-			case 'm':
-				if multiline {
-					invalidFlags()
-					return
+				idx, exists := named[pattern[i+3:i+3+end]]
+				if !exists {
+					return "", nil, fmt.Errorf("Invalid named capture referenced")
 				}
-				multiline = true
-			case 'i':
-				if ignoreCase {
-					invalidFlags()
-					return
+				if inClass {
+					return "", nil, fmt.Errorf("Invalid class escape")
+				}
+				buf.WriteString("(?:\\" + strconv.Itoa(idx) + ")")
+				i += 3 + end
+				continue
+			}
+			buf.WriteByte(c)
+			if i+1 < len(pattern) {
+				i++
+				buf.WriteByte(pattern[i])
+			}
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if !inClass {
+				if name, l, _ := groupAt(i); name != "" {
+					buf.WriteByte('(')
+					i += l - 1
+					continue
 				}
-				ignoreCase = true
-			default:
-				invalidFlags()
-				return
 			}
 		}
+		buf.WriteByte(c)
 	}
+	return buf.String(), names, nil
+}
 
-	re2Str, err1 := parser.TransformRegExp(patternStr)
-	if /*false &&*/ err1 == nil {
+// regexpLookbehindGroups returns, for every capture group (indexed as in a match result, so 0 is the whole match),
+// whether it's inside a lookbehind assertion, or nil if the pattern has no lookbehind.
+func regexpLookbehindGroups(pattern string) []bool {
+	if !strings.Contains(pattern, "(?<=") && !strings.Contains(pattern, "(?<!") {
+		return nil
+	}
+	groups := []bool{false}
+	// open holds, for every open parenthesis, whether it's in a lookbehind
+	var open []bool
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '(':
+			if inClass {
+				continue
+			}
+			rest := pattern[i+1:]
+			inLookbehind := len(open) > 0 && open[len(open)-1]
+			if strings.HasPrefix(rest, "?<=") || strings.HasPrefix(rest, "?<!") {
+				inLookbehind = true
+			} else if !strings.HasPrefix(rest, "?") || strings.HasPrefix(rest, "?<") {
+				groups = append(groups, inLookbehind)
+			}
+			open = append(open, inLookbehind)
+		case ')':
+			if !inClass && len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	return groups
+}
+
+// regexpHasContextAssertion reports whether the pattern contains an assertion which depends on the text before the
+// match: '^', '\b' or '\B'.
+func regexpHasContextAssertion(pattern string) bool {
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if !inClass && i+1 < len(pattern) && (pattern[i+1] == 'b' || pattern[i+1] == 'B') {
+				return true
+			}
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '^':
+			if !inClass {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// regexpDotAll replaces every '.' outside of character classes with a class that matches any character, including
+// line terminators, which is what '.' means with the 's' flag. Both engines get the transformed pattern so that
+// neither depends on its own notion of a "single line" mode.
+func regexpDotAll(pattern string) string {
+	if !strings.Contains(pattern, ".") {
+		return pattern
+	}
+	var buf bytes.Buffer
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			buf.WriteByte(c)
+			if i+1 < len(pattern) {
+				i++
+				buf.WriteByte(pattern[i])
+			}
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '.':
+			if !inClass {
+				buf.WriteString(`[\d\D]`)
+				continue
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// regexpGeneralCategories maps the long names of the general categories (and their aliases) to the short ones
+// used by JavaScript and Go.
+var regexpGeneralCategories = map[string]string{
+	"Letter":                "L",
+	"Cased_Letter":          "LC",
+	"Uppercase_Letter":      "Lu",
+	"Lowercase_Letter":      "Ll",
+	"Titlecase_Letter":      "Lt",
+	"Modifier_Letter":       "Lm",
+	"Other_Letter":          "Lo",
+	"Mark":                  "M",
+	"Combining_Mark":        "M",
+	"Nonspacing_Mark":       "Mn",
+	"Spacing_Mark":          "Mc",
+	"Enclosing_Mark":        "Me",
+	"Number":                "N",
+	"Decimal_Number":        "Nd",
+	"digit":                 "Nd",
+	"Letter_Number":         "Nl",
+	"Other_Number":          "No",
+	"Punctuation":           "P",
+	"punct":                 "P",
+	"Connector_Punctuation": "Pc",
+	"Dash_Punctuation":      "Pd",
+	"Open_Punctuation":      "Ps",
+	"Close_Punctuation":     "Pe",
+	"Initial_Punctuation":   "Pi",
+	"Final_Punctuation":     "Pf",
+	"Other_Punctuation":     "Po",
+	"Symbol":                "S",
+	"Math_Symbol":           "Sm",
+	"Currency_Symbol":       "Sc",
+	"Modifier_Symbol":       "Sk",
+	"Other_Symbol":          "So",
+	"Separator":             "Z",
+	"Space_Separator":       "Zs",
+	"Line_Separator":        "Zl",
+	"Paragraph_Separator":   "Zp",
+	"Other":                 "C",
+	"Control":               "Cc",
+	"cntrl":                 "Cc",
+	"Format":                "Cf",
+	"Surrogate":             "Cs",
+	"Private_Use":           "Co",
+	"Unassigned":            "Cn",
+}
+
+// regexpDerivedProperties lists the tables of the binary properties that have no table of their own in the
+// unicode package.
+var regexpDerivedProperties = map[string][]*unicode.RangeTable{
+	"Any": {{
+		R16: []unicode.Range16{{Lo: 0, Hi: 0xffff, Stride: 1}},
+		R32: []unicode.Range32{{Lo: 0x10000, Hi: unicode.MaxRune, Stride: 1}},
+	}},
+	"ASCII": {{
+		R16: []unicode.Range16{{Lo: 0, Hi: 0x7f, Stride: 1}},
+	}},
+	"Alphabetic": {unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl, unicode.Other_Alphabetic},
+	"Lowercase":  {unicode.Ll, unicode.Other_Lowercase},
+	"Uppercase":  {unicode.Lu, unicode.Other_Uppercase},
+	"Math":       {unicode.Sm, unicode.Other_Math},
+}
+
+// regexpGeneralCategory returns the short name of a general category if the unicode package has a table for it.
+func regexpGeneralCategory(name string) string {
+	if short, exists := regexpGeneralCategories[name]; exists {
+		name = short
+	}
+	if unicode.Categories[name] != nil {
+		return name
+	}
+	return ""
+}
+
+// regexpUnicodeProperties rewrites the property escapes (\p{...} and \P{...}) of a pattern with the 'u' flag into
+// a form both engines understand: general categories and scripts become \p{Name} with the name of the Go table,
+// binary properties are expanded into code point ranges.
+func regexpUnicodeProperties(pattern string) (string, error) {
+	if !strings.Contains(pattern, `\p`) && !strings.Contains(pattern, `\P`) {
+		return pattern, nil
+	}
+	var buf bytes.Buffer
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			if i+1 < len(pattern) && (pattern[i+1] == 'p' || pattern[i+1] == 'P') {
+				end := strings.IndexByte(pattern[i+2:], '}')
+				if end == -1 || pattern[i+2] != '{' {
+					return "", fmt.Errorf("Invalid property name")
+				}
+				if err := writeRegexpProperty(&buf, pattern[i+3:i+2+end], pattern[i+1] == 'P', inClass); err != nil {
+					return "", err
+				}
+				i += 2 + end
+				continue
+			}
+			buf.WriteByte(c)
+			if i+1 < len(pattern) {
+				i++
+				buf.WriteByte(pattern[i])
+			}
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), nil
+}
+
+func writeRegexpProperty(buf *bytes.Buffer, property string, negate, inClass bool) error {
+	name, value := property, ""
+	if eq := strings.IndexByte(property, '='); eq != -1 {
+		name, value = property[:eq], property[eq+1:]
+	}
+
+	var tableName string
+	var tables []*unicode.RangeTable
+	if value != "" {
+		switch name {
+		case "General_Category", "gc":
+			tableName = regexpGeneralCategory(value)
+		case "Script", "sc", "Script_Extensions", "scx":
+			// There are no Script_Extensions tables in the unicode package, they are approximated by the Script ones
+			if unicode.Scripts[value] != nil {
+				tableName = value
+			}
+		}
+	} else if category := regexpGeneralCategory(name); category != "" {
+		tableName = category
+	} else if t, exists := regexpDerivedProperties[name]; exists {
+		tables = t
+	} else if t, exists := unicode.Properties[name]; exists && !strings.HasPrefix(name, "Other_") {
+		tables = []*unicode.RangeTable{t}
+	}
+
+	if tableName != "" {
+		if negate {
+			buf.WriteString(`\P{`)
+		} else {
+			buf.WriteString(`\p{`)
+		}
+		buf.WriteString(tableName)
+		buf.WriteByte('}')
+		return nil
+	}
+	if tables == nil {
+		return fmt.Errorf("Invalid property name")
+	}
+
+	ranges := regexpPropertyRanges(tables)
+	if negate && inClass {
+		// A negated set cannot be nested in a class, so the complement is written instead
+		ranges = complementRegexpRanges(ranges)
+	}
+	if !inClass {
+		buf.WriteByte('[')
+		if negate {
+			buf.WriteByte('^')
+		}
+	}
+	for _, r := range ranges {
+		// Surrogates cannot be matched in the unicode mode
+		if r[0] < 0xD800 && r[1] > 0xDFFF {
+			writeRegexpRange(buf, r[0], 0xD7FF)
+			writeRegexpRange(buf, 0xE000, r[1])
+			continue
+		}
+		if r[0] >= 0xD800 && r[0] <= 0xDFFF {
+			r[0] = 0xE000
+		}
+		if r[1] >= 0xD800 && r[1] <= 0xDFFF {
+			r[1] = 0xD7FF
+		}
+		if r[0] <= r[1] {
+			writeRegexpRange(buf, r[0], r[1])
+		}
+	}
+	if !inClass {
+		buf.WriteByte(']')
+	}
+	return nil
+}
+
+func writeRegexpRange(buf *bytes.Buffer, lo, hi rune) {
+	fmt.Fprintf(buf, `\u{%X}`, lo)
+	if hi > lo {
+		fmt.Fprintf(buf, `-\u{%X}`, hi)
+	}
+}
+
+// regexpPropertyRanges returns the sorted and merged code point ranges of the union of the tables.
+func regexpPropertyRanges(tables []*unicode.RangeTable) [][2]rune {
+	var ranges [][2]rune
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, [2]rune{lo, hi})
+			return
+		}
+		for c := lo; c <= hi; c += stride {
+			ranges = append(ranges, [2]rune{c, c})
+		}
+	}
+	for _, t := range tables {
+		for _, r := range t.R16 {
+			add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+		for _, r := range t.R32 {
+			add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if l := len(merged); l > 0 && r[0] <= merged[l-1][1]+1 {
+			if r[1] > merged[l-1][1] {
+				merged[l-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func complementRegexpRanges(ranges [][2]rune) [][2]rune {
+	var res [][2]rune
+	next := rune(0)
+	for _, r := range ranges {
+		if r[0] > next {
+			res = append(res, [2]rune{next, r[0] - 1})
+		}
+		next = r[1] + 1
+	}
+	if next <= unicode.MaxRune {
+		res = append(res, [2]rune{next, unicode.MaxRune})
+	}
+	return res
+}
+
+// compiledRegexp is a pattern compiled by compileRegexp() with its flags.
+type compiledRegexp struct {
+	pattern          regexpPattern
+	groupNames       []string // names of the capture groups, nil if there are no named groups
+	lookbehindGroups []bool   // whether the capture groups are inside a lookbehind, nil if there is no lookbehind
+
+	regexpFlagSet
+}
+
+func compileRegexp(patternStr, flags string) (*compiledRegexp, error) {
+	return compileRegexpBackend(patternStr, flags, regexpBackendAuto)
+}
+
+// regexpBackend selects the engine compileRegexpBackend() uses.
+type regexpBackend int
+
+const (
+	// regexpBackendAuto uses Go's regexp if the pattern is compatible with it and regexp2 otherwise.
+	regexpBackendAuto regexpBackend = iota
+	// regexpBackendGo only uses Go's regexp and fails for the incompatible patterns.
+	regexpBackendGo
+	// regexpBackendBacktracking always uses regexp2.
+	regexpBackendBacktracking
+)
+
+func compileRegexpBackend(patternStr, flags string, backend regexpBackend) (*compiledRegexp, error) {
+	var re compiledRegexp
+
+	if !re.parseFlags(flags) {
+		return nil, fmt.Errorf("Invalid flags supplied to RegExp constructor '%s'", flags)
+	}
+
+	re.lookbehindGroups = regexpLookbehindGroups(patternStr)
+	var err error
+	patternStr, re.groupNames, err = stripRegexpGroupNames(patternStr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regular expression: %v", err)
+	}
+	if re.dotAll {
+		patternStr = regexpDotAll(patternStr)
+	}
+	if re.unicode {
+		transformed, err := regexpUnicodeProperties(patternStr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regular expression: /%s/: %v", patternStr, err)
+		}
+		patternStr = transformed
+	}
+
+	var re2Str string
+	var err1 error
+	if re.unicode {
+		re2Str, err1 = parser.TransformRegExpUnicode(patternStr)
+		if err1 != nil && re2Str == "" {
+			// The stricter syntax rules apply regardless of the engine
+			return nil, fmt.Errorf("Invalid regular expression: /%s/: %v", patternStr, err1)
+		}
+	} else {
+		re2Str, err1 = parser.TransformRegExp(patternStr)
+	}
+	// Go's regexp can only match from lastIndex by reading the string from there, so '^' and '\b' would see the
+	// start of the string at lastIndex. regexp2 searches the whole string instead.
+	fromLastIndex := (re.global || re.sticky) && regexpHasContextAssertion(patternStr)
+	if backend == regexpBackendGo {
+		if err1 == nil && fromLastIndex {
+			err1 = fmt.Errorf("the assertions cannot be matched from lastIndex")
+		}
+		if err1 != nil {
+			return nil, fmt.Errorf("Invalid regular expression (re2): %s (%v)", patternStr, err1)
+		}
+	}
+	if /*false &&*/ err1 == nil && backend != regexpBackendBacktracking && !fromLastIndex {
 		re2flags := ""
-		if multiline {
+		if re.multiline {
 			re2flags += "m"
 		}
-		if ignoreCase {
+		if re.ignoreCase {
 			re2flags += "i"
 		}
 		if len(re2flags) > 0 {
@@ -80,35 +570,40 @@ func compileRegexp(patternStr, flags string) (p regexpPattern, global, ignoreCas
 
 		pattern, err1 := regexp.Compile(re2Str)
 		if err1 != nil {
-			err = fmt.Errorf("Invalid regular expression (re2): %s (%v)", re2Str, err1)
-			return
+			return nil, fmt.Errorf("Invalid regular expression (re2): %s (%v)", re2Str, err1)
 		}
 
-		p = (*regexpWrapper)(pattern)
+		re.pattern = (*regexpWrapper)(pattern)
 	} else {
 		var opts regexp2.RegexOptions = regexp2.ECMAScript
-		if multiline {
+		if re.multiline {
 			opts |= regexp2.Multiline
 		}
-		if ignoreCase {
+		if re.ignoreCase {
 			opts |= regexp2.IgnoreCase
 		}
+		if re.unicode {
+			opts |= regexp2.Unicode
+		}
+		if re.sticky {
+			// only match at the start position
+			patternStr = `\G(?:` + patternStr + `)`
+		}
 		regexp2Pattern, err1 := regexp2.Compile(patternStr, opts)
 		if err1 != nil {
-			err = fmt.Errorf("Invalid regular expression (regexp2): %s (%v)", patternStr, err1)
-			return
+			return nil, fmt.Errorf("Invalid regular expression (regexp2): %s (%v)", patternStr, err1)
 		}
-		p = (*regexp2Wrapper)(regexp2Pattern)
+		re.pattern = (*regexp2Wrapper)(regexp2Pattern)
 	}
-	return
+	return &re, nil
 }
 
 func (r *Runtime) newRegExp(patternStr valueString, flags string, proto *Object) *Object {
-	pattern, global, ignoreCase, multiline, err := compileRegexp(patternStr.String(), flags)
+	re, err := compileRegexp(patternStr.String(), flags)
 	if err != nil {
 		panic(r.newSyntaxError(err.Error(), -1))
 	}
-	return r.newRegExpp(pattern, patternStr, global, ignoreCase, multiline, proto)
+	return r.newRegExpp(re, patternStr, proto)
 }
 
 func (r *Runtime) builtin_newRegExp(args []Value) *Object {
@@ -155,7 +650,7 @@ func (r *Runtime) regexpproto_exec(call FunctionCall) Value {
 	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
 		return this.exec(call.Argument(0).ToString())
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.exec called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.exec called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
@@ -168,26 +663,16 @@ func (r *Runtime) regexpproto_test(call FunctionCall) Value {
 			return valueFalse
 		}
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.test called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.test called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
 
 func (r *Runtime) regexpproto_toString(call FunctionCall) Value {
 	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
-		var g, i, m string
-		if this.global {
-			g = "g"
-		}
-		if this.ignoreCase {
-			i = "i"
-		}
-		if this.multiline {
-			m = "m"
-		}
-		return newStringValue(fmt.Sprintf("/%s/%s%s%s", this.source.String(), g, i, m))
+		return newStringValue(fmt.Sprintf("/%s/%s", this.source.String(), this.flags()))
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.toString called on incompatible receiver %s", call.This)
+		r.typeErrorResult(true, "Method RegExp.prototype.toString called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
@@ -195,8 +680,10 @@ func (r *Runtime) regexpproto_toString(call FunctionCall) Value {
 func (r *Runtime) regexpproto_getSource(call FunctionCall) Value {
 	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
 		return this.source
+	} else if call.This == r.global.RegExpPrototype {
+		return asciiString("(?:)")
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.source getter called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.source getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
@@ -208,8 +695,10 @@ func (r *Runtime) regexpproto_getGlobal(call FunctionCall) Value {
 		} else {
 			return valueFalse
 		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.global getter called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.global getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
@@ -221,8 +710,10 @@ func (r *Runtime) regexpproto_getMultiline(call FunctionCall) Value {
 		} else {
 			return valueFalse
 		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.multiline getter called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.multiline getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
@@ -234,12 +725,88 @@ func (r *Runtime) regexpproto_getIgnoreCase(call FunctionCall) Value {
 		} else {
 			return valueFalse
 		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
+	} else {
+		r.typeErrorResult(true, "Method RegExp.prototype.ignoreCase getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
+		return nil
+	}
+}
+
+func (r *Runtime) regexpproto_getUnicode(call FunctionCall) Value {
+	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
+		if this.unicode {
+			return valueTrue
+		} else {
+			return valueFalse
+		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
+	} else {
+		r.typeErrorResult(true, "Method RegExp.prototype.unicode getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
+		return nil
+	}
+}
+
+func (r *Runtime) regexpproto_getSticky(call FunctionCall) Value {
+	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
+		if this.sticky {
+			return valueTrue
+		} else {
+			return valueFalse
+		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
+	} else {
+		r.typeErrorResult(true, "Method RegExp.prototype.sticky getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
+		return nil
+	}
+}
+
+func (r *Runtime) regexpproto_getDotAll(call FunctionCall) Value {
+	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
+		if this.dotAll {
+			return valueTrue
+		} else {
+			return valueFalse
+		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
+	} else {
+		r.typeErrorResult(true, "Method RegExp.prototype.dotAll getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
+		return nil
+	}
+}
+
+func (r *Runtime) regexpproto_getHasIndices(call FunctionCall) Value {
+	if this, ok := r.toObject(call.This).self.(*regexpObject); ok {
+		if this.hasIndices {
+			return valueTrue
+		} else {
+			return valueFalse
+		}
+	} else if call.This == r.global.RegExpPrototype {
+		return _undefined
 	} else {
-		r.typeErrorResult(true, "Method RegExp.prototype.ignoreCase getter called on incompatible receiver %s", call.This.ToString())
+		r.typeErrorResult(true, "Method RegExp.prototype.hasIndices getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
 		return nil
 	}
 }
 
+func (r *Runtime) regexpproto_getFlags(call FunctionCall) Value {
+	if this, ok := call.This.(*Object); ok {
+		var buf bytes.Buffer
+		for _, flag := range regexpFlags {
+			if v := this.self.getStr(flag.name); v != nil && v.ToBoolean() {
+				buf.WriteByte(flag.chr)
+			}
+		}
+		return newStringValue(buf.String())
+	}
+	r.typeErrorResult(true, "Method RegExp.prototype.flags getter called on incompatible receiver %s", r.objectproto_toString(FunctionCall{This: call.This}))
+	return nil
+}
+
 func (r *Runtime) initRegExp() {
 	r.global.RegExpPrototype = r.NewObject()
 	o := r.global.RegExpPrototype.self
@@ -266,6 +833,31 @@ func (r *Runtime) initRegExp() {
 		getterFunc:   r.newNativeFunc(r.regexpproto_getIgnoreCase, nil, "get ignoreCase", nil, 0),
 		accessor:     true,
 	}, false)
+	o.putStr("unicode", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getUnicode, nil, "get unicode", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("sticky", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getSticky, nil, "get sticky", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("dotAll", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getDotAll, nil, "get dotAll", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("hasIndices", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getHasIndices, nil, "get hasIndices", nil, 0),
+		accessor:     true,
+	}, false)
+	o.putStr("flags", &valueProperty{
+		configurable: true,
+		getterFunc:   r.newNativeFunc(r.regexpproto_getFlags, nil, "get flags", nil, 0),
+		accessor:     true,
+	}, false)
 
 	r.global.RegExp = r.newNativeFunc(r.builtin_RegExp, r.builtin_newRegExp, "RegExp", r.global.RegExpPrototype, 2)
 	r.addToGlobal("RegExp", r.global.RegExp)
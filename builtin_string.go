@@ -4,17 +4,14 @@ import (
 	"bytes"
 	"github.com/dop251/goja/parser"
 	"golang.org/x/text/collate"
-	"golang.org/x/text/language"
 	"golang.org/x/text/unicode/norm"
 	"math"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
-var (
-	collator = collate.New(language.Und)
-)
-
 func (r *Runtime) builtin_String(call FunctionCall) Value {
 	if len(call.Arguments) > 0 {
 		arg := call.Arguments[0]
@@ -94,7 +91,7 @@ func (r *Runtime) string_fromcharcode(call FunctionCall) Value {
 		chr := toUInt16(arg)
 		if chr >= utf8.RuneSelf {
 			bb := make([]uint16, len(call.Arguments))
-			for j := 0; i < i; j++ {
+			for j := 0; j < i; j++ {
 				bb[j] = uint16(b[j])
 			}
 			bb[i] = chr
@@ -110,6 +107,69 @@ func (r *Runtime) string_fromcharcode(call FunctionCall) Value {
 	return asciiString(b)
 }
 
+func (r *Runtime) string_fromcodepoint(call FunctionCall) Value {
+	var units []uint16
+	ascii := true
+	for _, arg := range call.Arguments {
+		num := arg.ToNumber()
+		c, ok := toInt(num)
+		if f, isFloat := num.assertFloat(); isFloat && f == 0 {
+			// -0
+			c, ok = 0, true
+		}
+		if !ok || c < 0 || c > unicode.MaxRune {
+			panic(r.newError(r.global.RangeError, "Invalid code point %s", arg.ToString()))
+		}
+		if c >= utf8.RuneSelf {
+			ascii = false
+		}
+		if c > 0xFFFF {
+			r1, r2 := utf16.EncodeRune(rune(c))
+			units = append(units, uint16(r1), uint16(r2))
+		} else {
+			units = append(units, uint16(c))
+		}
+	}
+	if ascii {
+		b := make([]byte, len(units))
+		for i, c := range units {
+			b[i] = byte(c)
+		}
+		return asciiString(b)
+	}
+	return unicodeString(units)
+}
+
+func (r *Runtime) string_raw(call FunctionCall) Value {
+	cooked := call.Argument(0).ToObject(r)
+	raw := nilSafe(cooked.self.getStr("raw")).ToObject(r)
+	l := toLength(raw.self.getStr("length"))
+	var buf strings.Builder
+	for i := int64(0); i < l; i++ {
+		buf.WriteString(nilSafe(raw.self.get(intToValue(i))).ToString().String())
+		if i+1 < l && i+1 < int64(len(call.Arguments)) {
+			buf.WriteString(call.Arguments[i+1].ToString().String())
+		}
+	}
+	return newStringValue(buf.String())
+}
+
+func (r *Runtime) stringproto_codePointAt(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	pos := call.Argument(0).ToInteger()
+	if pos < 0 || pos >= s.length() {
+		return _undefined
+	}
+	c := s.charAt(pos)
+	if isUTF16FirstSurrogate(c) && pos+1 < s.length() {
+		if c1 := s.charAt(pos + 1); isUTF16SecondSurrogate(c1) {
+			return intToValue(int64(utf16.DecodeRune(c, c1)))
+		}
+	}
+	return intToValue(int64(c))
+}
+
 func (r *Runtime) stringproto_charAt(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
 	s := call.This.ToString()
@@ -117,7 +177,20 @@ func (r *Runtime) stringproto_charAt(call FunctionCall) Value {
 	if pos < 0 || pos >= s.length() {
 		return stringEmpty
 	}
-	return newStringValue(string(s.charAt(pos)))
+	return s.substring(pos, pos+1)
+}
+
+func (r *Runtime) stringproto_at(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	pos := call.Argument(0).ToInteger()
+	if pos < 0 {
+		pos += s.length()
+	}
+	if pos < 0 || pos >= s.length() {
+		return _undefined
+	}
+	return s.substring(pos, pos+1)
 }
 
 func (r *Runtime) stringproto_charCodeAt(call FunctionCall) Value {
@@ -144,6 +217,11 @@ func (r *Runtime) stringproto_concat(call FunctionCall) Value {
 		strs[i+1] = s
 		totalLen += s.length()
 	}
+	if allAscii {
+		r.reserveMemory(memStringSize + totalLen)
+	} else {
+		r.reserveMemory(memStringSize + 2*totalLen)
+	}
 
 	if allAscii {
 		buf := bytes.NewBuffer(make([]byte, 0, totalLen))
@@ -212,11 +290,136 @@ func (r *Runtime) stringproto_lastIndexOf(call FunctionCall) Value {
 	return intToValue(value.lastIndex(target, pos))
 }
 
+// maxStringLength is the maximum length of the strings created by String.prototype.repeat() and alike.
+const maxStringLength = 1 << 30
+
+// searchStringArg returns the search string argument of includes(), startsWith() and endsWith() which must not be
+// a RegExp.
+func (r *Runtime) searchStringArg(call FunctionCall, method string) valueString {
+	arg := call.Argument(0)
+	if o, ok := arg.(*Object); ok {
+		if _, ok := o.self.(*regexpObject); ok {
+			panic(r.NewTypeError("First argument to String.prototype.%s must not be a regular expression", method))
+		}
+	}
+	return arg.ToString()
+}
+
+func (r *Runtime) stringproto_includes(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	value := call.This.ToString()
+	target := r.searchStringArg(call, "includes")
+	pos := min(max(call.Argument(1).ToInteger(), 0), value.length())
+	return r.toBoolean(value.index(target, pos) >= 0)
+}
+
+func (r *Runtime) stringproto_startsWith(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	value := call.This.ToString()
+	target := r.searchStringArg(call, "startsWith")
+	start := min(max(call.Argument(1).ToInteger(), 0), value.length())
+	end := start + target.length()
+	if end > value.length() {
+		return valueFalse
+	}
+	return r.toBoolean(value.substring(start, end).compareTo(target) == 0)
+}
+
+func (r *Runtime) stringproto_endsWith(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	value := call.This.ToString()
+	target := r.searchStringArg(call, "endsWith")
+	end := value.length()
+	if endArg := call.Argument(1); endArg != _undefined {
+		end = min(max(endArg.ToInteger(), 0), value.length())
+	}
+	start := end - target.length()
+	if start < 0 {
+		return valueFalse
+	}
+	return r.toBoolean(value.substring(start, end).compareTo(target) == 0)
+}
+
+func (r *Runtime) stringproto_repeat(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	value := call.This.ToString()
+	num := call.Argument(0).ToNumber()
+	if f, ok := num.assertFloat(); ok && math.IsInf(f, 1) || num.ToInteger() < 0 {
+		panic(r.newError(r.global.RangeError, "Invalid count value"))
+	}
+	n := num.ToInteger()
+	l := value.length()
+	if n == 0 || l == 0 {
+		return stringEmpty
+	}
+	if n > maxStringLength/l {
+		panic(r.newError(r.global.RangeError, "Invalid string length"))
+	}
+	r.reserveMemory(stringMemorySize(value, l*n))
+	return repeatString(value, n)
+}
+
+func repeatString(s valueString, n int64) valueString {
+	switch s := s.(type) {
+	case asciiString:
+		return asciiString(strings.Repeat(string(s), int(n)))
+	case unicodeString:
+		res := make(unicodeString, 0, int64(len(s))*n)
+		for i := int64(0); i < n; i++ {
+			res = append(res, s...)
+		}
+		return res
+	default:
+		panic("Unsupported string type")
+	}
+}
+
+func (r *Runtime) stringPad(call FunctionCall, start bool) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+	maxLength := toLength(call.Argument(0))
+	l := s.length()
+	if maxLength <= l {
+		return s
+	}
+	var filler valueString = asciiString(" ")
+	if fillArg := call.Argument(1); fillArg != _undefined {
+		filler = fillArg.ToString()
+		if filler.length() == 0 {
+			return s
+		}
+	}
+	if maxLength > maxStringLength {
+		panic(r.newError(r.global.RangeError, "Invalid string length"))
+	}
+	r.reserveMemory(2 * stringMemorySize(filler, maxLength))
+	fillLen := maxLength - l
+	fill := repeatString(filler, fillLen/filler.length()+1).substring(0, fillLen)
+	if start {
+		return fill.concat(s)
+	}
+	return s.concat(fill)
+}
+
+func (r *Runtime) stringproto_padStart(call FunctionCall) Value {
+	return r.stringPad(call, true)
+}
+
+func (r *Runtime) stringproto_padEnd(call FunctionCall) Value {
+	return r.stringPad(call, false)
+}
+
 func (r *Runtime) stringproto_localeCompare(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
-	this := norm.NFD.String(call.This.String())
-	that := norm.NFD.String(call.Argument(0).String())
-	return intToValue(int64(collator.CompareString(this, that)))
+	c := r.getCollator()
+	if locales, options := call.Argument(1), call.Argument(2); locales != _undefined || options != _undefined {
+		c = r.newCollator(locales, options, nil).collator
+	}
+	return intToValue(int64(localeCompareStrings(c, call.This.String(), call.Argument(0).String())))
+}
+
+func localeCompareStrings(c *collate.Collator, a, b string) int {
+	return c.CompareString(norm.NFD.String(a), norm.NFD.String(b))
 }
 
 func (r *Runtime) stringproto_match(call FunctionCall) Value {
@@ -273,9 +476,11 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 	replaceValue := call.Argument(1)
 
 	var found [][]int
+	var groupNames []string
 
 	if searchValue, ok := searchValue.(*Object); ok {
 		if regexp, ok := searchValue.self.(*regexpObject); ok {
+			groupNames = regexp.groupNames
 			find := 1
 			if regexp.global {
 				find = -1
@@ -300,6 +505,7 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 	}
 
 	var buf bytes.Buffer
+	mem := memoryCharger{r: r}
 	lastIndex := 0
 
 	var rcall func(FunctionCall) Value
@@ -316,7 +522,7 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 				buf.WriteString(str[lastIndex:item[0]])
 			}
 			matchCount := len(item) / 2
-			argumentList := make([]Value, matchCount+2)
+			argumentList := make([]Value, matchCount+2, matchCount+3)
 			for index := 0; index < matchCount; index++ {
 				offset := 2 * index
 				if item[offset] != -1 {
@@ -331,12 +537,22 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 			}
 			argumentList[matchCount] = valueInt(item[0])
 			argumentList[matchCount+1] = s
+			if groupNames != nil {
+				groups := r.newBaseObject(nil, classObject)
+				for i, name := range groupNames {
+					if name != "" {
+						groups.putStr(name, argumentList[i], false)
+					}
+				}
+				argumentList = append(argumentList, groups.val)
+			}
 			replacement := rcall(FunctionCall{
 				This:      _undefined,
 				Arguments: argumentList,
 			}).String()
 			buf.WriteString(replacement)
 			lastIndex = item[1]
+			mem.charge(buf.Len())
 		}
 	} else {
 		newstring := replaceValue.String()
@@ -358,6 +574,23 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 						buf.WriteString(str[item[1]:])
 					case '&':
 						buf.WriteString(str[item[0]:item[1]])
+					case '<':
+						end := strings.IndexByte(newstring[i+2:], '>')
+						if groupNames == nil || end == -1 {
+							buf.WriteString("$<")
+							break
+						}
+						name := newstring[i+2 : i+2+end]
+						for index, n := range groupNames {
+							if n == name {
+								offset := 2 * index
+								if offset < len(item) && item[offset] != -1 {
+									buf.WriteString(str[item[offset]:item[offset+1]])
+								}
+								break
+							}
+						}
+						i += end + 1
 					default:
 						matchNumber := 0
 						l := 0
@@ -391,6 +624,7 @@ func (r *Runtime) stringproto_replace(call FunctionCall) Value {
 				}
 			}
 			lastIndex = item[1]
+			mem.charge(buf.Len())
 		}
 	}
 
@@ -545,7 +779,14 @@ func (r *Runtime) stringproto_split(call FunctionCall) Value {
 		return r.newArrayValues(valueArray)
 
 	} else {
-		separator := separatorValue.String()
+		separator := separatorValue.ToString()
+		if _, ok := s.(asciiString); !ok {
+			return r.newArrayValues(splitUTF16(s, separator, limit))
+		}
+		if _, ok := separator.(asciiString); !ok {
+			// A non-ASCII separator can't occur in an ASCII string
+			return r.newArrayValues([]Value{s})
+		}
 
 		excess := false
 		str := s.String()
@@ -558,7 +799,7 @@ func (r *Runtime) stringproto_split(call FunctionCall) Value {
 			excess = true
 		}
 
-		split := strings.SplitN(str, separator, splitLimit)
+		split := strings.SplitN(str, separator.String(), splitLimit)
 
 		if excess && len(split) > limit {
 			split = split[:limit]
@@ -574,6 +815,31 @@ func (r *Runtime) stringproto_split(call FunctionCall) Value {
 
 }
 
+// splitUTF16 splits s by code units, so that an empty separator separates the surrogate pairs and
+// the unpaired surrogates are kept.
+func splitUTF16(s, separator valueString, limit int) []Value {
+	var values []Value
+	l := s.length()
+	sepLen := separator.length()
+	if sepLen == 0 {
+		for i := int64(0); i < l && len(values) != limit; i++ {
+			values = append(values, s.substring(i, i+1))
+		}
+		return values
+	}
+	var pos int64
+	for len(values) != limit {
+		idx := s.index(separator, pos)
+		if idx < 0 {
+			values = append(values, s.substring(pos, l))
+			break
+		}
+		values = append(values, s.substring(pos, idx))
+		pos = idx + sepLen
+	}
+	return values
+}
+
 func (r *Runtime) stringproto_substring(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
 	s := call.This.ToString()
@@ -619,11 +885,49 @@ func (r *Runtime) stringproto_toUpperCase(call FunctionCall) Value {
 	return s.toUpper()
 }
 
-func (r *Runtime) stringproto_trim(call FunctionCall) Value {
+func (r *Runtime) stringproto_toLocaleLowerCase(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	s := call.This.ToString()
+
+	return r.localeLower(s)
+}
+
+func (r *Runtime) stringproto_toLocaleUpperCase(call FunctionCall) Value {
 	r.checkObjectCoercible(call.This)
 	s := call.This.ToString()
 
-	return newStringValue(strings.Trim(s.String(), parser.WhitespaceChars))
+	return r.localeUpper(s)
+}
+
+// trimString removes the white space and line terminators from the start and/or the end of s.
+func trimString(s valueString, start, end bool) valueString {
+	from, to := int64(0), s.length()
+	if start {
+		for from < to && strings.ContainsRune(parser.WhitespaceChars, s.charAt(from)) {
+			from++
+		}
+	}
+	if end {
+		for to > from && strings.ContainsRune(parser.WhitespaceChars, s.charAt(to-1)) {
+			to--
+		}
+	}
+	return s.substring(from, to)
+}
+
+func (r *Runtime) stringproto_trim(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	return trimString(call.This.ToString(), true, true)
+}
+
+func (r *Runtime) stringproto_trimStart(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	return trimString(call.This.ToString(), true, false)
+}
+
+func (r *Runtime) stringproto_trimEnd(call FunctionCall) Value {
+	r.checkObjectCoercible(call.This)
+	return trimString(call.This.ToString(), false, true)
 }
 
 func (r *Runtime) stringproto_substr(call FunctionCall) Value {
@@ -657,10 +961,16 @@ func (r *Runtime) initString() {
 	o._putProp("toString", r.newNativeFunc(r.stringproto_toString, nil, "toString", nil, 0), true, false, true)
 	o._putProp("valueOf", r.newNativeFunc(r.stringproto_valueOf, nil, "valueOf", nil, 0), true, false, true)
 	o._putProp("charAt", r.newNativeFunc(r.stringproto_charAt, nil, "charAt", nil, 1), true, false, true)
+	o._putProp("at", r.newNativeFunc(r.stringproto_at, nil, "at", nil, 1), true, false, true)
 	o._putProp("charCodeAt", r.newNativeFunc(r.stringproto_charCodeAt, nil, "charCodeAt", nil, 1), true, false, true)
+	o._putProp("codePointAt", r.newNativeFunc(r.stringproto_codePointAt, nil, "codePointAt", nil, 1), true, false, true)
 	o._putProp("concat", r.newNativeFunc(r.stringproto_concat, nil, "concat", nil, 1), true, false, true)
 	o._putProp("indexOf", r.newNativeFunc(r.stringproto_indexOf, nil, "indexOf", nil, 1), true, false, true)
 	o._putProp("lastIndexOf", r.newNativeFunc(r.stringproto_lastIndexOf, nil, "lastIndexOf", nil, 1), true, false, true)
+	o._putProp("includes", r.newNativeFunc(r.stringproto_includes, nil, "includes", nil, 1), true, false, true)
+	o._putProp("startsWith", r.newNativeFunc(r.stringproto_startsWith, nil, "startsWith", nil, 1), true, false, true)
+	o._putProp("endsWith", r.newNativeFunc(r.stringproto_endsWith, nil, "endsWith", nil, 1), true, false, true)
+	o._putProp("repeat", r.newNativeFunc(r.stringproto_repeat, nil, "repeat", nil, 1), true, false, true)
 	o._putProp("localeCompare", r.newNativeFunc(r.stringproto_localeCompare, nil, "localeCompare", nil, 1), true, false, true)
 	o._putProp("match", r.newNativeFunc(r.stringproto_match, nil, "match", nil, 1), true, false, true)
 	o._putProp("replace", r.newNativeFunc(r.stringproto_replace, nil, "replace", nil, 2), true, false, true)
@@ -669,10 +979,14 @@ func (r *Runtime) initString() {
 	o._putProp("split", r.newNativeFunc(r.stringproto_split, nil, "split", nil, 2), true, false, true)
 	o._putProp("substring", r.newNativeFunc(r.stringproto_substring, nil, "substring", nil, 2), true, false, true)
 	o._putProp("toLowerCase", r.newNativeFunc(r.stringproto_toLowerCase, nil, "toLowerCase", nil, 0), true, false, true)
-	o._putProp("toLocaleLowerCase", r.newNativeFunc(r.stringproto_toLowerCase, nil, "toLocaleLowerCase", nil, 0), true, false, true)
+	o._putProp("toLocaleLowerCase", r.newNativeFunc(r.stringproto_toLocaleLowerCase, nil, "toLocaleLowerCase", nil, 0), true, false, true)
 	o._putProp("toUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toUpperCase", nil, 0), true, false, true)
-	o._putProp("toLocaleUpperCase", r.newNativeFunc(r.stringproto_toUpperCase, nil, "toLocaleUpperCase", nil, 0), true, false, true)
+	o._putProp("toLocaleUpperCase", r.newNativeFunc(r.stringproto_toLocaleUpperCase, nil, "toLocaleUpperCase", nil, 0), true, false, true)
 	o._putProp("trim", r.newNativeFunc(r.stringproto_trim, nil, "trim", nil, 0), true, false, true)
+	o._putProp("trimStart", r.newNativeFunc(r.stringproto_trimStart, nil, "trimStart", nil, 0), true, false, true)
+	o._putProp("trimEnd", r.newNativeFunc(r.stringproto_trimEnd, nil, "trimEnd", nil, 0), true, false, true)
+	o._putProp("padStart", r.newNativeFunc(r.stringproto_padStart, nil, "padStart", nil, 1), true, false, true)
+	o._putProp("padEnd", r.newNativeFunc(r.stringproto_padEnd, nil, "padEnd", nil, 1), true, false, true)
 
 	// Annex B
 	o._putProp("substr", r.newNativeFunc(r.stringproto_substr, nil, "substr", nil, 2), true, false, true)
@@ -680,6 +994,8 @@ func (r *Runtime) initString() {
 	r.global.String = r.newNativeFunc(r.builtin_String, r.builtin_newString, "String", r.global.StringPrototype, 1)
 	o = r.global.String.self
 	o._putProp("fromCharCode", r.newNativeFunc(r.string_fromcharcode, nil, "fromCharCode", nil, 1), true, false, true)
+	o._putProp("fromCodePoint", r.newNativeFunc(r.string_fromcodepoint, nil, "fromCodePoint", nil, 1), true, false, true)
+	o._putProp("raw", r.newNativeFunc(r.string_raw, nil, "raw", nil, 1), true, false, true)
 
 	r.addToGlobal("String", r.global.String)
 
@@ -88,3 +88,197 @@ assert.sameValue(
 
 	testScript1(TESTLIB+SCRIPT, _undefined, t)
 }
+
+func TestStringUTF16(t *testing.T) {
+	const SCRIPT = `
+	var lone = String.fromCharCode(0xD83D);
+	assert.sameValue("aбb".indexOf("b"), 2, "indexOf at the end");
+	assert.sameValue("aбb".lastIndexOf("b"), 2, "lastIndexOf");
+	assert.sameValue("бbb".lastIndexOf("b", 10), 2, "lastIndexOf past the end");
+	assert.sameValue((lone + "A").indexOf("A"), 1, "indexOf after a lone surrogate");
+
+	assert.sameValue("😀".charAt(0).charCodeAt(0), 0xD83D, "charAt");
+	assert.sameValue("😀".split("").length, 2, "split surrogate pair");
+	assert.sameValue((lone + "x").split("")[0], lone, "split lone surrogate");
+	assert.sameValue("a😀b😀c".split("😀").join(), "a,b,c", "split non-ASCII separator");
+	assert.sameValue("a😀b".split("", 2).length, 2, "split limit");
+
+	assert("｡" > "😀", "compare code units");
+	assert.sameValue(["｡", "😀"].sort()[0], "😀", "sort");
+
+	assert.sameValue("\uD83D".length, 1, "lone surrogate escape");
+	assert.sameValue("\uD83Dx".charCodeAt(0), 0xD83D, "lone surrogate escape followed by a character");
+	assert.sameValue("a\uDE00".charCodeAt(1), 0xDE00, "lone trailing surrogate escape");
+	assert.sameValue("\uD83D\uDE00", "😀", "surrogate pair escape");
+	assert.sameValue(eval("'" + lone + "'"), lone, "eval");
+	assert.sameValue(eval("/" + lone + "/").source, lone, "regexp source");
+	assert.sameValue(new Function("return '" + lone + "'")(), lone, "Function");
+
+	assert.sameValue(JSON.stringify(lone + "😀"), '"\\ud83d😀"', "JSON.stringify");
+	assert.sameValue(/\é/.source, "\\é", "non-ASCII identity escape");
+	assert(/\é/.test("é"), "non-ASCII identity escape match");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringLoneSurrogatePropertyNames(t *testing.T) {
+	const SCRIPT = `
+	var o = {};
+	o["\ud83d"] = 1;
+	o["\ude00"] = 2;
+	o["�"] = 3;
+	var keys = Object.keys(o);
+	assert.sameValue(keys.length, 3, "distinct keys");
+	assert.sameValue(keys[0], "\ud83d", "leading surrogate key");
+	assert.sameValue(keys[1], "\ude00", "trailing surrogate key");
+	assert.sameValue(o["\ud83d"], 1, "leading surrogate value");
+	assert.sameValue(o["\ude00"], 2, "trailing surrogate value");
+	assert.sameValue(o["�"], 3, "replacement character value");
+
+	var p = JSON.parse('{"\\ud800":1,"\\udc00":2}');
+	assert.sameValue(Object.keys(p).length, 2, "JSON.parse distinct keys");
+	assert.sameValue(Object.keys(p)[0], "\ud800", "JSON.parse key");
+	assert.sameValue(p["\udc00"], 2, "JSON.parse value");
+	assert.sameValue(JSON.stringify(p), '{"\\ud800":1,"\\udc00":2}', "JSON.stringify keys");
+	assert.sameValue(JSON.parse('"\\udc00\\ud83d\\ude00"'), "\udc00😀", "JSON.parse string");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringAt(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("abc".at(0), "a", "0");
+	assert.sameValue("abc".at(-1), "c", "-1");
+	assert.sameValue("abc".at(3), undefined, "3");
+	assert.sameValue("abc".at(-4), undefined, "-4");
+	assert.sameValue("abc".at(), "a", "no argument");
+	assert.sameValue("a😀".at(-1), "\uDE00", "code unit");
+	assert.sameValue(String.prototype.at.call(123, 1), "2", "generic");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringIncludesStartsEndsWith(t *testing.T) {
+	const SCRIPT = `
+	assert("abcd".includes("bc"), "includes");
+	assert(!"abcd".includes("bc", 2), "includes position");
+	assert("abcd".includes(""), "includes empty");
+	assert("a😀b".includes("😀"), "includes unicode");
+
+	assert("abcd".startsWith("ab"), "startsWith");
+	assert("abcd".startsWith("cd", 2), "startsWith position");
+	assert(!"abcd".startsWith("cde", 2), "startsWith past end");
+	assert("abcd".startsWith("ab", -5), "startsWith negative");
+
+	assert("abcd".endsWith("cd"), "endsWith");
+	assert("abcd".endsWith("b", 2), "endsWith endPosition");
+	assert(!"abcd".endsWith("abcde"), "endsWith longer");
+	assert("abcd".endsWith("abcd", 10), "endsWith clamped");
+	assert("a😀".endsWith("😀"), "endsWith unicode");
+
+	["includes", "startsWith", "endsWith"].forEach(function(m) {
+		var thrown = false;
+		try {
+			"a"[m](/a/);
+		} catch (e) {
+			thrown = e instanceof TypeError;
+		}
+		assert(thrown, m + " with RegExp");
+	});
+	assert("/a/".startsWith({toString: function() { return "/a"; }}), "object argument");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringRepeat(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("ab".repeat(3), "ababab", "repeat");
+	assert.sameValue("ab".repeat(0), "", "zero");
+	assert.sameValue("ab".repeat(NaN), "", "NaN");
+	assert.sameValue("".repeat(100), "", "empty");
+	assert.sameValue("😀".repeat(2), "😀😀", "unicode");
+	assert.sameValue("ab".repeat(2.9), "abab", "fraction");
+	[-1, Infinity, 1 << 30].forEach(function(n) {
+		var thrown = false;
+		try {
+			"ab".repeat(n);
+		} catch (e) {
+			thrown = e instanceof RangeError;
+		}
+		assert(thrown, "RangeError for " + n);
+	});
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringPadTrim(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("5".padStart(3, "0"), "005", "padStart");
+	assert.sameValue("abc".padStart(10, "123"), "1231231abc", "padStart truncated filler");
+	assert.sameValue("abc".padEnd(6, "12"), "abc121", "padEnd");
+	assert.sameValue("abc".padEnd(5), "abc  ", "default filler");
+	assert.sameValue("abc".padStart(2, "x"), "abc", "shorter maxLength");
+	assert.sameValue("abc".padStart(5, ""), "abc", "empty filler");
+	assert.sameValue("abc".padStart(NaN, "x"), "abc", "NaN maxLength");
+	assert.sameValue("a".padEnd(3, "😀"), "a😀", "surrogate pair filler");
+	assert.sameValue("a".padEnd(2, "😀"), "a\uD83D", "filler is cut by code units");
+	var thrown = false;
+	try {
+		"a".padStart(Infinity, "x");
+	} catch (e) {
+		thrown = e instanceof RangeError;
+	}
+	assert(thrown, "too long");
+
+	var ws = " \t\n\v\f\r\u00A0\u1680\u2000\u200A\u2028\u2029\u202F\u205F\u3000\uFEFF";
+	assert.sameValue((ws + "a b" + ws).trimStart(), "a b" + ws, "trimStart");
+	assert.sameValue((ws + "a b" + ws).trimEnd(), ws + "a b", "trimEnd");
+	assert.sameValue((ws + "a b" + ws).trim(), "a b", "trim");
+	assert.sameValue(ws.trimStart(), "", "all white space");
+	assert.sameValue(" \u180E".trim(), "\u180E", "U+180E is not white space");
+	assert.sameValue(" \uD800 ".trim(), "\uD800", "lone surrogate");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestStringCodePoints(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(String.fromCodePoint(), "", "no arguments");
+	assert.sameValue(String.fromCodePoint(97, 0x1F600, 0x20AC), "a😀€", "fromCodePoint");
+	assert.sameValue(String.fromCodePoint(0x1F600).length, 2, "surrogate pair");
+	assert.sameValue(String.fromCodePoint(-0, "65"), "\0A", "-0 and strings");
+	assert.sameValue(String.fromCodePoint(0xD800), "\uD800", "lone surrogate");
+	[-1, 1.5, 0x110000, NaN, Infinity, "a"].forEach(function(c) {
+		var thrown = false;
+		try {
+			String.fromCodePoint(c);
+		} catch (e) {
+			thrown = e instanceof RangeError;
+		}
+		assert(thrown, "RangeError for " + c);
+	});
+	assert.sameValue(String.fromCharCode(65, 0x100, 66), "AĀB", "fromCharCode");
+
+	var s = "a😀\uD800";
+	assert.sameValue(s.codePointAt(0), 97, "ASCII");
+	assert.sameValue(s.codePointAt(1), 0x1F600, "surrogate pair");
+	assert.sameValue(s.codePointAt(2), 0xDE00, "trail surrogate");
+	assert.sameValue(s.codePointAt(3), 0xD800, "lone surrogate");
+	assert.sameValue(s.codePointAt(4), undefined, "out of range");
+	assert.sameValue(s.codePointAt(-1), undefined, "negative");
+
+	assert.sameValue(String.raw({raw: ["a", "b", "c"]}, 1, 2, 3), "a1b2c", "raw");
+	assert.sameValue(String.raw({raw: "xyz"}, "-", "-"), "x-y-z", "raw string");
+	assert.sameValue(String.raw({raw: ["a\\n", ""]}, "\uD800"), "a\\n\uD800", "raw substitutions");
+	assert.sameValue(String.raw({raw: []}, 1), "", "empty");
+	assert.sameValue(String.raw({raw: {length: 2, 0: "x"}}), "xundefined", "array-like");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
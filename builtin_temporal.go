@@ -0,0 +1,1459 @@
+package goja
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The Temporal API (an ECMAScript proposal) mapped onto the time package, see Runtime.SetTemporal().
+
+const (
+	temporalYears = iota
+	temporalMonths
+	temporalWeeks
+	temporalDays
+	temporalHours
+	temporalMinutes
+	temporalSeconds
+	temporalMilliseconds
+	temporalMicroseconds
+	temporalNanoseconds
+	temporalUnitCount
+)
+
+var temporalUnits = [temporalUnitCount]string{"years", "months", "weeks", "days", "hours", "minutes", "seconds",
+	"milliseconds", "microseconds", "nanoseconds"}
+
+const (
+	// the limits of the Instants (in seconds) and of the Duration fields and their time total (in seconds)
+	temporalMaxEpochSeconds   = 864e10
+	temporalMaxDurationFields = 1 << 53
+)
+
+var temporalUnitSeconds = [temporalUnitCount]float64{temporalHours: 3600, temporalMinutes: 60, temporalSeconds: 1,
+	temporalMilliseconds: 1e-3, temporalMicroseconds: 1e-6, temporalNanoseconds: 1e-9}
+
+type temporalPrototypes struct {
+	duration, instant, plainDate, zonedDateTime *Object
+}
+
+type temporalDuration [temporalUnitCount]float64
+
+func (d *temporalDuration) sign() int {
+	for _, v := range d {
+		if v > 0 {
+			return 1
+		}
+		if v < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+func (d *temporalDuration) negated() temporalDuration {
+	var res temporalDuration
+	for i, v := range d {
+		res[i] = -v
+	}
+	return res
+}
+
+// timeSeconds returns the time units (hours and smaller) as seconds and nanoseconds.
+func (d *temporalDuration) timeSeconds() (sec, nsec int64) {
+	sec = int64(d[temporalHours])*3600 + int64(d[temporalMinutes])*60 + int64(d[temporalSeconds])
+	ms, us, ns := int64(d[temporalMilliseconds]), int64(d[temporalMicroseconds]), int64(d[temporalNanoseconds])
+	sec += ms/1e3 + us/1e6 + ns/1e9
+	nsec = ms%1e3*1e6 + us%1e6*1e3 + ns%1e9
+	return
+}
+
+// hasDateUnits returns true if any of the units larger than hours is not zero.
+func (d *temporalDuration) hasDateUnits() bool {
+	for _, v := range d[:temporalHours] {
+		if v != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *temporalDuration) String() string {
+	sign := d.sign()
+	var b strings.Builder
+	if sign < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+	abs := func(u int) float64 {
+		return math.Abs(d[u])
+	}
+	for u, designator := range "YMWD" {
+		if v := abs(u); v != 0 {
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			b.WriteRune(designator)
+		}
+	}
+	sec, nsec := d.timeSeconds()
+	if sign < 0 {
+		sec, nsec = -sec, -nsec
+	}
+	hours, minutes := abs(temporalHours), abs(temporalMinutes)
+	seconds := sec - int64(hours)*3600 - int64(minutes)*60
+	if hours != 0 || minutes != 0 || seconds != 0 || nsec != 0 || sign == 0 {
+		b.WriteByte('T')
+		if hours != 0 {
+			b.WriteString(strconv.FormatFloat(hours, 'f', -1, 64))
+			b.WriteByte('H')
+		}
+		if minutes != 0 {
+			b.WriteString(strconv.FormatFloat(minutes, 'f', -1, 64))
+			b.WriteByte('M')
+		}
+		if seconds != 0 || nsec != 0 || sign == 0 {
+			b.WriteString(strconv.FormatInt(seconds, 10))
+			if nsec != 0 {
+				b.WriteByte('.')
+				b.WriteString(strings.TrimRight(fmt.Sprintf("%09d", nsec), "0"))
+			}
+			b.WriteByte('S')
+		}
+	}
+	return b.String()
+}
+
+// balanceTemporalTime creates a Duration of sec seconds and nsec nanoseconds (which have the same sign) with the
+// largest unit being hours or smaller.
+func balanceTemporalTime(sec, nsec int64, largest int) temporalDuration {
+	var d temporalDuration
+	neg := sec < 0 || sec == 0 && nsec < 0
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	switch largest {
+	case temporalMilliseconds:
+		d[temporalMilliseconds] = float64(sec*1e3 + nsec/1e6)
+	case temporalMicroseconds:
+		d[temporalMicroseconds] = float64(sec*1e6 + nsec/1e3)
+	case temporalNanoseconds:
+		d[temporalNanoseconds] = float64(sec)*1e9 + float64(nsec)
+		if neg {
+			d = d.negated()
+		}
+		return d
+	default:
+		d[temporalSeconds] = float64(sec)
+		d[temporalMilliseconds] = float64(nsec / 1e6)
+		if largest <= temporalMinutes {
+			d[temporalMinutes], d[temporalSeconds] = float64(sec/60), float64(sec%60)
+		}
+		if largest <= temporalHours {
+			d[temporalHours], d[temporalMinutes] = float64(sec/3600), float64(sec/60%60)
+		}
+	}
+	if largest < temporalMicroseconds {
+		d[temporalMicroseconds] = float64(nsec / 1e3 % 1e3)
+	}
+	d[temporalNanoseconds] = float64(nsec % 1e3)
+	if neg {
+		d = d.negated()
+	}
+	return d
+}
+
+// temporalTimeDiff returns the difference b-a in seconds and nanoseconds with the same sign.
+func temporalTimeDiff(a, b time.Time) (sec, nsec int64) {
+	sec = b.Unix() - a.Unix()
+	nsec = int64(b.Nanosecond() - a.Nanosecond())
+	if sec > 0 && nsec < 0 {
+		sec--
+		nsec += 1e9
+	} else if sec < 0 && nsec > 0 {
+		sec++
+		nsec -= 1e9
+	}
+	return
+}
+
+func temporalAddTime(t time.Time, sec, nsec int64) time.Time {
+	return time.Unix(t.Unix()+sec, int64(t.Nanosecond())+nsec).In(t.Location())
+}
+
+func compareTemporalTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+type temporalDate struct {
+	year, month, day int
+}
+
+func temporalDaysInMonth(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func temporalIsLeapYear(year int) bool {
+	return temporalDaysInMonth(year, 2) == 29
+}
+
+func (d temporalDate) time(loc *time.Location) time.Time {
+	return temporalWallTime(d.year, d.month, d.day, 0, 0, 0, 0, loc)
+}
+
+// temporalWallTime returns the instant of a wall-clock time in loc with the "compatible" disambiguation: a time
+// which occurs twice when the clocks are turned back is the earlier instant, and a time skipped when they are turned
+// forward is moved forward by the length of the gap (i.e. it's interpreted with the offset before the transition).
+// time.Date() doesn't specify either.
+func temporalWallTime(year, month, day, hour, minute, second, nanosecond int, loc *time.Location) time.Time {
+	u := time.Date(year, time.Month(month), day, hour, minute, second, nanosecond, time.UTC)
+	_, offsetBefore := u.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := u.Add(24 * time.Hour).In(loc).Zone()
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		t := u.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, o := t.Zone(); o == offset {
+			return t
+		}
+	}
+	return u.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+}
+
+func (d temporalDate) epochDays() int64 {
+	return d.time(time.UTC).Unix() / 86400
+}
+
+func temporalDateOf(t time.Time) temporalDate {
+	y, m, d := t.Date()
+	return temporalDate{y, int(m), d}
+}
+
+func compareTemporalDate(a, b temporalDate) int {
+	switch {
+	case a.year != b.year:
+		return compareInt(a.year, b.year)
+	case a.month != b.month:
+		return compareInt(a.month, b.month)
+	}
+	return compareInt(a.day, b.day)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (d temporalDate) String() string {
+	var y string
+	if d.year < 0 || d.year > 9999 {
+		y = fmt.Sprintf("%+07d", d.year)
+	} else {
+		y = fmt.Sprintf("%04d", d.year)
+	}
+	return fmt.Sprintf("%s-%02d-%02d", y, d.month, d.day)
+}
+
+// addMonths adds the months to the date, the day is constrained to the length of the month unless reject is
+// true, in which case false is returned if it doesn't fit.
+func (d temporalDate) addMonths(months int, reject bool) (temporalDate, bool) {
+	m := d.month - 1 + months
+	d.year += m / 12
+	if m %= 12; m < 0 {
+		m += 12
+		d.year--
+	}
+	d.month = m + 1
+	if dim := temporalDaysInMonth(d.year, d.month); d.day > dim {
+		if reject {
+			return d, false
+		}
+		d.day = dim
+	}
+	return d, true
+}
+
+func (d temporalDate) addDays(days int64) temporalDate {
+	return temporalDateOf(time.Unix((d.epochDays()+days)*86400, 0).UTC())
+}
+
+// temporalFields are the fields of a parsed ISO 8601 string.
+type temporalFields struct {
+	temporalDate
+	hour, minute, second, nanosecond int
+	utc, hasOffset                   bool
+	offset                           int
+	timeZone                         string
+}
+
+var (
+	temporalDateTimeRegexp = regexp.MustCompile(`^([+-]\d{6}|\d{4})-?(\d{2})-?(\d{2})` +
+		`(?:[Tt ](\d{2})(?::?(\d{2})(?::?(\d{2})(?:[.,](\d{1,9}))?)?)?)?` +
+		`([Zz]|[+-]\d{2}(?::?\d{2})?)?(?:\[!?([^\]=]+)\])?(?:\[!?u-ca=iso8601\])?$`)
+	temporalOffsetRegexp   = regexp.MustCompile(`^([+-])(\d{2}):?(\d{2})?$`)
+	temporalDurationRegexp = regexp.MustCompile(`^(?i)([+-])?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?` +
+		`(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)(?:[.,](\d{1,9}))?S)?)?$`)
+)
+
+func parseTemporalOffset(s string) (int, bool) {
+	m := temporalOffsetRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[2])
+	mi, _ := strconv.Atoi(m[3])
+	if h > 23 || mi > 59 {
+		return 0, false
+	}
+	offset := h*3600 + mi*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return offset, true
+}
+
+func formatTemporalOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	s := fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf(":%02d", offset%60)
+	}
+	return s
+}
+
+func formatTemporalTime(t time.Time) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour(), t.Minute(), t.Second())
+	if ns := t.Nanosecond(); ns != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", ns), "0")
+	}
+	return s
+}
+
+func (r *Runtime) parseTemporalString(s string) *temporalFields {
+	m := temporalDateTimeRegexp.FindStringSubmatch(s)
+	if m == nil || m[1] == "-000000" {
+		panic(r.newError(r.global.RangeError, "Invalid ISO 8601 string: %s", s))
+	}
+	var f temporalFields
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	f.year, f.month, f.day = atoi(m[1]), atoi(m[2]), atoi(m[3])
+	f.hour, f.minute, f.second = atoi(m[4]), atoi(m[5]), atoi(m[6])
+	if m[7] != "" {
+		f.nanosecond = atoi(m[7] + strings.Repeat("0", 9-len(m[7])))
+	}
+	if f.second == 60 {
+		f.second = 59
+	}
+	if f.month < 1 || f.month > 12 || f.day < 1 || f.day > temporalDaysInMonth(f.year, f.month) ||
+		f.hour > 23 || f.minute > 59 || f.second > 59 {
+		panic(r.newError(r.global.RangeError, "Invalid ISO 8601 string: %s", s))
+	}
+	switch {
+	case m[8] == "Z" || m[8] == "z":
+		f.utc = true
+	case m[8] != "":
+		offset, ok := parseTemporalOffset(m[8])
+		if !ok {
+			panic(r.newError(r.global.RangeError, "Invalid ISO 8601 string: %s", s))
+		}
+		f.hasOffset, f.offset = true, offset
+	}
+	f.timeZone = m[9]
+	return &f
+}
+
+func (f *temporalFields) wallTime(loc *time.Location) time.Time {
+	return temporalWallTime(f.year, f.month, f.day, f.hour, f.minute, f.second, f.nanosecond, loc)
+}
+
+func (r *Runtime) checkTemporalInstant(t time.Time) time.Time {
+	if sec := t.Unix(); math.Abs(float64(sec)) > temporalMaxEpochSeconds || sec == temporalMaxEpochSeconds && t.Nanosecond() > 0 {
+		panic(r.newError(r.global.RangeError, "Instant is outside of the supported range"))
+	}
+	return t
+}
+
+func (r *Runtime) checkTemporalDate(d temporalDate) temporalDate {
+	if math.Abs(float64(d.epochDays())) > temporalMaxEpochSeconds/86400+1 {
+		panic(r.newError(r.global.RangeError, "Date is outside of the supported range"))
+	}
+	return d
+}
+
+// temporalTimeZone converts a time zone identifier (an IANA name, "UTC" or an offset such as "+01:00") or a
+// ZonedDateTime to a location.
+func (r *Runtime) temporalTimeZone(v Value) *time.Location {
+	if o, ok := v.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			return z.t.Location()
+		}
+	}
+	if _, ok := v.(valueString); !ok {
+		panic(r.NewTypeError("Time zone must be a string"))
+	}
+	id := v.String()
+	if strings.EqualFold(id, "UTC") {
+		return time.UTC
+	}
+	if offset, ok := parseTemporalOffset(id); ok {
+		return time.FixedZone(formatTemporalOffset(offset), offset)
+	}
+	loc, err := time.LoadLocation(id)
+	if err != nil || id == "" || id == "Local" {
+		panic(r.newError(r.global.RangeError, "Invalid time zone specified: %s", id))
+	}
+	return loc
+}
+
+// temporalDefaultTimeZone returns the time zone of the Runtime (see SetLocaleTimeZone()). If it has no IANA name
+// (as time.Local), the current offset is used.
+func (r *Runtime) temporalDefaultTimeZone() *time.Location {
+	loc := r.locale.timeZone
+	if loc == nil {
+		loc = time.Local
+	}
+	if loc.String() == "Local" {
+		_, offset := time.Now().In(loc).Zone()
+		return time.FixedZone(formatTemporalOffset(offset), offset)
+	}
+	return loc
+}
+
+func (r *Runtime) temporalOptions(v Value) *Object {
+	switch v := v.(type) {
+	case valueUndefined:
+		return nil
+	case *Object:
+		return v
+	}
+	panic(r.NewTypeError("Options must be an object"))
+}
+
+func (r *Runtime) temporalOverflowReject(options Value) bool {
+	return r.getStringOption(r.temporalOptions(options), "overflow", []string{"constrain", "reject"}, "constrain") == "reject"
+}
+
+// temporalUnitOption reads a unit option (singular or plural), which must be between largest and smallest.
+func (r *Runtime) temporalUnitOption(options *Object, name string, largest, smallest, fallback int) int {
+	if options == nil {
+		return fallback
+	}
+	v := options.self.getStr(name)
+	if v == nil || v == _undefined {
+		return fallback
+	}
+	s := v.String()
+	if s == "auto" {
+		return fallback
+	}
+	for u := largest; u <= smallest; u++ {
+		if s == temporalUnits[u] || s == strings.TrimSuffix(temporalUnits[u], "s") {
+			return u
+		}
+	}
+	panic(r.newError(r.global.RangeError, "Value %s out of range for options property %s", s, name))
+}
+
+// temporalInteger converts a field value with truncation, it must be finite.
+func (r *Runtime) temporalInteger(v Value, name string) float64 {
+	x := v.ToFloat()
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		panic(r.newError(r.global.RangeError, "Invalid value for %s: %s", name, v.String()))
+	}
+	return math.Trunc(x)
+}
+
+func (r *Runtime) temporalValueOf(FunctionCall) Value {
+	panic(r.NewTypeError("Temporal objects cannot be converted to primitives, use compare() or equals() instead"))
+}
+
+// temporal objects
+
+type temporalDurationObject struct {
+	baseObject
+	d temporalDuration
+}
+
+type temporalInstantObject struct {
+	baseObject
+	t time.Time
+}
+
+type temporalPlainDateObject struct {
+	baseObject
+	d temporalDate
+}
+
+type temporalZonedDateTimeObject struct {
+	baseObject
+	t time.Time
+}
+
+func (r *Runtime) newTemporalBase(b *baseObject, proto *Object) *Object {
+	v := &Object{runtime: r}
+	b.class = classObject
+	b.val = v
+	b.prototype = proto
+	b.extensible = true
+	return v
+}
+
+func (r *Runtime) newTemporalDuration(d temporalDuration, proto *Object) *Object {
+	if proto == nil {
+		proto = r.temporal.duration
+	}
+	sign := d.sign()
+	var seconds float64
+	for i, v := range d {
+		if math.Abs(v) >= temporalMaxDurationFields || v != 0 && (v > 0) != (sign > 0) {
+			panic(r.newError(r.global.RangeError, "Invalid duration"))
+		}
+		if v == 0 {
+			d[i] = 0 // no -0
+		}
+		if i >= temporalHours {
+			seconds += math.Abs(v) * temporalUnitSeconds[i]
+		}
+	}
+	if seconds >= temporalMaxDurationFields {
+		panic(r.newError(r.global.RangeError, "Invalid duration"))
+	}
+	o := &temporalDurationObject{d: d}
+	v := r.newTemporalBase(&o.baseObject, proto)
+	v.self = o
+	o.init()
+	return v
+}
+
+func (r *Runtime) newTemporalInstant(t time.Time, proto *Object) *Object {
+	if proto == nil {
+		proto = r.temporal.instant
+	}
+	o := &temporalInstantObject{t: r.checkTemporalInstant(t).UTC()}
+	v := r.newTemporalBase(&o.baseObject, proto)
+	v.self = o
+	o.init()
+	return v
+}
+
+func (r *Runtime) newTemporalPlainDate(d temporalDate, proto *Object) *Object {
+	if proto == nil {
+		proto = r.temporal.plainDate
+	}
+	o := &temporalPlainDateObject{d: r.checkTemporalDate(d)}
+	v := r.newTemporalBase(&o.baseObject, proto)
+	v.self = o
+	o.init()
+	return v
+}
+
+func (r *Runtime) newTemporalZonedDateTime(t time.Time, proto *Object) *Object {
+	if proto == nil {
+		proto = r.temporal.zonedDateTime
+	}
+	o := &temporalZonedDateTimeObject{t: r.checkTemporalInstant(t)}
+	v := r.newTemporalBase(&o.baseObject, proto)
+	v.self = o
+	o.init()
+	return v
+}
+
+func (r *Runtime) thisTemporalDuration(v Value, method string) *temporalDurationObject {
+	if o, ok := v.(*Object); ok {
+		if d, ok := o.self.(*temporalDurationObject); ok {
+			return d
+		}
+	}
+	panic(r.NewTypeError("Method Temporal.Duration.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) thisTemporalInstant(v Value, method string) *temporalInstantObject {
+	if o, ok := v.(*Object); ok {
+		if i, ok := o.self.(*temporalInstantObject); ok {
+			return i
+		}
+	}
+	panic(r.NewTypeError("Method Temporal.Instant.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) thisTemporalPlainDate(v Value, method string) *temporalPlainDateObject {
+	if o, ok := v.(*Object); ok {
+		if d, ok := o.self.(*temporalPlainDateObject); ok {
+			return d
+		}
+	}
+	panic(r.NewTypeError("Method Temporal.PlainDate.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+func (r *Runtime) thisTemporalZonedDateTime(v Value, method string) *temporalZonedDateTimeObject {
+	if o, ok := v.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			return z
+		}
+	}
+	panic(r.NewTypeError("Method Temporal.ZonedDateTime.prototype.%s called on incompatible receiver %s", method, v.String()))
+}
+
+// conversions of the arguments
+
+func (r *Runtime) toTemporalDuration(v Value) temporalDuration {
+	switch v := v.(type) {
+	case *Object:
+		if d, ok := v.self.(*temporalDurationObject); ok {
+			return d.d
+		}
+		return r.temporalDurationFields(v, temporalDuration{}, true)
+	case valueString:
+		return r.parseTemporalDuration(v.String())
+	}
+	panic(r.NewTypeError("Invalid duration: %s", v.String()))
+}
+
+// temporalDurationFields reads the unit properties of o into d, at least one must be present if required.
+func (r *Runtime) temporalDurationFields(o *Object, d temporalDuration, required bool) temporalDuration {
+	found := false
+	for u, name := range temporalUnits {
+		if v := o.self.getStr(name); v != nil && v != _undefined {
+			x := v.ToFloat()
+			if math.IsNaN(x) || math.IsInf(x, 0) || x != math.Trunc(x) {
+				panic(r.newError(r.global.RangeError, "Invalid value for %s: %s", name, v.String()))
+			}
+			d[u] = x
+			found = true
+		}
+	}
+	if required && !found {
+		panic(r.NewTypeError("Invalid duration: no duration properties"))
+	}
+	return d
+}
+
+func (r *Runtime) parseTemporalDuration(s string) temporalDuration {
+	m := temporalDurationRegexp.FindStringSubmatch(s)
+	if m == nil || strings.HasSuffix(strings.ToUpper(s), "T") || strings.HasSuffix(strings.ToUpper(s), "P") {
+		panic(r.newError(r.global.RangeError, "Invalid duration: %s", s))
+	}
+	var d temporalDuration
+	for i, u := range []int{temporalYears, temporalMonths, temporalWeeks, temporalDays, temporalHours, temporalMinutes, temporalSeconds} {
+		if m[i+2] != "" {
+			d[u], _ = strconv.ParseFloat(m[i+2], 64)
+		}
+	}
+	if frac := m[9]; frac != "" {
+		ns, _ := strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+		d[temporalMilliseconds] = float64(ns / 1e6)
+		d[temporalMicroseconds] = float64(ns / 1e3 % 1e3)
+		d[temporalNanoseconds] = float64(ns % 1e3)
+	}
+	if m[1] == "-" {
+		d = d.negated()
+	}
+	return d
+}
+
+func (r *Runtime) toTemporalInstant(v Value) time.Time {
+	if o, ok := v.(*Object); ok {
+		switch o := o.self.(type) {
+		case *temporalInstantObject:
+			return o.t
+		case *temporalZonedDateTimeObject:
+			return o.t.UTC()
+		}
+	}
+	s := v.String()
+	f := r.parseTemporalString(s)
+	if !f.utc && !f.hasOffset {
+		panic(r.newError(r.global.RangeError, "Instant requires a UTC offset: %s", s))
+	}
+	return r.checkTemporalInstant(f.wallTime(time.UTC).Add(-time.Duration(f.offset) * time.Second))
+}
+
+func (r *Runtime) toTemporalPlainDate(v Value, reject bool) temporalDate {
+	if o, ok := v.(*Object); ok {
+		switch o := o.self.(type) {
+		case *temporalPlainDateObject:
+			return o.d
+		case *temporalZonedDateTimeObject:
+			return temporalDateOf(o.t)
+		}
+		return r.temporalDateFields(o, temporalDate{}, true, reject)
+	}
+	s := v.String()
+	f := r.parseTemporalString(s)
+	if f.utc {
+		panic(r.newError(r.global.RangeError, "Z designator is not supported for PlainDate: %s", s))
+	}
+	return r.checkTemporalDate(f.temporalDate)
+}
+
+// temporalDateFields reads year, month and day of o into d, they must be present if required.
+func (r *Runtime) temporalDateFields(o *Object, d temporalDate, required, reject bool) temporalDate {
+	fields := []struct {
+		name  string
+		field *int
+	}{{"day", &d.day}, {"month", &d.month}, {"year", &d.year}}
+	found := false
+	for _, f := range fields {
+		v := o.self.getStr(f.name)
+		if v == nil || v == _undefined {
+			if required {
+				panic(r.NewTypeError("Required property %s is missing or undefined", f.name))
+			}
+			continue
+		}
+		*f.field = int(r.temporalInteger(v, f.name))
+		found = true
+	}
+	if !found {
+		panic(r.NewTypeError("Invalid date fields"))
+	}
+	if d.month < 1 || d.day < 1 || reject && (d.month > 12 || d.day > temporalDaysInMonth(d.year, d.month)) {
+		panic(r.newError(r.global.RangeError, "Invalid date: %d-%d-%d", d.year, d.month, d.day))
+	}
+	if d.month > 12 {
+		d.month = 12
+	}
+	if dim := temporalDaysInMonth(d.year, d.month); d.day > dim {
+		d.day = dim
+	}
+	return r.checkTemporalDate(d)
+}
+
+func (r *Runtime) toTemporalZonedDateTime(v Value) time.Time {
+	if o, ok := v.(*Object); ok {
+		if z, ok := o.self.(*temporalZonedDateTimeObject); ok {
+			return z.t
+		}
+		tz := o.self.getStr("timeZone")
+		if tz == nil || tz == _undefined {
+			panic(r.NewTypeError("Required property timeZone is missing or undefined"))
+		}
+		d := r.temporalDateFields(o, temporalDate{}, true, false)
+		clock := [4]int{}
+		for i, name := range []string{"hour", "minute", "second", "nanosecond"} {
+			if v := o.self.getStr(name); v != nil && v != _undefined {
+				clock[i] = int(r.temporalInteger(v, name))
+			}
+		}
+		return r.checkTemporalInstant(temporalWallTime(d.year, d.month, d.day, clock[0], clock[1], clock[2], clock[3], r.temporalTimeZone(tz)))
+	}
+	s := v.String()
+	f := r.parseTemporalString(s)
+	if f.timeZone == "" {
+		panic(r.newError(r.global.RangeError, "ZonedDateTime requires a time zone annotation: %s", s))
+	}
+	loc := r.temporalTimeZone(newStringValue(f.timeZone))
+	switch {
+	case f.utc:
+		return r.checkTemporalInstant(f.wallTime(time.UTC).In(loc))
+	case f.hasOffset:
+		t := f.wallTime(time.UTC).Add(-time.Duration(f.offset) * time.Second).In(loc)
+		if _, offset := t.Zone(); offset != f.offset {
+			panic(r.newError(r.global.RangeError, "Offset %s is invalid for the time zone %s", formatTemporalOffset(f.offset), f.timeZone))
+		}
+		return r.checkTemporalInstant(t)
+	}
+	return r.checkTemporalInstant(f.wallTime(loc))
+}
+
+// epoch nanoseconds
+
+var temporalEpochNanosecondsRegexp = regexp.MustCompile(`^-?\d+$`)
+
+// temporalEpochNanoseconds converts the epoch nanoseconds argument of the constructors, which is a Number or a
+// decimal string as there is no BigInt.
+func (r *Runtime) temporalEpochNanoseconds(v Value) time.Time {
+	var sec, nsec int64
+	if _, ok := v.(valueString); ok {
+		s := v.String()
+		if !temporalEpochNanosecondsRegexp.MatchString(s) || len(s) > 24 {
+			panic(r.newError(r.global.RangeError, "Invalid epoch nanoseconds: %s", s))
+		}
+		neg := s[0] == '-'
+		s = strings.TrimPrefix(s, "-")
+		if len(s) > 9 {
+			sec, _ = strconv.ParseInt(s[:len(s)-9], 10, 64)
+			s = s[len(s)-9:]
+		}
+		nsec, _ = strconv.ParseInt(s, 10, 64)
+		if neg {
+			sec, nsec = -sec, -nsec
+		}
+	} else {
+		x := v.ToFloat()
+		if math.IsNaN(x) || math.IsInf(x, 0) || x != math.Trunc(x) || math.Abs(x) > temporalMaxEpochSeconds*1e9 {
+			panic(r.newError(r.global.RangeError, "Invalid epoch nanoseconds: %s", v.String()))
+		}
+		sec = int64(math.Floor(x / 1e9))
+		nsec = int64(x - float64(sec)*1e9)
+	}
+	return r.checkTemporalInstant(time.Unix(sec, nsec))
+}
+
+// Duration
+
+func (r *Runtime) builtin_TemporalDuration(args []Value, proto *Object) *Object {
+	var d temporalDuration
+	for i := range d {
+		if i < len(args) && args[i] != _undefined {
+			x := args[i].ToFloat()
+			if math.IsNaN(x) || math.IsInf(x, 0) || x != math.Trunc(x) {
+				panic(r.newError(r.global.RangeError, "Invalid value for %s: %s", temporalUnits[i], args[i].String()))
+			}
+			d[i] = x
+		}
+	}
+	return r.newTemporalDuration(d, proto)
+}
+
+func (r *Runtime) temporalDuration_from(call FunctionCall) Value {
+	return r.newTemporalDuration(r.toTemporalDuration(call.Argument(0)), nil)
+}
+
+func (r *Runtime) temporalDurationProto_with(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "with")
+	fields, ok := call.Argument(0).(*Object)
+	if !ok {
+		panic(r.NewTypeError("Invalid duration fields"))
+	}
+	return r.newTemporalDuration(r.temporalDurationFields(fields, d.d, true), nil)
+}
+
+func (r *Runtime) temporalDurationProto_negated(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "negated")
+	return r.newTemporalDuration(d.d.negated(), nil)
+}
+
+func (r *Runtime) temporalDurationProto_abs(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "abs")
+	res := d.d
+	if d.d.sign() < 0 {
+		res = d.d.negated()
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalDurationProto_toString(call FunctionCall) Value {
+	d := r.thisTemporalDuration(call.This, "toString")
+	return asciiString(d.d.String())
+}
+
+// Instant
+
+func (r *Runtime) builtin_TemporalInstant(args []Value, proto *Object) *Object {
+	var arg Value = _undefined
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	return r.newTemporalInstant(r.temporalEpochNanoseconds(arg), proto)
+}
+
+func (r *Runtime) temporalInstant_from(call FunctionCall) Value {
+	return r.newTemporalInstant(r.toTemporalInstant(call.Argument(0)), nil)
+}
+
+func (r *Runtime) temporalInstant_fromEpochMilliseconds(call FunctionCall) Value {
+	ms := call.Argument(0).ToFloat()
+	if math.IsNaN(ms) || math.IsInf(ms, 0) || ms != math.Trunc(ms) {
+		panic(r.newError(r.global.RangeError, "Invalid epoch milliseconds: %s", call.Argument(0).String()))
+	}
+	sec := math.Floor(ms / 1e3)
+	return r.newTemporalInstant(time.Unix(int64(sec), int64(ms-sec*1e3)*1e6), nil)
+}
+
+func (r *Runtime) temporalInstant_compare(call FunctionCall) Value {
+	return intToValue(int64(compareTemporalTime(r.toTemporalInstant(call.Argument(0)), r.toTemporalInstant(call.Argument(1)))))
+}
+
+func (r *Runtime) temporalInstantProto_add(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "add")
+	return r.newTemporalInstant(r.temporalAddExact(i.t, r.toTemporalDuration(call.Argument(0)), 1), nil)
+}
+
+func (r *Runtime) temporalInstantProto_subtract(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "subtract")
+	return r.newTemporalInstant(r.temporalAddExact(i.t, r.toTemporalDuration(call.Argument(0)), -1), nil)
+}
+
+// temporalAddExact adds a duration without the date units to the time.
+func (r *Runtime) temporalAddExact(t time.Time, d temporalDuration, sign int64) time.Time {
+	if d.hasDateUnits() {
+		panic(r.newError(r.global.RangeError, "Duration field years, months, weeks or days not supported by Temporal.Instant"))
+	}
+	sec, nsec := d.timeSeconds()
+	return r.checkTemporalInstant(temporalAddTime(t, sign*sec, sign*nsec))
+}
+
+func (r *Runtime) temporalInstantDifference(call FunctionCall, method string, sign int64) Value {
+	i := r.thisTemporalInstant(call.This, method)
+	other := r.toTemporalInstant(call.Argument(0))
+	largest := r.temporalUnitOption(r.temporalOptions(call.Argument(1)), "largestUnit", temporalHours, temporalNanoseconds, temporalSeconds)
+	sec, nsec := temporalTimeDiff(i.t, other)
+	return r.newTemporalDuration(balanceTemporalTime(sign*sec, sign*nsec, largest), nil)
+}
+
+func (r *Runtime) temporalInstantProto_until(call FunctionCall) Value {
+	return r.temporalInstantDifference(call, "until", 1)
+}
+
+func (r *Runtime) temporalInstantProto_since(call FunctionCall) Value {
+	return r.temporalInstantDifference(call, "since", -1)
+}
+
+func (r *Runtime) temporalInstantProto_equals(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "equals")
+	return r.toBoolean(i.t.Equal(r.toTemporalInstant(call.Argument(0))))
+}
+
+func (r *Runtime) temporalInstantProto_toString(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toString")
+	t := i.t
+	if options := r.temporalOptions(call.Argument(0)); options != nil {
+		if tz := options.self.getStr("timeZone"); tz != nil && tz != _undefined {
+			t = t.In(r.temporalTimeZone(tz))
+			_, offset := t.Zone()
+			return asciiString(temporalDateOf(t).String() + "T" + formatTemporalTime(t) + formatTemporalOffset(offset))
+		}
+	}
+	return asciiString(temporalDateOf(t).String() + "T" + formatTemporalTime(t) + "Z")
+}
+
+func (r *Runtime) temporalInstantProto_toJSON(call FunctionCall) Value {
+	r.thisTemporalInstant(call.This, "toJSON")
+	return r.temporalInstantProto_toString(FunctionCall{This: call.This})
+}
+
+func (r *Runtime) temporalInstantProto_toLocaleString(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toLocaleString")
+	return r.localeFormatDate(i.t, call.Argument(0), call.Argument(1), "any", "all")
+}
+
+func (r *Runtime) temporalInstantProto_toZonedDateTimeISO(call FunctionCall) Value {
+	i := r.thisTemporalInstant(call.This, "toZonedDateTimeISO")
+	return r.newTemporalZonedDateTime(i.t.In(r.temporalTimeZone(call.Argument(0))), nil)
+}
+
+// PlainDate
+
+func (r *Runtime) builtin_TemporalPlainDate(args []Value, proto *Object) *Object {
+	var fields [3]int
+	for i, name := range []string{"isoYear", "isoMonth", "isoDay"} {
+		var v Value = _undefined
+		if i < len(args) {
+			v = args[i]
+		}
+		fields[i] = int(r.temporalInteger(v, name))
+	}
+	d := temporalDate{fields[0], fields[1], fields[2]}
+	if d.month < 1 || d.month > 12 || d.day < 1 || d.day > temporalDaysInMonth(d.year, d.month) {
+		panic(r.newError(r.global.RangeError, "Invalid date: %d-%d-%d", d.year, d.month, d.day))
+	}
+	return r.newTemporalPlainDate(d, proto)
+}
+
+func (r *Runtime) temporalPlainDate_from(call FunctionCall) Value {
+	return r.newTemporalPlainDate(r.toTemporalPlainDate(call.Argument(0), r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+func (r *Runtime) temporalPlainDate_compare(call FunctionCall) Value {
+	return intToValue(int64(compareTemporalDate(r.toTemporalPlainDate(call.Argument(0), false), r.toTemporalPlainDate(call.Argument(1), false))))
+}
+
+// temporalAddDate adds the duration to the date: the years and months first, then the weeks and the days (the
+// time units are balanced into the days).
+func (r *Runtime) temporalAddDate(d temporalDate, dur temporalDuration, reject bool) temporalDate {
+	d, ok := d.addMonths(int(dur[temporalYears])*12+int(dur[temporalMonths]), reject)
+	if !ok {
+		panic(r.newError(r.global.RangeError, "Day %d is out of range for the resulting month", d.day))
+	}
+	r.checkTemporalDate(d)
+	sec, _ := dur.timeSeconds()
+	return r.checkTemporalDate(d.addDays(int64(dur[temporalWeeks])*7 + int64(dur[temporalDays]) + sec/86400))
+}
+
+func (r *Runtime) temporalPlainDateProto_add(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "add")
+	dur := r.toTemporalDuration(call.Argument(0))
+	return r.newTemporalPlainDate(r.temporalAddDate(d.d, dur, r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_subtract(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "subtract")
+	dur := r.toTemporalDuration(call.Argument(0))
+	return r.newTemporalPlainDate(r.temporalAddDate(d.d, dur.negated(), r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_with(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "with")
+	fields, ok := call.Argument(0).(*Object)
+	if !ok {
+		panic(r.NewTypeError("Invalid date fields"))
+	}
+	return r.newTemporalPlainDate(r.temporalDateFields(fields, d.d, false, r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+// temporalDateDifference returns the duration from a to b with the largest unit being days or larger.
+func temporalDateDifference(a, b temporalDate, largest int) temporalDuration {
+	var d temporalDuration
+	if largest <= temporalMonths {
+		sign := compareTemporalDate(b, a)
+		months := (b.year-a.year)*12 + b.month - a.month
+		mid, _ := a.addMonths(months, false)
+		if c := compareTemporalDate(mid, b); sign > 0 && c > 0 || sign < 0 && c < 0 {
+			months -= sign
+			mid, _ = a.addMonths(months, false)
+		}
+		d[temporalMonths] = float64(months)
+		if largest == temporalYears {
+			d[temporalYears], d[temporalMonths] = float64(months/12), float64(months%12)
+		}
+		a = mid
+	}
+	days := b.epochDays() - a.epochDays()
+	if largest == temporalWeeks {
+		d[temporalWeeks], days = float64(days/7), days%7
+	}
+	d[temporalDays] = float64(days)
+	return d
+}
+
+func (r *Runtime) temporalPlainDateDifference(call FunctionCall, method string, sign int) Value {
+	d := r.thisTemporalPlainDate(call.This, method)
+	other := r.toTemporalPlainDate(call.Argument(0), false)
+	largest := r.temporalUnitOption(r.temporalOptions(call.Argument(1)), "largestUnit", temporalYears, temporalDays, temporalDays)
+	var res temporalDuration
+	if sign > 0 {
+		res = temporalDateDifference(d.d, other, largest)
+	} else {
+		res = temporalDateDifference(other, d.d, largest)
+	}
+	return r.newTemporalDuration(res, nil)
+}
+
+func (r *Runtime) temporalPlainDateProto_until(call FunctionCall) Value {
+	return r.temporalPlainDateDifference(call, "until", 1)
+}
+
+func (r *Runtime) temporalPlainDateProto_since(call FunctionCall) Value {
+	return r.temporalPlainDateDifference(call, "since", -1)
+}
+
+func (r *Runtime) temporalPlainDateProto_equals(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "equals")
+	return r.toBoolean(d.d == r.toTemporalPlainDate(call.Argument(0), false))
+}
+
+func (r *Runtime) temporalPlainDateProto_toString(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toString")
+	return asciiString(d.d.String())
+}
+
+func (r *Runtime) temporalPlainDateProto_toLocaleString(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toLocaleString")
+	f := r.resolveDateTimeFormat(call.Argument(0), call.Argument(1), "date", "date")
+	f.loc = time.UTC
+	return newStringValue(f.format(d.d.time(time.UTC)))
+}
+
+func (r *Runtime) temporalPlainDateProto_toZonedDateTime(call FunctionCall) Value {
+	d := r.thisTemporalPlainDate(call.This, "toZonedDateTime")
+	tz := call.Argument(0)
+	if o, ok := tz.(*Object); ok {
+		if _, ok := o.self.(*temporalZonedDateTimeObject); !ok {
+			tz = o.self.getStr("timeZone")
+			if tz == nil {
+				tz = _undefined
+			}
+		}
+	}
+	return r.newTemporalZonedDateTime(d.d.time(r.temporalTimeZone(tz)), nil)
+}
+
+// ZonedDateTime
+
+func (r *Runtime) builtin_TemporalZonedDateTime(args []Value, proto *Object) *Object {
+	var ns, tz Value = _undefined, _undefined
+	if len(args) > 0 {
+		ns = args[0]
+	}
+	if len(args) > 1 {
+		tz = args[1]
+	}
+	t := r.temporalEpochNanoseconds(ns)
+	return r.newTemporalZonedDateTime(t.In(r.temporalTimeZone(tz)), proto)
+}
+
+func (r *Runtime) temporalZonedDateTime_from(call FunctionCall) Value {
+	return r.newTemporalZonedDateTime(r.toTemporalZonedDateTime(call.Argument(0)), nil)
+}
+
+func (r *Runtime) temporalZonedDateTime_compare(call FunctionCall) Value {
+	return intToValue(int64(compareTemporalTime(r.toTemporalZonedDateTime(call.Argument(0)), r.toTemporalZonedDateTime(call.Argument(1)))))
+}
+
+// temporalAddZoned adds the date units of the duration in the wall-clock time and the time units in the exact time.
+func (r *Runtime) temporalAddZoned(t time.Time, dur temporalDuration, reject bool) time.Time {
+	if dur.hasDateUnits() {
+		var dateDur temporalDuration
+		copy(dateDur[:temporalHours], dur[:temporalHours])
+		d := r.temporalAddDate(temporalDateOf(t), dateDur, reject)
+		t = temporalWallTime(d.year, d.month, d.day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	sec, nsec := dur.timeSeconds()
+	return r.checkTemporalInstant(temporalAddTime(t, sec, nsec))
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_add(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "add")
+	dur := r.toTemporalDuration(call.Argument(0))
+	return r.newTemporalZonedDateTime(r.temporalAddZoned(z.t, dur, r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_subtract(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "subtract")
+	dur := r.toTemporalDuration(call.Argument(0))
+	return r.newTemporalZonedDateTime(r.temporalAddZoned(z.t, dur.negated(), r.temporalOverflowReject(call.Argument(1))), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeDifference(call FunctionCall, method string, sign int64) Value {
+	z := r.thisTemporalZonedDateTime(call.This, method)
+	other := r.toTemporalZonedDateTime(call.Argument(0))
+	largest := r.temporalUnitOption(r.temporalOptions(call.Argument(1)), "largestUnit", temporalHours, temporalNanoseconds, temporalHours)
+	sec, nsec := temporalTimeDiff(z.t, other)
+	return r.newTemporalDuration(balanceTemporalTime(sign*sec, sign*nsec, largest), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_until(call FunctionCall) Value {
+	return r.temporalZonedDateTimeDifference(call, "until", 1)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_since(call FunctionCall) Value {
+	return r.temporalZonedDateTimeDifference(call, "since", -1)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_equals(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "equals")
+	other := r.toTemporalZonedDateTime(call.Argument(0))
+	return r.toBoolean(z.t.Equal(other) && z.t.Location().String() == other.Location().String())
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_withTimeZone(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "withTimeZone")
+	return r.newTemporalZonedDateTime(z.t.In(r.temporalTimeZone(call.Argument(0))), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_startOfDay(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "startOfDay")
+	return r.newTemporalZonedDateTime(temporalDateOf(z.t).time(z.t.Location()), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toInstant(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toInstant")
+	return r.newTemporalInstant(z.t, nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toPlainDate(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toPlainDate")
+	return r.newTemporalPlainDate(temporalDateOf(z.t), nil)
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toString(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toString")
+	_, offset := z.t.Zone()
+	return newStringValue(temporalDateOf(z.t).String() + "T" + formatTemporalTime(z.t) + formatTemporalOffset(offset) +
+		"[" + z.t.Location().String() + "]")
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toJSON(call FunctionCall) Value {
+	r.thisTemporalZonedDateTime(call.This, "toJSON")
+	return r.temporalZonedDateTimeProto_toString(FunctionCall{This: call.This})
+}
+
+func (r *Runtime) temporalZonedDateTimeProto_toLocaleString(call FunctionCall) Value {
+	z := r.thisTemporalZonedDateTime(call.This, "toLocaleString")
+	f := r.resolveDateTimeFormat(call.Argument(0), call.Argument(1), "any", "all")
+	if options, ok := call.Argument(1).(*Object); !ok || options.self.getStr("timeZone") == nil || options.self.getStr("timeZone") == _undefined {
+		f.loc = z.t.Location()
+	}
+	return newStringValue(f.format(z.t))
+}
+
+// Now
+
+func (r *Runtime) temporalNow_instant(FunctionCall) Value {
+	return r.newTemporalInstant(time.Now(), nil)
+}
+
+func (r *Runtime) temporalNow_timeZoneId(FunctionCall) Value {
+	return newStringValue(r.temporalDefaultTimeZone().String())
+}
+
+func (r *Runtime) temporalNowTimeZone(v Value) *time.Location {
+	if v == _undefined {
+		return r.temporalDefaultTimeZone()
+	}
+	return r.temporalTimeZone(v)
+}
+
+func (r *Runtime) temporalNow_zonedDateTimeISO(call FunctionCall) Value {
+	return r.newTemporalZonedDateTime(time.Now().In(r.temporalNowTimeZone(call.Argument(0))), nil)
+}
+
+func (r *Runtime) temporalNow_plainDateISO(call FunctionCall) Value {
+	return r.newTemporalPlainDate(temporalDateOf(time.Now().In(r.temporalNowTimeZone(call.Argument(0)))), nil)
+}
+
+// initialisation
+
+type temporalGetter struct {
+	name string
+	f    func(call FunctionCall) Value
+}
+
+type temporalMethod struct {
+	name   string
+	f      func(call FunctionCall) Value
+	length int
+}
+
+func (r *Runtime) newTemporalProto(getters []temporalGetter, methods []temporalMethod) *baseObject {
+	proto := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	for _, g := range getters {
+		proto._put(g.name, &valueProperty{
+			accessor:     true,
+			configurable: true,
+			getterFunc:   r.newNativeFunc(g.f, nil, "get "+g.name, nil, 0),
+		})
+	}
+	for _, m := range methods {
+		proto._putProp(m.name, r.newNativeFunc(m.f, nil, m.name, nil, m.length), true, false, true)
+	}
+	proto._putProp("valueOf", r.newNativeFunc(r.temporalValueOf, nil, "valueOf", nil, 0), true, false, true)
+	return proto
+}
+
+func (r *Runtime) newTemporalConstructor(construct func(args []Value, proto *Object) *Object, name string, proto *baseObject, length int, statics []temporalMethod) *Object {
+	c := r.newNativeFuncConstruct(construct, name, proto.val, length)
+	for _, m := range statics {
+		c.self._putProp(m.name, r.newNativeFunc(m.f, nil, m.name, nil, m.length), true, false, true)
+	}
+	return c
+}
+
+func (r *Runtime) temporalDurationGetters() []temporalGetter {
+	var getters []temporalGetter
+	for u, name := range temporalUnits {
+		u := u
+		getters = append(getters, temporalGetter{name, func(call FunctionCall) Value {
+			return floatToValue(r.thisTemporalDuration(call.This, name).d[u])
+		}})
+	}
+	return append(getters,
+		temporalGetter{"sign", func(call FunctionCall) Value {
+			return intToValue(int64(r.thisTemporalDuration(call.This, "sign").d.sign()))
+		}},
+		temporalGetter{"blank", func(call FunctionCall) Value {
+			return r.toBoolean(r.thisTemporalDuration(call.This, "blank").d.sign() == 0)
+		}},
+	)
+}
+
+// temporalCalendarGetters returns the getters of the date fields of the objects for which date() returns the date.
+func (r *Runtime) temporalCalendarGetters(date func(v Value, method string) temporalDate) []temporalGetter {
+	field := func(name string, f func(d temporalDate) Value) temporalGetter {
+		return temporalGetter{name, func(call FunctionCall) Value {
+			return f(date(call.This, name))
+		}}
+	}
+	return []temporalGetter{
+		field("calendarId", func(temporalDate) Value { return asciiString("iso8601") }),
+		field("year", func(d temporalDate) Value { return intToValue(int64(d.year)) }),
+		field("month", func(d temporalDate) Value { return intToValue(int64(d.month)) }),
+		field("monthCode", func(d temporalDate) Value { return asciiString(fmt.Sprintf("M%02d", d.month)) }),
+		field("day", func(d temporalDate) Value { return intToValue(int64(d.day)) }),
+		field("dayOfWeek", func(d temporalDate) Value {
+			wd := int64(d.time(time.UTC).Weekday())
+			if wd == 0 {
+				wd = 7
+			}
+			return intToValue(wd)
+		}),
+		field("dayOfYear", func(d temporalDate) Value { return intToValue(int64(d.time(time.UTC).YearDay())) }),
+		field("weekOfYear", func(d temporalDate) Value {
+			_, w := d.time(time.UTC).ISOWeek()
+			return intToValue(int64(w))
+		}),
+		field("daysInWeek", func(temporalDate) Value { return intToValue(7) }),
+		field("daysInMonth", func(d temporalDate) Value { return intToValue(int64(temporalDaysInMonth(d.year, d.month))) }),
+		field("daysInYear", func(d temporalDate) Value {
+			if temporalIsLeapYear(d.year) {
+				return intToValue(366)
+			}
+			return intToValue(365)
+		}),
+		field("monthsInYear", func(temporalDate) Value { return intToValue(12) }),
+		field("inLeapYear", func(d temporalDate) Value { return r.toBoolean(temporalIsLeapYear(d.year)) }),
+	}
+}
+
+func (r *Runtime) temporalZonedDateTimeGetters() []temporalGetter {
+	getters := r.temporalCalendarGetters(func(v Value, method string) temporalDate {
+		return temporalDateOf(r.thisTemporalZonedDateTime(v, method).t)
+	})
+	field := func(name string, f func(t time.Time) Value) temporalGetter {
+		return temporalGetter{name, func(call FunctionCall) Value {
+			return f(r.thisTemporalZonedDateTime(call.This, name).t)
+		}}
+	}
+	return append(getters,
+		field("hour", func(t time.Time) Value { return intToValue(int64(t.Hour())) }),
+		field("minute", func(t time.Time) Value { return intToValue(int64(t.Minute())) }),
+		field("second", func(t time.Time) Value { return intToValue(int64(t.Second())) }),
+		field("millisecond", func(t time.Time) Value { return intToValue(int64(t.Nanosecond() / 1e6)) }),
+		field("microsecond", func(t time.Time) Value { return intToValue(int64(t.Nanosecond() / 1e3 % 1e3)) }),
+		field("nanosecond", func(t time.Time) Value { return intToValue(int64(t.Nanosecond() % 1e3)) }),
+		field("epochMilliseconds", func(t time.Time) Value { return intToValue(t.Unix()*1e3 + int64(t.Nanosecond()/1e6)) }),
+		field("timeZoneId", func(t time.Time) Value { return newStringValue(t.Location().String()) }),
+		field("offset", func(t time.Time) Value {
+			_, offset := t.Zone()
+			return asciiString(formatTemporalOffset(offset))
+		}),
+		field("offsetNanoseconds", func(t time.Time) Value {
+			_, offset := t.Zone()
+			return intToValue(int64(offset) * 1e9)
+		}),
+		field("hoursInDay", func(t time.Time) Value {
+			d := temporalDateOf(t)
+			start, end := d.time(t.Location()), d.addDays(1).time(t.Location())
+			sec, _ := temporalTimeDiff(start, end)
+			return floatToValue(float64(sec) / 3600)
+		}),
+	)
+}
+
+func (r *Runtime) createTemporal(val *Object) objectImpl {
+	o := &baseObject{
+		class:      classObject,
+		val:        val,
+		extensible: true,
+		prototype:  r.global.ObjectPrototype,
+	}
+	o.init()
+	r.temporal = &temporalPrototypes{}
+
+	proto := r.newTemporalProto(r.temporalDurationGetters(), []temporalMethod{
+		{"with", r.temporalDurationProto_with, 1},
+		{"negated", r.temporalDurationProto_negated, 0},
+		{"abs", r.temporalDurationProto_abs, 0},
+		{"toString", r.temporalDurationProto_toString, 0},
+		{"toJSON", r.temporalDurationProto_toString, 0},
+	})
+	r.temporal.duration = proto.val
+	o._putProp("Duration", r.newTemporalConstructor(r.builtin_TemporalDuration, "Duration", proto, 0, []temporalMethod{
+		{"from", r.temporalDuration_from, 1},
+	}), true, false, true)
+
+	proto = r.newTemporalProto([]temporalGetter{
+		{"epochMilliseconds", func(call FunctionCall) Value {
+			t := r.thisTemporalInstant(call.This, "epochMilliseconds").t
+			return intToValue(t.Unix()*1e3 + int64(t.Nanosecond()/1e6))
+		}},
+	}, []temporalMethod{
+		{"add", r.temporalInstantProto_add, 1},
+		{"subtract", r.temporalInstantProto_subtract, 1},
+		{"until", r.temporalInstantProto_until, 1},
+		{"since", r.temporalInstantProto_since, 1},
+		{"equals", r.temporalInstantProto_equals, 1},
+		{"toString", r.temporalInstantProto_toString, 0},
+		{"toJSON", r.temporalInstantProto_toJSON, 0},
+		{"toLocaleString", r.temporalInstantProto_toLocaleString, 0},
+		{"toZonedDateTimeISO", r.temporalInstantProto_toZonedDateTimeISO, 1},
+	})
+	r.temporal.instant = proto.val
+	o._putProp("Instant", r.newTemporalConstructor(r.builtin_TemporalInstant, "Instant", proto, 1, []temporalMethod{
+		{"from", r.temporalInstant_from, 1},
+		{"fromEpochMilliseconds", r.temporalInstant_fromEpochMilliseconds, 1},
+		{"compare", r.temporalInstant_compare, 2},
+	}), true, false, true)
+
+	proto = r.newTemporalProto(r.temporalCalendarGetters(func(v Value, method string) temporalDate {
+		return r.thisTemporalPlainDate(v, method).d
+	}), []temporalMethod{
+		{"add", r.temporalPlainDateProto_add, 1},
+		{"subtract", r.temporalPlainDateProto_subtract, 1},
+		{"with", r.temporalPlainDateProto_with, 1},
+		{"until", r.temporalPlainDateProto_until, 1},
+		{"since", r.temporalPlainDateProto_since, 1},
+		{"equals", r.temporalPlainDateProto_equals, 1},
+		{"toString", r.temporalPlainDateProto_toString, 0},
+		{"toJSON", r.temporalPlainDateProto_toString, 0},
+		{"toLocaleString", r.temporalPlainDateProto_toLocaleString, 0},
+		{"toZonedDateTime", r.temporalPlainDateProto_toZonedDateTime, 1},
+	})
+	r.temporal.plainDate = proto.val
+	o._putProp("PlainDate", r.newTemporalConstructor(r.builtin_TemporalPlainDate, "PlainDate", proto, 3, []temporalMethod{
+		{"from", r.temporalPlainDate_from, 1},
+		{"compare", r.temporalPlainDate_compare, 2},
+	}), true, false, true)
+
+	proto = r.newTemporalProto(r.temporalZonedDateTimeGetters(), []temporalMethod{
+		{"add", r.temporalZonedDateTimeProto_add, 1},
+		{"subtract", r.temporalZonedDateTimeProto_subtract, 1},
+		{"until", r.temporalZonedDateTimeProto_until, 1},
+		{"since", r.temporalZonedDateTimeProto_since, 1},
+		{"equals", r.temporalZonedDateTimeProto_equals, 1},
+		{"withTimeZone", r.temporalZonedDateTimeProto_withTimeZone, 1},
+		{"startOfDay", r.temporalZonedDateTimeProto_startOfDay, 0},
+		{"toInstant", r.temporalZonedDateTimeProto_toInstant, 0},
+		{"toPlainDate", r.temporalZonedDateTimeProto_toPlainDate, 0},
+		{"toString", r.temporalZonedDateTimeProto_toString, 0},
+		{"toJSON", r.temporalZonedDateTimeProto_toJSON, 0},
+		{"toLocaleString", r.temporalZonedDateTimeProto_toLocaleString, 0},
+	})
+	r.temporal.zonedDateTime = proto.val
+	o._putProp("ZonedDateTime", r.newTemporalConstructor(r.builtin_TemporalZonedDateTime, "ZonedDateTime", proto, 2, []temporalMethod{
+		{"from", r.temporalZonedDateTime_from, 1},
+		{"compare", r.temporalZonedDateTime_compare, 2},
+	}), true, false, true)
+
+	now := r.newBaseObject(r.global.ObjectPrototype, classObject)
+	now._putProp("instant", r.newNativeFunc(r.temporalNow_instant, nil, "instant", nil, 0), true, false, true)
+	now._putProp("timeZoneId", r.newNativeFunc(r.temporalNow_timeZoneId, nil, "timeZoneId", nil, 0), true, false, true)
+	now._putProp("zonedDateTimeISO", r.newNativeFunc(r.temporalNow_zonedDateTimeISO, nil, "zonedDateTimeISO", nil, 0), true, false, true)
+	now._putProp("plainDateISO", r.newNativeFunc(r.temporalNow_plainDateISO, nil, "plainDateISO", nil, 0), true, false, true)
+	o._putProp("Now", now.val, true, false, true)
+
+	return o
+}
+
+// SetTemporal enables (or disables) the global Temporal object, a subset of the Temporal proposal: Temporal.Now,
+// Duration, Instant, PlainDate and ZonedDateTime with the ISO 8601 calendar. The time zones are the IANA time
+// zones known to the time package, "UTC" and fixed offsets such as "+01:00". As there is no BigInt, the epoch
+// nanoseconds are passed to the Instant and ZonedDateTime constructors as a Number or a decimal string, and the
+// epochNanoseconds properties are not available. Disabling deletes the property, so it should be done before
+// running any scripts.
+func (r *Runtime) SetTemporal(enabled bool) {
+	if enabled {
+		r.addToGlobal("Temporal", r.newLazyObject(r.createTemporal))
+	} else {
+		r.globalObject.self.deleteStr("Temporal", false)
+	}
+}
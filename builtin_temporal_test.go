@@ -0,0 +1,81 @@
+package goja
+
+import "testing"
+
+func TestTemporal(t *testing.T) {
+	const SCRIPT = `
+	var d = Temporal.Duration.from("P1Y2M3DT4H5M6.007S");
+	assert.sameValue(d.years, 1, "years");
+	assert.sameValue(d.milliseconds, 7, "milliseconds");
+	assert.sameValue(d.toString(), "P1Y2M3DT4H5M6.007S", "duration toString");
+	assert.sameValue(d.negated().toString(), "-P1Y2M3DT4H5M6.007S", "negated");
+	assert.sameValue(new Temporal.Duration().toString(), "PT0S", "zero");
+	assert.sameValue(Temporal.Duration.from({hours: 1, minutes: 30}).toString(), "PT1H30M", "from fields");
+	assert.sameValue(d.with({years: 0}).sign, 1, "with");
+
+	var i = Temporal.Instant.from("2020-02-29T12:00:00.5+01:00");
+	assert.sameValue(i.toString(), "2020-02-29T11:00:00.5Z", "instant");
+	assert.sameValue(i.epochMilliseconds, 1582974000500, "epochMilliseconds");
+	assert.sameValue(new Temporal.Instant("1582974000500000001").toString(), "2020-02-29T11:00:00.500000001Z", "string nanoseconds");
+	assert.sameValue(i.add({hours: 13}).toString(), "2020-03-01T00:00:00.5Z", "instant add");
+	assert.sameValue(i.until("2020-02-29T13:00:00Z").toString(), "PT7199.5S", "instant until");
+	assert.sameValue(i.until("2020-02-29T13:00:00Z", {largestUnit: "hour"}).toString(), "PT1H59M59.5S", "largestUnit");
+	assert.sameValue(Temporal.Instant.compare(i, Temporal.Instant.fromEpochMilliseconds(0)), 1, "compare");
+	assert.sameValue(i.toString({timeZone: "+05:30"}), "2020-02-29T16:30:00.5+05:30", "toString timeZone");
+
+	var pd = Temporal.PlainDate.from("2020-01-31");
+	assert.sameValue(pd.add({months: 1}).toString(), "2020-02-29", "constrain");
+	assert.sameValue(pd.dayOfWeek, 5, "dayOfWeek");
+	assert.sameValue(pd.daysInYear, 366, "daysInYear");
+	assert.sameValue(pd.monthCode, "M01", "monthCode");
+	assert.sameValue(pd.with({day: 1}).toString(), "2020-01-01", "with");
+	assert.sameValue(pd.until("2021-03-01").toString(), "P395D", "until days");
+	assert.sameValue(pd.until("2021-03-01", {largestUnit: "years"}).toString(), "P1Y1M1D", "until years");
+	assert.sameValue(new Temporal.PlainDate(2020, 1, 31).equals(pd), true, "equals");
+	assert.sameValue(Temporal.PlainDate.from({year: 2021, month: 2, day: 30}).toString(), "2021-02-28", "from fields");
+
+	var z = Temporal.ZonedDateTime.from("2020-03-29T00:30:00+00:00[Europe/London]");
+	assert.sameValue(z.hoursInDay, 23, "hoursInDay");
+	assert.sameValue(z.add({hours: 1}).toString(), "2020-03-29T02:30:00+01:00[Europe/London]", "exact time");
+	assert.sameValue(z.add({days: 1}).toString(), "2020-03-30T00:30:00+01:00[Europe/London]", "wall-clock time");
+	assert.sameValue(z.toInstant().toString(), "2020-03-29T00:30:00Z", "toInstant");
+	assert.sameValue(z.withTimeZone("Asia/Tokyo").hour, 9, "withTimeZone");
+	assert.sameValue(z.toPlainDate().toString(), "2020-03-29", "toPlainDate");
+	assert.sameValue(pd.toZonedDateTime("UTC").toString(), "2020-01-31T00:00:00+00:00[UTC]", "toZonedDateTime");
+	assert.sameValue(Temporal.ZonedDateTime.from("2024-03-10T02:30[America/New_York]").toString(), "2024-03-10T03:30:00-04:00[America/New_York]", "gap");
+	assert.sameValue(Temporal.ZonedDateTime.from("2024-11-03T01:30[America/New_York]").toString(), "2024-11-03T01:30:00-04:00[America/New_York]", "repeated time");
+	assert.sameValue(Temporal.ZonedDateTime.from("2024-03-09T02:30[America/New_York]").add({days: 1}).hour, 3, "add into gap");
+	assert.sameValue(new Temporal.Instant("8640000000000000000000").toString(), "+275760-09-13T00:00:00Z", "max instant");
+
+	assert.sameValue(typeof Temporal.Now.instant().epochMilliseconds, "number", "Now");
+
+	function throws(f, type) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof type;
+		}
+		return false;
+	}
+	assert(throws(function() { i < i; }, TypeError), "valueOf");
+	assert(throws(function() { i.add({days: 1}); }, RangeError), "instant days");
+	assert(throws(function() { Temporal.Instant.from("2020-01-01T00:00:00"); }, RangeError), "no offset");
+	assert(throws(function() { Temporal.PlainDate.from("2021-02-30"); }, RangeError), "invalid date");
+	assert(throws(function() { pd.add({months: 1}, {overflow: "reject"}); }, RangeError), "reject");
+	assert(throws(function() { new Temporal.Duration(1, -1); }, RangeError), "mixed signs");
+	assert(throws(function() { Temporal.ZonedDateTime.from("2020-01-01T00:00:00+05:00[Europe/London]"); }, RangeError), "offset mismatch");
+	assert(throws(function() { Temporal.PlainDate.prototype.toString.call(i); }, TypeError), "brand check");
+	assert(throws(function() { new Temporal.Instant("8640000000000000000001"); }, RangeError), "after max instant");
+	assert(throws(function() { new Temporal.Instant("-8640000000000000000001"); }, RangeError), "before min instant");
+	`
+
+	r := New()
+	r.SetTemporal(true)
+	if _, err := r.RunString(TESTLIB + SCRIPT); err != nil {
+		t.Fatal(err)
+	}
+	r.SetTemporal(false)
+	if v, err := r.RunString("typeof Temporal"); err != nil || v.String() != "undefined" {
+		t.Fatal(v, err)
+	}
+}
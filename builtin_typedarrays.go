@@ -29,7 +29,9 @@ func (r *Runtime) _newArrayBuffer(proto *Object, o *Object) *objectArrayBuffer {
 func (r *Runtime) builtin_ArrayBuffer(args []Value, proto *Object) *Object {
 	b := r._newArrayBuffer(proto, nil)
 	if len(args) > 0 {
-		b.data = make([]byte, toLength(args[0]))
+		l := toLength(args[0])
+		r.reserveMemory(l)
+		b.data = make([]byte, l)
 	}
 	return b.val
 }
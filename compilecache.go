@@ -0,0 +1,99 @@
+package goja
+
+import (
+	"container/list"
+)
+
+const defaultCompileCacheSize = 64
+
+type compileCacheKey struct {
+	src    string
+	strict bool
+	origin *ScriptOrigin
+
+	inFunction bool
+}
+
+type lruCacheEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// lruCache is a bounded cache which evicts the least recently used entries first. It's used for the programs
+// compiled by eval() and the Function constructor and for the patterns compiled by a RegexpEngine.
+type lruCache struct {
+	size    int
+	order   *list.List
+	entries map[interface{}]*list.Element
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[interface{}]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key interface{}) interface{} {
+	if e := c.entries[key]; e != nil {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruCacheEntry).value
+	}
+	return nil
+}
+
+func (c *lruCache) put(key, value interface{}) {
+	if c.size <= 0 {
+		return
+	}
+	if e := c.entries[key]; e != nil {
+		e.Value.(*lruCacheEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruCacheEntry{key: key, value: value})
+	c.resize(c.size)
+}
+
+func (c *lruCache) resize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	c.size = size
+	for c.order.Len() > size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*lruCacheEntry).key)
+	}
+}
+
+// SetCompileCacheSize sets the maximum number of programs compiled by eval() and the Function constructor that
+// are kept for reuse. If the same source is evaluated again, the cached program is run without being parsed and
+// compiled. The least recently used programs are evicted first. The default size is 64, 0 (or a negative
+// size) disables the cache.
+func (r *Runtime) SetCompileCacheSize(size int) {
+	r.compileCache.resize(size)
+}
+
+// compileDynamic compiles code created at run time by eval() or the Function constructor, reusing a previously
+// compiled program for the same source if it's still in the cache.
+// The program inherits the origin of the calling code. inFunction is set for a direct eval() called from function
+// code, where new.target is allowed.
+func (r *Runtime) compileDynamic(kind, src string, strict, inFunction bool) *Program {
+	var origin *ScriptOrigin
+	if r.vm.prg != nil {
+		origin = r.vm.prg.src.origin
+	}
+	key := compileCacheKey{src: src, strict: strict, origin: origin, inFunction: inFunction}
+	if p, ok := r.compileCache.get(key).(*Program); ok {
+		return p
+	}
+	p, err := r.compile(r.dynamicCodeName(kind, src), src, strict, true, inFunction)
+	if err != nil {
+		panic(err)
+	}
+	p.src.origin = origin
+	r.compileCache.put(key, p)
+	return p
+}
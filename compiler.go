@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/dop251/goja/ast"
 	"github.com/dop251/goja/file"
+	"github.com/dop251/goja/parser"
 	"strconv"
 )
 
@@ -39,6 +40,7 @@ type Program struct {
 	values []Value
 
 	funcName string
+	funcCode bool // the code of a function or of a direct eval() called from one
 	src      *SrcFile
 	srcMap   []srcMapItem
 }
@@ -52,6 +54,8 @@ type compiler struct {
 	enumGetExpr compiledEnumGetExpr
 
 	evalVM *vm
+
+	analysis *analyzer // records the bindings and their use when the compiler runs for Analyze()
 }
 
 type scope struct {
@@ -67,6 +71,8 @@ type scope struct {
 
 	namesMap    map[string]string
 	lastFreeTmp int
+
+	analysis *analysisScope
 }
 
 type block struct {
@@ -94,6 +100,9 @@ func (c *compiler) leaveBlock() {
 
 func (e *CompilerSyntaxError) Error() string {
 	if e.File != nil {
+		if name, pos, ok := e.File.mappedPosition(e.Offset); ok {
+			return fmt.Sprintf("SyntaxError: %s at %s:%s", e.Message, name, pos)
+		}
 		return fmt.Sprintf("SyntaxError: %s at %s", e.Message, e.File.Position(e.Offset))
 	}
 	return fmt.Sprintf("SyntaxError: %s", e.Message)
@@ -114,12 +123,50 @@ func (c *compiler) newScope() {
 		strict:   strict,
 		namesMap: make(map[string]string),
 	}
+	if c.analysis != nil {
+		c.scope.analysis = newAnalysisScope()
+	}
 }
 
 func (c *compiler) popScope() {
+	if c.analysis != nil {
+		c.analysis.leaveScope(c.scope)
+	}
 	c.scope = c.scope.outer
 }
 
+// declareBinding records a declaration in the scope the name is bound in (see bindName()) for Analyze().
+func (c *compiler) declareBinding(b analysisBinding) {
+	if c.analysis != nil {
+		nearestNonLexical(c.scope).analysis.declare(b)
+	}
+}
+
+func parse(name, src string) (prg *ast.Program, err error) {
+	prg, err1 := parser.ParseFile(nil, name, src, 0)
+	if err1 != nil {
+		switch err1 := err1.(type) {
+		case parser.ErrorList:
+			if len(err1) > 0 && err1[0].Message == "Invalid left-hand side in assignment" {
+				err = &CompilerReferenceError{
+					CompilerError: CompilerError{
+						Message: err1.Error(),
+					},
+				}
+				return
+			}
+		}
+		// FIXME offset
+		err = &CompilerSyntaxError{
+			CompilerError: CompilerError{
+				Message: err1.Error(),
+			},
+		}
+		return
+	}
+	return
+}
+
 func newCompiler() *compiler {
 	c := &compiler{
 		p: &Program{},
@@ -167,6 +214,9 @@ func (p *Program) sourceOffset(pc int) int {
 			return 0
 		}
 	}
+	if len(p.srcMap) == 0 {
+		return 0
+	}
 	return p.srcMap[len(p.srcMap)-1].srcPos
 }
 
@@ -177,7 +227,9 @@ func (s *scope) isFunction() bool {
 	return s.outer.isFunction()
 }
 
-func (s *scope) lookupName(name string) (idx uint32, found, noDynamics bool) {
+// lookupName resolves a name. read tells if the value is going to be read (as opposed to only assigned), which
+// is recorded for Analyze().
+func (s *scope) lookupName(name string, read bool) (idx uint32, found, noDynamics bool) {
 	var level uint32 = 0
 	noDynamics = true
 	for curScope := s; curScope != nil; curScope = curScope.outer {
@@ -194,6 +246,9 @@ func (s *scope) lookupName(name string) (idx uint32, found, noDynamics bool) {
 				mapped = name
 			}
 			if i, exists := curScope.names[mapped]; exists {
+				if read && curScope.analysis != nil {
+					curScope.analysis.use(mapped)
+				}
 				idx = i | (level << 24)
 				found = true
 				return
@@ -292,6 +347,23 @@ func (c *compiler) compile(in *ast.Program) {
 
 }
 
+// compileProgram compiles the parsed program, returning the syntax errors found by the compiler.
+func (c *compiler) compileProgram(prg *ast.Program) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			switch x1 := x.(type) {
+			case *CompilerSyntaxError:
+				err = x1
+			default:
+				panic(x)
+			}
+		}
+	}()
+
+	c.compile(prg)
+	return
+}
+
 func (c *compiler) compileDeclList(v []ast.Declaration, inFunc bool) {
 	for _, value := range v {
 		switch value := value.(type) {
@@ -320,6 +392,7 @@ func (c *compiler) compileVarDecl(v *ast.VariableDeclaration, inFunc bool) {
 			c.checkIdentifierName(item.Name, int(item.Idx)-1)
 		}
 		if !inFunc || item.Name != "arguments" {
+			c.declareBinding(analysisBinding{name: item.Name, idx: item.Idx, param: -1})
 			idx, ok := c.scope.bindName(item.Name)
 			_ = idx
 			//log.Printf("Define var: %s: %x", item.Name, idx)
@@ -399,6 +472,7 @@ func (c *compiler) convertFunctionToStashless(code []instruction, args int) {
 }
 
 func (c *compiler) compileFunctionDecl(v *ast.FunctionDeclaration) {
+	c.declareBinding(analysisBinding{name: v.Function.Name.Name, idx: v.Function.Name.Idx, param: -1, fn: true})
 	idx, ok := c.scope.bindName(v.Function.Name.Name)
 	if !ok {
 		// TODO: error
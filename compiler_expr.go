@@ -30,6 +30,7 @@ type compiledCallExpr struct {
 	baseCompiledExpr
 	args   []compiledExpr
 	callee compiledExpr
+	tail   bool
 }
 
 type compiledObjectLiteral struct {
@@ -98,6 +99,8 @@ type compiledFunctionLiteral struct {
 	baseCompiledExpr
 	expr   *ast.FunctionLiteral
 	isExpr bool
+	// inferredName is the name of an anonymous function taken from the variable or the property it's assigned to
+	inferredName string
 }
 
 type compiledBracketExpr struct {
@@ -109,6 +112,10 @@ type compiledThisExpr struct {
 	baseCompiledExpr
 }
 
+type compiledNewTarget struct {
+	baseCompiledExpr
+}
+
 type compiledNewExpr struct {
 	baseCompiledExpr
 	callee compiledExpr
@@ -232,6 +239,8 @@ func (c *compiler) compileExpression(v ast.Expression) compiledExpr {
 		return c.compileSequenceExpression(v)
 	case *ast.NewExpression:
 		return c.compileNewExpression(v)
+	case *ast.MetaProperty:
+		return c.compileMetaProperty(v)
 	default:
 		panic(fmt.Errorf("Unknown expression type: %T", v))
 	}
@@ -277,7 +286,7 @@ func (e *constantExpr) emitGetter(putOnStack bool) {
 
 func (e *compiledIdentifierExpr) emitGetter(putOnStack bool) {
 	e.addSrcMap()
-	if idx, found, noDynamics := e.c.scope.lookupName(e.name); noDynamics {
+	if idx, found, noDynamics := e.c.scope.lookupName(e.name, true); noDynamics {
 		if found {
 			if putOnStack {
 				e.c.emit(getLocal(idx))
@@ -299,7 +308,7 @@ func (e *compiledIdentifierExpr) emitGetter(putOnStack bool) {
 
 func (e *compiledIdentifierExpr) emitGetterOrRef() {
 	e.addSrcMap()
-	if idx, found, noDynamics := e.c.scope.lookupName(e.name); noDynamics {
+	if idx, found, noDynamics := e.c.scope.lookupName(e.name, true); noDynamics {
 		if found {
 			e.c.emit(getLocal(idx))
 		} else {
@@ -314,12 +323,13 @@ func (e *compiledIdentifierExpr) emitGetterOrRef() {
 	}
 }
 
-func (c *compiler) emitVarSetter1(name string, offset int, emitRight func(isRef bool)) {
+// emitVarSetter1 emits the assignment of a variable, read tells if the current value is read as well (e.g. +=).
+func (c *compiler) emitVarSetter1(name string, offset int, read bool, emitRight func(isRef bool)) {
 	if c.scope.strict {
 		c.checkIdentifierLName(name, offset)
 	}
 
-	if idx, found, noDynamics := c.scope.lookupName(name); noDynamics {
+	if idx, found, noDynamics := c.scope.lookupName(name, read); noDynamics {
 		emitRight(false)
 		if found {
 			c.emit(setLocal(idx))
@@ -348,7 +358,7 @@ func (c *compiler) emitVarSetter1(name string, offset int, emitRight func(isRef
 }
 
 func (c *compiler) emitVarSetter(name string, offset int, valueExpr compiledExpr) {
-	c.emitVarSetter1(name, offset, func(bool) {
+	c.emitVarSetter1(name, offset, false, func(bool) {
 		c.emitExpr(valueExpr, true)
 	})
 }
@@ -363,7 +373,7 @@ func (e *compiledIdentifierExpr) emitSetter(valueExpr compiledExpr) {
 
 func (e *compiledIdentifierExpr) emitUnary(prepare, body func(), postfix, putOnStack bool) {
 	if putOnStack {
-		e.c.emitVarSetter1(e.name, e.offset, func(isRef bool) {
+		e.c.emitVarSetter1(e.name, e.offset, true, func(isRef bool) {
 			e.c.emit(loadUndef)
 			if isRef {
 				e.c.emit(getValue)
@@ -383,7 +393,7 @@ func (e *compiledIdentifierExpr) emitUnary(prepare, body func(), postfix, putOnS
 		})
 		e.c.emit(pop)
 	} else {
-		e.c.emitVarSetter1(e.name, e.offset, func(isRef bool) {
+		e.c.emitVarSetter1(e.name, e.offset, true, func(isRef bool) {
 			if isRef {
 				e.c.emit(getValue)
 			} else {
@@ -400,7 +410,7 @@ func (e *compiledIdentifierExpr) deleteExpr() compiledExpr {
 		e.c.throwSyntaxError(e.offset, "Delete of an unqualified identifier in strict mode")
 		panic("Unreachable")
 	}
-	if _, found, noDynamics := e.c.scope.lookupName(e.name); noDynamics {
+	if _, found, noDynamics := e.c.scope.lookupName(e.name, true); noDynamics {
 		if !found {
 			r := &deleteGlobalExpr{
 				name: e.name,
@@ -724,12 +734,15 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	savedBlockStart := e.c.blockStart
 	savedPrg := e.c.p
 	e.c.p = &Program{
-		src: e.c.p.src,
+		src:      e.c.p.src,
+		funcCode: true,
 	}
 	e.c.blockStart = 0
 
 	if e.expr.Name != nil {
 		e.c.p.funcName = e.expr.Name.Name
+	} else {
+		e.c.p.funcName = e.inferredName
 	}
 	block := e.c.block
 	e.c.block = nil
@@ -753,7 +766,8 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 
 	length := len(e.expr.ParameterList.List)
 
-	for _, item := range e.expr.ParameterList.List {
+	for i, item := range e.expr.ParameterList.List {
+		e.c.declareBinding(analysisBinding{name: item.Name, idx: item.Idx, param: i})
 		_, unique := e.c.scope.bindNameShadow(item.Name)
 		if !unique && e.c.scope.strict {
 			e.c.throwSyntaxError(int(item.Idx)-1, "Strict mode function may not have duplicate parameter names (%s)", item.Name)
@@ -765,6 +779,8 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	var needCallee bool
 	var calleeIdx uint32
 	if e.isExpr && e.expr.Name != nil {
+		// the name of a function expression is only visible inside it, it's never reported
+		e.c.declareBinding(analysisBinding{name: e.expr.Name.Name, idx: e.expr.Name.Idx, param: -1, silent: true})
 		if idx, ok := e.c.scope.bindName(e.expr.Name.Name); ok {
 			calleeIdx = idx
 			needCallee = true
@@ -852,7 +868,7 @@ func (e *compiledFunctionLiteral) emitGetter(putOnStack bool) {
 	e.c.popScope()
 	e.c.p = savedPrg
 	e.c.blockStart = savedBlockStart
-	name := ""
+	name := e.inferredName
 	if e.expr.Name != nil {
 		name = e.expr.Name.Name
 	}
@@ -874,6 +890,16 @@ func (c *compiler) compileFunctionLiteral(v *ast.FunctionLiteral, isExpr bool) c
 	return r
 }
 
+// compileNamedExpression compiles the value assigned to a variable or a property, if it's an anonymous function
+// it gets the name (NamedEvaluation in the specification).
+func (c *compiler) compileNamedExpression(v ast.Expression, name string) compiledExpr {
+	r := c.compileExpression(v)
+	if f, ok := r.(*compiledFunctionLiteral); ok && f.expr.Name == nil {
+		f.inferredName = name
+	}
+	return r
+}
+
 func nearestNonLexical(s *scope) *scope {
 	for ; s != nil && s.lexical; s = s.outer {
 	}
@@ -902,6 +928,13 @@ func (e *compiledThisExpr) deleteExpr() compiledExpr {
 }
 */
 
+func (e *compiledNewTarget) emitGetter(putOnStack bool) {
+	if putOnStack {
+		e.addSrcMap()
+		e.c.emit(loadNewTarget)
+	}
+}
+
 func (e *compiledNewExpr) emitGetter(putOnStack bool) {
 	e.callee.emitGetter(true)
 	for _, expr := range e.args {
@@ -927,6 +960,19 @@ func (c *compiler) compileNewExpression(v *ast.NewExpression) compiledExpr {
 	return r
 }
 
+func (c *compiler) compileMetaProperty(v *ast.MetaProperty) compiledExpr {
+	if v.Meta.Name == "new" && v.Property.Name == "target" {
+		if !c.p.funcCode {
+			c.throwSyntaxError(int(v.Idx)-1, "new.target expression is not allowed here")
+		}
+		r := &compiledNewTarget{}
+		r.init(c, v.Idx0())
+		return r
+	}
+	c.throwSyntaxError(int(v.Idx)-1, "Unsupported meta property: %s.%s", v.Meta.Name, v.Property.Name)
+	return nil
+}
+
 func (e *compiledSequenceExpr) emitGetter(putOnStack bool) {
 	if len(e.sequence) > 0 {
 		for i := 0; i < len(e.sequence)-1; i++ {
@@ -1328,7 +1374,7 @@ func (e *compiledVariableExpr) emitGetter(putOnStack bool) {
 func (c *compiler) compileVariableExpression(v *ast.VariableExpression) compiledExpr {
 	r := &compiledVariableExpr{
 		name:        v.Name,
-		initializer: c.compileExpression(v.Initializer),
+		initializer: c.compileNamedExpression(v.Initializer, v.Name),
 	}
 	r.init(c, v.Idx0())
 	return r
@@ -1338,7 +1384,11 @@ func (e *compiledObjectLiteral) emitGetter(putOnStack bool) {
 	e.addSrcMap()
 	e.c.emit(newObject)
 	for _, prop := range e.expr.Value {
-		e.c.compileExpression(prop.Value).emitGetter(true)
+		name := prop.Key
+		if prop.Kind != "value" {
+			name = prop.Kind + " " + name
+		}
+		e.c.compileNamedExpression(prop.Value, name).emitGetter(true)
 		switch prop.Kind {
 		case "value":
 			if prop.Key == "__proto__" {
@@ -1392,16 +1442,14 @@ func (c *compiler) compileArrayLiteral(v *ast.ArrayLiteral) compiledExpr {
 
 func (e *compiledRegexpLiteral) emitGetter(putOnStack bool) {
 	if putOnStack {
-		pattern, global, ignoreCase, multiline, err := compileRegexp(e.expr.Pattern, e.expr.Flags)
+		re, err := compileRegexp(e.expr.Pattern, e.expr.Flags)
 		if err != nil {
 			e.c.throwSyntaxError(e.offset, err.Error())
 		}
 
-		e.c.emit(&newRegexp{pattern: pattern,
-			src:        newStringValue(e.expr.Pattern),
-			global:     global,
-			ignoreCase: ignoreCase,
-			multiline:  multiline,
+		e.c.emit(&newRegexp{
+			re:  re,
+			src: newStringValue(e.expr.Pattern),
 		})
 	}
 }
@@ -1452,6 +1500,8 @@ func (e *compiledCallExpr) emitGetter(putOnStack bool) {
 		} else {
 			e.c.emit(callEval(len(e.args)))
 		}
+	} else if e.tail {
+		e.c.emit(tailCall(len(e.args)))
 	} else {
 		e.c.emit(call(len(e.args)))
 	}
@@ -1546,9 +1596,13 @@ func (c *compiler) compileAssignExpression(v *ast.AssignExpression) compiledExpr
 
 	r := &compiledAssignExpr{
 		left:     c.compileExpression(v.Left),
-		right:    c.compileExpression(v.Right),
 		operator: v.Operator,
 	}
+	if id, ok := v.Left.(*ast.Identifier); ok && v.Operator == token.ASSIGN {
+		r.right = c.compileNamedExpression(v.Right, id.Name)
+	} else {
+		r.right = c.compileExpression(v.Right)
+	}
 	r.init(c, v.Idx0())
 	return r
 }
@@ -534,8 +534,20 @@ func (c *compiler) compileIfStatement(v *ast.IfStatement, needResult bool) {
 }
 
 func (c *compiler) compileReturnStatement(v *ast.ReturnStatement) {
+	inTry := false
+	for b := c.block; b != nil; b = b.outer {
+		if b.typ == blockTry {
+			inTry = true
+			break
+		}
+	}
 	if v.Argument != nil {
-		c.compileExpression(v.Argument).emitGetter(true)
+		expr := c.compileExpression(v.Argument)
+		if call, ok := expr.(*compiledCallExpr); ok && c.scope.strict && !inTry {
+			// A call in the tail position of a strict mode function reuses the current frame
+			call.tail = true
+		}
+		expr.emitGetter(true)
 		//c.emit(checkResolve)
 	} else {
 		c.emit(loadUndef)
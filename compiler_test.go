@@ -723,6 +723,49 @@ func TestTypeError(t *testing.T) {
 	testScript1(SCRIPT, valueTrue, t)
 }
 
+func TestErrorCause(t *testing.T) {
+	const SCRIPT = `
+	var cause = new TypeError("inner");
+	var e = new Error("outer", {cause: cause});
+	assert.sameValue(e.cause, cause, "cause");
+	assert(!e.propertyIsEnumerable("cause"), "cause must not be enumerable");
+	assert(!new RangeError("test", {}).hasOwnProperty("cause"), "no cause");
+	assert(new Error("test", {cause: undefined}).hasOwnProperty("cause"), "undefined cause");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestAggregateError(t *testing.T) {
+	const SCRIPT = `
+	var e = new AggregateError([new Error("a"), "b"], "failed", {cause: 1});
+	assert(e instanceof AggregateError && e instanceof Error, "instanceof");
+	assert.sameValue(e.name, "AggregateError", "name");
+	assert.sameValue(e.message, "failed", "message");
+	assert.sameValue(e.cause, 1, "cause");
+	assert.sameValue(e.errors.length, 2, "errors.length");
+	assert.sameValue(e.errors[1], "b", "errors[1]");
+	assert.sameValue(AggregateError.length, 2, "length");
+	var thrown;
+	try {
+		new AggregateError();
+	} catch (err) {
+		thrown = err;
+	}
+	assert(thrown instanceof TypeError, "undefined errors");
+	`
+
+	vm := New()
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errors, ok := vm.Get("e").ToObject(vm).Get("errors").Export().([]interface{})
+	if !ok || len(errors) != 2 || errors[1] != "b" {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+}
+
 func TestToString(t *testing.T) {
 	const SCRIPT = `
 	var o = {x: 42};
@@ -1797,6 +1840,160 @@ func TestObjectLiteral__Proto__(t *testing.T) {
 	testScript1(SCRIPT, _null, t)
 }
 
+func TestTailCall(t *testing.T) {
+	const SCRIPT = `
+	function even(n, extra) {
+		"use strict";
+		if (n === 0) {
+			return depth();
+		}
+		return odd(n - 1);
+	}
+	function odd(n) {
+		"use strict";
+		var captured = function() { return n; };
+		return even(n - 1, captured, 1, 2);
+	}
+	function sloppy(n) {
+		if (n === 0) {
+			return depth();
+		}
+		return sloppy(n - 1);
+	}
+	function withTry(n) {
+		"use strict";
+		try {
+			return n === 0 ? depth() : withTry(n - 1);
+		} finally {
+		}
+	}
+	function native(n) {
+		"use strict";
+		return Math.max(n, 1);
+	}
+	[even(10000), sloppy(100), withTry(10), native(5)];
+	`
+
+	vm := New()
+	vm.Set("depth", func() int {
+		return len(vm.vm.callStack)
+	})
+	v, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := v.Export().([]interface{})
+	if d := res[0].(int64); d > 3 {
+		t.Fatalf("Tail calls use the call stack: %d", d)
+	}
+	if d := res[1].(int64); d < 100 {
+		t.Fatalf("Unexpected non-strict depth: %d", d)
+	}
+	if d := res[2].(int64); d < 10 {
+		t.Fatalf("Unexpected depth in try: %d", d)
+	}
+	if n := res[3].(int64); n != 5 {
+		t.Fatalf("Unexpected native call result: %d", n)
+	}
+}
+
+func TestNewTarget(t *testing.T) {
+	const SCRIPT = `
+	var captured;
+	function F() {
+		captured = new.target;
+		if (!new.target) {
+			return new F();
+		}
+		this.inner = (function() { return new.target; })();
+	}
+	var o = F();
+	assert(o instanceof F, "called without new");
+	assert.sameValue(captured, F, "new.target in construct call");
+	assert.sameValue(o.inner, undefined, "new.target in nested plain call");
+
+	var Bound = F.bind(null);
+	new Bound();
+	assert.sameValue(captured, F, "new.target with a bound function");
+
+	function G() {
+		return eval("new.target");
+	}
+	assert.sameValue(new G(), G, "new.target in eval");
+	assert.sameValue(G(), undefined, "new.target in eval, plain call");
+
+	function H() {
+		return eval("eval('new.target')");
+	}
+	assert.sameValue(new H(), H, "new.target in nested eval");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestNewTargetEvalOutsideFunction(t *testing.T) {
+	vm := New()
+	for _, src := range []string{
+		`eval("new.target")`,
+		`eval("eval('new.target')")`,
+		`(function() { return (0, eval)("new.target"); })()`,
+	} {
+		_, err := vm.RunString(src)
+		if ex, ok := err.(*Exception); !ok || ex.Value().ToObject(vm).Get("name").String() != "SyntaxError" {
+			t.Fatalf("%s: unexpected error: %v", src, err)
+		}
+	}
+	v, err := vm.RunString(`new Function("return eval('new.target')")()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != _undefined {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+}
+
+func TestFunctionNameInference(t *testing.T) {
+	const SCRIPT = `
+	var f = function() {};
+	var g;
+	g = function() {};
+	var h = function named() {};
+	var o = {
+		m: function() {},
+		get p() { return 1; },
+		set p(v) {}
+	};
+	var desc = Object.getOwnPropertyDescriptor(o, "p");
+	o.x = function() {};
+
+	assert.sameValue(f.name, "f", "var");
+	assert.sameValue(function() { var g1; g1 = function() {}; return g1.name; }(), "g1", "assignment");
+	assert.sameValue(h.name, "named", "named");
+	assert.sameValue(o.m.name, "m", "property");
+	assert.sameValue(desc.get.name, "get p", "getter");
+	assert.sameValue(desc.set.name, "set p", "setter");
+	assert.sameValue(o.x.name, "", "member assignment");
+	assert.sameValue((0, function() {}).name, "", "anonymous");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestFunctionNameInferenceStack(t *testing.T) {
+	const SCRIPT = `
+	var handler = function() {
+		throw new Error("test");
+	};
+	handler();
+	`
+	_, err := New().RunString(SCRIPT)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if frame := ex.stack[0]; frame.FuncName() != "handler" {
+		t.Fatalf("Unexpected function name: %q", frame.FuncName())
+	}
+}
+
 // FIXME
 /*
 func TestDummyCompile(t *testing.T) {
@@ -0,0 +1,189 @@
+package goja
+
+// ChangeKind describes the type of a Change reported by Diff().
+type ChangeKind int
+
+const (
+	// ChangeAdded means the property exists only in the 'after' value.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved means the property exists only in the 'before' value.
+	ChangeRemoved
+	// ChangeModified means the property exists in both values but its value has changed.
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Change is a single difference reported by Diff().
+type Change struct {
+	// Path is the sequence of property names leading to the changed value. It is empty if the root
+	// values differ.
+	Path []string
+	Kind ChangeKind
+	// Before is the old value, nil for ChangeAdded.
+	Before Value
+	// After is the new value, nil for ChangeRemoved.
+	After Value
+}
+
+type diffPair struct {
+	before, after *Object
+}
+
+type differ struct {
+	changes []Change
+	seen    map[diffPair]bool
+}
+
+/*
+Diff compares two values structurally and returns the list of differences between them.
+
+Objects (including arrays and wrapped Go values) are compared by their own enumerable properties, recursively.
+Primitive values are compared using SameValue, so NaN is considered equal to NaN. Functions are compared by identity.
+Date, RegExp and primitive wrapper objects are compared by their internal value and are not descended into.
+Accessor properties are compared by their getter and setter functions, the accessors are never called, so
+Diff() does not run any JavaScript code and may be safely used outside of the Runtime's execution.
+
+Note that comparing an object to itself always yields no changes, so to audit modifications made by a script
+the 'before' value must be a separate copy.
+*/
+func Diff(before, after Value) []Change {
+	d := &differ{
+		seen: make(map[diffPair]bool),
+	}
+	d.diff(nil, before, after)
+	return d.changes
+}
+
+func (d *differ) add(path []string, kind ChangeKind, before, after Value) {
+	p := make([]string, len(path))
+	copy(p, path)
+	d.changes = append(d.changes, Change{
+		Path:   p,
+		Kind:   kind,
+		Before: before,
+		After:  after,
+	})
+}
+
+func (d *differ) diff(path []string, before, after Value) {
+	beforeObj, beforeIsObj := before.(*Object)
+	afterObj, afterIsObj := after.(*Object)
+	if !beforeIsObj || !afterIsObj {
+		if beforeIsObj || afterIsObj || !before.SameAs(after) {
+			d.add(path, ChangeModified, before, after)
+		}
+		return
+	}
+
+	if beforeObj == afterObj {
+		return
+	}
+
+	pair := diffPair{before: beforeObj, after: afterObj}
+	if d.seen[pair] {
+		return
+	}
+	d.seen[pair] = true
+
+	if !diffSameKind(beforeObj, afterObj) {
+		d.add(path, ChangeModified, before, after)
+		return
+	}
+
+	names := make(map[string]bool)
+	for item, f := beforeObj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		names[item.name] = true
+		p := append(path, item.name)
+		bv := diffOwnValue(beforeObj, item.name)
+		if av := afterObj.self.getOwnProp(item.name); av == nil || !diffIsEnumerable(av) {
+			d.add(p, ChangeRemoved, bv, nil)
+		} else {
+			d.diffProp(p, bv, diffOwnValue(afterObj, item.name))
+		}
+	}
+
+	for item, f := afterObj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		if !names[item.name] {
+			d.add(append(path, item.name), ChangeAdded, nil, diffOwnValue(afterObj, item.name))
+		}
+	}
+}
+
+func (d *differ) diffProp(path []string, before, after Value) {
+	bp, beforeIsAccessor := before.(*valueProperty)
+	ap, afterIsAccessor := after.(*valueProperty)
+	if beforeIsAccessor || afterIsAccessor {
+		if !beforeIsAccessor || !afterIsAccessor || bp.getterFunc != ap.getterFunc || bp.setterFunc != ap.setterFunc {
+			d.add(path, ChangeModified, before, after)
+		}
+		return
+	}
+	d.diff(path, before, after)
+}
+
+// diffOwnValue returns the value of an own data property, or the property itself (*valueProperty) if it's an
+// accessor property.
+func diffOwnValue(o *Object, name string) Value {
+	v := o.self.getOwnProp(name)
+	if p, ok := v.(*valueProperty); ok {
+		if p.accessor {
+			return p
+		}
+		return p.value
+	}
+	if v == nil {
+		return _undefined
+	}
+	return v
+}
+
+func diffIsEnumerable(prop Value) bool {
+	if p, ok := prop.(*valueProperty); ok {
+		return p.enumerable
+	}
+	return true
+}
+
+// diffSameKind returns true if both objects are of the same kind and have the same internal value (for objects
+// that have one). Only in this case their properties are compared.
+func diffSameKind(before, after *Object) bool {
+	if before.self.className() != after.self.className() {
+		return false
+	}
+	switch b := before.self.(type) {
+	case *funcObject, *nativeFuncObject, *boundFuncObject:
+		return false
+	case *dateObject:
+		if a, ok := after.self.(*dateObject); ok {
+			return b.isSet == a.isSet && (!b.isSet || b.time.Equal(a.time))
+		}
+		return false
+	case *regexpObject:
+		if a, ok := after.self.(*regexpObject); ok {
+			return b.source.SameAs(a.source) && b.global == a.global && b.multiline == a.multiline && b.ignoreCase == a.ignoreCase && b.unicode == a.unicode && b.sticky == a.sticky && b.dotAll == a.dotAll && b.hasIndices == a.hasIndices
+		}
+		return false
+	case *stringObject:
+		if a, ok := after.self.(*stringObject); ok {
+			return b.value.SameAs(a.value)
+		}
+		return false
+	case *primitiveValueObject:
+		if a, ok := after.self.(*primitiveValueObject); ok {
+			return b.pValue.SameAs(a.pValue)
+		}
+		return false
+	}
+	return true
+}
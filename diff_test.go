@@ -0,0 +1,85 @@
+package goja
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	const SCRIPT = `
+	var before = {a: 1, b: {c: "x", d: [1, 2]}, e: NaN, f: "removed", g: new Date(0)};
+	var after = {a: 2, b: {c: "x", d: [1, 2, 3]}, e: NaN, h: {}, g: new Date(0)};
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Diff(vm.Get("before"), vm.Get("after"))
+
+	type change struct {
+		path string
+		kind ChangeKind
+	}
+	var actual []change
+	for _, c := range changes {
+		p := ""
+		for i, n := range c.Path {
+			if i > 0 {
+				p += "."
+			}
+			p += n
+		}
+		actual = append(actual, change{p, c.Kind})
+	}
+	expected := []change{
+		{"a", ChangeModified},
+		{"b.d.2", ChangeAdded},
+		{"f", ChangeRemoved},
+		{"h", ChangeAdded},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Unexpected changes: %v", actual)
+	}
+
+	if !changes[0].Before.SameAs(intToValue(1)) || !changes[0].After.SameAs(intToValue(2)) {
+		t.Fatalf("Unexpected values: %v", changes[0])
+	}
+	if changes[2].After != nil || changes[3].Before != nil {
+		t.Fatalf("Unexpected values: %v, %v", changes[2], changes[3])
+	}
+}
+
+func TestDiffCircular(t *testing.T) {
+	const SCRIPT = `
+	var before = {a: 1};
+	before.self = before;
+	var after = {a: 1};
+	after.self = after;
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := Diff(vm.Get("before"), vm.Get("after")); len(changes) != 0 {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+}
+
+func TestDiffGoMap(t *testing.T) {
+	vm := New()
+	before := vm.ToValue(map[string]interface{}{"a": 1, "b": "test"})
+	after := vm.ToValue(map[string]interface{}{"a": 1, "b": "changed"})
+
+	changes := Diff(before, after)
+	if len(changes) != 1 || changes[0].Kind != ChangeModified || len(changes[0].Path) != 1 || changes[0].Path[0] != "b" {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+
+	if changes := Diff(vm.ToValue(1), vm.ToValue("1")); len(changes) != 1 || len(changes[0].Path) != 0 {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+}
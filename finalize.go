@@ -0,0 +1,90 @@
+package goja
+
+import (
+	"runtime"
+	"sync"
+)
+
+// objectFinalizers holds the callbacks registered with Runtime.OnObjectFinalize() that haven't been called yet.
+// It is shared with the Go finalizers which run in a separate goroutine, hence the mutex.
+type objectFinalizers struct {
+	mu      sync.Mutex
+	pending map[*objectFinalizer]struct{}
+	closed  bool
+}
+
+// objectFinalizer holds the callbacks for one Object.
+type objectFinalizer struct {
+	fns []func()
+}
+
+// finalizerToken is referenced only by the Object it belongs to, so it becomes unreachable together with the Object.
+// The Go finalizer is set on the token rather than on the Object itself because Objects are part of a reference
+// cycle (Object -> objectImpl -> Object) and cyclic structures with finalizers are never collected.
+type finalizerToken struct {
+	f       *objectFinalizer
+	holders *objectFinalizers
+}
+
+func (t *finalizerToken) finalize() {
+	h := t.holders
+	h.mu.Lock()
+	_, exists := h.pending[t.f]
+	delete(h.pending, t.f)
+	h.mu.Unlock()
+	if exists {
+		t.f.run()
+	}
+}
+
+func (f *objectFinalizer) run() {
+	for _, fn := range f.fns {
+		fn()
+	}
+}
+
+// OnObjectFinalize registers fn to be called once obj is no longer reachable, so that a host resource attached
+// to a JavaScript object (a file handle, a database cursor) can be released when the scripts drop all references to
+// it without having to close it explicitly.
+// This is best-effort: fn is called by a Go finalizer after obj has been garbage collected, which may happen
+// much later or not at all before the program exits. Any callbacks that haven't been called are called by
+// Runtime.Close(). If the Runtime is already closed fn is called immediately.
+// Callbacks for the same object are called in the order they were registered. Because they may be called from
+// a separate goroutine they must not use the Runtime or any of its values.
+func (r *Runtime) OnObjectFinalize(obj *Object, fn func()) {
+	h := &r.finalizers
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		fn()
+		return
+	}
+	if obj.finalizer == nil {
+		t := &finalizerToken{
+			f:       &objectFinalizer{},
+			holders: h,
+		}
+		if h.pending == nil {
+			h.pending = make(map[*objectFinalizer]struct{})
+		}
+		h.pending[t.f] = struct{}{}
+		runtime.SetFinalizer(t, (*finalizerToken).finalize)
+		obj.finalizer = t
+	}
+	obj.finalizer.f.fns = append(obj.finalizer.f.fns, fn)
+	h.mu.Unlock()
+}
+
+// Close calls all callbacks registered with OnObjectFinalize() that haven't been called yet. It should be called
+// once the Runtime is no longer used so that the host resources held by its objects are released deterministically.
+func (r *Runtime) Close() {
+	h := &r.finalizers
+	h.mu.Lock()
+	pending := h.pending
+	h.pending = nil
+	h.closed = true
+	h.mu.Unlock()
+	for f := range pending {
+		f.run()
+	}
+}
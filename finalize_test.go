@@ -0,0 +1,58 @@
+package goja
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestObjectFinalizeOnClose(t *testing.T) {
+	vm := New()
+	obj := vm.NewObject()
+	var calls []int
+	vm.OnObjectFinalize(obj, func() {
+		calls = append(calls, 1)
+	})
+	vm.OnObjectFinalize(obj, func() {
+		calls = append(calls, 2)
+	})
+	vm.Set("handle", obj)
+	vm.Close()
+	if len(calls) != 2 || calls[0] != 1 || calls[1] != 2 {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+	vm.Close()
+	if len(calls) != 2 {
+		t.Fatalf("Callbacks called more than once: %v", calls)
+	}
+	called := false
+	vm.OnObjectFinalize(vm.NewObject(), func() {
+		called = true
+	})
+	if !called {
+		t.Fatal("Callback registered after Close() is not called")
+	}
+}
+
+func TestObjectFinalizeOnGC(t *testing.T) {
+	vm := New()
+	done := make(chan struct{})
+	func() {
+		obj := vm.NewObject()
+		obj.self.putStr("self", obj, true)
+		vm.OnObjectFinalize(obj, func() {
+			close(done)
+		})
+	}()
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			t.Fatal("Callback is not called")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
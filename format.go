@@ -0,0 +1,117 @@
+package goja
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Format returns a string formatted in the same way as Node's util.format() does it: if the first argument is a
+// string it may contain the following placeholders which are replaced by the subsequent arguments:
+//
+//  %s: String for primitive values, objects are inspected with depth 0
+//  %d: Number
+//  %i: parseInt()
+//  %f: parseFloat()
+//  %j: JSON.stringify(), "[Circular]" if the argument contains circular references
+//  %o, %O: the inspected value (with depth 4 and 2 respectively)
+//  %%: a single percent sign
+//
+// Placeholders without a corresponding argument are left as is. The remaining arguments are appended,
+// separated by spaces; strings as they are, other values inspected.
+// Objects are rendered without invoking getters, nested objects beyond the depth limit are abbreviated and
+// circular references are marked as [Circular]. The conversions for %d, %i, %f and %j may call JavaScript
+// code (valueOf() or toJSON()), exceptions thrown by it are propagated as panics, the same way as by ToString().
+func (r *Runtime) Format(args ...Value) string {
+	var buf bytes.Buffer
+	i := 0
+	if len(args) > 0 {
+		if f, ok := args[0].assertString(); ok {
+			i = r.formatPlaceholders(&buf, f.String(), args[1:]) + 1
+		}
+	}
+	// the format string counts as written even if it's empty
+	written := i > 0
+	for ; i < len(args); i++ {
+		if written {
+			buf.WriteByte(' ')
+		}
+		written = true
+		if s, ok := args[i].assertString(); ok {
+			buf.WriteString(s.String())
+		} else {
+			buf.WriteString(r.inspect(args[i], inspectDefaultDepth))
+		}
+	}
+	return buf.String()
+}
+
+// formatPlaceholders writes the format string substituting the placeholders and returns the number of
+// arguments used.
+func (r *Runtime) formatPlaceholders(buf *bytes.Buffer, f string, args []Value) int {
+	n := 0
+	for {
+		pos := strings.IndexByte(f, '%')
+		if pos == -1 || pos == len(f)-1 {
+			buf.WriteString(f)
+			return n
+		}
+		buf.WriteString(f[:pos])
+		c := f[pos+1]
+		f = f[pos+2:]
+		if c == '%' {
+			buf.WriteByte('%')
+			continue
+		}
+		if !strings.ContainsRune("sdifjoO", rune(c)) || n >= len(args) {
+			buf.WriteByte('%')
+			buf.WriteByte(c)
+			continue
+		}
+		arg := args[n]
+		n++
+		switch c {
+		case 's':
+			if _, ok := arg.(*Object); ok {
+				buf.WriteString(r.inspect(arg, 0))
+			} else {
+				buf.WriteString(inspectPrimitive(arg, false))
+			}
+		case 'd':
+			buf.WriteString(inspectPrimitive(arg.ToNumber(), false))
+		case 'i':
+			buf.WriteString(inspectPrimitive(r.builtin_parseInt(FunctionCall{
+				This:      _undefined,
+				Arguments: []Value{arg},
+			}), false))
+		case 'f':
+			buf.WriteString(inspectPrimitive(r.builtin_parseFloat(FunctionCall{
+				This:      _undefined,
+				Arguments: []Value{arg},
+			}), false))
+		case 'j':
+			buf.WriteString(r.formatJSON(arg))
+		case 'o':
+			buf.WriteString(r.inspect(arg, 4))
+		case 'O':
+			buf.WriteString(r.inspect(arg, inspectDefaultDepth))
+		}
+	}
+}
+
+func (r *Runtime) formatJSON(v Value) (str string) {
+	var res Value
+	ex := r.vm.try(func() {
+		res = r.builtinJSON_stringify(FunctionCall{
+			This:      _undefined,
+			Arguments: []Value{v},
+		})
+	})
+	if ex != nil {
+		if e, ok := ex.val.(*Object); ok && e.self.className() == classError &&
+			strings.HasPrefix(e.self.getStr("message").String(), jsonCircularErrorMsg) {
+			return "[Circular]"
+		}
+		panic(ex)
+	}
+	return res.String()
+}
@@ -0,0 +1,58 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var o = {a: 1, b: "x", "c-d": [1, , 3], e: {f: {g: {h: 1}}}, get acc() { return 1; }};
+	o.self = o;
+	var fn = function named() {};
+	var num = {valueOf: function() { return 42; }};
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := vm.Get("o")
+
+	tests := []struct {
+		args     []Value
+		expected string
+	}{
+		{[]Value{vm.ToValue("%s=%d, %i, %f%%"), vm.ToValue("a"), vm.ToValue("42"), vm.ToValue(42.5), vm.ToValue("1.5px")}, "a=42, 42, 1.5%"},
+		{[]Value{vm.ToValue("%s %s"), vm.ToValue("only")}, "only %s"},
+		{[]Value{vm.ToValue("%j"), vm.ToValue(map[string]interface{}{"a": 1})}, `{"a":1}`},
+		{[]Value{vm.ToValue("%j"), o}, "[Circular]"},
+		{[]Value{vm.ToValue("a"), vm.ToValue(1), vm.ToValue("b"), _undefined, _null}, "a 1 b undefined null"},
+		{[]Value{vm.ToValue(1), vm.ToValue("it's")}, "1 it's"},
+		{[]Value{vm.ToValue([]interface{}{"it's"})}, `[ 'it\'s' ]`},
+		{[]Value{o}, "{ a: 1, b: 'x', 'c-d': [ 1, <1 empty item>, 3 ], e: { f: { g: [Object] } }, acc: [Getter], self: [Circular] }"},
+		{[]Value{vm.ToValue("%s"), o}, "{ a: 1, b: 'x', 'c-d': [Array], e: [Object], acc: [Getter], self: [Circular] }"},
+		{[]Value{vm.Get("fn"), vm.ToValue(vm.NewObject())}, "[Function: named] {}"},
+		{[]Value{vm.ToValue("%d %d"), vm.Get("num"), vm.ToValue(vm.NewObject())}, "42 NaN"},
+		{[]Value{vm.ToValue(""), vm.ToValue("a")}, " a"},
+		{[]Value{vm.ToValue(""), vm.ToValue(""), vm.ToValue("a")}, "  a"},
+	}
+
+	for i, test := range tests {
+		if res := vm.Format(test.args...); res != test.expected {
+			t.Fatalf("%d: expected %q, got %q", i, test.expected, res)
+		}
+	}
+}
+
+func TestFormatInspect(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`
+	[new Date(0), /a+/g, new Number(1), new String("s"), new Error("test"), [], new Array(200).join(",").split(",").length]
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = "[ 1970-01-01T00:00:00.000Z, /a+/g, [Number: 1], [String: 's'], Error: test\n    at <eval>:2:55, [], 200 ]"
+	if res := vm.Format(v); res != expected {
+		t.Fatalf("expected %q, got %q", expected, res)
+	}
+}
@@ -88,10 +88,10 @@ func (f *funcObject) construct(args []Value) *Object {
 		protoObj = f.val.runtime.global.ObjectPrototype
 	}
 	obj := f.val.runtime.newBaseObject(protoObj, classObject).val
-	ret := f.Call(FunctionCall{
+	ret := f.call(FunctionCall{
 		This:      obj,
 		Arguments: args,
-	})
+	}, f.val)
 
 	if ret, ok := ret.(*Object); ok {
 		return ret
@@ -100,6 +100,10 @@ func (f *funcObject) construct(args []Value) *Object {
 }
 
 func (f *funcObject) Call(call FunctionCall) Value {
+	return f.call(call, nil)
+}
+
+func (f *funcObject) call(call FunctionCall, newTarget Value) Value {
 	vm := f.val.runtime.vm
 	pc := vm.pc
 	vm.push(f.val)
@@ -110,6 +114,7 @@ func (f *funcObject) Call(call FunctionCall) Value {
 	vm.pc = -1
 	vm.pushCtx()
 	vm.args = len(call.Arguments)
+	vm.newTarget = newTarget
 	vm.prg = f.prg
 	vm.stash = f.stash
 	vm.pc = 0
@@ -0,0 +1,128 @@
+package goja
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// GlobalStore is an embedder-provided storage for global properties, see Runtime.SetGlobalStore().
+// The values are JSON-able, i.e. nil, bool, float64, string, []interface{} and map[string]interface{} (as produced
+// by json.Unmarshal()), or anything else that encoding/json can marshal.
+type GlobalStore interface {
+	// Get returns the stored value of the property. If exists is false the property reads as undefined.
+	Get(name string) (value interface{}, exists bool, err error)
+	// Set stores the value of the property.
+	Set(name string, value interface{}) error
+}
+
+type globalStoreProperty struct {
+	r      *Runtime
+	store  GlobalStore
+	name   string
+	value  Value
+	loaded bool
+}
+
+// SetGlobalStore binds the named properties of the global object to the store. Each property is loaded using
+// store.Get() the first time it is read and every assignment to it is written through using store.Set().
+// The values are transferred as JSON, i.e. objects and arrays are copied, functions and undefined are stored
+// as nil, so changes made to a loaded object in place are not seen by the store until the property is
+// assigned again or FlushGlobalStore() is called.
+// Errors returned by the store are thrown as GoError.
+func (r *Runtime) SetGlobalStore(store GlobalStore, names ...string) {
+	for _, name := range names {
+		p := &globalStoreProperty{
+			r:     r,
+			store: store,
+			name:  name,
+		}
+		descr := r.NewObject().self
+		descr.putStr("get", r.newNativeFunc(p.get, nil, "get "+name, nil, 0), false)
+		descr.putStr("set", r.newNativeFunc(p.set, nil, "set "+name, nil, 1), false)
+		descr.putStr("enumerable", valueTrue, false)
+		descr.putStr("configurable", valueTrue, false)
+		r.globalObject.self.defineOwnProperty(newStringValue(name), descr, true)
+		r.globalStoreProps = append(r.globalStoreProps, p)
+	}
+}
+
+// FlushGlobalStore writes the current values of all properties bound by SetGlobalStore() that have been
+// loaded or assigned back to their stores.
+func (r *Runtime) FlushGlobalStore() error {
+	for _, p := range r.globalStoreProps {
+		if !p.loaded {
+			continue
+		}
+		var v interface{}
+		var err error
+		if ex := r.vm.try(func() {
+			v, err = p.exportValue(p.value)
+		}); ex != nil {
+			return ex
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.store.Set(p.name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *globalStoreProperty) get(call FunctionCall) Value {
+	if !p.loaded {
+		v, exists, err := p.store.Get(p.name)
+		if err != nil {
+			panic(p.r.NewGoError(err))
+		}
+		if exists {
+			p.value = p.importValue(v)
+		} else {
+			p.value = _undefined
+		}
+		p.loaded = true
+	}
+	return p.value
+}
+
+func (p *globalStoreProperty) set(call FunctionCall) Value {
+	value := call.Argument(0)
+	v, err := p.exportValue(value)
+	if err == nil {
+		err = p.store.Set(p.name, v)
+	}
+	if err != nil {
+		panic(p.r.NewGoError(err))
+	}
+	p.value = value
+	p.loaded = true
+	return _undefined
+}
+
+func (p *globalStoreProperty) importValue(v interface{}) Value {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(p.r.NewGoError(err))
+	}
+	value, err := p.r.builtinJSON_decodeValue(json.NewDecoder(bytes.NewReader(b)), &jsonSources{text: string(b)})
+	if err != nil {
+		panic(p.r.NewGoError(err))
+	}
+	return value
+}
+
+func (p *globalStoreProperty) exportValue(value Value) (interface{}, error) {
+	s := p.r.builtinJSON_stringify(FunctionCall{
+		This:      _undefined,
+		Arguments: []Value{value},
+	})
+	if s == _undefined {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s.String()), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,77 @@
+package goja
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testGlobalStore struct {
+	values map[string]interface{}
+	gets   int
+}
+
+func (s *testGlobalStore) Get(name string) (interface{}, bool, error) {
+	s.gets++
+	v, exists := s.values[name]
+	return v, exists, nil
+}
+
+func (s *testGlobalStore) Set(name string, value interface{}) error {
+	if name == "readOnly" {
+		return errors.New("read only")
+	}
+	s.values[name] = value
+	return nil
+}
+
+func TestGlobalStore(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(counter, 1, "counter");
+	counter++;
+	assert.sameValue(missing, undefined, "missing");
+	state.items.push("b");
+	state.items.length;
+	saved = {a: [1, "x"], f: function() {}};
+	var thrown;
+	try {
+		readOnly = 1;
+	} catch (e) {
+		thrown = e;
+	}
+	assert(thrown instanceof GoError, "thrown");
+	`
+
+	store := &testGlobalStore{
+		values: map[string]interface{}{
+			"counter": 1,
+			"state":   map[string]interface{}{"items": []interface{}{"a"}},
+		},
+	}
+	vm := New()
+	vm.SetGlobalStore(store, "counter", "missing", "state", "saved", "readOnly")
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := store.values["counter"]; v != float64(2) {
+		t.Fatalf("Unexpected counter: %v", v)
+	}
+	if v := store.values["saved"]; !reflect.DeepEqual(v, map[string]interface{}{"a": []interface{}{float64(1), "x"}}) {
+		t.Fatalf("Unexpected saved: %v", v)
+	}
+	if v := store.values["state"]; !reflect.DeepEqual(v, map[string]interface{}{"items": []interface{}{"a"}}) {
+		t.Fatalf("state must not be written before flush: %v", v)
+	}
+	if store.gets != 3 {
+		t.Fatalf("Unexpected number of loads: %d", store.gets)
+	}
+
+	if err := vm.FlushGlobalStore(); err != nil {
+		t.Fatal(err)
+	}
+	if v := store.values["state"]; !reflect.DeepEqual(v, map[string]interface{}{"items": []interface{}{"a", "b"}}) {
+		t.Fatalf("Unexpected state: %v", v)
+	}
+}
@@ -0,0 +1,73 @@
+package goja
+
+// Lockdown hardens the Runtime along the lines of the SES lockdown(): everything reachable from the global object is
+// frozen, the existing globals become non-writable and non-configurable, and the global harden() function is
+// defined. Host objects should be added afterwards: if freezing one throws, the TypeError is returned as *Exception
+// and the Runtime is left partially frozen.
+func (r *Runtime) Lockdown() error {
+	ex := r.vm.try(func() {
+		seen := map[*Object]bool{
+			r.globalObject: true,
+		}
+		var queue []*Object
+		for item, f := r.globalObject.self.enumerate(true, false)(); f != nil; item, f = f() {
+			queue = r.appendPropertyObjects(queue, r.globalObject.self.getOwnProp(item.name))
+		}
+		harden := r.newNativeFunc(r.builtin_harden, nil, "harden", nil, 1)
+		queue = append(queue, harden)
+		r.hardenAll(queue, seen)
+		r.addToGlobal("harden", harden)
+		r.freezeProperties(r.globalObject)
+	})
+	if ex != nil {
+		return ex
+	}
+	return nil
+}
+
+func (r *Runtime) builtin_harden(call FunctionCall) Value {
+	arg := call.Argument(0)
+	if obj, ok := arg.(*Object); ok {
+		r.hardenAll([]*Object{obj}, map[*Object]bool{})
+	}
+	return arg
+}
+
+// hardenAll freezes the objects in queue and the objects reachable from them (through their properties, accessors
+// and prototypes) that are not in seen.
+func (r *Runtime) hardenAll(queue []*Object, seen map[*Object]bool) {
+	for len(queue) > 0 {
+		obj := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if seen[obj] {
+			continue
+		}
+		seen[obj] = true
+		r.freeze(obj)
+		for item, f := obj.self.enumerate(true, false)(); f != nil; item, f = f() {
+			queue = r.appendPropertyObjects(queue, obj.self.getOwnProp(item.name))
+		}
+		if proto := obj.self.proto(); proto != nil {
+			queue = append(queue, proto)
+		}
+	}
+}
+
+func (r *Runtime) appendPropertyObjects(queue []*Object, v Value) []*Object {
+	if prop, ok := v.(*valueProperty); ok {
+		if prop.accessor {
+			if prop.getterFunc != nil {
+				queue = append(queue, prop.getterFunc)
+			}
+			if prop.setterFunc != nil {
+				queue = append(queue, prop.setterFunc)
+			}
+			return queue
+		}
+		v = prop.value
+	}
+	if obj, ok := v.(*Object); ok {
+		queue = append(queue, obj)
+	}
+	return queue
+}
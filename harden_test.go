@@ -0,0 +1,72 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestLockdown(t *testing.T) {
+	const SCRIPT = `
+	(function() {
+	"use strict";
+	function throws(f, msg) {
+		try {
+			f();
+		} catch (e) {
+			if (e instanceof TypeError) {
+				return;
+			}
+			throw e;
+		}
+		throw new Error("Expected TypeError: " + msg);
+	}
+	throws(function() { Object.prototype.polluted = 1; }, "Object.prototype");
+	throws(function() { Array.prototype.push = null; }, "Array.prototype.push");
+	throws(function() { JSON.parse = null; }, "JSON.parse");
+	throws(function() { Math.max.extra = 1; }, "function property");
+	throws(function() { Object.getOwnPropertyDescriptor(RegExp.prototype, "global").get.x = 1; }, "getter");
+	throws(function() { delete String.prototype.trim; }, "delete");
+	assert(Object.isFrozen(Function.prototype), "Function.prototype");
+	assert(Object.isFrozen(harden), "harden");
+
+	throws(function() { JSON = {stringify: function() { return ""; }}; }, "JSON");
+	throws(function() { harden = function(x) { return x; }; }, "harden binding");
+	throws(function() { delete globalThis.JSON; }, "delete JSON");
+	throws(function() { var o = {}; o.toString = function() { return ""; }; }, "override mistake");
+
+	// The global object is not frozen
+	globalThis.h = 2;
+
+	var o = {a: {b: [1]}, get c() { return 1; }};
+	assert.sameValue(harden(o), o, "harden result");
+	assert(Object.isFrozen(o) && Object.isFrozen(o.a) && Object.isFrozen(o.a.b), "harden");
+	assert(Object.isFrozen(Object.getOwnPropertyDescriptor(o, "c").get), "harden getter");
+	assert.sameValue(harden(42), 42, "harden primitive");
+
+	// Ordinary objects still work
+	var p = {x: 1};
+	p.x = 2;
+	var arr = [1, 2];
+	arr.push(3);
+	assert.sameValue(arr.join(), "1,2,3", "array");
+	})();
+	`
+
+	vm := New()
+	if err := vm.Lockdown(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockdownHostObject(t *testing.T) {
+	vm := New()
+	vm.Set("host", map[string]interface{}{"a": 1})
+	if err := vm.Lockdown(); err == nil {
+		t.Fatal("Expected error")
+	} else if _, ok := err.(*Exception); !ok {
+		t.Fatalf("Unexpected error type: %T", err)
+	}
+}
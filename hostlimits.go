@@ -0,0 +1,111 @@
+package goja
+
+// HostArgLimits limits the size of the arguments a script can pass to a host (Go) function, see Runtime.LimitArgs().
+// A zero value of a field means no limit.
+type HostArgLimits struct {
+	// MaxStringLength is the maximum length of a string (in UTF-16 code units), including the strings nested
+	// in objects and arrays.
+	MaxStringLength int
+	// MaxArrayLength is the maximum length of an array, including the nested arrays.
+	MaxArrayLength int
+	// MaxDepth is the maximum nesting of objects and arrays: with 1 the arguments may be objects, but the values
+	// of their properties may not.
+	MaxDepth int
+}
+
+// LimitArgs returns a function that calls fn (a Go function which is converted with ToValue(), or a JavaScript
+// function) after checking that the arguments are within the limits. If an argument exceeds a limit a TypeError
+// naming the argument is thrown before it is converted, so that a script cannot make the host allocate large
+// amounts of memory by, say, passing a huge string to a logging function.
+// Only the own data properties of objects are checked (accessors are not called), so the limits can be exceeded
+// through getters when converting to Go types that invoke them. The length of an object converted to a Go string
+// while fn runs (e.g. one with a toString() method passed for a string parameter) is checked on the conversion.
+func (r *Runtime) LimitArgs(fn interface{}, limits HostArgLimits) Value {
+	obj := r.ToValue(fn).ToObject(r)
+	call, ok := obj.self.assertCallable()
+	if !ok {
+		panic(r.NewTypeError("Value is not callable: %s", obj.String()))
+	}
+	name := ""
+	if v := obj.self.getStr("name"); v != nil {
+		name = v.String()
+	}
+	length := 0
+	if v := obj.self.getStr("length"); v != nil {
+		length = int(v.ToInteger())
+	}
+	return r.newNativeFunc(func(c FunctionCall) Value {
+		seen := make(map[*Object]bool)
+		for i, arg := range c.Arguments {
+			r.checkArgLimits(arg, i, 0, &limits, seen)
+		}
+		saved := r.argLimits
+		r.argLimits = &limits
+		defer func() {
+			r.argLimits = saved
+		}()
+		return call(c)
+	}, nil, name, nil, length)
+}
+
+func (r *Runtime) checkArgLimits(v Value, idx, depth int, limits *HostArgLimits, seen map[*Object]bool) {
+	if s, ok := v.assertString(); ok {
+		if limits.MaxStringLength > 0 && s.length() > int64(limits.MaxStringLength) {
+			panic(r.NewTypeError("Argument %d exceeds the maximum string length (%d)", idx, limits.MaxStringLength))
+		}
+		return
+	}
+	o, ok := v.(*Object)
+	if !ok || seen[o] {
+		return
+	}
+	seen[o] = true
+	if limits.MaxDepth > 0 && depth >= limits.MaxDepth {
+		panic(r.NewTypeError("Argument %d exceeds the maximum nesting depth (%d)", idx, limits.MaxDepth))
+	}
+	var iter iterNextFunc
+	if so, ok := o.self.(*stringObject); ok {
+		if limits.MaxStringLength > 0 && so.length > int64(limits.MaxStringLength) {
+			panic(r.NewTypeError("Argument %d exceeds the maximum string length (%d)", idx, limits.MaxStringLength))
+		}
+		// only the properties other than the characters
+		iter = (&propFilterIter{
+			wrapped: so.baseObject._enumerate(false),
+			seen:    make(map[string]bool),
+		}).next
+	}
+	if limits.MaxArrayLength > 0 && o.self.className() == classArray {
+		if l := toLength(o.self.getStr("length")); l > int64(limits.MaxArrayLength) {
+			panic(r.NewTypeError("Argument %d exceeds the maximum array length (%d)", idx, limits.MaxArrayLength))
+		}
+	}
+	if _, ok := o.self.assertCallable(); ok {
+		return
+	}
+	if iter == nil {
+		iter = o.self.enumerate(false, false)
+	}
+	for item, f := iter(); f != nil; item, f = f() {
+		val := item.value
+		if val == nil {
+			val = o.self.getOwnProp(item.name)
+		}
+		if prop, ok := val.(*valueProperty); ok {
+			if prop.accessor {
+				continue
+			}
+			val = prop.value
+		}
+		if val != nil {
+			r.checkArgLimits(val, idx, depth+1, limits, seen)
+		}
+	}
+}
+
+// checkConvertedString checks the length of the result of a conversion of an object to a Go string made by the
+// host function.
+func (limits *HostArgLimits) checkConvertedString(r *Runtime, s valueString) {
+	if limits.MaxStringLength > 0 && s.length() > int64(limits.MaxStringLength) {
+		panic(r.NewTypeError("String conversion exceeds the maximum string length (%d)", limits.MaxStringLength))
+	}
+}
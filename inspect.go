@@ -0,0 +1,383 @@
+package goja
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	inspectDefaultDepth       = 2
+	inspectMaxArrayItems      = 100
+	inspectMaxArrayBufferData = 50
+)
+
+var (
+	inspectIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	inspectANSIRegexp       = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+type inspectStyle struct {
+	open, close string
+}
+
+// The same colors as used by Node's util.inspect().
+var (
+	inspectStyleNumber    = inspectStyle{"33", "39"} // yellow
+	inspectStyleString    = inspectStyle{"32", "39"} // green
+	inspectStyleSpecial   = inspectStyle{"36", "39"} // cyan
+	inspectStyleUndefined = inspectStyle{"90", "39"} // grey
+	inspectStyleNull      = inspectStyle{"1", "22"}  // bold
+	inspectStyleDate      = inspectStyle{"35", "39"} // magenta
+	inspectStyleRegExp    = inspectStyle{"31", "39"} // red
+)
+
+// InspectRenderer returns the representation of a wrapped Go value, see InspectOptions.
+type InspectRenderer func(value interface{}) string
+
+// InspectOptions control the output of Runtime.Inspect().
+type InspectOptions struct {
+	// Depth is the number of nesting levels of objects whose properties are shown, deeper objects are abbreviated
+	// as [Object] or [Array]. If 0, the default of 2 is used, a negative value means no limit.
+	Depth int
+
+	// Colors enables ANSI color codes in the output.
+	Colors bool
+
+	// BreakLength is the maximum line length, an object that does not fit is split into multiple lines with one
+	// property per line. If 0 (the default) the output is never split.
+	BreakLength int
+
+	// Renderers are used for Go values wrapped with ToValue() (structs, maps, slices, etc.) keyed by their
+	// type. The result is inserted into the output as is.
+	Renderers map[reflect.Type]InspectRenderer
+}
+
+// inspector renders values in the style of Node's util.inspect().
+type inspector struct {
+	r           *Runtime
+	depth       int
+	colors      bool
+	breakLength int
+	renderers   map[reflect.Type]InspectRenderer
+	seen        []*Object
+}
+
+// Inspect returns a human-readable representation of the value in the same format as Node's util.inspect().
+// Strings nested in objects are quoted, functions are shown as [Function: name], accessor properties are shown as
+// [Getter], [Setter] or [Getter/Setter] without being invoked, and circular references are marked as [Circular].
+// Dates, regular expressions, errors, primitive wrappers and ArrayBuffers are shown with their internal values.
+// This is the same representation used by Format() for objects.
+func (r *Runtime) Inspect(v Value, opts InspectOptions) string {
+	i := &inspector{
+		r:           r,
+		depth:       opts.Depth,
+		colors:      opts.Colors,
+		breakLength: opts.BreakLength,
+		renderers:   opts.Renderers,
+	}
+	if i.depth == 0 {
+		i.depth = inspectDefaultDepth
+	}
+	return i.value(v, 0)
+}
+
+func (r *Runtime) inspect(v Value, depth int) string {
+	i := &inspector{
+		r:     r,
+		depth: depth,
+	}
+	return i.value(v, 0)
+}
+
+// inspectPrimitive renders a primitive value. Strings are single-quoted if quote is true.
+func inspectPrimitive(v Value, quote bool) string {
+	switch v := v.(type) {
+	case valueString:
+		if quote {
+			return inspectQuote(v.String())
+		}
+		return v.String()
+	case valueFloat:
+		if v == 0 && math.Signbit(float64(v)) {
+			return "-0"
+		}
+	}
+	return v.String()
+}
+
+func inspectQuote(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('\'')
+	for _, c := range s {
+		switch c {
+		case '\'', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\x`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xF])
+			} else {
+				buf.WriteRune(c)
+			}
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
+
+func inspectKey(name string) string {
+	if inspectIdentifierRegexp.MatchString(name) {
+		return name
+	}
+	return inspectQuote(name)
+}
+
+// inspectGoValue returns the Go value wrapped by the object, if any.
+func inspectGoValue(o *Object) (interface{}, bool) {
+	switch obj := o.self.(type) {
+	case *objectGoReflect:
+		return obj.origValue.Interface(), true
+	case *objectGoMapReflect:
+		return obj.origValue.Interface(), true
+	case *objectGoSliceReflect:
+		return obj.origValue.Interface(), true
+	case *objectGoMapSimple:
+		return obj.data, true
+	case *objectGoSlice:
+		return *obj.data, true
+	case *objectDynamic:
+		return obj.d, true
+	}
+	return nil, false
+}
+
+func (i *inspector) stylize(s string, style inspectStyle) string {
+	if !i.colors {
+		return s
+	}
+	return "\x1b[" + style.open + "m" + s + "\x1b[" + style.close + "m"
+}
+
+func (i *inspector) value(v Value, level int) string {
+	o, ok := v.(*Object)
+	if !ok {
+		s := inspectPrimitive(v, true)
+		switch v.(type) {
+		case valueInt, valueFloat, valueBool:
+			return i.stylize(s, inspectStyleNumber)
+		case valueString:
+			return i.stylize(s, inspectStyleString)
+		case valueNull:
+			return i.stylize(s, inspectStyleNull)
+		case valueUndefined:
+			return i.stylize(s, inspectStyleUndefined)
+		}
+		return s
+	}
+	for _, s := range i.seen {
+		if s == o {
+			return i.stylize("[Circular]", inspectStyleSpecial)
+		}
+	}
+	i.seen = append(i.seen, o)
+	s := i.object(o, level)
+	i.seen = i.seen[:len(i.seen)-1]
+	return s
+}
+
+// prefix returns the description of the object's internal value (if any) that precedes its properties.
+func (i *inspector) prefix(o *Object) string {
+	switch obj := o.self.(type) {
+	case *funcObject, *nativeFuncObject, *boundFuncObject:
+		name := o.self.getStr("name")
+		if name == nil || name.String() == "" {
+			return i.stylize("[Function (anonymous)]", inspectStyleSpecial)
+		}
+		return i.stylize("[Function: "+name.String()+"]", inspectStyleSpecial)
+	case *dateObject:
+		if !obj.isSet {
+			return i.stylize("Invalid Date", inspectStyleDate)
+		}
+		return i.stylize(obj.time.UTC().Format("2006-01-02T15:04:05.000Z"), inspectStyleDate)
+	case *regexpObject:
+		return i.stylize(o.ToString().String(), inspectStyleRegExp)
+	case *primitiveValueObject:
+		style := inspectStyleNumber
+		if _, ok := obj.pValue.(valueString); ok {
+			style = inspectStyleString
+		}
+		return i.stylize("["+obj.class+": "+inspectPrimitive(obj.pValue, true)+"]", style)
+	case *stringObject:
+		return i.stylize("[String: "+inspectPrimitive(obj.value, true)+"]", inspectStyleString)
+	case *objectArrayBuffer:
+		return "ArrayBuffer"
+	}
+	if o.self.className() == classError {
+		if stack, ok := o.self.getStr("stack").(valueString); ok {
+			return stack.String()
+		}
+		return o.ToString().String()
+	}
+	return ""
+}
+
+func (i *inspector) object(o *Object, level int) string {
+	if i.renderers != nil {
+		if v, ok := inspectGoValue(o); ok {
+			if render := i.renderers[reflect.TypeOf(v)]; render != nil {
+				return render(v)
+			}
+		}
+	}
+
+	prefix := i.prefix(o)
+	isArray := o.self.className() == classArray
+	_, isString := o.self.(*stringObject)
+
+	if i.depth >= 0 && level > i.depth {
+		switch {
+		case prefix != "":
+			return prefix
+		case isArray:
+			return i.stylize("[Array]", inspectStyleSpecial)
+		default:
+			return i.stylize("[Object]", inspectStyleSpecial)
+		}
+	}
+
+	var items []string
+	if isArray {
+		items = i.arrayItems(o, level)
+	}
+	if b, ok := o.self.(*objectArrayBuffer); ok {
+		items = append(items, i.arrayBufferItems(b)...)
+	}
+	for item, f := o.self.enumerate(false, false)(); f != nil; item, f = f() {
+		if (isArray || isString) && strToIdx(item.name) >= 0 {
+			continue
+		}
+		items = append(items, inspectKey(item.name)+": "+i.property(o, item.name, level))
+	}
+
+	if len(items) == 0 {
+		switch {
+		case prefix != "":
+			return prefix
+		case isArray:
+			return "[]"
+		default:
+			return "{}"
+		}
+	}
+
+	open, close := "{", "}"
+	if isArray {
+		open, close = "[", "]"
+	}
+	if prefix != "" {
+		open = prefix + " " + open
+	}
+
+	s := open + " " + strings.Join(items, ", ") + " " + close
+	if i.breakLength > 0 && 2*level+len(inspectANSIRegexp.ReplaceAllString(s, "")) > i.breakLength {
+		indent := strings.Repeat("  ", level+1)
+		s = open + "\n" + indent + strings.Join(items, ",\n"+indent) + "\n" + indent[2:] + close
+	}
+	return s
+}
+
+func (i *inspector) arrayItems(o *Object, level int) (items []string) {
+	length := toLength(o.self.getStr("length"))
+	// Only the indices actually present are visited, the length of a sparse array may be huge.
+	var keys []int64
+	for item, f := o.self._enumerate(false)(); f != nil; item, f = f() {
+		if idx := strToIdx(item.name); idx >= 0 && idx < length {
+			keys = append(keys, idx)
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a] < keys[b]
+	})
+	holes := func(n int64) string {
+		if n == 1 {
+			return i.stylize("<1 empty item>", inspectStyleUndefined)
+		}
+		return i.stylize("<"+strconv.FormatInt(n, 10)+" empty items>", inspectStyleUndefined)
+	}
+	next := int64(0)
+	for n, idx := range keys {
+		if len(items) >= inspectMaxArrayItems {
+			items = append(items, "... "+strconv.Itoa(len(keys)-n)+" more items")
+			return
+		}
+		if idx > next {
+			items = append(items, holes(idx-next))
+		}
+		items = append(items, i.property(o, strconv.FormatInt(idx, 10), level))
+		next = idx + 1
+	}
+	if next < length {
+		items = append(items, holes(length-next))
+	}
+	return
+}
+
+func (i *inspector) arrayBufferItems(b *objectArrayBuffer) []string {
+	var buf bytes.Buffer
+	buf.WriteString("[Uint8Contents]: <")
+	for idx, c := range b.data {
+		if idx >= inspectMaxArrayBufferData {
+			buf.WriteString(" ... " + strconv.Itoa(len(b.data)-idx) + " more bytes")
+			break
+		}
+		if idx > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte(hex[c>>4])
+		buf.WriteByte(hex[c&0xF])
+	}
+	buf.WriteByte('>')
+	return []string{
+		buf.String(),
+		"byteLength: " + i.stylize(strconv.Itoa(len(b.data)), inspectStyleNumber),
+	}
+}
+
+// property renders an own property value, accessors are not invoked.
+func (i *inspector) property(o *Object, name string, level int) string {
+	v := o.self.getOwnProp(name)
+	if p, ok := v.(*valueProperty); ok {
+		if p.accessor {
+			switch {
+			case p.getterFunc != nil && p.setterFunc != nil:
+				return i.stylize("[Getter/Setter]", inspectStyleSpecial)
+			case p.getterFunc != nil:
+				return i.stylize("[Getter]", inspectStyleSpecial)
+			default:
+				return i.stylize("[Setter]", inspectStyleSpecial)
+			}
+		}
+		v = p.value
+	}
+	if v == nil {
+		v = _undefined
+	}
+	return i.value(v, level+1)
+}
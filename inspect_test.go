@@ -0,0 +1,100 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testInspectPoint struct {
+	X, Y int
+}
+
+func TestInspect(t *testing.T) {
+	vm := New()
+	b := vm._newArrayBuffer(vm.global.ObjectPrototype, nil)
+	b.data = make([]byte, 3)
+	vm.Set("b", b.val)
+	v, err := vm.RunString(`
+	({a: {b: {c: {d: 1}}}, buf: b, s: "str", n: null})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := vm.Inspect(v, InspectOptions{}); res != "{ a: { b: { c: [Object] } }, buf: ArrayBuffer { [Uint8Contents]: <00 00 00>, byteLength: 3 }, s: 'str', n: null }" {
+		t.Fatalf("Unexpected default output: %q", res)
+	}
+
+	if res := vm.Inspect(v, InspectOptions{Depth: -1}); res != "{ a: { b: { c: { d: 1 } } }, buf: ArrayBuffer { [Uint8Contents]: <00 00 00>, byteLength: 3 }, s: 'str', n: null }" {
+		t.Fatalf("Unexpected unlimited output: %q", res)
+	}
+
+	const expected = `{
+  a: { b: { c: [Object] } },
+  buf: ArrayBuffer { [Uint8Contents]: <00 00 00>, byteLength: 3 },
+  s: 'str',
+  n: null
+}`
+	if res := vm.Inspect(v, InspectOptions{BreakLength: 80}); res != expected {
+		t.Fatalf("Unexpected multi-line output: %q", res)
+	}
+
+	if res := vm.Inspect(vm.ToValue([]interface{}{"s", 1, nil}), InspectOptions{Colors: true}); res != "[ \x1b[32m's'\x1b[39m, \x1b[33m1\x1b[39m, \x1b[1mnull\x1b[22m ]" {
+		t.Fatalf("Unexpected colored output: %q", res)
+	}
+}
+
+func TestInspectRenderers(t *testing.T) {
+	vm := New()
+	vm.Set("p", &testInspectPoint{1, 2})
+	vm.Set("m", map[string]interface{}{"a": 1})
+	opts := InspectOptions{
+		Renderers: map[reflect.Type]InspectRenderer{
+			reflect.TypeOf(&testInspectPoint{}): func(v interface{}) string {
+				p := v.(*testInspectPoint)
+				return fmt.Sprintf("Point(%d, %d)", p.X, p.Y)
+			},
+		},
+	}
+
+	v, err := vm.RunString(`[p, m]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := vm.Inspect(v, opts); res != "[ Point(1, 2), { a: 1 } ]" {
+		t.Fatalf("Unexpected output: %q", res)
+	}
+}
+
+func TestInspectSparseArray(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`
+	var a = [];
+	a.length = 4294967295;
+	a[5] = 1;
+	a
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := vm.Inspect(v, InspectOptions{}); res != "[ <5 empty items>, 1, <4294967289 empty items> ]" {
+		t.Fatalf("Unexpected output: %q", res)
+	}
+
+	v, err = vm.RunString(`
+	var a = [];
+	a.length = 4294967295;
+	for (var i = 0; i < 300; i += 2) {
+		a[i] = i;
+	}
+	a
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := vm.Inspect(v, InspectOptions{}); !strings.HasSuffix(res, ", 98, <1 empty item>, 100, ... 99 more items ]") {
+		t.Fatalf("Unexpected output: %q", res)
+	}
+}
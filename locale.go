@@ -0,0 +1,597 @@
+package goja
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+type dateLocaleLayouts struct {
+	dateTime, date, time string
+}
+
+var (
+	defaultDateLocaleLayouts = dateLocaleLayouts{
+		dateTime: datetimeLayout_en_GB,
+		date:     dateLayout_en_GB,
+		time:     timeLayout_en_GB,
+	}
+
+	// Keyed either by the full tag or by the base language.
+	dateLocaleLayoutsMap = map[string]dateLocaleLayouts{
+		"en-US": {"1/2/2006, 3:04:05 PM", "1/2/2006", "3:04:05 PM"},
+		"en-GB": {"02/01/2006, 15:04:05", "02/01/2006", "15:04:05"},
+		"de":    {"2.1.2006, 15:04:05", "2.1.2006", "15:04:05"},
+		"es":    {"2/1/2006, 15:04:05", "2/1/2006", "15:04:05"},
+		"fr":    {"02/01/2006 15:04:05", "02/01/2006", "15:04:05"},
+		"it":    {"2/1/2006, 15:04:05", "2/1/2006", "15:04:05"},
+		"ja":    {"2006/1/2 15:04:05", "2006/1/2", "15:04:05"},
+		"nl":    {"2-1-2006 15:04:05", "2-1-2006", "15:04:05"},
+		"pt":    {"02/01/2006, 15:04:05", "02/01/2006", "15:04:05"},
+		"ru":    {"02.01.2006, 15:04:05", "02.01.2006", "15:04:05"},
+		"zh":    {"2006/1/2 15:04:05", "2006/1/2", "15:04:05"},
+	}
+)
+
+// localeInfo holds the locale configuration of a Runtime and the objects derived from it.
+type localeInfo struct {
+	tag         language.Tag
+	collator    *collate.Collator
+	printer     *message.Printer
+	dateLayouts *dateLocaleLayouts
+	timeZone    *time.Location
+}
+
+// SetLocale sets the default locale (a BCP 47 language tag such as "en-US" or "de") for this Runtime. It is
+// consulted by all locale-sensitive built-ins: String.prototype.localeCompare, toLocaleLowerCase and
+// toLocaleUpperCase, and the toLocaleString family of methods of Number, Date and Array.
+// If not called, the root locale ("und") is used, which keeps the locale-independent behaviour of the built-ins.
+// Returns an error if the tag cannot be parsed.
+func (r *Runtime) SetLocale(tag string) error {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return err
+	}
+	r.locale = localeInfo{
+		tag:      t,
+		timeZone: r.locale.timeZone,
+	}
+	return nil
+}
+
+// SetLocaleTimeZone sets the time zone used by Date.prototype.toLocaleString, toLocaleDateString and
+// toLocaleTimeString unless a timeZone option is passed to them. If not called (or if loc is nil), the local
+// time zone is used. The other methods of Date are not affected.
+func (r *Runtime) SetLocaleTimeZone(loc *time.Location) {
+	r.locale.timeZone = loc
+}
+
+// Locale returns the default locale of this Runtime as set by SetLocale().
+func (r *Runtime) Locale() string {
+	return r.locale.tag.String()
+}
+
+func (r *Runtime) hasLocale() bool {
+	return r.locale.tag != language.Und
+}
+
+func (r *Runtime) getCollator() *collate.Collator {
+	if r.locale.collator == nil {
+		r.locale.collator = collate.New(r.locale.tag)
+	}
+	return r.locale.collator
+}
+
+func (r *Runtime) getPrinter() *message.Printer {
+	if r.locale.printer == nil {
+		r.locale.printer = message.NewPrinter(r.locale.tag)
+	}
+	return r.locale.printer
+}
+
+func (r *Runtime) getDateLocaleLayouts() *dateLocaleLayouts {
+	if r.locale.dateLayouts == nil {
+		r.locale.dateLayouts = dateLocaleLayoutsFor(r.locale.tag)
+	}
+	return r.locale.dateLayouts
+}
+
+func dateLocaleLayoutsFor(tag language.Tag) *dateLocaleLayouts {
+	if tag != language.Und {
+		base, _ := tag.Base()
+		region, _ := tag.Region()
+		if l, exists := dateLocaleLayoutsMap[base.String()+"-"+region.String()]; exists {
+			return &l
+		}
+		if l, exists := dateLocaleLayoutsMap[base.String()]; exists {
+			return &l
+		}
+	}
+	return &defaultDateLocaleLayouts
+}
+
+// requestedLocales returns the tags that can be parsed from the locales argument of a locale-sensitive function
+// (a tag or an array of tags).
+func (r *Runtime) requestedLocales(locales Value) []language.Tag {
+	var values []Value
+	if o, ok := locales.(*Object); ok {
+		values = r.toValueArray(o)
+	} else if locales != _undefined {
+		values = []Value{locales}
+	}
+	var tags []language.Tag
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if parsed, err := language.Parse(v.String()); err == nil {
+			tags = append(tags, parsed)
+		}
+	}
+	return tags
+}
+
+// requestedLocale returns the first tag that can be parsed from the locales argument, or false if there is none.
+func (r *Runtime) requestedLocale(locales Value) (language.Tag, bool) {
+	if tags := r.requestedLocales(locales); len(tags) > 0 {
+		return tags[0], true
+	}
+	return language.Und, false
+}
+
+var (
+	dateTimeDateOptions  = []string{"weekday", "year", "month", "day"}
+	dateTimeTimeOptions  = []string{"hour", "minute", "second"}
+	dateTimeStyles       = []string{"full", "long", "medium", "short"}
+	dateTimeLayoutTokens = []struct {
+		token, typ string
+	}{
+		// longest first
+		{"2006", "year"}, {"01", "month"}, {"02", "day"}, {"15", "hour"}, {"04", "minute"}, {"05", "second"},
+		{"PM", "dayPeriod"}, {"1", "month"}, {"2", "day"}, {"3", "hour"},
+	}
+
+	// dateTimeComponentStyles are the allowed values of the component options.
+	dateTimeComponentStyles = map[string][]string{
+		"weekday": {"narrow", "short", "long"},
+		"year":    {"numeric", "2-digit"},
+		"month":   {"numeric", "2-digit", "narrow", "short", "long"},
+		"day":     {"numeric", "2-digit"},
+		"hour":    {"numeric", "2-digit"},
+		"minute":  {"numeric", "2-digit"},
+		"second":  {"numeric", "2-digit"},
+	}
+	// dateTimeTwoDigitLayouts are the Go layouts of the components with the "2-digit" style.
+	dateTimeTwoDigitLayouts = map[string]string{
+		"year": "06", "month": "01", "day": "02", "hour": "15", "minute": "04", "second": "05",
+	}
+	// dateTimeUnsupportedOptions are the allowed values of the options of Intl.DateTimeFormat which cannot be
+	// honoured, the components are left out.
+	dateTimeUnsupportedOptions = []struct {
+		name    string
+		allowed []string
+	}{
+		{"dayPeriod", []string{"narrow", "short", "long"}},
+		{"era", []string{"narrow", "short", "long"}},
+		{"timeZoneName", []string{"short", "long", "shortOffset", "longOffset", "shortGeneric", "longGeneric"}},
+	}
+)
+
+// dateTimePart is a part of a formatted date as returned by Intl.DateTimeFormat.prototype.formatToParts(). For
+// the layout the value is the Go layout of the component.
+type dateTimePart struct {
+	typ, value string
+	narrow     bool // only the first letter of the month or weekday name is shown
+}
+
+// dateTimeFormat is a resolved Intl.DateTimeFormat: the layout built from the requested components and a time
+// zone.
+type dateTimeFormat struct {
+	locale  language.Tag
+	hasDate bool
+	hasTime bool
+	parts   []dateTimePart
+	loc     *time.Location
+}
+
+// splitDateLayout splits a Go time layout into the components and the literals.
+func splitDateLayout(layout string) []dateTimePart {
+	var parts []dateTimePart
+	literalStart := 0
+	for i := 0; i < len(layout); {
+		found := false
+		for _, t := range dateTimeLayoutTokens {
+			if strings.HasPrefix(layout[i:], t.token) {
+				if literalStart < i {
+					parts = append(parts, dateTimePart{typ: "literal", value: layout[literalStart:i]})
+				}
+				parts = append(parts, dateTimePart{typ: t.typ, value: t.token})
+				i += len(t.token)
+				literalStart = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			i++
+		}
+	}
+	if literalStart < len(layout) {
+		parts = append(parts, dateTimePart{typ: "literal", value: layout[literalStart:]})
+	}
+	return parts
+}
+
+// filterDateParts keeps the components of a locale layout which are in components (the day period goes with the
+// hour) in the requested style. A kept component is separated from the previous one by the literal that precedes
+// it in the layout.
+func filterDateParts(parts []dateTimePart, components map[string]string) []dateTimePart {
+	var res []dateTimePart
+	literal := ""
+	for _, p := range parts {
+		if p.typ == "literal" {
+			literal = p.value
+			continue
+		}
+		style, keep := components[p.typ]
+		if p.typ == "dayPeriod" {
+			_, keep = components["hour"]
+		}
+		if keep {
+			if len(res) > 0 && literal != "" {
+				res = append(res, dateTimePart{typ: "literal", value: literal})
+			}
+			if style == "2-digit" {
+				if p.typ == "hour" && p.value == "3" {
+					p.value = "03"
+				} else {
+					p.value = dateTimeTwoDigitLayouts[p.typ]
+				}
+			}
+			res = append(res, p)
+		}
+		literal = ""
+	}
+	return res
+}
+
+// setHour12 switches the parts of a time layout to the 12-hour or the 24-hour clock.
+func setHour12(parts []dateTimePart, hour12 bool) []dateTimePart {
+	res := make([]dateTimePart, 0, len(parts)+2)
+	hasDayPeriod := false
+	for i, p := range parts {
+		switch p.typ {
+		case "hour":
+			if hour12 {
+				p.value = "3"
+			} else {
+				p.value = "15"
+			}
+		case "dayPeriod":
+			hasDayPeriod = true
+			if !hour12 {
+				continue
+			}
+		case "literal":
+			if !hour12 && i+1 < len(parts) && parts[i+1].typ == "dayPeriod" {
+				continue
+			}
+		}
+		res = append(res, p)
+	}
+	if hour12 && !hasDayPeriod {
+		res = append(res, dateTimePart{typ: "literal", value: " "}, dateTimePart{typ: "dayPeriod", value: "PM"})
+	}
+	return res
+}
+
+// isEnglishLocale reports whether the month and weekday names (which are only available in English) can be used
+// for the locale. The root locale uses the English names.
+func isEnglishLocale(tag language.Tag) bool {
+	if tag == language.Und {
+		return true
+	}
+	base, _ := tag.Base()
+	return base.String() == "en"
+}
+
+// englishTextDateParts returns the parts of a date with the month name, in the US order (January 2, 2006) or the
+// order used by the other English locales (2 January 2006).
+func englishTextDateParts(tag language.Tag, components map[string]string) []dateTimePart {
+	month := dateTimePart{typ: "month", value: "January"}
+	switch components["month"] {
+	case "short":
+		month.value = "Jan"
+	case "narrow":
+		month.narrow = true
+	}
+	var day, year []dateTimePart
+	if style, ok := components["day"]; ok {
+		day = []dateTimePart{{typ: "day", value: "2"}}
+		if style == "2-digit" {
+			day[0].value = "02"
+		}
+	}
+	if style, ok := components["year"]; ok {
+		year = []dateTimePart{{typ: "year", value: "2006"}}
+		if style == "2-digit" {
+			year[0].value = "06"
+		}
+	}
+
+	region, _ := tag.Region()
+	if tag == language.Und || region.String() == "US" {
+		parts := []dateTimePart{month}
+		if day != nil {
+			parts = append(parts, dateTimePart{typ: "literal", value: " "})
+			parts = append(parts, day...)
+		}
+		if year != nil {
+			if day != nil {
+				parts = append(parts, dateTimePart{typ: "literal", value: ", "})
+			} else {
+				parts = append(parts, dateTimePart{typ: "literal", value: " "})
+			}
+			parts = append(parts, year...)
+		}
+		return parts
+	}
+	var parts []dateTimePart
+	if day != nil {
+		parts = append(day, dateTimePart{typ: "literal", value: " "})
+	}
+	parts = append(parts, month)
+	if year != nil {
+		parts = append(parts, dateTimePart{typ: "literal", value: " "})
+		parts = append(parts, year...)
+	}
+	return parts
+}
+
+// resolveDateTimeFormat resolves the locales and options arguments of Intl.DateTimeFormat and the Date locale
+// methods: the first locale that can be parsed overrides the Runtime locale and the timeZone option (an IANA time
+// zone name) overrides the Runtime time zone. The layout is built from the component options (e.g. year: "numeric",
+// month: "long") by keeping the requested components of the locale layout, or from dateStyle and timeStyle (which
+// show all the numeric components of the date and/or time), required and defaults are as in ToDateTimeOptions().
+// The hour12 and hourCycle options select the clock. The month names are only available for English, the other
+// locales show the month as a number and the weekday name in English. The components which cannot be shown (e.g.
+// era or timeZoneName) are validated and left out.
+func (r *Runtime) resolveDateTimeFormat(locales, options Value, required, defaults string) *dateTimeFormat {
+	f := &dateTimeFormat{
+		locale: r.locale.tag,
+		loc:    r.locale.timeZone,
+	}
+	layouts := r.getDateLocaleLayouts()
+	if tag, ok := r.requestedLocale(locales); ok {
+		f.locale = tag
+		layouts = dateLocaleLayoutsFor(tag)
+	}
+
+	o, _ := options.(*Object)
+	var hour12 *bool
+	if o != nil {
+		if tz := o.self.getStr("timeZone"); tz != nil && tz != _undefined {
+			l, err := time.LoadLocation(tz.String())
+			if err != nil || tz.String() == "" || tz.String() == "Local" {
+				panic(r.newError(r.global.RangeError, "Invalid time zone specified: %s", tz.String()))
+			}
+			f.loc = l
+		}
+		for _, opt := range dateTimeUnsupportedOptions {
+			r.getStringOption(o, opt.name, opt.allowed, "")
+		}
+		r.getNumberOption(o, "fractionalSecondDigits", 1, 3, 0)
+		if v := o.self.getStr("hour12"); v != nil && v != _undefined {
+			b := v.ToBoolean()
+			hour12 = &b
+		}
+	}
+	// h11 and h24 are shown as h12 and h23
+	switch r.getStringOption(o, "hourCycle", []string{"h11", "h12", "h23", "h24"}, "") {
+	case "h11", "h12":
+		if hour12 == nil {
+			b := true
+			hour12 = &b
+		}
+	case "h23", "h24":
+		if hour12 == nil {
+			b := false
+			hour12 = &b
+		}
+	}
+
+	components := make(map[string]string)
+	for _, names := range [][]string{dateTimeDateOptions, dateTimeTimeOptions} {
+		for _, name := range names {
+			if style := r.getStringOption(o, name, dateTimeComponentStyles[name], ""); style != "" {
+				components[name] = style
+			}
+		}
+	}
+	has := func(names []string) bool {
+		for _, name := range names {
+			if _, ok := components[name]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	f.hasDate = has(dateTimeDateOptions)
+	f.hasTime = has(dateTimeTimeOptions)
+	dateStyle := r.getStringOption(o, "dateStyle", dateTimeStyles, "")
+	timeStyle := r.getStringOption(o, "timeStyle", dateTimeStyles, "")
+	if dateStyle != "" || timeStyle != "" {
+		if f.hasDate || f.hasTime {
+			panic(r.NewTypeError("dateStyle and timeStyle can't be combined with the date and time component options"))
+		}
+		f.hasDate, f.hasTime = dateStyle != "", timeStyle != ""
+	}
+	needDefaults := dateStyle == "" && timeStyle == ""
+	if required == "date" || required == "any" {
+		needDefaults = needDefaults && !f.hasDate
+	}
+	if required == "time" || required == "any" {
+		needDefaults = needDefaults && !f.hasTime
+	}
+	if dateStyle != "" || needDefaults && (defaults == "date" || defaults == "all") {
+		f.hasDate = true
+		components["year"], components["month"], components["day"] = "numeric", "numeric", "numeric"
+	}
+	if timeStyle != "" || needDefaults && (defaults == "time" || defaults == "all") {
+		f.hasTime = true
+		components["hour"], components["minute"], components["second"] = "numeric", "numeric", "numeric"
+	}
+
+	var date, tm []dateTimePart
+	if style := components["month"]; style == "narrow" || style == "short" || style == "long" {
+		if isEnglishLocale(f.locale) {
+			date = englishTextDateParts(f.locale, components)
+		} else {
+			components["month"] = "numeric"
+			date = filterDateParts(splitDateLayout(layouts.date), components)
+		}
+	} else {
+		date = filterDateParts(splitDateLayout(layouts.date), components)
+	}
+	if style, ok := components["weekday"]; ok {
+		weekday := dateTimePart{typ: "weekday", value: "Monday", narrow: style == "narrow"}
+		if style == "short" {
+			weekday.value = "Mon"
+		}
+		if len(date) > 0 {
+			date = append([]dateTimePart{weekday, {typ: "literal", value: ", "}}, date...)
+		} else {
+			date = []dateTimePart{weekday}
+		}
+	}
+	timeParts := splitDateLayout(layouts.time)
+	if hour12 != nil {
+		timeParts = setHour12(timeParts, *hour12)
+	}
+	tm = filterDateParts(timeParts, components)
+
+	f.parts = date
+	if len(date) > 0 && len(tm) > 0 {
+		sep := strings.TrimSuffix(strings.TrimPrefix(layouts.dateTime, layouts.date), layouts.time)
+		f.parts = append(f.parts, dateTimePart{typ: "literal", value: sep})
+	}
+	f.parts = append(f.parts, tm...)
+	return f
+}
+
+func (f *dateTimeFormat) formatToParts(t time.Time) []dateTimePart {
+	if f.loc != nil {
+		t = t.In(f.loc)
+	}
+	parts := make([]dateTimePart, len(f.parts))
+	for i, p := range f.parts {
+		parts[i] = dateTimePart{typ: p.typ, value: p.value}
+		if p.typ != "literal" {
+			parts[i].value = t.Format(p.value)
+			if p.narrow {
+				parts[i].value = parts[i].value[:1]
+			}
+		}
+	}
+	return parts
+}
+
+func (f *dateTimeFormat) format(t time.Time) string {
+	var b strings.Builder
+	for _, p := range f.formatToParts(t) {
+		b.WriteString(p.value)
+	}
+	return b.String()
+}
+
+func (f *dateTimeFormat) hour12() bool {
+	for _, p := range f.parts {
+		if p.typ == "dayPeriod" {
+			return true
+		}
+	}
+	return false
+}
+
+// timeZone returns the IANA name of the time zone. The name of the local time zone is taken from the TZ
+// environment variable or the /etc/localtime link, if it cannot be determined (or the zone has no IANA name)
+// the UTC offset is returned, e.g. "+02:00".
+func (f *dateTimeFormat) timeZone() string {
+	loc := f.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	name := loc.String()
+	if name == "Local" {
+		name = localTimeZoneName()
+	}
+	if name == "UTC" {
+		return name
+	}
+	if name != "" && name != "Local" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	_, offset := time.Now().In(loc).Zone()
+	if offset == 0 {
+		return "UTC"
+	}
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// localTimeZoneName returns the IANA name of the local time zone, or "" if it's not known.
+func localTimeZoneName() string {
+	if tz, ok := os.LookupEnv("TZ"); ok {
+		if tz == "" {
+			return "UTC"
+		}
+		return strings.TrimPrefix(tz, ":")
+	}
+	if p, err := os.Readlink("/etc/localtime"); err == nil {
+		if i := strings.Index(p, "zoneinfo/"); i >= 0 {
+			return p[i+len("zoneinfo/"):]
+		}
+	}
+	return ""
+}
+
+// localeFormatDate formats the time as Date.prototype.toLocaleString() and alike do it, see
+// resolveDateTimeFormat().
+func (r *Runtime) localeFormatDate(t time.Time, locales, options Value, required, defaults string) valueString {
+	return newStringValue(r.resolveDateTimeFormat(locales, options, required, defaults).format(t))
+}
+
+func (r *Runtime) localeLower(s valueString) valueString {
+	if !r.hasLocale() {
+		return s.toLower()
+	}
+	return newStringValue(cases.Lower(r.locale.tag).String(s.String()))
+}
+
+func (r *Runtime) localeUpper(s valueString) valueString {
+	if !r.hasLocale() {
+		return s.toUpper()
+	}
+	return newStringValue(cases.Upper(r.locale.tag).String(s.String()))
+}
+
+func (r *Runtime) localeFormatNumber(num float64) valueString {
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return floatToValue(num).ToString()
+	}
+	return newStringValue(r.getPrinter().Sprint(number.Decimal(num)))
+}
@@ -0,0 +1,112 @@
+package goja
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetLocale(t *testing.T) {
+	l := time.Local
+	defer func() {
+		time.Local = l
+	}()
+	time.Local = time.UTC
+
+	const SCRIPT = `
+	var d = new Date(2016, 8, 1, 15, 23, 45);
+	assert.sameValue(d.toLocaleDateString(), "1.9.2016", "toLocaleDateString");
+	assert.sameValue(d.toLocaleTimeString(), "15:23:45", "toLocaleTimeString");
+	assert.sameValue(d.toLocaleString(), "1.9.2016, 15:23:45", "toLocaleString");
+	assert.sameValue((1234567.891).toLocaleString(), "1.234.567,891", "Number.prototype.toLocaleString");
+	assert.sameValue([1000, 2.5].toLocaleString(), "1.000,2,5", "Array.prototype.toLocaleString");
+	assert.sameValue("ä".localeCompare("b"), -1, "localeCompare");
+	`
+
+	vm := New()
+	if err := vm.SetLocale("de"); err != nil {
+		t.Fatal(err)
+	}
+	if l := vm.Locale(); l != "de" {
+		t.Fatalf("Unexpected locale: %s", l)
+	}
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetLocaleCollation(t *testing.T) {
+	vm := New()
+	if err := vm.SetLocale("sv"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := vm.RunString(`"ä".localeCompare("z")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.SameAs(intToValue(1)) {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestSetLocaleCase(t *testing.T) {
+	vm := New()
+	res, err := vm.RunString(`"i".toLocaleUpperCase()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != "I" {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+
+	if err := vm.SetLocale("tr"); err != nil {
+		t.Fatal(err)
+	}
+	res, err = vm.RunString(`"i".toLocaleUpperCase()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != "İ" {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestSetLocaleInvalid(t *testing.T) {
+	vm := New()
+	if err := vm.SetLocale("not a locale"); err == nil {
+		t.Fatal("Expected error")
+	}
+	if l := vm.Locale(); l != "und" {
+		t.Fatalf("Unexpected locale: %s", l)
+	}
+}
+
+func TestLocaleDateFormat(t *testing.T) {
+	const SCRIPT = `
+	var d = new Date(Date.UTC(2016, 8, 1, 23, 5, 9));
+	assert.sameValue(d.toLocaleString(), "09/02/2016, 08:05:09", "configured time zone");
+	assert.sameValue(d.toLocaleDateString("en-US"), "9/2/2016", "locales argument");
+	assert.sameValue(d.toLocaleTimeString(["not a locale", "en-US"]), "8:05:09 AM", "locales list");
+	assert.sameValue(d.toLocaleString("de", {timeZone: "America/New_York"}), "1.9.2016, 19:05:09", "timeZone option");
+	assert.sameValue(d.toLocaleTimeString(undefined, {timeZone: "UTC"}), "23:05:09", "UTC");
+	var thrown;
+	try {
+		d.toLocaleString(undefined, {timeZone: "Nowhere/Special"});
+	} catch (e) {
+		thrown = e;
+	}
+	assert(thrown instanceof RangeError, "invalid time zone");
+	`
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	vm := New()
+	vm.SetLocaleTimeZone(loc)
+	_, err = vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
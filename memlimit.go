@@ -0,0 +1,266 @@
+package goja
+
+import "fmt"
+
+// The approximate sizes used by the memory accounting, see Runtime.SetMemoryLimit().
+const (
+	// memObjectSize is the size of an object without properties: the Object, its implementation and the property map.
+	memObjectSize = 160
+	// memPropertySize is the size of an own property excluding its name and value: the map entry and the propNames
+	// element.
+	memPropertySize = 48
+	// memValueSize is the size of a value in an array, a stash or a property, which is an interface.
+	memValueSize = 16
+	// memStringSize is the size of a string header, the characters are counted separately.
+	memStringSize = 16
+
+	// memCheckFraction determines how much memory can be allocated after a measurement before the next one:
+	// limit/memCheckFraction.
+	memCheckFraction = 8
+)
+
+// MemoryLimitError is the value of the *InterruptedError returned when a script exceeds the limit set with
+// Runtime.SetMemoryLimit().
+type MemoryLimitError struct {
+	// Limit is the limit in bytes.
+	Limit int64
+	// Usage is the estimated memory usage in bytes which exceeded the limit, including the allocation that was
+	// about to be made.
+	Usage int64
+}
+
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("memory limit of %d bytes exceeded (%d bytes)", e.Limit, e.Usage)
+}
+
+type memoryLimit struct {
+	limit int64
+	// used is the memory usage measured the last time.
+	used int64
+	// allocated is the memory allocated since the last measurement.
+	allocated int64
+	// backoff is the amount of memory that has to be allocated after a measurement before the next one, so that
+	// allocating garbage near the limit doesn't measure the whole heap on every allocation.
+	backoff int64
+}
+
+// SetMemoryLimit limits the approximate amount of memory used by the values reachable by the scripts to the given
+// number of bytes, 0 removes the limit. The limit may be exceeded by up to 1/8 of it. When it is exceeded the running
+// script stops with an *InterruptedError holding a *MemoryLimitError, which the script cannot catch, and the Runtime
+// stays interrupted until ClearInterrupt() is called.
+func (r *Runtime) SetMemoryLimit(bytes int64) {
+	if bytes <= 0 {
+		r.mem = nil
+		return
+	}
+	r.mem = &memoryLimit{
+		limit: bytes,
+		used:  r.measureMemory(),
+	}
+}
+
+// MemoryUsage returns the approximate amount of memory used by the values reachable from the global object and
+// the running scripts, in bytes, as it's measured for SetMemoryLimit().
+func (r *Runtime) MemoryUsage() int64 {
+	return r.measureMemory()
+}
+
+// trackMemory records an allocation of size bytes which has been made. If the limit is exceeded the running script
+// is stopped right away.
+func (r *Runtime) trackMemory(size int64) {
+	m := r.mem
+	if m == nil {
+		return
+	}
+	m.allocated += size
+	if m.used+m.allocated > m.limit && m.allocated >= m.backoff {
+		if err := r.checkMemoryLimit(0); err != nil {
+			panic(&InterruptedError{iface: err})
+		}
+	}
+}
+
+// reserveMemory records an allocation of size bytes which is about to be made, and stops the script right away if
+// it exceeds the limit.
+func (r *Runtime) reserveMemory(size int64) {
+	r.reserveMemoryHeld(size, 0)
+}
+
+// reserveMemoryHeld is reserveMemory() for the growth of a buffer of which held bytes have already been reserved.
+// The buffer is not reachable by the scripts, so a measurement doesn't see it and it has to be added back.
+func (r *Runtime) reserveMemoryHeld(size, held int64) {
+	m := r.mem
+	if m == nil {
+		return
+	}
+	if m.used+m.allocated+size > m.limit && (m.allocated+size >= m.backoff || m.used+held+size > m.limit) {
+		if err := r.checkMemoryLimit(held + size); err != nil {
+			panic(&InterruptedError{iface: err})
+		}
+		m.allocated = held
+	}
+	m.allocated += size
+}
+
+// memoryCharger records the growth of a buffer built by a builtin with reserveMemory(), so that a script building
+// a large string is stopped before the string is complete.
+type memoryCharger struct {
+	r       *Runtime
+	charged int
+}
+
+// charge records the buffer growing to size bytes.
+func (c *memoryCharger) charge(size int) {
+	if size > c.charged {
+		c.r.reserveMemoryHeld(int64(size-c.charged), int64(c.charged))
+		c.charged = size
+	}
+}
+
+func (r *Runtime) checkMemoryLimit(size int64) *MemoryLimitError {
+	m := r.mem
+	m.used = r.measureMemory()
+	m.allocated = 0
+	m.backoff = m.limit / memCheckFraction
+	if usage := m.used + size; usage > m.limit {
+		err := &MemoryLimitError{
+			Limit: m.limit,
+			Usage: usage,
+		}
+		r.vm.Interrupt(err)
+		return err
+	}
+	return nil
+}
+
+// stringMemorySize returns the size of a string of the given type and length.
+func stringMemorySize(s valueString, length int64) int64 {
+	if _, ok := s.(unicodeString); ok {
+		return memStringSize + 2*length
+	}
+	return memStringSize + length
+}
+
+// baseObjectImpl is implemented by all the object implementations based on baseObject.
+type baseObjectImpl interface {
+	memBase() *baseObject
+}
+
+func (o *baseObject) memBase() *baseObject {
+	return o
+}
+
+type memoryMeasurer struct {
+	objects map[*Object]bool
+	stashes map[*stash]bool
+	size    int64
+}
+
+func (r *Runtime) measureMemory() int64 {
+	m := &memoryMeasurer{
+		objects: make(map[*Object]bool),
+		stashes: make(map[*stash]bool),
+	}
+	vm := r.vm
+	m.object(r.globalObject)
+	if vm.sp <= len(vm.stack) {
+		for _, v := range vm.stack[:vm.sp] {
+			m.value(v)
+		}
+	}
+	m.stash(vm.stash)
+	for i := range vm.callStack {
+		m.stash(vm.callStack[i].stash)
+	}
+	for _, item := range vm.iterStack {
+		m.value(item.val)
+	}
+	return m.size
+}
+
+func (m *memoryMeasurer) value(v Value) {
+	switch v := v.(type) {
+	case nil:
+	case *Object:
+		m.object(v)
+	case valueString:
+		m.size += stringMemorySize(v, v.length())
+	case *valueProperty:
+		m.property(v)
+	case *mappedProperty:
+		m.property(&v.valueProperty)
+	default:
+		m.size += memValueSize
+	}
+}
+
+func (m *memoryMeasurer) property(p *valueProperty) {
+	m.value(p.value)
+	if p.getterFunc != nil {
+		m.object(p.getterFunc)
+	}
+	if p.setterFunc != nil {
+		m.object(p.setterFunc)
+	}
+}
+
+func (m *memoryMeasurer) object(o *Object) {
+	if o == nil || m.objects[o] {
+		return
+	}
+	m.objects[o] = true
+	if _, ok := o.self.(*lazyObject); ok {
+		// not created yet
+		return
+	}
+	m.size += memObjectSize
+	switch impl := o.self.(type) {
+	case *arrayObject:
+		m.size += int64(cap(impl.values)) * memValueSize
+		for _, v := range impl.values {
+			m.value(v)
+		}
+	case *sparseArrayObject:
+		m.size += int64(cap(impl.items)) * (memValueSize + 8)
+		for _, item := range impl.items {
+			m.value(item.value)
+		}
+	case *objectArrayBuffer:
+		m.size += int64(len(impl.data))
+	case *funcObject:
+		m.stash(impl.stash)
+	case *stringObject:
+		m.value(impl.value)
+	case *primitiveValueObject:
+		m.value(impl.pValue)
+	case *regexpObject:
+		m.value(impl.source)
+	}
+	if b, ok := o.self.(baseObjectImpl); ok {
+		base := b.memBase()
+		m.object(base.prototype)
+		for name, v := range base.values {
+			m.size += memPropertySize + int64(len(name))
+			m.value(v)
+		}
+	}
+}
+
+func (m *memoryMeasurer) stash(s *stash) {
+	for ; s != nil && !m.stashes[s]; s = s.outer {
+		m.stashes[s] = true
+		m.size += int64(len(s.values)+len(s.extraArgs)) * memValueSize
+		for _, v := range s.values {
+			m.value(v)
+		}
+		for _, v := range s.extraArgs {
+			m.value(v)
+		}
+		for name := range s.names {
+			m.size += memPropertySize + int64(len(name))
+		}
+		if b, ok := s.obj.(baseObjectImpl); ok {
+			m.object(b.memBase().val)
+		}
+	}
+}
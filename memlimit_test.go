@@ -0,0 +1,186 @@
+package goja
+
+import (
+	"testing"
+	"time"
+)
+
+func expectMemoryLimitError(t *testing.T, err error) *MemoryLimitError {
+	t.Helper()
+	intr, ok := err.(*InterruptedError)
+	if !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+	memErr, ok := intr.Value().(*MemoryLimitError)
+	if !ok {
+		t.Fatalf("Unexpected value: %v (%T)", intr.Value(), intr.Value())
+	}
+	if memErr.Usage <= memErr.Limit {
+		t.Fatalf("Usage %d does not exceed the limit %d", memErr.Usage, memErr.Limit)
+	}
+	return memErr
+}
+
+func TestMemoryUsage(t *testing.T) {
+	vm := New()
+	base := vm.MemoryUsage()
+	if base <= 0 {
+		t.Fatalf("Unexpected baseline: %d", base)
+	}
+	_, err := vm.RunString(`
+	var a = [];
+	for (var i = 0; i < 1000; i++) {
+		a.push({n: i, s: "item " + i});
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := vm.MemoryUsage()
+	if used-base < 1000*memObjectSize {
+		t.Fatalf("Unexpected usage: %d (baseline %d)", used, base)
+	}
+	_, err = vm.RunString("a = null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := vm.MemoryUsage(); u >= used {
+		t.Fatalf("The usage has not decreased: %d (was %d)", u, used)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	vm := New()
+	limit := vm.MemoryUsage() + 1<<20
+	vm.SetMemoryLimit(limit)
+
+	// garbage doesn't count
+	_, err := vm.RunString(`
+	for (var i = 0; i < 100000; i++) {
+		var o = {n: i, s: "item " + i};
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = vm.RunString(`
+	var caught = false;
+	var a = [];
+	try {
+		for (;;) {
+			a.push({n: a.length});
+		}
+	} catch (e) {
+		caught = true;
+	}
+	`)
+	memErr := expectMemoryLimitError(t, err)
+	if memErr.Limit != limit {
+		t.Fatalf("Unexpected limit: %d", memErr.Limit)
+	}
+	if vm.Get("caught").ToBoolean() {
+		t.Fatal("The error was caught by the script")
+	}
+
+	_, err = vm.RunString("1")
+	expectMemoryLimitError(t, err)
+
+	vm.SetMemoryLimit(0)
+	vm.ClearInterrupt()
+	res, err := vm.RunString("a = null; 1 + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.StrictEquals(intToValue(2)) {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestMemoryLimitStrings(t *testing.T) {
+	vm := New()
+	vm.SetMemoryLimit(vm.MemoryUsage() + 1<<20)
+	_, err := vm.RunString(`
+	var s = "x", parts = [];
+	for (;;) {
+		s += s;
+		parts.push(s);
+	}
+	`)
+	expectMemoryLimitError(t, err)
+
+	vm.ClearInterrupt()
+	_, err = vm.RunString(`"x".repeat(1 << 24)`)
+	expectMemoryLimitError(t, err)
+}
+
+func TestMemoryLimitArrayBuffer(t *testing.T) {
+	vm := New()
+	vm.initTypedArrays()
+	vm.SetMemoryLimit(vm.MemoryUsage() + 1<<20)
+	_, err := vm.RunString(`
+	var b = new ArrayBuffer(1 << 16);
+	try {
+		new ArrayBuffer(1 << 30);
+	} catch (e) {
+	}
+	`)
+	expectMemoryLimitError(t, err)
+}
+
+func TestMemoryLimitGarbageNearLimit(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var a = [];
+	for (var i = 0; i < 5000; i++) {
+		a.push({n: i});
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.SetMemoryLimit(vm.MemoryUsage() + 4096)
+
+	// every allocation is over the limit until the garbage is measured, which must not happen every time
+	start := time.Now()
+	_, err = vm.RunString(`
+	for (var i = 0; i < 20000; i++) {
+		var o = {x: i};
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("Took too long: %v", d)
+	}
+}
+
+func TestMemoryLimitBuiltinStrings(t *testing.T) {
+	for _, script := range []string{
+		`var a = []; a[1 << 20] = 1; a.join("xx")`,
+		`var s = "x".repeat(1 << 10), a = []; for (var i = 0; i < 100; i++) a.push(s); s.concat.apply(s, a).concat(a.join(""))`,
+		`"x".repeat(1 << 14).replace(/x/g, "yyyyyyyyyy")`,
+		`var a = []; for (var i = 0; i < 4000; i++) a.push("item number " + i); JSON.stringify({a: a, b: a, c: a})`,
+	} {
+		vm := New()
+		vm.SetMemoryLimit(vm.MemoryUsage() + 100<<10)
+		_, err := vm.RunString(script)
+		expectMemoryLimitError(t, err)
+	}
+}
+
+func TestMemoryLimitBuiltinArrays(t *testing.T) {
+	for _, script := range []string{
+		`var a = []; a[1e8] = 1; a.fill(0, 0)`,
+		`var a = []; a[3e7] = 1; a.map(function(v) { return v; })`,
+		`var a = []; a[1e6] = 1; a.fill(0, 0, 1e6); [].concat(a, a, a, a)`,
+		`var a = []; a[2e6] = 1; a.fill(0, 0, 2e6); a.splice(0, 2e6)`,
+		`Array.from({length: 3e7})`,
+	} {
+		vm := New()
+		vm.SetMemoryLimit(50 << 20)
+		_, err := vm.RunString(script)
+		expectMemoryLimitError(t, err)
+	}
+}
@@ -0,0 +1,226 @@
+package goja
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+)
+
+// The conversions of Number::toString() and Number.prototype.toFixed(), toExponential() and toPrecision(). They
+// work on the decimal digits of the value: either the shortest ones that round-trip (as produced by strconv), or
+// the exact ones rounded to the requested precision with the ties rounded up, as the specification requires.
+
+// decimalDigits returns the decimal digits (without trailing zeros) and the exponent of x, which must be finite
+// and positive, so that x = d1.d2d3... * 10^exp. If exact is false the digits are the shortest ones that
+// round-trip, otherwise they are the exact value of x.
+func decimalDigits(x float64, exact bool) ([]byte, int) {
+	prec := -1
+	if exact {
+		// a float64 has at most 767 significant decimal digits
+		prec = 767
+	}
+	s := strconv.AppendFloat(nil, x, 'e', prec, 64)
+	e := bytes.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(string(s[e+1:]))
+	digits := make([]byte, 0, e)
+	digits = append(digits, s[0])
+	if e > 1 {
+		digits = append(digits, s[2:e]...)
+	}
+	return bytes.TrimRight(digits, "0"), exp
+}
+
+// roundDigits rounds the digits returned by decimalDigits() to n significant digits, padding them with zeros if
+// needed. Ties are rounded up. If n is 0 the result is either zero (no digits) or a 1 at the position following
+// the last digit kept; if n is negative the result is zero.
+func roundDigits(digits []byte, exp, n int) ([]byte, int) {
+	if n < 0 {
+		return nil, exp
+	}
+	res := make([]byte, n)
+	for i := range res {
+		if i < len(digits) {
+			res[i] = digits[i]
+		} else {
+			res[i] = '0'
+		}
+	}
+	if n < len(digits) && digits[n] >= '5' {
+		i := n - 1
+		for ; i >= 0 && res[i] == '9'; i-- {
+			res[i] = '0'
+		}
+		if i >= 0 {
+			res[i]++
+		} else {
+			res = append([]byte{'1'}, res...)
+			if n > 0 {
+				res = res[:n]
+			}
+			exp++
+		}
+	} else if n == 0 {
+		return nil, exp
+	}
+	return res, exp
+}
+
+func appendExponent(b []byte, exp int) []byte {
+	b = append(b, 'e')
+	if exp < 0 {
+		b = append(b, '-')
+		exp = -exp
+	} else {
+		b = append(b, '+')
+	}
+	return strconv.AppendInt(b, int64(exp), 10)
+}
+
+// appendExponential appends the digits in the exponential notation, i.e. d1.d2d3...e+exp
+func appendExponential(b, digits []byte, exp int) []byte {
+	b = append(b, digits[0])
+	if len(digits) > 1 {
+		b = append(b, '.')
+		b = append(b, digits[1:]...)
+	}
+	return appendExponent(b, exp)
+}
+
+func appendZeros(b []byte, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, '0')
+	}
+	return b
+}
+
+// numberToString implements Number::toString(x) with radix 10.
+func numberToString(x float64) string {
+	switch {
+	case math.IsNaN(x):
+		return "NaN"
+	case x == 0:
+		return "0"
+	case math.IsInf(x, 1):
+		return "Infinity"
+	case math.IsInf(x, -1):
+		return "-Infinity"
+	}
+	var b []byte
+	if x < 0 {
+		b = append(b, '-')
+		x = -x
+	}
+	digits, exp := decimalDigits(x, false)
+	k, n := len(digits), exp+1
+	switch {
+	case k <= n && n <= 21:
+		b = append(b, digits...)
+		b = appendZeros(b, n-k)
+	case 0 < n && n <= 21:
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:]...)
+	case -6 < n && n <= 0:
+		b = append(b, '0', '.')
+		b = appendZeros(b, -n)
+		b = append(b, digits...)
+	default:
+		b = appendExponential(b, digits, exp)
+	}
+	return string(b)
+}
+
+// numberToFixed implements Number.prototype.toFixed() for a finite x with |x| < 1e21 and 0 <= f.
+func numberToFixed(x float64, f int) string {
+	var b []byte
+	if x < 0 {
+		b = append(b, '-')
+		x = -x
+	}
+	var digits []byte
+	exp := -1
+	if x != 0 {
+		digits, exp = decimalDigits(x, true)
+		digits, exp = roundDigits(digits, exp, exp+1+f)
+	}
+	if len(digits) == 0 {
+		exp = -1
+	}
+	digit := func(i int) byte {
+		if i >= 0 && i < len(digits) {
+			return digits[i]
+		}
+		return '0'
+	}
+	if exp < 0 {
+		b = append(b, '0')
+	} else {
+		for i := 0; i <= exp; i++ {
+			b = append(b, digit(i))
+		}
+	}
+	if f > 0 {
+		b = append(b, '.')
+		for i := 1; i <= f; i++ {
+			b = append(b, digit(exp+i))
+		}
+	}
+	return string(b)
+}
+
+// numberToExponential implements Number.prototype.toExponential() for a finite x, f is the number of the
+// fraction digits or -1 if it is undefined.
+func numberToExponential(x float64, f int) string {
+	var b []byte
+	if x < 0 {
+		b = append(b, '-')
+		x = -x
+	}
+	var digits []byte
+	var exp int
+	switch {
+	case x == 0:
+		if f < 0 {
+			f = 0
+		}
+		digits = appendZeros(nil, f+1)
+	case f < 0:
+		digits, exp = decimalDigits(x, false)
+	default:
+		digits, exp = decimalDigits(x, true)
+		digits, exp = roundDigits(digits, exp, f+1)
+	}
+	return string(appendExponential(b, digits, exp))
+}
+
+// numberToPrecision implements Number.prototype.toPrecision() for a finite x and 1 <= p.
+func numberToPrecision(x float64, p int) string {
+	var b []byte
+	if x < 0 {
+		b = append(b, '-')
+		x = -x
+	}
+	var digits []byte
+	var exp int
+	if x == 0 {
+		digits = appendZeros(nil, p)
+	} else {
+		digits, exp = decimalDigits(x, true)
+		digits, exp = roundDigits(digits, exp, p)
+	}
+	switch {
+	case exp < -6 || exp >= p:
+		b = appendExponential(b, digits, exp)
+	case exp >= 0:
+		b = append(b, digits[:exp+1]...)
+		if exp+1 < p {
+			b = append(b, '.')
+			b = append(b, digits[exp+1:]...)
+		}
+	default:
+		b = append(b, '0', '.')
+		b = appendZeros(b, -exp-1)
+		b = append(b, digits...)
+	}
+	return string(b)
+}
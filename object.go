@@ -1,6 +1,9 @@
 package goja
 
-import "reflect"
+import (
+	"reflect"
+	"sort"
+)
 
 const (
 	classObject   = "Object"
@@ -15,8 +18,9 @@ const (
 )
 
 type Object struct {
-	runtime *Runtime
-	self    objectImpl
+	runtime   *Runtime
+	self      objectImpl
+	finalizer *finalizerToken
 }
 
 type iterNextFunc func() (propIterItem, iterNextFunc)
@@ -67,6 +71,8 @@ type baseObject struct {
 
 	values    map[string]Value
 	propNames []string
+	// idxPropNames is the number of array index names at the start of propNames
+	idxPropNames int
 }
 
 type primitiveValueObject struct {
@@ -96,6 +102,9 @@ func (f FunctionCall) Argument(idx int) Value {
 
 func (o *baseObject) init() {
 	o.values = make(map[string]Value)
+	if o.val != nil && o.val.runtime != nil {
+		o.val.runtime.trackMemory(memObjectSize)
+	}
 }
 
 func (o *baseObject) className() string {
@@ -172,11 +181,37 @@ func (o *baseObject) _delete(name string) {
 		if n == name {
 			copy(o.propNames[i:], o.propNames[i+1:])
 			o.propNames = o.propNames[:len(o.propNames)-1]
+			if i < o.idxPropNames {
+				o.idxPropNames--
+			}
 			break
 		}
 	}
 }
 
+// addPropName records a new own property name. The names are kept in the order required by [[OwnPropertyKeys]]:
+// array indexes in ascending order followed by the other names in the order of creation.
+func (o *baseObject) addPropName(name string) {
+	if o.val != nil && o.val.runtime != nil {
+		o.val.runtime.trackMemory(memPropertySize + int64(len(name)))
+	}
+	idx := strToIdx(name)
+	if idx < 0 {
+		o.propNames = append(o.propNames, name)
+		return
+	}
+	pos := o.idxPropNames
+	if pos > 0 && strToIdx(o.propNames[pos-1]) > idx {
+		pos = sort.Search(pos, func(i int) bool {
+			return strToIdx(o.propNames[i]) > idx
+		})
+	}
+	o.propNames = append(o.propNames, "")
+	copy(o.propNames[pos+1:], o.propNames[pos:])
+	o.propNames[pos] = name
+	o.idxPropNames++
+}
+
 func (o *baseObject) deleteStr(name string, throw bool) bool {
 	if val, exists := o.values[name]; exists {
 		if !o.checkDelete(name, val, throw) {
@@ -218,7 +253,8 @@ func (o *baseObject) putStr(name string, val Value, throw bool) {
 		return
 	}
 
-	if name == "__proto__" {
+	// objects without a prototype don't inherit the __proto__ accessor, it's an ordinary property for them
+	if name == "__proto__" && o.prototype != nil {
 		if !o.extensible {
 			o.val.runtime.typeErrorResult(throw, "%s is not extensible", o.val)
 			return
@@ -258,7 +294,7 @@ func (o *baseObject) putStr(name string, val Value, throw bool) {
 	}
 
 	o.values[name] = val
-	o.propNames = append(o.propNames, name)
+	o.addPropName(name)
 }
 
 func (o *baseObject) hasOwnProperty(n Value) bool {
@@ -404,7 +440,7 @@ func (o *baseObject) defineOwnProperty(n Value, descr objectImpl, throw bool) bo
 	if v, ok := o._defineOwnProperty(n, val, descr, throw); ok {
 		o.values[name] = v
 		if val == nil {
-			o.propNames = append(o.propNames, name)
+			o.addPropName(name)
 		}
 		return true
 	}
@@ -413,7 +449,7 @@ func (o *baseObject) defineOwnProperty(n Value, descr objectImpl, throw bool) bo
 
 func (o *baseObject) _put(name string, v Value) {
 	if _, exists := o.values[name]; !exists {
-		o.propNames = append(o.propNames, name)
+		o.addPropName(name)
 	}
 
 	o.values[name] = v
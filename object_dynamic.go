@@ -0,0 +1,202 @@
+package goja
+
+import "reflect"
+
+// DynamicObject is implemented by the host to provide the properties of an object on demand, so that large or
+// computed datasets can be exposed to scripts without converting them into regular objects first. See
+// Runtime.NewDynamicObject().
+//
+// All the properties are writable, enumerable and configurable data properties. The methods are called on every
+// access, the values are not cached.
+type DynamicObject interface {
+	// Get returns the value of the property, or nil if it does not exist.
+	Get(key string) Value
+	// Set sets the value of the property, creating it if needed. It returns false if the property cannot be set,
+	// which throws a TypeError in strict mode.
+	Set(key string, val Value) bool
+	// Has returns true if the property exists.
+	Has(key string) bool
+	// Delete deletes the property. It returns false if it cannot be deleted (deleting a property that does not
+	// exist should succeed).
+	Delete(key string) bool
+	// Keys returns the names of the existing properties in the order they are enumerated.
+	Keys() []string
+}
+
+// NewDynamicObject creates an object backed by d. The object has Object.prototype as its prototype and is always
+// extensible: Object.preventExtensions() has no effect on it, and Object.freeze() and the definition of accessor or
+// non-writable properties fail with a TypeError.
+// Export() returns d. Note that ToValue() does not treat DynamicObject values specially, use this method instead.
+func (r *Runtime) NewDynamicObject(d DynamicObject) *Object {
+	v := &Object{runtime: r}
+	o := &objectDynamic{
+		baseObject: baseObject{
+			val: v,
+		},
+		d: d,
+	}
+	v.self = o
+	o.init()
+	return v
+}
+
+// objectDynamic is an object whose own properties are provided by a DynamicObject.
+type objectDynamic struct {
+	baseObject
+	d DynamicObject
+}
+
+func (o *objectDynamic) init() {
+	o.baseObject.init()
+	o.class = classObject
+	o.prototype = o.val.runtime.global.ObjectPrototype
+	o.extensible = true
+}
+
+func (o *objectDynamic) get(n Value) Value {
+	return o.getStr(n.String())
+}
+
+func (o *objectDynamic) getProp(n Value) Value {
+	return o.getPropStr(n.String())
+}
+
+func (o *objectDynamic) getPropStr(name string) Value {
+	if v := o.d.Get(name); v != nil {
+		return v
+	}
+	if o.prototype != nil {
+		return o.prototype.self.getPropStr(name)
+	}
+	return nil
+}
+
+func (o *objectDynamic) getOwnProp(name string) Value {
+	return o.d.Get(name)
+}
+
+func (o *objectDynamic) put(n Value, val Value, throw bool) {
+	o.putStr(n.String(), val, throw)
+}
+
+func (o *objectDynamic) putStr(name string, val Value, throw bool) {
+	if !o.d.Set(name, val) {
+		o.val.runtime.typeErrorResult(throw, "Cannot set property '%s' of a dynamic object", name)
+	}
+}
+
+func (o *objectDynamic) hasProperty(n Value) bool {
+	return o.hasPropertyStr(n.String())
+}
+
+func (o *objectDynamic) hasPropertyStr(name string) bool {
+	if o.d.Has(name) {
+		return true
+	}
+	return o.prototype != nil && o.prototype.self.hasPropertyStr(name)
+}
+
+func (o *objectDynamic) hasOwnProperty(n Value) bool {
+	return o.d.Has(n.String())
+}
+
+func (o *objectDynamic) hasOwnPropertyStr(name string) bool {
+	return o.d.Has(name)
+}
+
+func (o *objectDynamic) _putProp(name string, value Value, writable, enumerable, configurable bool) Value {
+	o.putStr(name, value, false)
+	return value
+}
+
+func (o *objectDynamic) defineOwnProperty(n Value, descr objectImpl, throw bool) bool {
+	name := n.String()
+	v, ok := o.baseObject._defineOwnProperty(n, o.d.Get(name), descr, throw)
+	if !ok {
+		return false
+	}
+	if prop, ok := v.(*valueProperty); ok {
+		if prop.accessor || !prop.writable || !prop.enumerable || !prop.configurable {
+			o.val.runtime.typeErrorResult(throw, "Dynamic object property '%s' must be a writable, enumerable and configurable data property", name)
+			return false
+		}
+		v = prop.value
+	}
+	if v == nil {
+		v = _undefined
+	}
+	if !o.d.Set(name, v) {
+		o.val.runtime.typeErrorResult(throw, "Cannot set property '%s' of a dynamic object", name)
+		return false
+	}
+	return true
+}
+
+func (o *objectDynamic) deleteStr(name string, throw bool) bool {
+	if !o.d.Delete(name) {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of a dynamic object", name)
+		return false
+	}
+	return true
+}
+
+func (o *objectDynamic) delete(n Value, throw bool) bool {
+	return o.deleteStr(n.String(), throw)
+}
+
+func (o *objectDynamic) preventExtensions() {
+}
+
+type dynamicPropIter struct {
+	o         *objectDynamic
+	propNames []string
+	recursive bool
+	idx       int
+}
+
+func (i *dynamicPropIter) next() (propIterItem, iterNextFunc) {
+	for i.idx < len(i.propNames) {
+		name := i.propNames[i.idx]
+		i.idx++
+		if i.o.d.Has(name) {
+			return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+		}
+	}
+
+	if i.recursive && i.o.prototype != nil {
+		return i.o.prototype.self._enumerate(true)()
+	}
+
+	return propIterItem{}, nil
+}
+
+func (o *objectDynamic) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: o._enumerate(recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+
+func (o *objectDynamic) _enumerate(recursive bool) iterNextFunc {
+	return (&dynamicPropIter{
+		o:         o,
+		propNames: o.d.Keys(),
+		recursive: recursive,
+	}).next
+}
+
+func (o *objectDynamic) export() interface{} {
+	return o.d
+}
+
+func (o *objectDynamic) exportType() reflect.Type {
+	return reflect.TypeOf(o.d)
+}
+
+func (o *objectDynamic) equal(other objectImpl) bool {
+	if other, ok := other.(*objectDynamic); ok {
+		return o == other
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package goja
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+)
+
+type testDynObject struct {
+	m map[string]Value
+}
+
+func (t *testDynObject) Get(key string) Value {
+	return t.m[key]
+}
+
+func (t *testDynObject) Set(key string, val Value) bool {
+	if key == "readonly" {
+		return false
+	}
+	t.m[key] = val
+	return true
+}
+
+func (t *testDynObject) Has(key string) bool {
+	_, exists := t.m[key]
+	return exists
+}
+
+func (t *testDynObject) Delete(key string) bool {
+	if key == "permanent" {
+		return false
+	}
+	delete(t.m, key)
+	return true
+}
+
+func (t *testDynObject) Keys() []string {
+	keys := make([]string, 0, len(t.m))
+	for k := range t.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// testDynSquares is an infinite object where each index maps to its square.
+type testDynSquares struct{}
+
+func (testDynSquares) Get(key string) Value {
+	if n, err := strconv.ParseInt(key, 10, 64); err == nil && n >= 0 {
+		return intToValue(n * n)
+	}
+	return nil
+}
+
+func (testDynSquares) Set(key string, val Value) bool {
+	return false
+}
+
+func (s testDynSquares) Has(key string) bool {
+	return s.Get(key) != nil
+}
+
+func (s testDynSquares) Delete(key string) bool {
+	return !s.Has(key)
+}
+
+func (testDynSquares) Keys() []string {
+	return nil
+}
+
+func TestDynamicObject(t *testing.T) {
+	vm := New()
+	d := &testDynObject{m: map[string]Value{"permanent": valueTrue}}
+	o := vm.NewDynamicObject(d)
+	vm.Set("o", o)
+	vm.Set("sq", vm.NewDynamicObject(testDynSquares{}))
+
+	_, err := vm.RunString(TESTLIB + `
+	function throws(f, msg) {
+		try {
+			f();
+		} catch (e) {
+			if (e instanceof TypeError) {
+				return;
+			}
+			throw e;
+		}
+		$ERROR("Expected TypeError: " + msg);
+	}
+
+	o.a = 1;
+	o["b"] = "x";
+	assert.sameValue(o.a, 1, "a");
+	assert("a" in o && o.hasOwnProperty("b") && !("c" in o), "has");
+	assert("toString" in o && typeof o.toString === "function", "prototype");
+	assert.sameValue(Object.keys(o).join(), "a,b,permanent", "keys");
+	var keys = [];
+	for (var k in o) {
+		keys.push(k);
+	}
+	assert.sameValue(keys.join(), "a,b,permanent", "for-in");
+	assert(delete o.a && !("a" in o), "delete");
+	o.readonly = 1;
+	assert(!("readonly" in o), "readonly");
+	assert.sameValue(delete o.permanent, false, "permanent");
+	Object.defineProperty(o, "c", {value: 3, writable: true, enumerable: true, configurable: true});
+	assert.sameValue(o.c, 3, "defineProperty");
+	var d = Object.getOwnPropertyDescriptor(o, "c");
+	assert(d.writable && d.enumerable && d.configurable, "descriptor");
+	throws(function() {
+		Object.defineProperty(o, "d", {get: function() {}});
+	}, "accessor");
+	throws(function() {
+		Object.freeze(o);
+	}, "freeze");
+	throws(function() {
+		"use strict";
+		o.readonly = 1;
+	}, "strict set");
+	throws(function() {
+		"use strict";
+		delete o.permanent;
+	}, "strict delete");
+	assert.sameValue(sq[12], 144, "computed");
+	assert.sameValue(sq.x, undefined, "missing");
+	JSON.stringify(o);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Export() != d {
+		t.Fatal("Unexpected export")
+	}
+	if v := d.m["b"]; v == nil || v.String() != "x" {
+		t.Fatalf("Unexpected b: %v", v)
+	}
+}
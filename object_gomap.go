@@ -2,12 +2,17 @@ package goja
 
 import (
 	"reflect"
+	"sort"
 	"strconv"
 )
 
 type objectGoMapSimple struct {
 	baseObject
 	data map[string]interface{}
+
+	// addedKeys are the keys added through the wrapper in that order, recorded if the GoMapOrder is
+	// GoMapOrderInsertion.
+	addedKeys []string
 }
 
 func (o *objectGoMapSimple) init() {
@@ -71,7 +76,10 @@ func (o *objectGoMapSimple) _has(n Value) bool {
 }
 
 func (o *objectGoMapSimple) putStr(name string, val Value, throw bool) {
-	if o.extensible || o._hasStr(name) {
+	if exists := o._hasStr(name); o.extensible || exists {
+		if !exists && o.val.runtime.goMapOrder == GoMapOrderInsertion {
+			o.addedKeys = append(o.addedKeys, name)
+		}
 		o.data[name] = val.Export()
 	}
 	o.val.runtime.typeErrorResult(throw, "Host object is not extensible")
@@ -133,6 +141,12 @@ func (o *objectGoMapSimple) assertCallable() (call func(FunctionCall) Value, ok
 
 func (o *objectGoMapSimple) deleteStr(name string, throw bool) bool {
 	delete(o.data, name)
+	for i, key := range o.addedKeys {
+		if key == name {
+			o.addedKeys = append(o.addedKeys[:i], o.addedKeys[i+1:]...)
+			break
+		}
+	}
 	return true
 }
 
@@ -178,6 +192,29 @@ func (o *objectGoMapSimple) _enumerate(recursive bool) iterNextFunc {
 		propNames[i] = key
 		i++
 	}
+	switch o.val.runtime.goMapOrder {
+	case GoMapOrderSorted:
+		sort.Strings(propNames)
+	case GoMapOrderInsertion:
+		added := make(map[string]bool, len(o.addedKeys))
+		for _, key := range o.addedKeys {
+			added[key] = true
+		}
+		propNames = propNames[:0]
+		for key := range o.data {
+			if !added[key] {
+				propNames = append(propNames, key)
+			}
+		}
+		sort.Strings(propNames)
+		// a key could have been deleted and added again by the host
+		for _, key := range o.addedKeys {
+			if _, exists := o.data[key]; exists && added[key] {
+				propNames = append(propNames, key)
+				added[key] = false
+			}
+		}
+	}
 	return (&gomapPropIter{
 		o:         o,
 		propNames: propNames,
@@ -1,11 +1,18 @@
 package goja
 
-import "reflect"
+import (
+	"reflect"
+	"sort"
+)
 
 type objectGoMapReflect struct {
 	objectGoReflect
 
 	keyType, valueType reflect.Type
+
+	// addedKeys are the keys added through the wrapper in that order, recorded if the GoMapOrder is
+	// GoMapOrderInsertion.
+	addedKeys []interface{}
 }
 
 func (o *objectGoMapReflect) init() {
@@ -89,13 +96,20 @@ func (o *objectGoMapReflect) toValue(val Value, throw bool) (reflect.Value, bool
 	return v, true
 }
 
+func (o *objectGoMapReflect) setMapIndex(k, v reflect.Value) {
+	if o.val.runtime.goMapOrder == GoMapOrderInsertion && !o.value.MapIndex(k).IsValid() {
+		o.addedKeys = append(o.addedKeys, k.Interface())
+	}
+	o.value.SetMapIndex(k, v)
+}
+
 func (o *objectGoMapReflect) put(key, val Value, throw bool) {
 	k := o.toKey(key)
 	v, ok := o.toValue(val, throw)
 	if !ok {
 		return
 	}
-	o.value.SetMapIndex(k, v)
+	o.setMapIndex(k, v)
 }
 
 func (o *objectGoMapReflect) putStr(name string, val Value, throw bool) {
@@ -104,7 +118,7 @@ func (o *objectGoMapReflect) putStr(name string, val Value, throw bool) {
 	if !ok {
 		return
 	}
-	o.value.SetMapIndex(k, v)
+	o.setMapIndex(k, v)
 }
 
 func (o *objectGoMapReflect) _putProp(name string, value Value, writable, enumerable, configurable bool) Value {
@@ -144,13 +158,26 @@ func (o *objectGoMapReflect) hasPropertyStr(name string) bool {
 	return o.objectGoReflect.hasPropertyStr(name)
 }
 
+func (o *objectGoMapReflect) deleteKey(k reflect.Value) {
+	o.value.SetMapIndex(k, reflect.Value{})
+	if len(o.addedKeys) > 0 {
+		key := k.Interface()
+		for i, added := range o.addedKeys {
+			if added == key {
+				o.addedKeys = append(o.addedKeys[:i], o.addedKeys[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
 func (o *objectGoMapReflect) delete(n Value, throw bool) bool {
-	o.value.SetMapIndex(o.toKey(n), reflect.Value{})
+	o.deleteKey(o.toKey(n))
 	return true
 }
 
 func (o *objectGoMapReflect) deleteStr(name string, throw bool) bool {
-	o.value.SetMapIndex(o.strToKey(name), reflect.Value{})
+	o.deleteKey(o.strToKey(name))
 	return true
 }
 
@@ -167,7 +194,13 @@ func (i *gomapReflectPropIter) next() (propIterItem, iterNextFunc) {
 		v := i.o.value.MapIndex(key)
 		i.idx++
 		if v.IsValid() {
-			return propIterItem{name: key.String(), enumerable: _ENUM_TRUE}, i.next
+			var name string
+			if key.Kind() == reflect.String {
+				name = key.String()
+			} else {
+				name = i.o.val.runtime.ToValue(key.Interface()).String()
+			}
+			return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
 		}
 	}
 
@@ -178,10 +211,59 @@ func (i *gomapReflectPropIter) next() (propIterItem, iterNextFunc) {
 	return propIterItem{}, nil
 }
 
+// sortMapKeys sorts the keys of a map (which are all of the same kind) in ascending order.
+func sortMapKeys(keys []reflect.Value) {
+	if len(keys) == 0 {
+		return
+	}
+	var less func(a, b reflect.Value) bool
+	switch keys[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	default:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+}
+
 func (o *objectGoMapReflect) _enumerate(recusrive bool) iterNextFunc {
+	keys := o.value.MapKeys()
+	switch o.val.runtime.goMapOrder {
+	case GoMapOrderSorted:
+		sortMapKeys(keys)
+	case GoMapOrderInsertion:
+		added := make(map[interface{}]bool, len(o.addedKeys))
+		for _, key := range o.addedKeys {
+			added[key] = true
+		}
+		n := 0
+		for _, key := range keys {
+			if !added[key.Interface()] {
+				keys[n] = key
+				n++
+			}
+		}
+		keys = keys[:n]
+		sortMapKeys(keys)
+		// a key could have been deleted and added again by the host
+		for _, key := range o.addedKeys {
+			if added[key] {
+				if k := reflect.ValueOf(key); o.value.MapIndex(k).IsValid() {
+					keys = append(keys, k)
+				}
+				added[key] = false
+			}
+		}
+	}
 	r := &gomapReflectPropIter{
 		o:         o,
-		keys:      o.value.MapKeys(),
+		keys:      keys,
 		recursive: recusrive,
 	}
 	return r.next
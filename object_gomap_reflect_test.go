@@ -119,3 +119,35 @@ func TestGoMapReflectProto(t *testing.T) {
 		t.Fatalf("Expected true, got %v", v)
 	}
 }
+
+func TestGoMapReflectOrder(t *testing.T) {
+	vm := New()
+	vm.SetGoMapOrder(GoMapOrderSorted)
+	vm.Set("ints", map[int]bool{10: true, -1: true, 2: true, 0: true})
+	vm.Set("strs", map[string]int{"b": 1, "c": 2, "a": 3})
+	res, err := vm.RunString(`Object.keys(ints).join() + "|" + JSON.stringify(strs)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != `-1,0,2,10|{"a":3,"b":1,"c":2}` {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+
+	vm.SetGoMapOrder(GoMapOrderInsertion)
+	m := map[string]int{"b": 1, "a": 2}
+	vm.Set("m", m)
+	res, err = vm.RunString(`
+	m.z = 1;
+	m.c = 1;
+	m.y = 1;
+	delete m.c;
+	m.c = 1;
+	Object.keys(m).join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "a,b,z,y,c" {
+		t.Fatalf("Unexpected order: %s", s)
+	}
+}
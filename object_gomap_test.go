@@ -150,3 +150,38 @@ func TestGomapProto(t *testing.T) {
 	}
 
 }
+
+func TestGoMapOrder(t *testing.T) {
+	vm := New()
+	vm.SetGoMapOrder(GoMapOrderSorted)
+	m := map[string]interface{}{"d": 1, "b": 2, "c": 3, "a": 4}
+	vm.Set("m", m)
+	res, err := vm.RunString(`var keys = []; for (var k in m) { keys.push(k); } keys.join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "a,b,c,d" {
+		t.Fatalf("Unexpected order: %s", s)
+	}
+
+	vm.SetGoMapOrder(GoMapOrderInsertion)
+	res, err = vm.RunString(`
+	m.z = 1;
+	m.e = 1;
+	m.y = 1;
+	delete m.e;
+	m.e = 1;
+	Object.keys(m).join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := res.String(); s != "a,b,c,d,z,y,e" {
+		t.Fatalf("Unexpected order: %s", s)
+	}
+	delete(m, "z")
+	m["z"] = 1
+	if res, _ = vm.RunString(`Object.keys(m).join()`); res.String() != "a,b,c,d,z,y,e" {
+		t.Fatalf("Unexpected order after re-adding from Go: %s", res)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"go/ast"
 	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // JsonEncodable allows custom JSON encoding by JSON.stringify()
@@ -23,6 +26,83 @@ type FieldNameMapper interface {
 	MethodName(t reflect.Type, m reflect.Method) string
 }
 
+type tagFieldNameMapper struct {
+	tagName      string
+	uncapMethods bool
+}
+
+func (tfm tagFieldNameMapper) FieldName(_ reflect.Type, f reflect.StructField) string {
+	if !ast.IsExported(f.Name) {
+		return ""
+	}
+	tag := f.Tag.Get(tfm.tagName)
+	if idx := strings.IndexByte(tag, ','); idx != -1 {
+		tag = tag[:idx]
+	}
+	switch tag {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return tag
+}
+
+func (tfm tagFieldNameMapper) MethodName(_ reflect.Type, m reflect.Method) string {
+	if tfm.uncapMethods {
+		return uncapitalize(m.Name)
+	}
+	return m.Name
+}
+
+type uncapFieldNameMapper struct{}
+
+func (u uncapFieldNameMapper) FieldName(_ reflect.Type, f reflect.StructField) string {
+	if !ast.IsExported(f.Name) {
+		return ""
+	}
+	return uncapitalize(f.Name)
+}
+
+func (u uncapFieldNameMapper) MethodName(_ reflect.Type, m reflect.Method) string {
+	return uncapitalize(m.Name)
+}
+
+// uncapitalize converts an exported Go name to lowerCamelCase, including the leading initialisms: "Name" becomes
+// "name", "ID" becomes "id" and "HTTPServer" becomes "httpServer".
+func uncapitalize(s string) string {
+	var b strings.Builder
+	for i, c := range s {
+		if !unicode.IsUpper(c) {
+			b.WriteString(s[i:])
+			break
+		}
+		if i > 0 {
+			if next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(c):]); unicode.IsLower(next) {
+				// the start of the next word
+				b.WriteString(s[i:])
+				break
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// TagFieldNameMapper returns a FieldNameMapper that names the struct fields after the value of the given tag (the
+// part before the first comma), like encoding/json does: the fields without the tag keep their Go names and the
+// ones tagged "-" are hidden. The methods keep their Go names, or are converted to lowerCamelCase if uncapMethods
+// is true.
+func TagFieldNameMapper(tagName string, uncapMethods bool) FieldNameMapper {
+	return tagFieldNameMapper{tagName, uncapMethods}
+}
+
+// UncapFieldNameMapper returns a FieldNameMapper that converts the names of the fields and the methods to
+// lowerCamelCase, e.g. "Name" to "name" and "URLPath" to "urlPath".
+func UncapFieldNameMapper() FieldNameMapper {
+	return uncapFieldNameMapper{}
+}
+
 type reflectFieldInfo struct {
 	Index     []int
 	Anonymous bool
@@ -565,3 +565,63 @@ func BenchmarkGoReflectGet(b *testing.B) {
 		v.Get("Test")
 	}
 }
+
+func TestUncapitalize(t *testing.T) {
+	for _, test := range []struct{ in, out string }{
+		{"Name", "name"},
+		{"ID", "id"},
+		{"HTTPServer", "httpServer"},
+		{"URL2", "url2"},
+		{"A", "a"},
+		{"ÉtéTime", "étéTime"},
+	} {
+		if s := uncapitalize(test.in); s != test.out {
+			t.Fatalf("%q: %q, expected %q", test.in, s, test.out)
+		}
+	}
+}
+
+type testFieldNameMapperStruct struct {
+	Name     string `json:"name,omitempty"`
+	Count    int    `json:"count"`
+	Hidden   string `json:"-"`
+	Untagged string
+	secret   string
+}
+
+func (s *testFieldNameMapperStruct) GetURL() string {
+	return "url:" + s.Name
+}
+
+func TestTagFieldNameMapper(t *testing.T) {
+	vm := New()
+	vm.SetFieldNameMapper(TagFieldNameMapper("json", true))
+	s := &testFieldNameMapperStruct{Name: "n", Count: 2, Hidden: "h", Untagged: "u", secret: "s"}
+	vm.Set("s", s)
+	v, err := vm.RunString(`
+	s.count++;
+	[s.name, s.count, typeof s.Hidden, typeof s.hidden, s.Untagged, typeof s.secret, s.getURL()].join(",")
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := v.String(); res != "n,3,undefined,undefined,u,undefined,url:n" {
+		t.Fatalf("Unexpected result: %s", res)
+	}
+	if s.Count != 3 {
+		t.Fatalf("Unexpected count: %d", s.Count)
+	}
+}
+
+func TestUncapFieldNameMapper(t *testing.T) {
+	vm := New()
+	vm.SetFieldNameMapper(UncapFieldNameMapper())
+	vm.Set("s", &testFieldNameMapperStruct{Name: "n", Untagged: "u"})
+	v, err := vm.RunString(`[s.name, s.untagged, typeof s.Name, s.getURL()].join(",")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := v.String(); res != "n,u,undefined,url:n" {
+		t.Fatalf("Unexpected result: %s", res)
+	}
+}
@@ -0,0 +1,228 @@
+package goja
+
+import "reflect"
+
+// ProxyTrapConfig holds the Go functions that intercept the operations on an object created by Runtime.NewProxy().
+// A nil trap forwards the operation to the target. The traps receive the target (and, where it applies, the proxy
+// as the receiver) and can forward to it themselves, e.g. to audit the access.
+type ProxyTrapConfig struct {
+	// Get intercepts reading a property. A nil result is undefined.
+	Get func(target *Object, property string, receiver Value) Value
+	// Set intercepts assigning a property. Returning false throws a TypeError in strict mode.
+	Set func(target *Object, property string, value Value, receiver Value) bool
+	// Has intercepts the 'in' operator.
+	Has func(target *Object, property string) bool
+	// DeleteProperty intercepts the 'delete' operator. Returning false throws a TypeError in strict mode.
+	DeleteProperty func(target *Object, property string) bool
+	// OwnKeys intercepts the enumeration of the own properties (for-in, Object.keys(), JSON.stringify(), etc.). All
+	// the returned keys are enumerated.
+	OwnKeys func(target *Object) []string
+	// Apply intercepts calling the proxy. It's only used if the target is a function. A nil result is undefined.
+	Apply func(target *Object, this Value, args []Value) Value
+}
+
+// NewProxy creates an object which forwards the operations to target, intercepting them with the traps of handler.
+// The operations which have no trap (Object.defineProperty(), Object.getOwnPropertyDescriptor(), hasOwnProperty(),
+// the prototype and extensibility) are always forwarded. A proxy of a function can be called, but not used as a
+// constructor. Export() returns the export of the target.
+func (r *Runtime) NewProxy(target *Object, handler *ProxyTrapConfig) *Object {
+	if handler == nil {
+		handler = &ProxyTrapConfig{}
+	}
+	v := &Object{runtime: r}
+	o := &objectProxy{
+		baseObject: baseObject{
+			val: v,
+		},
+		target:  target,
+		handler: handler,
+	}
+	v.self = o
+	o.init()
+	return v
+}
+
+// objectProxy is an object created by Runtime.NewProxy().
+type objectProxy struct {
+	baseObject
+	target  *Object
+	handler *ProxyTrapConfig
+}
+
+func (o *objectProxy) init() {
+	o.baseObject.init()
+	o.class = o.target.self.className()
+	o.extensible = true
+}
+
+func (o *objectProxy) className() string {
+	return o.target.self.className()
+}
+
+func (o *objectProxy) get(n Value) Value {
+	return o.getStr(n.String())
+}
+
+func (o *objectProxy) getStr(name string) Value {
+	if trap := o.handler.Get; trap != nil {
+		return nilSafe(trap(o.target, name, o.val))
+	}
+	return o.target.self.getStr(name)
+}
+
+func (o *objectProxy) getProp(n Value) Value {
+	return o.getPropStr(n.String())
+}
+
+func (o *objectProxy) getPropStr(name string) Value {
+	if trap := o.handler.Get; trap != nil {
+		return nilSafe(trap(o.target, name, o.val))
+	}
+	return o.target.self.getPropStr(name)
+}
+
+func (o *objectProxy) getOwnProp(name string) Value {
+	return o.target.self.getOwnProp(name)
+}
+
+func (o *objectProxy) put(n Value, val Value, throw bool) {
+	o.putStr(n.String(), val, throw)
+}
+
+func (o *objectProxy) putStr(name string, val Value, throw bool) {
+	if trap := o.handler.Set; trap != nil {
+		if !trap(o.target, name, val, o.val) {
+			o.val.runtime.typeErrorResult(throw, "'set' on proxy: trap returned false for property '%s'", name)
+		}
+		return
+	}
+	o.target.self.putStr(name, val, throw)
+}
+
+func (o *objectProxy) hasProperty(n Value) bool {
+	return o.hasPropertyStr(n.String())
+}
+
+func (o *objectProxy) hasPropertyStr(name string) bool {
+	if trap := o.handler.Has; trap != nil {
+		return trap(o.target, name)
+	}
+	return o.target.self.hasPropertyStr(name)
+}
+
+func (o *objectProxy) hasOwnProperty(n Value) bool {
+	return o.target.self.hasOwnProperty(n)
+}
+
+func (o *objectProxy) hasOwnPropertyStr(name string) bool {
+	return o.target.self.hasOwnPropertyStr(name)
+}
+
+func (o *objectProxy) _putProp(name string, value Value, writable, enumerable, configurable bool) Value {
+	return o.target.self._putProp(name, value, writable, enumerable, configurable)
+}
+
+func (o *objectProxy) defineOwnProperty(name Value, descr objectImpl, throw bool) bool {
+	return o.target.self.defineOwnProperty(name, descr, throw)
+}
+
+func (o *objectProxy) assertCallable() (func(FunctionCall) Value, bool) {
+	call, ok := o.target.self.assertCallable()
+	if !ok {
+		return nil, false
+	}
+	if trap := o.handler.Apply; trap != nil {
+		return func(c FunctionCall) Value {
+			return nilSafe(trap(o.target, c.This, c.Arguments))
+		}, true
+	}
+	return call, true
+}
+
+func (o *objectProxy) deleteStr(name string, throw bool) bool {
+	if trap := o.handler.DeleteProperty; trap != nil {
+		if !trap(o.target, name) {
+			o.val.runtime.typeErrorResult(throw, "'deleteProperty' on proxy: trap returned false for property '%s'", name)
+			return false
+		}
+		return true
+	}
+	return o.target.self.deleteStr(name, throw)
+}
+
+func (o *objectProxy) delete(n Value, throw bool) bool {
+	return o.deleteStr(n.String(), throw)
+}
+
+func (o *objectProxy) proto() *Object {
+	return o.target.self.proto()
+}
+
+func (o *objectProxy) hasInstance(v Value) bool {
+	return o.target.self.hasInstance(v)
+}
+
+func (o *objectProxy) isExtensible() bool {
+	return o.target.self.isExtensible()
+}
+
+func (o *objectProxy) preventExtensions() {
+	o.target.self.preventExtensions()
+}
+
+type proxyPropIter struct {
+	o         *objectProxy
+	propNames []string
+	recursive bool
+	idx       int
+}
+
+func (i *proxyPropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.propNames) {
+		name := i.propNames[i.idx]
+		i.idx++
+		return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+	}
+
+	if i.recursive {
+		if proto := i.o.proto(); proto != nil {
+			return proto.self._enumerate(true)()
+		}
+	}
+
+	return propIterItem{}, nil
+}
+
+func (o *objectProxy) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: o._enumerate(recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+
+func (o *objectProxy) _enumerate(recursive bool) iterNextFunc {
+	if trap := o.handler.OwnKeys; trap != nil {
+		return (&proxyPropIter{
+			o:         o,
+			propNames: trap(o.target),
+			recursive: recursive,
+		}).next
+	}
+	return o.target.self._enumerate(recursive)
+}
+
+func (o *objectProxy) export() interface{} {
+	return o.target.self.export()
+}
+
+func (o *objectProxy) exportType() reflect.Type {
+	return o.target.self.exportType()
+}
+
+func (o *objectProxy) equal(other objectImpl) bool {
+	if other, ok := other.(*objectProxy); ok {
+		return o == other
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProxyTraps(t *testing.T) {
+	vm := New()
+	var log []string
+	target := vm.NewObject()
+	target.Set("a", 1)
+	proxy := vm.NewProxy(target, &ProxyTrapConfig{
+		Get: func(target *Object, property string, receiver Value) Value {
+			log = append(log, "get "+property)
+			if property == "virtual" {
+				return vm.ToValue("v")
+			}
+			return target.Get(property)
+		},
+		Set: func(target *Object, property string, value Value, receiver Value) bool {
+			log = append(log, "set "+property)
+			if property == "ro" {
+				return false
+			}
+			target.Set(property, value)
+			return true
+		},
+		Has: func(target *Object, property string) bool {
+			return property == "virtual" || target.self.hasPropertyStr(property)
+		},
+		DeleteProperty: func(target *Object, property string) bool {
+			log = append(log, "delete "+property)
+			return target.self.deleteStr(property, false)
+		},
+		OwnKeys: func(target *Object) []string {
+			var keys []string
+			for item, f := target.self.enumerate(false, false)(); f != nil; item, f = f() {
+				keys = append(keys, item.name)
+			}
+			return append(keys, "virtual")
+		},
+	})
+	vm.Set("p", proxy)
+
+	_, err := vm.RunString(TESTLIB + `
+	assert.sameValue(p.a, 1, "a");
+	assert.sameValue(p.virtual, "v", "virtual");
+	assert("virtual" in p && "a" in p && !("b" in p), "in");
+	p.b = 2;
+	p.ro = 3;
+	assert.sameValue(Object.keys(p).join(), "a,b,virtual", "keys");
+	delete p.a;
+	assert(!("a" in p), "deleted");
+	assert.sameValue(JSON.stringify(p), '{"b":2,"virtual":"v"}', "JSON");
+	(function() {
+		"use strict";
+		try {
+			p.ro = 1;
+		} catch (e) {
+			assert(e instanceof TypeError, "strict set");
+			return;
+		}
+		$ERROR("strict set didn't throw");
+	})();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Get("b").ToInteger() != 2 || target.Get("a") != nil {
+		t.Fatalf("Unexpected target: %v", target.Export())
+	}
+	if s := strings.Join(log[:5], ","); s != "get a,get virtual,set b,set ro,delete a" {
+		t.Fatalf("Unexpected log: %s", s)
+	}
+}
+
+func TestProxyForward(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	function add(a, b) {
+		return a + b;
+	}
+	var o = {x: 1, get y() { return this.x + 1; }};
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Set("po", vm.NewProxy(vm.Get("o").ToObject(vm), nil))
+	vm.Set("padd", vm.NewProxy(vm.Get("add").ToObject(vm), &ProxyTrapConfig{
+		Apply: func(target *Object, this Value, args []Value) Value {
+			f, _ := AssertFunction(target)
+			res, err := f(this, args...)
+			if err != nil {
+				panic(err)
+			}
+			return vm.ToValue(res.ToInteger() * 10)
+		},
+	}))
+
+	_, err = vm.RunString(TESTLIB + `
+	assert.sameValue(po.x, 1, "x");
+	assert.sameValue(po.y, 2, "getter");
+	po.x = 5;
+	assert.sameValue(o.x, 5, "set");
+	assert.sameValue(Object.getPrototypeOf(po), Object.prototype, "prototype");
+	assert.sameValue(typeof po, "object", "typeof object");
+	assert.sameValue(typeof padd, "function", "typeof function");
+	assert.sameValue(padd(1, 2), 30, "apply");
+	assert.sameValue(padd.call(null, 2, 2), 40, "call");
+	assert.sameValue(padd.bind(null, 5)(1), 60, "bind");
+	assert.sameValue(padd.bind(null, 5).bind(null, 2)(), 70, "bind bound");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -238,3 +238,52 @@ func BenchmarkAddString(b *testing.B) {
 		}
 	}
 }
+
+func TestObjectDefineProperties(t *testing.T) {
+	vm := New()
+	o := vm.NewObject()
+
+	if err := o.DefineDataProperty("ro", vm.ToValue(1), FLAG_FALSE, FLAG_FALSE, FLAG_TRUE); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.DefineDataProperty("hidden", vm.ToValue(2), FLAG_TRUE, FLAG_TRUE, FLAG_FALSE); err != nil {
+		t.Fatal(err)
+	}
+	getter, _ := vm.RunString("(function() { return this.hidden * 10; })")
+	setter, _ := vm.RunString("(function(v) { this.hidden = v; })")
+	if err := o.DefineAccessorProperty("acc", getter, setter, FLAG_TRUE, FLAG_TRUE); err != nil {
+		t.Fatal(err)
+	}
+	vm.Set("o", o)
+
+	_, err := vm.RunString(TESTLIB + `
+	o.ro = 5;
+	assert.sameValue(o.ro, 1, "ro");
+	assert.sameValue(Object.keys(o).join(), "ro,acc", "keys");
+	assert.sameValue(o.acc, 20, "getter");
+	o.acc = 3;
+	assert.sameValue(o.hidden, 3, "setter");
+	var d = Object.getOwnPropertyDescriptor(o, "ro");
+	assert(!d.writable && !d.configurable && d.enumerable, "ro descriptor");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.DefineDataProperty("ro", vm.ToValue(2), FLAG_NOT_SET, FLAG_NOT_SET, FLAG_NOT_SET); err == nil {
+		t.Fatal("Redefinition of a non-configurable property succeeded")
+	} else if _, ok := err.(*Exception); !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := o.DefineAccessorProperty("bad", vm.ToValue(1), nil, FLAG_TRUE, FLAG_TRUE); err == nil {
+		t.Fatal("Non-callable getter accepted")
+	}
+
+	// FLAG_NOT_SET keeps the current attributes
+	if err := o.DefineDataProperty("hidden", vm.ToValue(4), FLAG_NOT_SET, FLAG_NOT_SET, FLAG_NOT_SET); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := vm.RunString(`var d = Object.getOwnPropertyDescriptor(o, "hidden"); d.value === 4 && d.writable && !d.enumerable`); err != nil || !v.ToBoolean() {
+		t.Fatalf("Unexpected result: %v, %v", v, err)
+	}
+}
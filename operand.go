@@ -0,0 +1,84 @@
+package goja
+
+// Operand can be implemented by host types which behave like primitive values, e.g. decimal numbers, so that the
+// values wrapped with ToValue() can be used with the operators:
+//
+//	price.mul(qty).add(tax)  // becomes
+//	price * qty + tax
+//
+// Operate is called for the arithmetic operators (+, -, *, /, %, unary minus, ++ and --) when either operand
+// is an Operand, and Compare for the relational and equality operators (<, <=, >, >=, == and !=). ToString() and
+// therefore string concatenation use the String() method if the type implements fmt.Stringer.
+// Note that the strict equality (===) is not affected (it compares the wrapped Go values), and the bitwise
+// operators convert the values to numbers as usual.
+type Operand interface {
+	// Operate returns the result of the binary operator op ("+", "-", "*", "/" or "%") applied to the value and
+	// other, where the value is the left operand unless right is true. For the unary minus op is "neg" and other
+	// is undefined. ++ and -- are performed as "+" and "-" with the number 1.
+	// If it returns nil the operator works as if the value wasn't an Operand.
+	// The other operand is passed as is, so another Operand is a wrapped host object (use Export() to get it).
+	Operate(op string, other Value, right bool) Value
+
+	// Compare returns a negative number, zero or a positive number if the value is less than, equal to or
+	// greater than other, or false if they cannot be compared, in which case all the comparisons are false (and
+	// != is true) as with NaN.
+	Compare(other Value) (result int, ok bool)
+}
+
+func toOperand(v Value) Operand {
+	if o, ok := v.(*Object); ok {
+		if g, ok := o.self.(*objectGoReflect); ok {
+			if op, ok := g.origValue.Interface().(Operand); ok {
+				return op
+			}
+		}
+	}
+	return nil
+}
+
+// operate applies a binary operator if either operand is an Operand, it returns nil otherwise.
+func operate(op string, left, right Value) Value {
+	if l := toOperand(left); l != nil {
+		if res := l.Operate(op, right, false); res != nil {
+			return res
+		}
+	}
+	if r := toOperand(right); r != nil {
+		return r.Operate(op, left, true)
+	}
+	return nil
+}
+
+// compareOperands applies a relational or equality operator ("<", "<=", ">", ">=", "==" or "!=") if either
+// operand is an Operand, it returns nil otherwise.
+func compareOperands(op string, left, right Value) Value {
+	var c int
+	var ok bool
+	if l := toOperand(left); l != nil {
+		c, ok = l.Compare(right)
+	} else if r := toOperand(right); r != nil {
+		c, ok = r.Compare(left)
+		c = -c
+	} else {
+		return nil
+	}
+	var res bool
+	switch op {
+	case "<":
+		res = ok && c < 0
+	case "<=":
+		res = ok && c <= 0
+	case ">":
+		res = ok && c > 0
+	case ">=":
+		res = ok && c >= 0
+	case "==":
+		res = ok && c == 0
+	case "!=":
+		res = !ok || c != 0
+	}
+	if res {
+		return valueTrue
+	}
+	return valueFalse
+}
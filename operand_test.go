@@ -0,0 +1,101 @@
+package goja
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testDecimal is a fixed point number with two decimal places.
+type testDecimal struct {
+	cents int64
+}
+
+func (d testDecimal) String() string {
+	sign := ""
+	c := d.cents
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func toTestDecimal(v Value) (testDecimal, bool) {
+	switch e := v.Export().(type) {
+	case testDecimal:
+		return e, true
+	case int64:
+		return testDecimal{e * 100}, true
+	}
+	return testDecimal{}, false
+}
+
+func (d testDecimal) Operate(op string, other Value, right bool) Value {
+	if op == "neg" {
+		return testRuntime.ToValue(testDecimal{-d.cents})
+	}
+	o, ok := toTestDecimal(other)
+	if !ok {
+		return nil
+	}
+	a, b := d, o
+	if right {
+		a, b = o, d
+	}
+	switch op {
+	case "+":
+		return testRuntime.ToValue(testDecimal{a.cents + b.cents})
+	case "-":
+		return testRuntime.ToValue(testDecimal{a.cents - b.cents})
+	case "*":
+		return testRuntime.ToValue(testDecimal{a.cents * b.cents / 100})
+	}
+	return nil
+}
+
+func (d testDecimal) Compare(other Value) (int, bool) {
+	o, ok := toTestDecimal(other)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case d.cents < o.cents:
+		return -1, true
+	case d.cents > o.cents:
+		return 1, true
+	}
+	return 0, true
+}
+
+var testRuntime *Runtime
+
+func TestOperand(t *testing.T) {
+	const SCRIPT = `
+	var price = dec(1999), qty = 3;
+	assert.sameValue(String(price * qty), "59.97", "*");
+	assert.sameValue(String(qty * price), "59.97", "* right");
+	assert.sameValue(String(price + dec(1)), "20.00", "+");
+	assert.sameValue("total: " + price, "total: 19.99", "string concatenation");
+	assert.sameValue(String(2 - price), "-17.99", "- right");
+	assert.sameValue(String(-price), "-19.99", "unary minus");
+	var x = dec(50);
+	x++;
+	x -= 2;
+	assert.sameValue(String(x), "-0.50", "++ and -=");
+	assert(price > 19 && price < 20 && price >= dec(1999) && price <= dec(1999), "relational");
+	assert(!(price < "abc") && !(price >= "abc"), "not comparable");
+	assert(price == dec(1999) && price != dec(1), "equality");
+	assert.sameValue(price / 2, 19.99 / 2, "unsupported operator converts to number");
+	`
+	vm := New()
+	testRuntime = vm
+	defer func() {
+		testRuntime = nil
+	}()
+	vm.Set("dec", func(cents int64) testDecimal {
+		return testDecimal{cents}
+	})
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -320,6 +320,10 @@ func (self *_parser) parseArgumentList() (argumentList []ast.Expression, idx0, i
 				break
 			}
 			self.next()
+			if self.token == token.RIGHT_PARENTHESIS {
+				// trailing comma
+				break
+			}
 		}
 	}
 	idx1 = self.expect(token.RIGHT_PARENTHESIS)
@@ -373,6 +377,22 @@ func (self *_parser) parseBracketMember(left ast.Expression) ast.Expression {
 
 func (self *_parser) parseNewExpression() ast.Expression {
 	idx := self.expect(token.NEW)
+	if self.token == token.PERIOD {
+		self.next()
+		prop := self.parseIdentifier()
+		if prop.Name != "target" {
+			self.error(prop.Idx, "Unexpected identifier")
+			return &ast.BadExpression{From: idx, To: prop.Idx1()}
+		}
+		return &ast.MetaProperty{
+			Meta: &ast.Identifier{
+				Name: token.NEW.String(),
+				Idx:  idx,
+			},
+			Property: prop,
+			Idx:      idx,
+		}
+	}
 	callee := self.parseLeftHandSideExpression()
 	node := &ast.NewExpression{
 		New:    idx,
@@ -380,7 +380,7 @@ func (self *_parser) read() {
 		self.chrOffset = self.offset
 		chr, width := rune(self.str[self.offset]), 1
 		if chr >= utf8.RuneSelf { // !ASCII
-			chr, width = utf8.DecodeRuneInString(self.str[self.offset:])
+			chr, width = decodeRune(self.str[self.offset:])
 			if chr == utf8.RuneError && width == 1 {
 				self.error(self.chrOffset, "Invalid UTF-8 character")
 			}
@@ -393,13 +393,31 @@ func (self *_parser) read() {
 	}
 }
 
+// decodeRune is utf8.DecodeRuneInString that also accepts the unpaired surrogates (U+D800-U+DFFF) encoded
+// as 3-byte sequences (as in WTF-8). This is how a string value with unpaired surrogates is passed as source
+// code, e.g. to eval().
+func decodeRune(s string) (rune, int) {
+	chr, width := utf8.DecodeRuneInString(s)
+	if chr == utf8.RuneError && width == 1 && len(s) >= 3 && s[0] == 0xED && s[1]&0xE0 == 0xA0 && s[2]&0xC0 == 0x80 {
+		return 0xD000 | rune(s[1]&0x3F)<<6 | rune(s[2]&0x3F), 3
+	}
+	return chr, width
+}
+
+// writeSurrogate writes an unpaired surrogate in the encoding accepted by decodeRune.
+func writeSurrogate(buffer *bytes.Buffer, value rune) {
+	buffer.WriteByte(byte(0xE0 | value>>12))
+	buffer.WriteByte(byte(0x80 | (value>>6)&0x3F))
+	buffer.WriteByte(byte(0x80 | value&0x3F))
+}
+
 // This is here since the functions are so similar
 func (self *_RegExp_parser) read() {
 	if self.offset < self.length {
 		self.chrOffset = self.offset
 		chr, width := rune(self.str[self.offset]), 1
 		if chr >= utf8.RuneSelf { // !ASCII
-			chr, width = utf8.DecodeRuneInString(self.str[self.offset:])
+			chr, width = decodeRune(self.str[self.offset:])
 			if chr == utf8.RuneError && width == 1 {
 				self.error(self.chrOffset, "Invalid UTF-8 character")
 			}
@@ -421,6 +439,14 @@ func (self *_parser) skipSingleLineComment() {
 	}
 }
 
+// skipHashbang skips the "#!" comment line the source may start with.
+func (self *_parser) skipHashbang() {
+	if strings.HasPrefix(self.str, "#!") {
+		self.read()
+		self.skipSingleLineComment()
+	}
+}
+
 func (self *_parser) skipMultiLineComment() {
 	self.read()
 	for self.chr >= 0 {
@@ -631,18 +657,26 @@ func parseStringLiteral(literal string) (string, error) {
 	str := literal
 	buffer := bytes.NewBuffer(make([]byte, 0, 3*len(literal)/2))
 	var surrogate rune
-	S:
+	flushSurrogate := func() {
+		if surrogate != 0 {
+			writeSurrogate(buffer, surrogate)
+			surrogate = 0
+		}
+	}
 	for len(str) > 0 {
 		switch chr := str[0]; {
 		// We do not explicitly handle the case of the quote
 		// value, which can be: " ' /
 		// This assumes we're already passed a partially well-formed literal
 		case chr >= utf8.RuneSelf:
-			chr, size := utf8.DecodeRuneInString(str)
-			buffer.WriteRune(chr)
+			_, size := utf8.DecodeRuneInString(str)
+			flushSurrogate()
+			// Copied as is, to keep the unpaired surrogates
+			buffer.WriteString(str[:size])
 			str = str[size:]
 			continue
 		case chr != '\\':
+			flushSurrogate()
 			buffer.WriteByte(chr)
 			str = str[1:]
 			continue
@@ -656,7 +690,7 @@ func parseStringLiteral(literal string) (string, error) {
 		if chr >= utf8.RuneSelf {
 			str = str[1:]
 			var size int
-			value, size = utf8.DecodeRuneInString(str)
+			value, size = decodeRune(str)
 			str = str[size:] // \ + <character>
 		} else {
 			str = str[2:] // \<character>
@@ -736,18 +770,26 @@ func parseStringLiteral(literal string) (string, error) {
 			default:
 				value = rune(chr)
 			}
-			if surrogate != 0 {
-				value = utf16.DecodeRune(surrogate, value)
+		}
+		if surrogate != 0 {
+			if value >= 0xDC00 && value <= 0xDFFF {
+				buffer.WriteRune(utf16.DecodeRune(surrogate, value))
 				surrogate = 0
-			} else {
-				if utf16.IsSurrogate(value) {
-					surrogate = value
-					continue S
-				}
+				continue
 			}
+			flushSurrogate()
+		}
+		if value >= 0xD800 && value <= 0xDBFF {
+			surrogate = value
+			continue
+		}
+		if value >= 0xDC00 && value <= 0xDFFF {
+			writeSurrogate(buffer, value)
+			continue
 		}
 		buffer.WriteRune(value)
 	}
+	flushSurrogate()
 
 	return buffer.String(), nil
 }
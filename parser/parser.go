@@ -179,6 +179,7 @@ func (self *_parser) slice(idx0, idx1 file.Idx) string {
 }
 
 func (self *_parser) parse() (*ast.Program, error) {
+	self.skipHashbang()
 	self.next()
 	program := self.parseProgram()
 	if false {
@@ -50,6 +50,15 @@ func TestParseFile(t *testing.T) {
 
 		_, err = ParseFile(nil, "", `/(?!def)abc/; return`, IgnoreRegExpErrors)
 		is(err, "(anonymous): Line 1:15 Illegal return statement")
+
+		_, err = ParseFile(nil, "", "#!/usr/bin/env goja\nvar a = 1", 0)
+		is(err, nil)
+
+		_, err = ParseFile(nil, "", "#!/usr/bin/env goja\n return", 0)
+		is(err, "(anonymous): Line 2:2 Illegal return statement")
+
+		_, err = ParseFile(nil, "", " #!/usr/bin/env goja", 0)
+		is(firstErr(err), "(anonymous): Line 1:2 Unexpected token ILLEGAL")
 	})
 }
 
@@ -219,6 +228,12 @@ func TestParserErr(t *testing.T) {
 
 		test("function if() {}", "(anonymous): Line 1:10 Unexpected token if")
 
+		test("function abc(a,,) {}", "(anonymous): Line 1:16 Unexpected token ,")
+
+		test("abc(,)", "(anonymous): Line 1:5 Unexpected token ,")
+
+		test("abc(1,,)", "(anonymous): Line 1:7 Unexpected token ,")
+
 		test("a b;", "(anonymous): Line 1:3 Unexpected identifier")
 
 		test("if.a", "(anonymous): Line 1:3 Unexpected token .")
@@ -649,6 +664,11 @@ func TestParser(t *testing.T) {
 		test(`new abc()`, nil)
 		test(`new {}`, nil)
 
+		test(`function abc(a, b,) {}`, nil)
+		program = test(`abc(1, 2,)`, nil)
+		is(len(program.Body[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).ArgumentList), 2)
+		test(`new abc(1,)`, nil)
+
 		test(`
             limit = 4
             result = 0
@@ -915,6 +935,12 @@ func Test_parseStringLiteral(t *testing.T) {
 
 		test("\\\u4e16", "\u4e16")
 
+		// Unpaired surrogates
+		test("\\uD83D\\uDE00", "\U0001F600")
+		test("\\uD83Dx", "\xED\xA0\xBDx")
+		test("x\\uDE00", "x\xED\xB8\x80")
+		test("\xED\xA0\xBD\\x41", "\xED\xA0\xBDA")
+
 		// err
 		test = func(have, want string) {
 			have, err := parseStringLiteral(have)
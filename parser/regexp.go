@@ -3,14 +3,19 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
 	WhitespaceChars = " \f\n\r\t\v\u00a0\u1680\u2000\u2001\u2002\u2003\u2004\u2005\u2006\u2007\u2008\u2009\u200a\u2028\u2029\u202f\u205f\u3000\ufeff"
 )
 
+var quantifierRegexp = regexp.MustCompile(`^\{[0-9]+(,[0-9]*)?\}`)
+
 type _RegExp_parser struct {
 	str    string
 	length int
@@ -21,13 +26,14 @@ type _RegExp_parser struct {
 
 	errors  []error
 	invalid bool // The input is an invalid JavaScript RegExp
+	unicode bool // The pattern has the 'u' flag
 
 	goRegexp *bytes.Buffer
 }
 
 // TransformRegExp transforms a JavaScript pattern into  a Go "regexp" pattern.
 //
-// re2 (Go) cannot do backtracking, so the presence of a lookahead (?=) (?!) or
+// re2 (Go) cannot do backtracking, so the presence of a lookahead (?=) (?!), lookbehind (?<=) (?<!) or
 // backreference (\1, \2, ...) will cause an error.
 //
 // re2 (Go) has a different definition for \s: [\t\n\f\r ].
@@ -36,9 +42,22 @@ type _RegExp_parser struct {
 // If the pattern is invalid (not valid even in JavaScript), then this function
 // returns the empty string and an error.
 //
-// If the pattern is valid, but incompatible (contains a lookahead or backreference),
+// If the pattern is valid, but incompatible (contains a lookaround or backreference),
 // then this function returns the transformation (a non-empty string) AND an error.
 func TransformRegExp(pattern string) (string, error) {
+	return transformRegExp(pattern, false)
+}
+
+// TransformRegExpUnicode is the same as TransformRegExp, but for patterns with the 'u' flag: code point escapes
+// (\u{1F600}) and escaped surrogate pairs are transformed into single code points and the stricter syntax
+// rules apply, i.e. identity escapes are only allowed for syntax characters and lone braces are errors.
+// Property escapes are supported for the names of Go's unicode.Categories and unicode.Scripts tables (\p{Lu},
+// \P{Greek}), the other JavaScript forms have to be rewritten by the caller.
+func TransformRegExpUnicode(pattern string) (string, error) {
+	return transformRegExp(pattern, true)
+}
+
+func transformRegExp(pattern string, unicode bool) (string, error) {
 
 	if pattern == "" {
 		return "", nil
@@ -49,6 +68,7 @@ func TransformRegExp(pattern string) (string, error) {
 	parser := _RegExp_parser{
 		str:      pattern,
 		length:   len(pattern),
+		unicode:  unicode,
 		goRegexp: bytes.NewBuffer(make([]byte, 0, 3*len(pattern)/2)),
 	}
 	parser.read() // Pull in the first character
@@ -83,6 +103,8 @@ func (self *_RegExp_parser) scan() {
 		case '.':
 			self.goRegexp.WriteString("[^\\r\\n]")
 			self.read()
+		case '{', '}', ']':
+			self.scanBrace()
 		default:
 			self.pass()
 		}
@@ -99,6 +121,13 @@ func (self *_RegExp_parser) scanGroup() {
 			}
 		}
 	}
+	if len(str) > 2 { // A possibility of (?<= or (?<!
+		if str[0] == '?' && str[1] == '<' {
+			if str[2] == '=' || str[2] == '!' {
+				self.error(-1, "re2: Invalid (%s) <lookbehind>", self.str[self.chrOffset:self.chrOffset+3])
+			}
+		}
+	}
 	for self.chr != -1 && self.chr != ')' {
 		switch self.chr {
 		case '\\':
@@ -112,6 +141,8 @@ func (self *_RegExp_parser) scanGroup() {
 		case '.':
 			self.goRegexp.WriteString("[^\\r\\n]")
 			self.read()
+		case '{', '}', ']':
+			self.scanBrace()
 		default:
 			self.pass()
 			continue
@@ -125,6 +156,21 @@ func (self *_RegExp_parser) scanGroup() {
 	self.pass()
 }
 
+// {n}, {n,}, {n,m} or a literal brace (only allowed without the 'u' flag)
+func (self *_RegExp_parser) scanBrace() {
+	if self.unicode {
+		if q := quantifierRegexp.FindString(self.str[self.chrOffset:]); q != "" {
+			for range q {
+				self.pass()
+			}
+			return
+		}
+		self.error(self.chrOffset, "Lone quantifier brackets")
+		self.invalid = true
+	}
+	self.pass()
+}
+
 // [...]
 func (self *_RegExp_parser) scanBracket() {
 	str := self.str[self.chrOffset:]
@@ -145,15 +191,42 @@ func (self *_RegExp_parser) scanBracket() {
 
 
 	self.pass()
+	if self.chr == '^' {
+		self.pass()
+	}
+	// With the 'u' flag class escapes (\d, \w, etc.) cannot be used as range boundaries
+	var atom, classAtom, rangeDash bool
 	for self.chr != -1 {
 		if self.chr == ']' {
 			break
-		} else if self.chr == '\\' {
+		}
+		if self.chr == '-' && atom && !rangeDash && self.offset < self.length && self.str[self.offset] != ']' {
+			if self.unicode && classAtom {
+				self.error(self.chrOffset, "Invalid character class range")
+				self.invalid = true
+			}
+			rangeDash = true
+			self.pass()
+			continue
+		}
+		isClass := false
+		if self.chr == '\\' {
 			self.read()
+			isClass = strings.ContainsRune("dDwWsS", self.chr)
+			if self.unicode && isClass && rangeDash {
+				self.error(self.chrOffset, "Invalid character class range")
+				self.invalid = true
+			}
 			self.scanEscape(true)
-			continue
+		} else {
+			self.pass()
+		}
+		if rangeDash {
+			// the range is complete
+			atom, rangeDash = false, false
+		} else {
+			atom, classAtom = true, isClass
 		}
-		self.pass()
 	}
 	if self.chr != ']' {
 		self.error(-1, "Unterminated character class")
@@ -183,6 +256,11 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 			self.read()
 			size += 1
 		}
+		if self.unicode && size > 1 && self.str[offset] == '0' {
+			// Legacy octal escapes are not allowed
+			self.error(offset, "Invalid decimal escape")
+			self.invalid = true
+		}
 		if size == 1 { // The number of characters read
 			_, err := self.goRegexp.Write([]byte{'\\', byte(value) + '0'})
 			if err != nil {
@@ -235,6 +313,10 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 
 	case 'u':
 		self.read()
+		if self.unicode && self.chr == '{' {
+			self.scanCodePointEscape()
+			return
+		}
 		length, base = 4, 16
 
 	case 'b':
@@ -275,6 +357,10 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 		} else if 'A' <= self.chr && self.chr <= 'Z' {
 			value = int64(self.chr) - 'A' + 1
 		} else {
+			if self.unicode {
+				self.error(self.chrOffset, "Invalid escape")
+				self.invalid = true
+			}
 			err := self.goRegexp.WriteByte('c')
 			if err != nil {
 				self.errors = append(self.errors, err)
@@ -313,10 +399,18 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 		self.read()
 		return
 	default:
+		if self.unicode && (self.chr == 'p' || self.chr == 'P') {
+			self.scanPropertyEscape()
+			return
+		}
+		if self.unicode && !strings.ContainsRune("^$\\.*+?()[]{}|/", self.chr) && !(inClass && self.chr == '-') {
+			self.error(self.chrOffset, "Invalid escape")
+			self.invalid = true
+		}
 		// $ is an identifier character, so we have to have
 		// a special case for it here
-		if self.chr == '$' || !isIdentifierPart(self.chr) {
-			// A non-identifier character needs escaping
+		if self.chr == '$' || self.chr < utf8.RuneSelf && !isIdentifierPart(self.chr) {
+			// A non-identifier character needs escaping (re2 only allows escaping ASCII punctuation)
 			err := self.goRegexp.WriteByte('\\')
 			if err != nil {
 				self.errors = append(self.errors, err)
@@ -345,6 +439,18 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 		}
 	}
 
+	if length == 4 && self.unicode && value >= 0xD800 && value <= 0xDBFF &&
+		self.chrOffset+6 <= self.length && self.str[self.chrOffset:self.chrOffset+2] == "\\u" {
+		// A surrogate pair is a single code point
+		if lo, err := strconv.ParseUint(self.str[self.chrOffset+2:self.chrOffset+6], 16, 32); err == nil && lo >= 0xDC00 && lo <= 0xDFFF {
+			for i := 0; i < 6; i++ {
+				self.read()
+			}
+			self.writeCodePoint((value-0xD800)<<10 + uint32(lo) - 0xDC00 + 0x10000)
+			return
+		}
+	}
+
 	if length == 4 {
 		_, err := self.goRegexp.Write([]byte{
 			'\\',
@@ -378,12 +484,70 @@ func (self *_RegExp_parser) scanEscape(inClass bool) {
 	return
 
 skip:
+	if self.unicode {
+		self.error(offset, "Invalid escape")
+		self.invalid = true
+	}
 	_, err := self.goRegexp.WriteString(self.str[offset:self.chrOffset])
 	if err != nil {
 		self.errors = append(self.errors, err)
 	}
 }
 
+// \p{...} or \P{...}
+func (self *_RegExp_parser) scanPropertyEscape() {
+	offset := self.chrOffset
+	self.read()
+	if self.chr == '{' {
+		if end := strings.IndexByte(self.str[self.chrOffset:], '}'); end != -1 {
+			name := self.str[self.chrOffset+1 : self.chrOffset+end]
+			if unicode.Categories[name] != nil || unicode.Scripts[name] != nil {
+				for self.chr != '}' {
+					self.read()
+				}
+				self.read()
+				_, err := self.goRegexp.WriteString(self.str[offset-1 : self.chrOffset])
+				if err != nil {
+					self.errors = append(self.errors, err)
+				}
+				return
+			}
+		}
+	}
+	self.error(offset, "Invalid property name")
+	self.invalid = true
+}
+
+// \u{...}
+func (self *_RegExp_parser) scanCodePointEscape() {
+	offset := self.chrOffset
+	self.read()
+	var value uint32
+	digits := 0
+	for ; self.chr != '}'; digits++ {
+		digit := uint32(digitValue(self.chr))
+		if digit >= 16 || value > 0x10FFFF {
+			break
+		}
+		value = value*16 + digit
+		self.read()
+	}
+	if self.chr != '}' || digits == 0 || value > 0x10FFFF {
+		self.error(offset, "Invalid Unicode escape")
+		self.invalid = true
+		return
+	}
+	self.read()
+	self.writeCodePoint(value)
+}
+
+func (self *_RegExp_parser) writeCodePoint(value uint32) {
+	_, err := fmt.Fprintf(self.goRegexp, "\\x{%X}", value)
+	if err != nil {
+		self.errors = append(self.errors, err)
+	}
+}
+
 func (self *_RegExp_parser) pass() {
 	if self.chr != -1 {
 		_, err := self.goRegexp.WriteRune(self.chr)
@@ -114,6 +114,61 @@ func TestRegExp(t *testing.T) {
 
 			test(`[G-b\0]`, `[G-b\0]`)
 		}
+
+		{
+			// unicode
+			test := func(input string, expect string) {
+				result, err := TransformRegExpUnicode(input)
+				is(err, nil)
+				is(result, expect)
+				_, err = regexp.Compile(result)
+				is(err, nil)
+			}
+
+			testErr := func(input string, expectErr string) {
+				result, err := TransformRegExpUnicode(input)
+				is(result, "")
+				is(err, expectErr)
+			}
+
+			test(`\u{1F600}`, `\x{1F600}`)
+
+			test(`\u{0000000041}`, `\x{41}`)
+
+			test(`\uD83D\uDE00`, `\x{1F600}`)
+
+			test(`\u0041`, `\x{0041}`)
+
+			test(`\.\/\$?`, `\.\/\$?`)
+
+			test(`[\-\d-]`, `[\-\d-]`)
+
+			test(`a{2,3}`, `a{2,3}`)
+
+			testErr(`\u{110000}`, "Invalid Unicode escape")
+
+			testErr(`\u{}`, "Invalid Unicode escape")
+
+			testErr(`\u12`, "Invalid escape")
+
+			testErr(`\a`, "Invalid escape")
+
+			testErr(`\-`, "Invalid escape")
+
+			testErr(`\c1`, "Invalid escape")
+
+			testErr(`\00`, "Invalid decimal escape")
+
+			testErr(`a{`, "Lone quantifier brackets")
+
+			testErr(`}`, "Lone quantifier brackets")
+
+			testErr(`]`, "Lone quantifier brackets")
+
+			testErr(`[\d-z]`, "Invalid character class range")
+
+			testErr(`[a-\w]`, "Invalid character class range")
+		}
 	})
 }
 
@@ -0,0 +1,126 @@
+package goja
+
+import "fmt"
+
+// PerformanceWarningKind identifies the pattern reported by a PerformanceWarning.
+type PerformanceWarningKind int
+
+const (
+	// PerformanceWarningStringConcat is reported for a + or += that repeatedly concatenates long strings, e.g.
+	// when a string is built in a loop, which copies the whole string each time.
+	PerformanceWarningStringConcat PerformanceWarningKind = iota
+	// PerformanceWarningArrayShift is reported for a call site that repeatedly calls Array.prototype.shift() on
+	// large arrays, e.g. when an array is used as a queue, which moves all the remaining elements each time.
+	PerformanceWarningArrayShift
+)
+
+func (k PerformanceWarningKind) String() string {
+	switch k {
+	case PerformanceWarningStringConcat:
+		return "string concatenation"
+	case PerformanceWarningArrayShift:
+		return "array shift"
+	}
+	return fmt.Sprintf("PerformanceWarningKind(%d)", int(k))
+}
+
+// PerformanceWarning is reported by the instrumented mode enabled with Runtime.SetPerformanceWarnings().
+type PerformanceWarning struct {
+	Kind    PerformanceWarningKind
+	Message string
+	// Frame is the innermost script frame at the time of the warning, its Position() is the offending
+	// expression or call.
+	Frame StackFrame
+}
+
+func (w PerformanceWarning) String() string {
+	return fmt.Sprintf("%s:%s: %s", w.Frame.SrcName(), w.Frame.Position(), w.Message)
+}
+
+// PerformanceWarningHandler is called with the warnings of the instrumented mode, see
+// Runtime.SetPerformanceWarnings().
+type PerformanceWarningHandler func(w PerformanceWarning)
+
+const (
+	// perfConcatMinLength is the minimum length of the result of a concatenation that is counted.
+	perfConcatMinLength = 10000
+	// perfShiftMinLength is the minimum length of an array a shift() on which is counted.
+	perfShiftMinLength = 1000
+	// perfRepeatCount is the number of counted operations at the same site that triggers a warning.
+	perfRepeatCount = 100
+)
+
+type perfSite struct {
+	prg  *Program
+	pc   int
+	kind PerformanceWarningKind
+}
+
+type perfWarnings struct {
+	handler PerformanceWarningHandler
+	counts  map[perfSite]int
+}
+
+// SetPerformanceWarnings enables an instrumented mode which detects the patterns that are accidentally
+// quadratic, such as building a long string with repeated concatenation or using a large array as a queue with
+// shift(), and calls the handler with a warning pointing to the offending code. This is meant for script authors
+// and comes at some cost, so it should not be enabled in production. Passing nil disables it.
+// The detection is heuristic: an operation is counted when it works on a long string (10000 code units) or a large
+// array (1000 elements), and the warning is reported once per location when the count reaches 100.
+func (r *Runtime) SetPerformanceWarnings(handler PerformanceWarningHandler) {
+	if handler == nil {
+		r.perfWarnings = nil
+		return
+	}
+	r.perfWarnings = &perfWarnings{
+		handler: handler,
+		counts:  make(map[perfSite]int),
+	}
+}
+
+// scriptFrame returns the innermost frame that belongs to a script, the caller if a native function is running.
+func (vm *vm) scriptFrame() (StackFrame, bool) {
+	if vm.prg != nil {
+		return StackFrame{prg: vm.prg, pc: vm.pc, funcName: vm.funcName}, true
+	}
+	for i := len(vm.callStack) - 1; i >= 0; i-- {
+		if ctx := &vm.callStack[i]; ctx.prg != nil && ctx.pc != -1 {
+			return StackFrame{prg: ctx.prg, pc: ctx.pc - 1, funcName: ctx.funcName}, true
+		}
+	}
+	return StackFrame{}, false
+}
+
+// countPerfSite counts an operation of the given kind at the current location and reports a warning when the
+// count reaches perfRepeatCount.
+func (r *Runtime) countPerfSite(kind PerformanceWarningKind, format string, args ...interface{}) {
+	frame, ok := r.vm.scriptFrame()
+	if !ok {
+		return
+	}
+	p := r.perfWarnings
+	site := perfSite{prg: frame.prg, pc: frame.pc, kind: kind}
+	n := p.counts[site] + 1
+	p.counts[site] = n
+	if n == perfRepeatCount {
+		p.handler(PerformanceWarning{
+			Kind:    kind,
+			Message: fmt.Sprintf(format, args...),
+			Frame:   frame,
+		})
+	}
+}
+
+func (r *Runtime) checkConcatPerf(res valueString) {
+	if res.length() >= perfConcatMinLength {
+		r.countPerfSite(PerformanceWarningStringConcat,
+			"Repeated concatenation of long strings (%d characters), consider collecting the parts in an array and calling join()", res.length())
+	}
+}
+
+func (r *Runtime) checkShiftPerf(length int64) {
+	if length >= perfShiftMinLength {
+		r.countPerfSite(PerformanceWarningArrayShift,
+			"Repeated shift() on a large array (%d elements), consider reading it with an index instead", length)
+	}
+}
@@ -0,0 +1,55 @@
+package goja
+
+import (
+	"testing"
+)
+
+func TestRuntime_SetPerformanceWarnings(t *testing.T) {
+	const SCRIPT = `
+	var s = "";
+	for (var i = 0; i < 20000; i++) {
+		s += "x";
+	}
+	var q = [];
+	for (var i = 0; i < 2000; i++) {
+		q.push(i);
+	}
+	while (q.length > 0) {
+		q.shift();
+	}
+	var parts = [];
+	for (var i = 0; i < 20000; i++) {
+		parts.push("x");
+	}
+	parts.join("");
+	`
+
+	r := New()
+	var warnings []PerformanceWarning
+	r.SetPerformanceWarnings(func(w PerformanceWarning) {
+		warnings = append(warnings, w)
+	})
+	_, err := r.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("Unexpected warnings: %v", warnings)
+	}
+	if w := warnings[0]; w.Kind != PerformanceWarningStringConcat || w.Frame.Position().Line != 4 {
+		t.Fatalf("Unexpected warning: %v", w)
+	}
+	if w := warnings[1]; w.Kind != PerformanceWarningArrayShift || w.Frame.Position().Line != 11 {
+		t.Fatalf("Unexpected warning: %v", w)
+	}
+
+	warnings = nil
+	r.SetPerformanceWarnings(nil)
+	_, err = r.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("Unexpected warnings: %v", warnings)
+	}
+}
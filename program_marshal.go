@@ -0,0 +1,670 @@
+package goja
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"reflect"
+)
+
+// programMagic starts the binary form of a Program, the last byte is the version of the format. The version must
+// be incremented whenever the instruction set or the encoding changes. The encoded program follows, and then the
+// CRC-32 (IEEE) of the encoded program, big-endian.
+const programMagic = "goja\x00prg\x02"
+
+// programOpcodes lists the instructions that can be marshalled, the index of an instruction's type plus one is its
+// opcode (0 is used for the unused slots of the code, which are nil). New instructions must be appended at the end
+// (and the format version incremented).
+var programOpcodes = []instruction{
+	_newStash{},
+	_noop{},
+	loadVal(0),
+	_loadUndef{},
+	_loadNil{},
+	_loadGlobalObject{},
+	_loadNewTarget{},
+	loadStack(0),
+	_loadCallee{},
+	storeStack(0),
+	storeStackP(0),
+	_toNumber{},
+	_add{},
+	_sub{},
+	_mul{},
+	_div{},
+	_mod{},
+	_neg{},
+	_plus{},
+	_inc{},
+	_dec{},
+	_and{},
+	_or{},
+	_xor{},
+	_bnot{},
+	_sal{},
+	_sar{},
+	_shr{},
+	_halt{},
+	jump(0),
+	_setElem{},
+	_setElemStrict{},
+	_deleteElem{},
+	_deleteElemStrict{},
+	deleteProp(""),
+	deletePropStrict(""),
+	setProp(""),
+	setPropStrict(""),
+	setProp1(""),
+	_setProto{},
+	setPropGetter(""),
+	setPropSetter(""),
+	getProp(""),
+	getPropCallee(""),
+	_getElem{},
+	_getElemCallee{},
+	_dup{},
+	dupN(0),
+	rdupN(0),
+	_newObject{},
+	newArray(0),
+	&newRegexp{},
+	setLocal(0),
+	setLocalP(0),
+	setVar{},
+	resolveVar1(""),
+	deleteVar(""),
+	deleteGlobal(""),
+	resolveVar1Strict(""),
+	setGlobal(""),
+	setVarStrict{},
+	setVar1Strict(""),
+	setGlobalStrict(""),
+	getLocal(0),
+	getVar{},
+	resolveVar{},
+	_getValue{},
+	_putValue{},
+	getVar1(""),
+	getVar1Callee(""),
+	_pop{},
+	_swap{},
+	callEval(0),
+	callEvalStrict(0),
+	_boxThis{},
+	call(0),
+	tailCall(0),
+	enterFunc(0),
+	_ret{},
+	enterFuncStashless{},
+	_retStashless{},
+	&newFunc{},
+	bindName(""),
+	jne(0),
+	jeq(0),
+	jeq1(0),
+	jneq1(0),
+	_not{},
+	_op_lt{},
+	_op_lte{},
+	_op_gt{},
+	_op_gte{},
+	_op_eq{},
+	_op_neq{},
+	_op_strict_eq{},
+	_op_strict_neq{},
+	_op_instanceof{},
+	_op_in{},
+	try{},
+	_retFinally{},
+	enterCatch(""),
+	_throw{},
+	_new(0),
+	_typeof{},
+	createArgs(0),
+	createArgsStrict(0),
+	_enterWith{},
+	_leaveWith{},
+	_enumerate{},
+	enumNext(0),
+	_enumGet{},
+	_enumPop{},
+}
+
+var programOpcodeTypes = func() map[reflect.Type]uint64 {
+	m := make(map[reflect.Type]uint64, len(programOpcodes))
+	for i, ins := range programOpcodes {
+		m[reflect.TypeOf(ins)] = uint64(i) + 1
+	}
+	return m
+}()
+
+// The tags of the literal values.
+const (
+	programValueUndefined byte = iota
+	programValueNull
+	programValueFalse
+	programValueTrue
+	programValueInt
+	programValueFloat
+	programValueASCII
+	programValueUnicode
+)
+
+// ErrInvalidProgram is returned by Program.UnmarshalBinary() when the data is not a valid marshalled Program, e.g.
+// because it has been produced by a different version of the package or has been corrupted.
+var ErrInvalidProgram = errors.New("invalid or incompatible binary program")
+
+type programEncoder struct {
+	buf bytes.Buffer
+	// srcFiles maps the source files to their indexes, a source file is shared by a program and its functions.
+	srcFiles map[*SrcFile]uint64
+	tmp      [binary.MaxVarintLen64]byte
+}
+
+func (e *programEncoder) uint(v uint64) {
+	e.buf.Write(e.tmp[:binary.PutUvarint(e.tmp[:], v)])
+}
+
+func (e *programEncoder) int(v int64) {
+	e.buf.Write(e.tmp[:binary.PutVarint(e.tmp[:], v)])
+}
+
+func (e *programEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *programEncoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+// MarshalBinary encodes the compiled program (the bytecode, the literal values and the source with the positions)
+// so that it can be cached, e.g. on disk, and loaded with UnmarshalBinary() without compiling it again. The format
+// is only compatible with the same version of the package.
+// Programs bound with Bind() cannot be marshalled, and the PositionMapper is not included.
+func (p *Program) MarshalBinary() ([]byte, error) {
+	e := &programEncoder{
+		srcFiles: make(map[*SrcFile]uint64),
+	}
+	e.buf.WriteString(programMagic)
+	if err := e.program(p); err != nil {
+		return nil, err
+	}
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(e.buf.Bytes()[len(programMagic):]))
+	e.buf.Write(sum[:])
+	return e.buf.Bytes(), nil
+}
+
+func (e *programEncoder) program(p *Program) error {
+	e.string(p.funcName)
+	e.srcFile(p.src)
+
+	e.uint(uint64(len(p.values)))
+	for _, v := range p.values {
+		if err := e.value(v); err != nil {
+			return err
+		}
+	}
+
+	e.uint(uint64(len(p.srcMap)))
+	for _, item := range p.srcMap {
+		e.uint(uint64(item.pc))
+		e.int(int64(item.srcPos))
+	}
+
+	e.uint(uint64(len(p.code)))
+	for _, ins := range p.code {
+		if err := e.instruction(ins); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// srcFile writes the index of the source file (0 for none), followed by the file itself when it's its first use.
+func (e *programEncoder) srcFile(f *SrcFile) {
+	if f == nil {
+		e.uint(0)
+		return
+	}
+	if idx, exists := e.srcFiles[f]; exists {
+		e.uint(idx)
+		return
+	}
+	idx := uint64(len(e.srcFiles) + 1)
+	e.srcFiles[f] = idx
+	e.uint(idx)
+	e.string(f.name)
+	e.string(f.src)
+	e.bool(f.origin != nil)
+	if f.origin != nil {
+		e.string(f.origin.URL)
+		e.string(f.origin.Tenant)
+		e.string(f.origin.Integrity)
+	}
+}
+
+func (e *programEncoder) value(v Value) error {
+	switch v := v.(type) {
+	case valueUndefined:
+		e.buf.WriteByte(programValueUndefined)
+	case valueNull:
+		e.buf.WriteByte(programValueNull)
+	case valueBool:
+		if v {
+			e.buf.WriteByte(programValueTrue)
+		} else {
+			e.buf.WriteByte(programValueFalse)
+		}
+	case valueInt:
+		e.buf.WriteByte(programValueInt)
+		e.int(int64(v))
+	case valueFloat:
+		e.buf.WriteByte(programValueFloat)
+		e.uint(math.Float64bits(float64(v)))
+	case asciiString:
+		e.buf.WriteByte(programValueASCII)
+		e.string(string(v))
+	case unicodeString:
+		e.buf.WriteByte(programValueUnicode)
+		e.uint(uint64(len(v)))
+		for _, c := range v {
+			e.uint(uint64(c))
+		}
+	default:
+		return fmt.Errorf("cannot marshal literal value of type %T", v)
+	}
+	return nil
+}
+
+func (e *programEncoder) instruction(ins instruction) error {
+	if ins == nil {
+		e.uint(0)
+		return nil
+	}
+	opcode, ok := programOpcodeTypes[reflect.TypeOf(ins)]
+	if !ok {
+		return fmt.Errorf("cannot marshal instruction %T", ins)
+	}
+	e.uint(opcode)
+	switch ins := ins.(type) {
+	case *newRegexp:
+		e.string(ins.src.String())
+		e.string(ins.re.flags())
+	case setVar:
+		e.string(ins.name)
+		e.uint(uint64(ins.idx))
+	case setVarStrict:
+		e.string(ins.name)
+		e.uint(uint64(ins.idx))
+	case getVar:
+		e.string(ins.name)
+		e.uint(uint64(ins.idx))
+		e.bool(ins.ref)
+	case resolveVar:
+		e.string(ins.name)
+		e.uint(uint64(ins.idx))
+		e.bool(ins.strict)
+	case enterFuncStashless:
+		e.uint(uint64(ins.stackSize))
+		e.uint(uint64(ins.args))
+	case *newFunc:
+		e.string(ins.name)
+		e.uint(uint64(ins.length))
+		e.bool(ins.strict)
+		e.uint(uint64(ins.srcStart))
+		e.uint(uint64(ins.srcEnd))
+		return e.program(ins.prg)
+	case try:
+		e.int(int64(ins.catchOffset))
+		e.int(int64(ins.finallyOffset))
+		e.bool(ins.dynamic)
+	default:
+		v := reflect.ValueOf(ins)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int32:
+			e.int(v.Int())
+		case reflect.Uint32:
+			e.uint(v.Uint())
+		case reflect.String:
+			e.string(v.String())
+		}
+	}
+	return nil
+}
+
+type programDecoder struct {
+	r        *bytes.Reader
+	srcFiles []*SrcFile
+	err      error
+}
+
+func (d *programDecoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidProgram
+	}
+}
+
+func (d *programDecoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.fail()
+	}
+	return v
+}
+
+func (d *programDecoder) int() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.fail()
+	}
+	return v
+}
+
+func (d *programDecoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		d.fail()
+	}
+	return b
+}
+
+func (d *programDecoder) bool() bool {
+	return d.byte() != 0
+}
+
+// length reads the length of a sequence, each element of which takes at least one byte.
+func (d *programDecoder) length() int {
+	l := d.uint()
+	if l > uint64(d.r.Len()) {
+		d.fail()
+		return 0
+	}
+	return int(l)
+}
+
+func (d *programDecoder) string() string {
+	l := d.length()
+	if d.err != nil {
+		return ""
+	}
+	b := make([]byte, l)
+	d.r.Read(b)
+	return string(b)
+}
+
+// UnmarshalBinary decodes a program encoded with MarshalBinary(). The program can be run by any Runtime.
+// The data is checked for corruption with a checksum, and the decoded code is checked to only jump to its own
+// instructions and to use the literal values and the stack variables that exist. This is not a verification of the
+// bytecode though: the data must come from a trusted source, such as a cache written by the application itself,
+// because a crafted program can still crash the Runtime or do what the compiler would never produce.
+func (p *Program) UnmarshalBinary(data []byte) error {
+	if len(data) < len(programMagic)+4 || !bytes.HasPrefix(data, []byte(programMagic)) {
+		return ErrInvalidProgram
+	}
+	payload := data[len(programMagic) : len(data)-4]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return ErrInvalidProgram
+	}
+	d := &programDecoder{
+		r: bytes.NewReader(payload),
+	}
+	var res Program
+	d.program(&res)
+	if d.err == nil && d.r.Len() > 0 {
+		d.fail()
+	}
+	if d.err != nil {
+		return d.err
+	}
+	*p = res
+	return nil
+}
+
+func (d *programDecoder) program(p *Program) {
+	p.funcName = d.string()
+	p.src = d.srcFile()
+
+	if l := d.length(); l > 0 {
+		p.values = make([]Value, l)
+		for i := range p.values {
+			p.values[i] = d.value()
+		}
+	}
+
+	if l := d.length(); l > 0 {
+		p.srcMap = make([]srcMapItem, l)
+		for i := range p.srcMap {
+			p.srcMap[i] = srcMapItem{
+				pc:     int(d.uint()),
+				srcPos: int(d.int()),
+			}
+		}
+	}
+
+	if l := d.length(); l > 0 {
+		p.code = make([]instruction, l)
+		for i := range p.code {
+			p.code[i] = d.instruction()
+			if d.err != nil {
+				return
+			}
+		}
+	}
+	d.validate(p)
+}
+
+// validate follows the control flow of the code and checks that it only reaches the existing non-nil
+// instructions, and that the literal values and the stack variables it uses exist.
+func (d *programDecoder) validate(p *Program) {
+	if d.err != nil {
+		return
+	}
+	if len(p.code) == 0 {
+		d.fail()
+		return
+	}
+	// the stack variables only exist in the functions which keep them on the stack
+	var stackSize, args int
+	if e, ok := p.code[0].(enterFuncStashless); ok {
+		stackSize, args = int(e.stackSize), int(e.args)
+	}
+	validStack := func(idx int) bool {
+		return idx <= stackSize && -idx <= args
+	}
+
+	reached := make([]bool, len(p.code))
+	queue := []int{0}
+	for len(queue) > 0 {
+		pc := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if pc < 0 || pc >= len(p.code) || p.code[pc] == nil {
+			d.fail()
+			return
+		}
+		if reached[pc] {
+			continue
+		}
+		reached[pc] = true
+		next := pc + 1
+		switch ins := p.code[pc].(type) {
+		case jump:
+			next = pc + int(ins)
+		case jne:
+			queue = append(queue, pc+int(ins))
+		case jeq:
+			queue = append(queue, pc+int(ins))
+		case jeq1:
+			queue = append(queue, pc+int(ins))
+		case jneq1:
+			queue = append(queue, pc+int(ins))
+		case enumNext:
+			queue = append(queue, pc+int(ins))
+		case try:
+			if ins.catchOffset > 0 {
+				queue = append(queue, pc+int(ins.catchOffset))
+			}
+			if ins.finallyOffset > 0 {
+				queue = append(queue, pc+int(ins.finallyOffset))
+			}
+		case loadVal:
+			if int(ins) >= len(p.values) {
+				d.fail()
+				return
+			}
+		case loadStack:
+			if !validStack(int(ins)) {
+				d.fail()
+				return
+			}
+		case storeStack:
+			if ins == 0 || !validStack(int(ins)) {
+				d.fail()
+				return
+			}
+		case storeStackP:
+			if ins == 0 || !validStack(int(ins)) {
+				d.fail()
+				return
+			}
+		case _halt, _retFinally:
+			// the end of the code, a try block or a finally block
+			if next == len(p.code) {
+				continue
+			}
+		case _ret, _retStashless, _throw:
+			continue
+		}
+		queue = append(queue, next)
+	}
+}
+
+func (d *programDecoder) srcFile() *SrcFile {
+	idx := d.uint()
+	switch {
+	case idx == 0 || d.err != nil:
+		return nil
+	case idx <= uint64(len(d.srcFiles)):
+		return d.srcFiles[idx-1]
+	case idx != uint64(len(d.srcFiles)+1):
+		d.fail()
+		return nil
+	}
+	f := NewSrcFile(d.string(), d.string())
+	if d.bool() {
+		f.origin = &ScriptOrigin{
+			URL:       d.string(),
+			Tenant:    d.string(),
+			Integrity: d.string(),
+		}
+	}
+	d.srcFiles = append(d.srcFiles, f)
+	return f
+}
+
+func (d *programDecoder) value() Value {
+	switch d.byte() {
+	case programValueUndefined:
+		return _undefined
+	case programValueNull:
+		return _null
+	case programValueFalse:
+		return valueFalse
+	case programValueTrue:
+		return valueTrue
+	case programValueInt:
+		return valueInt(d.int())
+	case programValueFloat:
+		return valueFloat(math.Float64frombits(d.uint()))
+	case programValueASCII:
+		return asciiString(d.string())
+	case programValueUnicode:
+		s := make(unicodeString, d.length())
+		for i := range s {
+			s[i] = uint16(d.uint())
+		}
+		return s
+	}
+	d.fail()
+	return _undefined
+}
+
+func (d *programDecoder) instruction() instruction {
+	opcode := d.uint()
+	if opcode == 0 || d.err != nil {
+		return nil
+	}
+	if opcode > uint64(len(programOpcodes)) {
+		d.fail()
+		return nil
+	}
+	opcode--
+	switch programOpcodes[opcode].(type) {
+	case *newRegexp:
+		src := d.string()
+		flags := d.string()
+		if d.err != nil {
+			return nil
+		}
+		re, err := compileRegexp(src, flags)
+		if err != nil {
+			d.fail()
+			return nil
+		}
+		return &newRegexp{
+			re:  re,
+			src: newStringValue(src),
+		}
+	case setVar:
+		return setVar{name: d.string(), idx: uint32(d.uint())}
+	case setVarStrict:
+		return setVarStrict{name: d.string(), idx: uint32(d.uint())}
+	case getVar:
+		return getVar{name: d.string(), idx: uint32(d.uint()), ref: d.bool()}
+	case resolveVar:
+		return resolveVar{name: d.string(), idx: uint32(d.uint()), strict: d.bool()}
+	case enterFuncStashless:
+		return enterFuncStashless{stackSize: uint32(d.uint()), args: uint32(d.uint())}
+	case *newFunc:
+		f := &newFunc{
+			name:     d.string(),
+			length:   uint32(d.uint()),
+			strict:   d.bool(),
+			srcStart: uint32(d.uint()),
+			srcEnd:   uint32(d.uint()),
+			prg:      &Program{funcCode: true},
+		}
+		d.program(f.prg)
+		return f
+	case try:
+		return try{catchOffset: int32(d.int()), finallyOffset: int32(d.int()), dynamic: d.bool()}
+	}
+	t := reflect.TypeOf(programOpcodes[opcode])
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32:
+		v.SetInt(d.int())
+	case reflect.Uint32:
+		v.SetUint(d.uint())
+	case reflect.String:
+		v.SetString(d.string())
+	}
+	return v.Interface().(instruction)
+}
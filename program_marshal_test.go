@@ -0,0 +1,195 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgramMarshalBinary(t *testing.T) {
+	const SCRIPT = `
+	var log = [];
+	function Counter(start) {
+		this.n = start;
+	}
+	Counter.prototype = {
+		get double() { return this.n * 2; },
+		set value(v) { this.n = v; },
+		inc: function() { return ++this.n; }
+	};
+
+	function sum() {
+		var s = 0;
+		for (var i = 0; i < arguments.length; i++) {
+			s += arguments[i];
+		}
+		return s;
+	}
+
+	function makeAdder(x) {
+		return function(y) { return x + y; };
+	}
+
+	function Target() {
+		this.t = eval("new.target") === Target;
+	}
+
+	var c = new Counter(1);
+	c.inc();
+	c.value = 5;
+	log.push(c.double, c instanceof Counter, "n" in c, typeof c.inc);
+
+	var o = {a: 1, b: "two", "ünï": 3.5};
+	delete o.a;
+	for (var k in o) {
+		log.push(k + "=" + o[k]);
+	}
+
+	try {
+		null.x;
+	} catch (e) {
+		log.push(e instanceof TypeError);
+	} finally {
+		log.push("finally");
+	}
+
+	outer: for (var i = 0; i < 3; i++) {
+		switch (i) {
+		case 1:
+			continue outer;
+		default:
+			log.push(i << 2 | 1, ~i, -i >>> 28);
+		}
+	}
+
+	with ({w: 42}) {
+		log.push(w);
+	}
+
+	var re = /(\d+)-(\d+)/gi;
+	log.push("10-20 30-40".replace(re, "$2:$1"), re.flags);
+	log.push(makeAdder(1)(2), sum(1, 2, 3), eval("c.n + 1"), 0.1 + 0.2, "☺".length, new Target().t);
+	log.join(",");
+	`
+
+	p, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p1 Program
+	if err := p1.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := New().RunProgram(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := New().RunProgram(&p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != expected.String() {
+		t.Fatalf("Unexpected result: %s, expected %s", res, expected)
+	}
+
+	data1, err := p1.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data1) != string(data) {
+		t.Fatal("The program has changed after a round trip")
+	}
+}
+
+func TestProgramMarshalBinaryPositions(t *testing.T) {
+	const SCRIPT = `
+	function f() {
+		throw new Error("boom");
+	}
+	f();
+	`
+	p, err := CompileWithOrigin("pos.js", SCRIPT, false, &ScriptOrigin{URL: "https://example.com/pos.js"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p1 Program
+	if err := p1.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if o := p1.Origin(); o == nil || o.URL != "https://example.com/pos.js" {
+		t.Fatalf("Unexpected origin: %v", o)
+	}
+	_, err = New().RunProgram(&p1)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s := ex.String(); !strings.Contains(s, "f (pos.js:3:9(") {
+		t.Fatalf("Unexpected stack: %s", s)
+	}
+}
+
+func TestProgramUnmarshalBinaryInvalid(t *testing.T) {
+	p, err := Compile("", "var x = 1 + 2; x", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range [][]byte{
+		nil,
+		[]byte("not a program"),
+		data[:len(data)-1],
+		append(append([]byte{}, data...), 0),
+		append(append([]byte{}, data[:len(data)-5]...), data[len(data)-5]^1, 0, 0, 0, 0),
+	} {
+		var p1 Program
+		if err := p1.UnmarshalBinary(d); err != ErrInvalidProgram {
+			t.Fatalf("Unexpected error for %q: %v", d, err)
+		}
+	}
+}
+
+func TestProgramUnmarshalBinaryInvalidCode(t *testing.T) {
+	for _, corrupt := range []func(p *Program){
+		func(p *Program) { p.code[0] = jump(len(p.code)) },
+		func(p *Program) { p.code[0] = jump(-1) },
+		func(p *Program) { p.code[0] = nil },
+		func(p *Program) { p.code[0] = loadVal(len(p.values)) },
+		func(p *Program) { p.code[0] = loadStack(1) },
+		func(p *Program) { p.code[len(p.code)-1] = pop },
+		func(p *Program) { p.code = nil },
+		func(p *Program) { p.code[2].(*newFunc).prg.code[1] = storeStack(-3) },
+		func(p *Program) { p.code[2].(*newFunc).prg.code[4] = nil },
+	} {
+		p, err := Compile("", "var f = function(a, b) { return a + b; }; f(1, 2)", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrupt(p)
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p1 Program
+		if err := p1.UnmarshalBinary(data); err != ErrInvalidProgram {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestProgramOpcodes(t *testing.T) {
+	if len(programOpcodeTypes) != len(programOpcodes) {
+		t.Fatal("Duplicate instruction types")
+	}
+}
@@ -1,9 +1,11 @@
 package goja
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/dlclark/regexp2"
 	"regexp"
+	"sort"
 	"unicode/utf16"
 	"unicode/utf8"
 )
@@ -21,21 +23,79 @@ type regexpWrapper regexp.Regexp
 
 type regexpObject struct {
 	baseObject
-	pattern regexpPattern
-	source  valueString
+	pattern          regexpPattern
+	groupNames       []string // names of the capture groups, nil if there are no named groups
+	lookbehindGroups []bool   // whether the capture groups are inside a lookbehind, nil if there is no lookbehind
+	source           valueString
 
-	global, multiline, ignoreCase bool
+	regexpFlagSet
+}
+
+// regexpFlagSet holds the flags of a regular expression.
+type regexpFlagSet struct {
+	global, multiline, ignoreCase, unicode, sticky, dotAll, hasIndices bool
+}
+
+// regexpFlags lists the flags (the names of their properties and their characters) in the order they appear in
+// RegExp.prototype.flags. The parsing and the formatting of the flags are derived from it.
+var regexpFlags = []struct {
+	name  string
+	chr   byte
+	field func(f *regexpFlagSet) *bool
+}{
+	{"hasIndices", 'd', func(f *regexpFlagSet) *bool { return &f.hasIndices }},
+	{"global", 'g', func(f *regexpFlagSet) *bool { return &f.global }},
+	{"ignoreCase", 'i', func(f *regexpFlagSet) *bool { return &f.ignoreCase }},
+	{"multiline", 'm', func(f *regexpFlagSet) *bool { return &f.multiline }},
+	{"dotAll", 's', func(f *regexpFlagSet) *bool { return &f.dotAll }},
+	{"unicode", 'u', func(f *regexpFlagSet) *bool { return &f.unicode }},
+	{"sticky", 'y', func(f *regexpFlagSet) *bool { return &f.sticky }},
+}
+
+// parseFlags sets the flags listed in s. It returns false if s contains an unknown or a repeated flag.
+func (f *regexpFlagSet) parseFlags(s string) bool {
+next:
+	for i := 0; i < len(s); i++ {
+		for _, flag := range regexpFlags {
+			if s[i] == flag.chr {
+				if p := flag.field(f); !*p {
+					*p = true
+					continue next
+				}
+				return false
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// flags returns the flags in the same order as RegExp.prototype.flags.
+func (f *regexpFlagSet) flags() string {
+	var buf bytes.Buffer
+	for _, flag := range regexpFlags {
+		if *flag.field(f) {
+			buf.WriteByte(flag.chr)
+		}
+	}
+	return buf.String()
 }
 
 func (r *regexp2Wrapper) FindSubmatchIndex(s valueString, start int) (result []int) {
 	wrapped := (*regexp2.Regexp)(r)
 	var match *regexp2.Match
 	var err error
+	var posMap []int // maps rune indexes to UTF-16 positions
+	// The whole string is searched (rather than s[start:]) so that lookbehind assertions can see the text
+	// before the start position.
 	switch s := s.(type) {
 	case asciiString:
-		match, err = wrapped.FindStringMatch(string(s)[start:])
+		match, err = wrapped.FindStringMatchStartingAt(string(s), start)
 	case unicodeString:
-		match, err = wrapped.FindRunesMatch(utf16.Decode(s[start:]))
+		var runes []rune
+		runes, posMap = utf16DecodeWithPositions(s)
+		startAt := sort.SearchInts(posMap, start)
+		match, err = wrapped.FindRunesMatchStartingAt(runes, startAt)
 	default:
 		panic(fmt.Errorf("Unknown string type: %T", s))
 	}
@@ -51,7 +111,11 @@ func (r *regexp2Wrapper) FindSubmatchIndex(s valueString, start int) (result []i
 	result = make([]int, 0, len(groups)<<1)
 	for _, group := range groups {
 		if len(group.Captures) > 0 {
-			result = append(result, group.Index, group.Index+group.Length)
+			if posMap != nil {
+				result = append(result, posMap[group.Index], posMap[group.Index+group.Length])
+			} else {
+				result = append(result, group.Index, group.Index+group.Length)
+			}
 		} else {
 			result = append(result, -1, 0)
 		}
@@ -59,6 +123,30 @@ func (r *regexp2Wrapper) FindSubmatchIndex(s valueString, start int) (result []i
 	return
 }
 
+// utf16DecodeWithPositions decodes the string into runes in the same way as utf16.Decode() does it and returns
+// the position of each rune in the string (followed by the length of the string).
+func utf16DecodeWithPositions(s unicodeString) (runes []rune, posMap []int) {
+	runes = make([]rune, 0, len(s))
+	posMap = make([]int, 0, len(s)+1)
+	for i := 0; i < len(s); i++ {
+		posMap = append(posMap, i)
+		r := rune(s[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(s) {
+				if r1 := utf16.DecodeRune(r, rune(s[i+1])); r1 != utf8.RuneError {
+					runes = append(runes, r1)
+					i++
+					continue
+				}
+			}
+			r = utf8.RuneError
+		}
+		runes = append(runes, r)
+	}
+	posMap = append(posMap, len(s))
+	return
+}
+
 func (r *regexp2Wrapper) FindAllSubmatchIndexUTF8(s string, n int) [][]int {
 	wrapped := (*regexp2.Regexp)(r)
 	if n < 0 {
@@ -217,7 +305,14 @@ func (r *regexp2Wrapper) MatchString(s valueString) bool {
 
 func (r *regexpWrapper) FindSubmatchIndex(s valueString, start int) (result []int) {
 	wrapped := (*regexp.Regexp)(r)
-	return wrapped.FindReaderSubmatchIndex(runeReaderReplace{s.reader(start)})
+	result = wrapped.FindReaderSubmatchIndex(runeReaderReplace{s.reader(start)})
+	// The match was done on a reader starting at start, not on the whole string
+	for i, pos := range result {
+		if pos >= 0 {
+			result[i] += start
+		}
+	}
+	return
 }
 
 func (r *regexpWrapper) MatchString(s valueString) bool {
@@ -283,10 +378,19 @@ func (r *regexpObject) execResultToArray(target valueString, result []int) Value
 	captureCount := len(result) >> 1
 	valueArray := make([]Value, captureCount)
 	matchIndex := result[0]
+	// The engines don't reset the captures of a quantified group on each iteration, a capture that starts before the
+	// previous one is left over from an earlier iteration. This doesn't hold for the captures inside a lookbehind,
+	// which precede the match.
 	lowerBound := matchIndex
 	for index := 0; index < captureCount; index++ {
 		offset := index << 1
-		if result[offset] >= lowerBound {
+		if index < len(r.lookbehindGroups) && r.lookbehindGroups[index] {
+			if result[offset] >= 0 {
+				valueArray[index] = target.substring(int64(result[offset]), int64(result[offset+1]))
+			} else {
+				valueArray[index] = _undefined
+			}
+		} else if result[offset] >= lowerBound {
 			valueArray[index] = target.substring(int64(result[offset]), int64(result[offset+1]))
 			lowerBound = result[offset]
 		} else {
@@ -296,9 +400,46 @@ func (r *regexpObject) execResultToArray(target valueString, result []int) Value
 	match := r.val.runtime.newArrayValues(valueArray)
 	match.self.putStr("input", target, false)
 	match.self.putStr("index", intToValue(int64(matchIndex)), false)
+	match.self.putStr("groups", r.groups(valueArray), false)
+	if r.hasIndices {
+		match.self.putStr("indices", r.indices(result), false)
+	}
 	return match
 }
 
+// indices returns the array of [start, end] pairs of the captures of a match (undefined for the captures that
+// didn't participate), as required by the 'd' flag.
+func (r *regexpObject) indices(result []int) Value {
+	runtime := r.val.runtime
+	captureCount := len(result) >> 1
+	pairs := make([]Value, captureCount)
+	for index := 0; index < captureCount; index++ {
+		offset := index << 1
+		if result[offset] >= 0 {
+			pairs[index] = runtime.newArrayValues([]Value{intToValue(int64(result[offset])), intToValue(int64(result[offset+1]))})
+		} else {
+			pairs[index] = _undefined
+		}
+	}
+	indices := runtime.newArrayValues(pairs)
+	indices.self.putStr("groups", r.groups(pairs), false)
+	return indices
+}
+
+// groups returns the object with the named groups of a match, or undefined if there are no named groups.
+func (r *regexpObject) groups(captures []Value) Value {
+	if r.groupNames == nil {
+		return _undefined
+	}
+	groups := r.val.runtime.newBaseObject(nil, classObject)
+	for i, name := range r.groupNames {
+		if name != "" {
+			groups.putStr(name, captures[i], false)
+		}
+	}
+	return groups.val
+}
+
 func (r *regexpObject) execRegexp(target valueString) (match bool, result []int) {
 	lastIndex := int64(0)
 	if p := r.getStr("lastIndex"); p != nil {
@@ -308,26 +449,20 @@ func (r *regexpObject) execRegexp(target valueString) (match bool, result []int)
 		}
 	}
 	index := lastIndex
-	if !r.global {
+	if !r.global && !r.sticky {
 		index = 0
 	}
 	if index >= 0 && index <= target.length() {
 		result = r.pattern.FindSubmatchIndex(target, int(index))
 	}
-	if result == nil {
+	if result == nil || r.sticky && result[0] != int(index) {
+		// a sticky regexp only matches at lastIndex
 		r.putStr("lastIndex", intToValue(0), true)
-		return
+		return false, nil
 	}
 	match = true
-	startIndex := index
-	endIndex := int(lastIndex) + result[1]
-	// We do this shift here because the .FindStringSubmatchIndex above
-	// was done on a local subordinate slice of the string, not the whole string
-	for index, _ := range result {
-		result[index] += int(startIndex)
-	}
-	if r.global {
-		r.putStr("lastIndex", intToValue(int64(endIndex)), true)
+	if r.global || r.sticky {
+		r.putStr("lastIndex", intToValue(int64(result[1])), true)
 	}
 	return
 }
@@ -349,9 +484,9 @@ func (r *regexpObject) clone() *Object {
 	r1 := r.val.runtime.newRegexpObject(r.prototype)
 	r1.source = r.source
 	r1.pattern = r.pattern
-	r1.global = r.global
-	r1.ignoreCase = r.ignoreCase
-	r1.multiline = r.multiline
+	r1.groupNames = r.groupNames
+	r1.lookbehindGroups = r.lookbehindGroups
+	r1.regexpFlagSet = r.regexpFlagSet
 	return r1.val
 }
 
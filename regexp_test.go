@@ -1,7 +1,10 @@
 package goja
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"unicode/utf16"
 )
 
 func TestRegexp1(t *testing.T) {
@@ -173,3 +176,407 @@ func TestRegexpDotMatchSlashRInGroup(t *testing.T) {
 
 	testScript1(SCRIPT, valueFalse, t)
 }
+
+func TestRegexpUnicodeFlag(t *testing.T) {
+	const SCRIPT = `
+	var r = /^.$/u;
+	assert(r.unicode, "unicode");
+	assert(!/a/.unicode, "not unicode");
+	assert.sameValue(r.toString(), "/^.$/u", "toString");
+	assert(r.test("\uD83D\uDE00"), "dot matches a code point");
+	assert(/\u{1F600}/u.test("\uD83D\uDE00"), "code point escape");
+	assert(/^[\uD83D\uDE00]$/u.test("\uD83D\uDE00"), "surrogate pair escape in class");
+	assert(new RegExp("\\u{61}", "u").test("a"), "RegExp constructor");
+
+	var m = /(\u{1F600})(b)\2/u.exec("a\uD83D\uDE00bb");
+	assert.sameValue(m.index, 1, "regexp2 index");
+	assert.sameValue(m[1], "\uD83D\uDE00", "regexp2 group");
+
+	var thrown = 0;
+	["\\a", "a{", "]", "\\u{110000}"].forEach(function(src) {
+		try {
+			new RegExp(src, "u");
+		} catch (e) {
+			if (e instanceof SyntaxError) {
+				thrown++;
+			}
+		}
+	});
+	assert.sameValue(thrown, 4, "syntax errors");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpStickyFlag(t *testing.T) {
+	const SCRIPT = `
+	var r = /a+/y;
+	assert(r.sticky, "sticky");
+	assert.sameValue(r.toString(), "/a+/y", "toString");
+	assert.sameValue(r.exec("baa"), null, "no match at lastIndex 0");
+	r.lastIndex = 1;
+	var m = r.exec("baab");
+	assert.sameValue(m[0], "aa", "match at lastIndex");
+	assert.sameValue(m.index, 1, "index");
+	assert.sameValue(r.lastIndex, 3, "lastIndex");
+	assert(!r.test("baab"), "no match after lastIndex");
+	assert.sameValue(r.lastIndex, 0, "lastIndex reset");
+
+	var tokens = [], re = /\s*(\d+|[+*])/y, t;
+	while ((t = re.exec("1 + 22*x")) !== null) {
+		tokens.push(t[1]);
+	}
+	assert.sameValue(tokens.join(","), "1,+,22,*", "tokens");
+	assert.sameValue("aaba".match(/a/gy).length, 2, "match");
+
+	r = /^b/y;
+	r.lastIndex = 1;
+	assert(!r.test("ab"), "^ with lastIndex");
+	r = /^b/my;
+	r.lastIndex = 2;
+	assert(r.test("a\nb"), "^ after a line terminator");
+	r.lastIndex = 1;
+	assert(!r.test("ab"), "^ with m and lastIndex");
+	r = /\bb/y;
+	r.lastIndex = 1;
+	assert(!r.test("ab"), "\\b with lastIndex");
+	r = /\Bb/y;
+	r.lastIndex = 1;
+	assert(r.test("ab"), "\\B with lastIndex");
+	r = /^b/g;
+	r.lastIndex = 1;
+	assert.sameValue(r.exec("ab"), null, "^ with g");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpFlags(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(/a/yumig.flags, "gimuy", "all flags");
+	assert.sameValue(/a/.flags, "", "no flags");
+	assert.sameValue(new RegExp("a", "yg").toString(), "/a/gy", "toString");
+	var getter = Object.getOwnPropertyDescriptor(RegExp.prototype, "flags").get;
+	assert.sameValue(getter.call({global: 1, sticky: true, unicode: 0}), "gy", "generic");
+	assert.sameValue(RegExp.prototype.flags, "", "prototype flags");
+	var sticky = Object.getOwnPropertyDescriptor(RegExp.prototype, "sticky").get;
+	assert.sameValue(sticky.call(RegExp.prototype), undefined, "prototype sticky");
+	assert.sameValue(RegExp.prototype.global, undefined, "prototype global");
+	assert.sameValue(RegExp.prototype.source, "(?:)", "prototype source");
+	var thrown;
+	try {
+		sticky.call({});
+	} catch (e) {
+		thrown = e;
+	}
+	assert(thrown instanceof TypeError, "incompatible receiver");
+	thrown = undefined;
+	try {
+		RegExp.prototype.toString.call(RegExp.prototype);
+	} catch (e) {
+		thrown = e;
+	}
+	assert(thrown instanceof TypeError, "toString of prototype");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpNamedGroups(t *testing.T) {
+	const SCRIPT = `
+	var m = /(?<year>\d{4})-(\d{2})-(?<day>\d{2})/.exec("on 2020-05-17");
+	assert.sameValue(m.groups.year, "2020", "year");
+	assert.sameValue(m.groups.day, "17", "day");
+	assert.sameValue(m[2], "05", "unnamed group number");
+	assert.sameValue(m[3], "17", "named group number");
+	assert.sameValue(Object.getPrototypeOf(m.groups), null, "groups prototype");
+	assert.sameValue(/(a)/.exec("a").groups, undefined, "no named groups");
+
+	// regexp2
+	m = /(?<q>['"])(x)\k<q>(?<rest>.*)/.exec("'x'y");
+	assert.sameValue(m.groups.rest, "y", "backreference");
+	assert.sameValue(m[2], "x", "backreference group number");
+	assert.sameValue(/(?<a>a)|(?<b>b)/.exec("b").groups.a, undefined, "unmatched group");
+
+	assert.sameValue("2020-05-17".replace(/(?<y>\d+)-(?<m>\d+)-(?<d>\d+)/, "$<d>.$<m>.$<y>"), "17.05.2020", "replace");
+	assert.sameValue("ab".replace(/(?<x>a)/, "[$<nope>]"), "[]b", "unknown name");
+	assert.sameValue("ab".replace(/(a)/, "$<x>"), "$<x>b", "no named groups");
+	assert.sameValue("ab".replace(/(?<x>a)/, function() {
+		return arguments[arguments.length - 1].x + "!";
+	}), "a!b", "replace function");
+
+	var errors = 0;
+	["(?<a>.)(?<a>.)", "(?<1a>.)", "(?<a>.)\\k<b>"].forEach(function(src) {
+		try {
+			new RegExp(src);
+		} catch (e) {
+			if (e instanceof SyntaxError) {
+				errors++;
+			}
+		}
+	});
+	assert.sameValue(errors, 3, "syntax errors");
+	assert(/\k<a>/.test("k<a>"), "identity escape without named groups");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpLookbehind(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue("$10 €20".match(/(?<=\$)\d+/)[0], "10", "positive");
+	assert.sameValue("$10 €20".match(/(?<!\$)\b\d+/)[0], "20", "negative");
+	assert.sameValue("abc".replace(/(?<=a)b/g, "X"), "aXc", "replace");
+	assert.sameValue("aby".match(/(?<=(a)b)y/)[1], "a", "capture inside lookbehind");
+	assert.sameValue("aby".match(/(?<=(?<p>a)b)y/).groups.p, "a", "named group inside lookbehind");
+	var re = /(?<=a)b/y;
+	re.lastIndex = 1;
+	assert(re.test("ab"), "sticky");
+	assert.sameValue("xaby".match(/(?<=(a)b)(y)/)[2], "y", "capture after lookbehind");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpDotAll(t *testing.T) {
+	const SCRIPT = `
+	assert(!/a.b/.test("a\nb"), "without s");
+	assert(/a.b/s.test("a\nb"), "with s");
+	assert(/a.b/s.test("a\u2028b"), "line separator");
+	assert(/a[.]b/s.test("a.b") && !/a[.]b/s.test("a\nb"), "dot in class");
+	assert(/a\.b/s.test("a.b") && !/a\.b/s.test("a\nb"), "escaped dot");
+	assert(/^.$/su.test("😀"), "code point");
+	assert(/(?=x)?a.b/s.test("a\nb"), "regexp2");
+	var re = new RegExp("a.b", "s");
+	assert(re.dotAll, "dotAll");
+	assert(!/a/.dotAll, "no dotAll");
+	assert.sameValue(re.flags, "s", "flags");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpIndices(t *testing.T) {
+	const SCRIPT = `
+	var m = /b(c)?(?<x>d)/d.exec("abdbcd");
+	assert.sameValue(m.indices.length, 3, "length");
+	assert.sameValue(m.indices[0].join(), "1,3", "match");
+	assert.sameValue(m.indices[1], undefined, "unmatched");
+	assert.sameValue(m.indices[2].join(), "2,3", "group");
+	assert.sameValue(m.indices.groups.x.join(), "2,3", "named group");
+	var re = /c/dg;
+	re.lastIndex = 3;
+	assert.sameValue(re.exec("abcabc").indices[0].join(), "5,6", "global");
+	assert.sameValue(/a/.exec("a").indices, undefined, "without d");
+	assert.sameValue(/a/d.exec("a").indices.groups, undefined, "no named groups");
+	assert(/a/d.hasIndices, "hasIndices");
+	assert.sameValue(/a/gimsuyd.flags, "dgimsuy", "flags");
+	assert.sameValue(new RegExp("a", "yusmigd").toString(), "/a/dgimsuy", "toString");
+	["gg", "x", "gé", "dd"].forEach(function(flags) {
+		try {
+			new RegExp("a", flags);
+			$ERROR("Flags " + flags + " were accepted");
+		} catch (e) {
+			if (!(e instanceof SyntaxError)) {
+				throw e;
+			}
+		}
+	});
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestRegexpUnicodeProperties(t *testing.T) {
+	const SCRIPT = `
+	assert(/^\p{Script=Greek}+$/u.test("αβγ"), "script");
+	assert(/^\p{sc=Greek}$/u.test("α") && !/^\p{sc=Greek}$/u.test("a"), "script alias");
+	assert(/^\p{L}+$/u.test("aЖ中"), "category");
+	assert(/^\p{Letter}$/u.test("a") && /^\p{gc=Lu}$/u.test("A") && !/^\p{General_Category=Uppercase_Letter}$/u.test("a"), "long category names");
+	assert(/^\P{L}$/u.test("1") && !/^\P{L}$/u.test("a"), "negated category");
+	assert(/^[\p{Lu}\d]+$/u.test("A1B"), "category in class");
+	assert(/^\p{White_Space}$/u.test("　") && !/^\p{White_Space}$/u.test("a"), "binary property");
+	assert(/^\P{White_Space}$/u.test("a") && /^[^\P{White_Space}]$/u.test(" "), "negated binary property");
+	assert(/^[\P{ASCII}x]$/u.test("ж") && /^[\P{ASCII}x]$/u.test("x") && !/^[\P{ASCII}x]$/u.test("y"), "negated in class");
+	assert(/^\p{Alphabetic}$/u.test("ж") && /^\p{Any}$/u.test("😀"), "derived properties");
+	assert(/(?=a)\p{Script=Latin}/u.test("a"), "regexp2");
+	assert(/\p{L}/.test("p{L}"), "without u");
+	var errors = 0;
+	["\\p{Foo}", "\\p{Script=Foo}", "\\p{Greek}", "\\p", "\\pL"].forEach(function(src) {
+		try {
+			new RegExp(src, "u");
+		} catch (e) {
+			if (e instanceof SyntaxError) {
+				errors++;
+			}
+		}
+	});
+	assert.sameValue(errors, 5, "syntax errors");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+// literalRegexpEngine matches the patterns without special characters as literal strings.
+type literalRegexpEngine struct {
+	compiled int
+}
+
+type literalRegexpMatcher []uint16
+
+func (e *literalRegexpEngine) Compile(pattern, flags string) (RegexpMatcher, error) {
+	if strings.ContainsAny(pattern, `\^$.|?*+()[]{}`) || strings.ContainsAny(flags, "imsu") {
+		return nil, nil
+	}
+	if pattern == "fail" {
+		return nil, errors.New("unsupported pattern")
+	}
+	e.compiled++
+	return literalRegexpMatcher(utf16.Encode([]rune(pattern))), nil
+}
+
+func (m literalRegexpMatcher) FindSubmatchIndex(s []uint16, start int) []int {
+	for i := start; i+len(m) <= len(s); i++ {
+		found := true
+		for j, c := range m {
+			if s[i+j] != c {
+				found = false
+				break
+			}
+		}
+		if found {
+			return []int{i, i + len(m)}
+		}
+	}
+	return nil
+}
+
+func TestRegexpEngine(t *testing.T) {
+	const SCRIPT = `
+	var re = /b/g;
+	assert.sameValue("abcb".replace(re, "x"), "axcx", "replace");
+	assert.sameValue("😀b😀b".replace(new RegExp("b", "g"), "x"), "😀x😀x", "replace unicode");
+	assert.sameValue("abcb".split(/b/).join(), "a,c,", "split");
+	re.lastIndex = 0;
+	assert.sameValue(re.exec("abcb").index, 1, "exec");
+	assert.sameValue(re.exec("abcb").index, 3, "exec global");
+	assert.sameValue("aXa".replace(/a/g, ""), "X", "empty replacement");
+	assert.sameValue("".replace(new RegExp("", "g"), "x"), "x", "empty pattern");
+	assert(/^(a)\1$/.test("aa"), "fallback");
+	var thrown = false;
+	try {
+		new RegExp("fail");
+	} catch (e) {
+		thrown = e instanceof SyntaxError;
+	}
+	assert(thrown, "error");
+	`
+
+	vm := New()
+	engine := &literalRegexpEngine{}
+	vm.SetRegexpEngine(engine)
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.compiled != 4 {
+		t.Fatalf("Unexpected number of compiled patterns: %d", engine.compiled)
+	}
+}
+
+func TestRegexpEngineCache(t *testing.T) {
+	vm := New()
+	engine := &literalRegexpEngine{}
+	vm.SetRegexpEngine(engine)
+	_, err := vm.RunString(`
+	for (var i = 0; i < 200; i++) {
+		new RegExp("a" + i);
+		new RegExp("a0");
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := vm.regexpEngineCache.order.Len(); l != regexpEngineCacheSize {
+		t.Fatalf("Unexpected cache size: %d", l)
+	}
+	if engine.compiled != 200 {
+		t.Fatalf("Unexpected number of compiled patterns: %d", engine.compiled)
+	}
+}
+
+func TestRegexpEngineBuiltin(t *testing.T) {
+	vm := New()
+	vm.SetRegexpEngine(RegexpEngineGo)
+	if _, err := vm.RunString(`/(?=a)/`); err == nil {
+		t.Fatal("Expected error")
+	}
+	if _, err := vm.RunString(`/^a/y`); err == nil {
+		t.Fatal("Expected error")
+	}
+	v, err := vm.RunString(`/b(c)?/.exec("abd").index`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ToInteger() != 1 {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+
+	vm.SetRegexpEngine(RegexpEngineBacktracking)
+	v, err = vm.RunString(`/(?=a)a(b)/.exec("xab")[1]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.SameAs(asciiString("b")) {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if m, err := RegexpEngineBacktracking.Compile("a(b)?", ""); err != nil {
+		t.Fatal(err)
+	} else if res := m.FindSubmatchIndex([]uint16{'x', 'a', 'a'}, 2); len(res) != 4 || res[0] != 2 || res[1] != 3 || res[2] != -1 {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func BenchmarkRegexpEngine(b *testing.B) {
+	const SCRIPT = `
+	var s = "";
+	for (var i = 0; i < 20; i++) {
+		s += "The quick brown fox jumps over the lazy dog. ";
+	}
+	var re = new RegExp("(\\w+) (fox|dog)", "g");
+	var count = 0;
+	for (var i = 0; i < 10; i++) {
+		count += s.replace(re, "$2 $1").length;
+	}
+	`
+	prg, err := Compile("test.js", SCRIPT, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, engine := range []struct {
+		name   string
+		engine RegexpEngine
+	}{
+		{"default", nil},
+		{"go", RegexpEngineGo},
+		{"backtracking", RegexpEngineBacktracking},
+	} {
+		b.Run(engine.name, func(b *testing.B) {
+			vm := New()
+			vm.SetRegexpEngine(engine.engine)
+			for i := 0; i < b.N; i++ {
+				if _, err := vm.RunProgram(prg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRegexpQuantifiedCaptures(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(String(/(z)((a+)?(b+)?(c))*/.exec("zaacbbbcac")), "zaacbbbcac,z,ac,a,,c");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
@@ -0,0 +1,174 @@
+package goja
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// RegexpEngine compiles the regular expressions of a Runtime, see Runtime.SetRegexpEngine().
+type RegexpEngine interface {
+	// Compile compiles a pattern in the JavaScript syntax with the given flags ("dgimsuy"; the 'g', 'y' and 'd' flags
+	// only affect how the matches are used and are handled by the Runtime). The pattern has already been validated
+	// by the default engines. If the engine doesn't support the pattern it may return a nil RegexpMatcher and no
+	// error, in which case the default engine selection applies. An error is thrown as a SyntaxError.
+	Compile(pattern, flags string) (RegexpMatcher, error)
+}
+
+// RegexpMatcher is a regular expression compiled by a RegexpEngine.
+type RegexpMatcher interface {
+	// FindSubmatchIndex returns the positions of the leftmost match in s that starts at or after start followed by
+	// the positions of its capture groups ([-1, -1] for the groups that didn't participate in the match), or nil if
+	// there is no match. The positions are indexes of UTF-16 code units.
+	FindSubmatchIndex(s []uint16, start int) []int
+}
+
+type builtinRegexpEngine regexpBackend
+
+// regexpEngineCacheSize is the number of patterns compiled by a RegexpEngine which are kept for reuse by a Runtime.
+const regexpEngineCacheSize = 64
+
+var (
+	// RegexpEngineGo compiles the regular expressions with Go's regexp package (RE2). It is fast and runs in linear
+	// time, but fails to compile the patterns that need backtracking (lookarounds and backreferences).
+	RegexpEngineGo RegexpEngine = builtinRegexpEngine(regexpBackendGo)
+
+	// RegexpEngineBacktracking compiles all regular expressions with the backtracking engine
+	// (github.com/dlclark/regexp2), which supports the complete JavaScript syntax.
+	RegexpEngineBacktracking RegexpEngine = builtinRegexpEngine(regexpBackendBacktracking)
+)
+
+func (e builtinRegexpEngine) Compile(pattern, flags string) (RegexpMatcher, error) {
+	re, err := compileRegexpBackend(pattern, flags, regexpBackend(e))
+	if err != nil {
+		return nil, err
+	}
+	return builtinRegexpMatcher{re.pattern}, nil
+}
+
+// builtinRegexpMatcher exposes a pattern compiled by one of the built-in engines as a RegexpMatcher. The Runtime
+// uses the wrapped pattern directly.
+type builtinRegexpMatcher struct {
+	p regexpPattern
+}
+
+func (m builtinRegexpMatcher) FindSubmatchIndex(s []uint16, start int) []int {
+	return m.p.FindSubmatchIndex(unicodeString(s), start)
+}
+
+// regexpMatcherWrapper adapts a RegexpMatcher provided by an embedder to regexpPattern.
+type regexpMatcherWrapper struct {
+	m RegexpMatcher
+}
+
+func valueStringToUTF16(s valueString) []uint16 {
+	switch s := s.(type) {
+	case unicodeString:
+		return s
+	case asciiString:
+		units := make([]uint16, len(s))
+		for i := 0; i < len(s); i++ {
+			units[i] = uint16(s[i])
+		}
+		return units
+	default:
+		panic("Unsupported string type")
+	}
+}
+
+func (w regexpMatcherWrapper) FindSubmatchIndex(s valueString, start int) []int {
+	return w.m.FindSubmatchIndex(valueStringToUTF16(s), start)
+}
+
+func (w regexpMatcherWrapper) findAll(units []uint16, n int) [][]int {
+	var results [][]int
+	start := 0
+	for (n < 0 || len(results) < n) && start <= len(units) {
+		result := w.m.FindSubmatchIndex(units, start)
+		if result == nil {
+			break
+		}
+		results = append(results, result)
+		if result[1] > result[0] {
+			start = result[1]
+		} else {
+			start = result[1] + 1
+		}
+	}
+	return results
+}
+
+func (w regexpMatcherWrapper) FindAllSubmatchIndex(s valueString, n int) [][]int {
+	return w.findAll(valueStringToUTF16(s), n)
+}
+
+func (w regexpMatcherWrapper) FindAllSubmatchIndexUTF8(s string, n int) [][]int {
+	// posMap maps the UTF-16 positions to the byte positions in s
+	units := make([]uint16, 0, len(s))
+	posMap := make([]int, 0, len(s)+1)
+	for pos, r := range s {
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			units = append(units, uint16(r1), uint16(r2))
+			posMap = append(posMap, pos, pos+utf8.RuneLen(r))
+		} else {
+			units = append(units, uint16(r))
+			posMap = append(posMap, pos)
+		}
+	}
+	posMap = append(posMap, len(s))
+	results := w.findAll(units, n)
+	for _, result := range results {
+		for i, pos := range result {
+			if pos >= 0 {
+				result[i] = posMap[pos]
+			}
+		}
+	}
+	return results
+}
+
+func (w regexpMatcherWrapper) FindAllSubmatchIndexASCII(s string, n int) [][]int {
+	return w.FindAllSubmatchIndex(asciiString(s), n)
+}
+
+func (w regexpMatcherWrapper) MatchString(s valueString) bool {
+	return w.FindSubmatchIndex(s, 0) != nil
+}
+
+// SetRegexpEngine sets the engine that compiles the regular expressions created in this Runtime from then on.
+// The engine can choose the backend for each pattern based on the features it requires, e.g. use an external
+// engine for some patterns and return nil for the others so that the default selection applies (Go's regexp if
+// the pattern is compatible with it, the backtracking engine otherwise). Passing nil restores the default.
+// Note that the patterns of regular expression literals are still validated when the code is compiled.
+func (r *Runtime) SetRegexpEngine(engine RegexpEngine) {
+	r.regexpEngine = engine
+	r.regexpEngineCache = nil
+}
+
+// regexpEnginePattern returns the pattern compiled by the Runtime's RegexpEngine, or p if there is no engine
+// or it doesn't support the pattern. The most recently used patterns are cached.
+func (r *Runtime) regexpEnginePattern(source, flags string, p regexpPattern) regexpPattern {
+	if r.regexpEngine == nil {
+		return p
+	}
+	key := flags + "/" + source
+	if r.regexpEngineCache == nil {
+		r.regexpEngineCache = newLRUCache(regexpEngineCacheSize)
+	}
+	if cached, ok := r.regexpEngineCache.get(key).(regexpPattern); ok {
+		return cached
+	}
+	m, err := r.regexpEngine.Compile(source, flags)
+	if err != nil {
+		panic(r.newSyntaxError(err.Error(), -1))
+	}
+	switch m := m.(type) {
+	case nil:
+	case builtinRegexpMatcher:
+		p = m.p
+	default:
+		p = regexpMatcherWrapper{m}
+	}
+	r.regexpEngineCache.put(key, p)
+	return p
+}
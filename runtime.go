@@ -2,14 +2,17 @@ package goja
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
-	"github.com/dop251/goja/parser"
 	"go/ast"
 	"math"
 	"math/rand"
 	"reflect"
+	"regexp"
+	"runtime"
 	"strconv"
+	"time"
 )
 
 const (
@@ -18,6 +21,10 @@ const (
 
 var (
 	typeCallable = reflect.TypeOf(Callable(nil))
+	typeTime     = reflect.TypeOf(time.Time{})
+	typeError    = reflect.TypeOf((*error)(nil)).Elem()
+
+	sourceURLRegexp = regexp.MustCompile(`(?m)^[ \t]*//[#@][ \t]*sourceURL=[ \t]*(\S+)[ \t]*$`)
 )
 
 type global struct {
@@ -39,6 +46,7 @@ type global struct {
 	RangeError     *Object
 	EvalError      *Object
 	URIError       *Object
+	AggregateError *Object
 
 	GoError *Object
 
@@ -60,6 +68,7 @@ type global struct {
 	ReferenceErrorPrototype *Object
 	EvalErrorPrototype      *Object
 	URIErrorPrototype       *Object
+	AggregateErrorPrototype *Object
 
 	GoErrorPrototype *Object
 
@@ -80,22 +89,126 @@ type Runtime struct {
 	typeInfoCache   map[reflect.Type]*reflectTypeInfo
 	fieldNameMapper FieldNameMapper
 
+	strictNumberConversion bool
+	goMapOrder             GoMapOrder
+	jsonNonFiniteNumbers   JSONNonFiniteNumbers
+	jsonCircularReferences JSONCircularReferences
+	jsonParseNullPrototype bool
+	annexB                 bool
+
+	locale                     localeInfo
+	numberFormatProvider       NumberFormatProvider
+	pluralRulesProvider        PluralRulesProvider
+	relativeTimeFormatProvider RelativeTimeFormatProvider
+	temporal                   *temporalPrototypes
+
+	globalStoreProps []*globalStoreProperty
+
+	dynamicCodeId int
+	compileCache  *lruCache
+
+	sourceProvider      SourceProvider
+	stackFrameFilter    StackFrameFilter
+	goPanicStacks       bool
+	perfWarnings        *perfWarnings
+	errorStackFormatter ErrorStackFormatter
+	mem                 *memoryLimit
+	argLimits           *HostArgLimits // of the host function being called, see LimitArgs()
+
+	finalizers objectFinalizers
+
+	regexpEngine      RegexpEngine
+	regexpEngineCache *lruCache
+
+	sharedObjects map[*sharedNode]*Object
+
 	vm *vm
 }
 
-type stackFrame struct {
+// StackFrame is a frame of the stack trace of an Exception.
+type StackFrame struct {
 	prg      *Program
 	funcName string
 	pc       int
 }
 
-func (f stackFrame) position() Position {
+// StackFrameFilter is called with the frames of a stack trace (the innermost first) and returns the frames that
+// should be shown. It may remove frames (e.g. of polyfills or a test harness) or collapse a sequence of frames
+// into one of them, see Runtime.SetStackFrameFilter().
+type StackFrameFilter func(frames []StackFrame) []StackFrame
+
+// SrcName returns the name of the script the frame belongs to, or an empty string for native functions.
+func (f StackFrame) SrcName() string {
+	if f.prg == nil {
+		return ""
+	}
+	if n, _, ok := f.prg.src.mappedPosition(f.prg.sourceOffset(f.pc)); ok {
+		return n
+	}
+	if f.prg.src != nil && f.prg.src.name != "" {
+		return f.prg.src.name
+	}
+	return "<eval>"
+}
+
+// FuncName returns the name of the function, or an empty string for the top level code and anonymous functions.
+func (f StackFrame) FuncName() string {
+	if f.prg == nil {
+		return f.funcName
+	}
+	return f.prg.funcName
+}
+
+// Position returns the line and column in the script, or the zero Position for native functions. If the Program
+// has a PositionMapper the position in the original source is returned.
+func (f StackFrame) Position() Position {
+	if f.prg == nil {
+		return Position{}
+	}
+	if _, pos, ok := f.prg.src.mappedPosition(f.prg.sourceOffset(f.pc)); ok {
+		return pos
+	}
+	return f.GeneratedPosition()
+}
+
+// GeneratedPosition returns the line and column in the source the Program has been compiled from, regardless of
+// the PositionMapper, or the zero Position for native functions.
+func (f StackFrame) GeneratedPosition() Position {
+	if f.prg == nil || f.prg.src == nil {
+		return Position{}
+	}
 	return f.prg.src.Position(f.prg.sourceOffset(f.pc))
 }
 
+// Origin returns the metadata of the script the frame belongs to (see CompileWithOrigin()), or nil for native
+// functions and scripts compiled without it.
+func (f StackFrame) Origin() *ScriptOrigin {
+	if f.prg == nil {
+		return nil
+	}
+	return f.prg.src.origin
+}
+
+// String returns the frame in the format of the stack traces without the program counter: "f (file.js:1:2)" for
+// a script function, "file.js:1:2" for the top level code and "f (native)" for a native function.
+func (f StackFrame) String() string {
+	var loc string
+	if f.prg != nil {
+		loc = f.SrcName() + ":" + f.Position().String()
+	} else {
+		loc = "native"
+	}
+	if n := f.FuncName(); n != "" {
+		return n + " (" + loc + ")"
+	}
+	return loc
+}
+
 type Exception struct {
-	val   Value
-	stack []stackFrame
+	val     Value
+	stack   []StackFrame
+	sources SourceProvider
+	filter  StackFrameFilter
 }
 
 type InterruptedError struct {
@@ -103,10 +216,40 @@ type InterruptedError struct {
 	iface interface{}
 }
 
+// Value returns the value passed to Runtime.Interrupt().
 func (e *InterruptedError) Value() interface{} {
 	return e.iface
 }
 
+func (e *InterruptedError) String() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var b bytes.Buffer
+	if e.iface != nil {
+		b.WriteString(fmt.Sprint(e.iface))
+	}
+	b.WriteByte('\n')
+	e.writeStack(&b)
+	return b.String()
+}
+
+// Unwrap returns the value passed to Runtime.Interrupt() if it's an error (e.g. the error of the context passed to
+// RunStringContext()), or nil.
+func (e *InterruptedError) Unwrap() error {
+	if err, ok := e.iface.(error); ok {
+		return err
+	}
+	return nil
+}
+
+func (e *InterruptedError) Error() string {
+	if e == nil || e.iface == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(e.iface)
+}
+
 func (e *Exception) String() string {
 	if e == nil {
 		return "<nil>"
@@ -116,20 +259,22 @@ func (e *Exception) String() string {
 		b.WriteString(e.val.String())
 	}
 	b.WriteByte('\n')
-	for _, frame := range e.stack {
+	e.writeStack(&b)
+	return b.String()
+}
+
+// writeStack writes the stack trace of the exception to b, one frame per line.
+func (e *Exception) writeStack(b *bytes.Buffer) {
+	for _, frame := range e.Stack() {
 		b.WriteString("\tat ")
 		if frame.prg != nil {
 			if n := frame.prg.funcName; n != "" {
 				b.WriteString(n)
 				b.WriteString(" (")
 			}
-			if n := frame.prg.src.name; n != "" {
-				b.WriteString(n)
-			} else {
-				b.WriteString("<eval>")
-			}
+			b.WriteString(frame.SrcName())
 			b.WriteByte(':')
-			b.WriteString(frame.position().String())
+			b.WriteString(frame.Position().String())
 			b.WriteByte('(')
 			b.WriteString(strconv.Itoa(frame.pc))
 			b.WriteByte(')')
@@ -148,7 +293,6 @@ func (e *Exception) String() string {
 		}
 		b.WriteByte('\n')
 	}
-	return b.String()
 }
 
 func (e *Exception) Error() string {
@@ -162,12 +306,60 @@ func (e *Exception) Value() Value {
 	return e.val
 }
 
+// Unwrap returns the Go error the exception has been created from, i.e. the 'value' of a GoError (see
+// NewGoError()) or a thrown Go error, or nil.
+func (e *Exception) Unwrap() error {
+	obj, ok := e.val.(*Object)
+	if !ok {
+		return nil
+	}
+	if err, ok := obj.Export().(error); ok {
+		return err
+	}
+	if obj.self.className() != classError {
+		return nil
+	}
+	v := obj.self.getOwnProp("value")
+	if prop, ok := v.(*valueProperty); ok {
+		v = prop.value
+	}
+	if v != nil {
+		if err, ok := v.Export().(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// GoStack returns the Go stack trace of the panic the exception was converted from (see
+// Runtime.SetGoPanicStacks()), or an empty string.
+func (e *Exception) GoStack() string {
+	if obj, ok := e.val.(*Object); ok {
+		if prop, ok := obj.self.getOwnProp("goStack").(*valueProperty); ok && !prop.enumerable {
+			if s, ok := prop.value.assertString(); ok {
+				return s.String()
+			}
+		}
+	}
+	return ""
+}
+
+// Stack returns the frames of the stack trace, the innermost first. If the Runtime has a StackFrameFilter, only
+// the frames returned by it are included.
+func (e *Exception) Stack() []StackFrame {
+	if e.filter == nil {
+		return e.stack
+	}
+	return e.filter(append([]StackFrame(nil), e.stack...))
+}
+
 func (r *Runtime) addToGlobal(name string, value Value) {
 	r.globalObject.self._putProp(name, value, true, false, true)
 }
 
 func (r *Runtime) init() {
 	r.rand = rand.Float64
+	r.compileCache = newLRUCache(defaultCompileCacheSize)
 	r.global.ObjectPrototype = r.newBaseObject(nil, classObject).val
 	r.globalObject = r.NewObject()
 
@@ -195,6 +387,7 @@ func (r *Runtime) init() {
 
 	r.initMath()
 	r.initJSON()
+	r.initIntl()
 
 	//r.initTypedArrays()
 
@@ -290,6 +483,9 @@ func (r *Runtime) NewTypeError(args ...interface{}) *Object {
 	return r.builtin_new(r.global.TypeError, []Value{newStringValue(msg)})
 }
 
+// NewGoError creates a GoError with the message of err and err in its 'value' property. When it's thrown, the
+// resulting *Exception unwraps to err, so that errors.Is() and errors.As() can be used with it.
+// The errors returned by the Go functions (and the non-runtime errors they panic with) are thrown as GoError.
 func (r *Runtime) NewGoError(err error) *Object {
 	e := r.newError(r.global.GoError, err.Error()).(*Object)
 	e.Set("value", err)
@@ -491,9 +687,43 @@ func (r *Runtime) builtin_Error(args []Value, proto *Object) *Object {
 	if len(args) > 0 && args[0] != _undefined {
 		obj._putProp("message", args[0], true, false, true)
 	}
+	if len(args) > 1 {
+		r.installErrorCause(obj, args[1])
+	}
+	r.installErrorStack(obj)
 	return obj.val
 }
 
+func (r *Runtime) builtin_AggregateError(args []Value, proto *Object) *Object {
+	obj := r.newBaseObject(proto, classError)
+	if len(args) > 1 && args[1] != _undefined {
+		obj._putProp("message", args[1], true, false, true)
+	}
+	if len(args) > 2 {
+		r.installErrorCause(obj, args[2])
+	}
+	var errors Value = _undefined
+	if len(args) > 0 {
+		errors = args[0]
+	}
+	list := r.toValueArray(errors)
+	for i, v := range list {
+		if v == nil {
+			list[i] = _undefined
+		}
+	}
+	obj._putProp("errors", r.newArrayValues(list), true, false, true)
+	r.installErrorStack(obj)
+	return obj.val
+}
+
+// installErrorCause sets the 'cause' property from the options argument of an Error constructor.
+func (r *Runtime) installErrorCause(obj *baseObject, options Value) {
+	if o, ok := options.(*Object); ok && o.self.hasPropertyStr("cause") {
+		obj._putProp("cause", o.self.getStr("cause"), true, false, true)
+	}
+}
+
 func (r *Runtime) builtin_new(construct *Object, args []Value) *Object {
 repeat:
 	switch f := construct.self.(type) {
@@ -529,12 +759,19 @@ func (r *Runtime) builtin_thrower(call FunctionCall) Value {
 	return nil
 }
 
-func (r *Runtime) eval(src string, direct, strict bool, this Value) Value {
-
-	p, err := r.compile("<eval>", src, strict, true)
-	if err != nil {
-		panic(err)
+// dynamicCodeName returns the file name for code created at run time by eval() or the Function constructor: the
+// value of the "//# sourceURL=" comment if the code contains one, or a synthetic name with a unique id otherwise.
+func (r *Runtime) dynamicCodeName(kind, src string) string {
+	if m := sourceURLRegexp.FindStringSubmatch(src); m != nil {
+		return m[1]
 	}
+	r.dynamicCodeId++
+	return "<" + kind + ":" + strconv.Itoa(r.dynamicCodeId) + ">"
+}
+
+func (r *Runtime) eval(kind, src string, direct, strict bool, this Value) Value {
+
+	p := r.compileDynamic(kind, src, strict, direct && r.vm.prg != nil && r.vm.prg.funcCode)
 
 	vm := r.vm
 
@@ -564,7 +801,7 @@ func (r *Runtime) builtin_eval(call FunctionCall) Value {
 		return _undefined
 	}
 	if str, ok := call.Arguments[0].assertString(); ok {
-		return r.eval(str.String(), false, false, r.globalObject)
+		return r.eval("eval", str.String(), false, false, r.globalObject)
 	}
 	return call.Arguments[0]
 }
@@ -662,55 +899,58 @@ func New() *Runtime {
 // method. This representation is not linked to a runtime in any way and can be run in multiple runtimes (possibly
 // at the same time).
 func Compile(name, src string, strict bool) (p *Program, err error) {
-	return compile(name, src, strict, false)
-}
-
-func compile(name, src string, strict, eval bool) (p *Program, err error) {
-	prg, err1 := parser.ParseFile(nil, name, src, 0)
-	if err1 != nil {
-		switch err1 := err1.(type) {
-		case parser.ErrorList:
-			if len(err1) > 0 && err1[0].Message == "Invalid left-hand side in assignment" {
-				err = &CompilerReferenceError{
-					CompilerError: CompilerError{
-						Message: err1.Error(),
-					},
-				}
-				return
-			}
-		}
-		// FIXME offset
-		err = &CompilerSyntaxError{
-			CompilerError: CompilerError{
-				Message: err1.Error(),
-			},
-		}
+	return compile(name, src, strict, false, false)
+}
+
+// ScriptOrigin describes where a script comes from, see CompileWithOrigin(). In a multi-tenant setup it makes it
+// possible to attribute every stack frame to its owner.
+type ScriptOrigin struct {
+	// URL the script was loaded from.
+	URL string
+	// Tenant is the identifier of the owner of the script.
+	Tenant string
+	// Integrity is a hash of the source, e.g. a Subresource Integrity string ("sha384-...").
+	Integrity string
+}
+
+// CompileWithOrigin is like Compile, but retains the origin metadata on the Program. The metadata is available
+// from Program.Origin() and StackFrame.Origin() for the frames of the Program's code, including the code compiled
+// by eval() and the Function constructor called from it.
+func CompileWithOrigin(name, src string, strict bool, origin *ScriptOrigin) (p *Program, err error) {
+	p, err = compile(name, src, strict, false, false)
+	if err == nil {
+		p.src.origin = origin
+	}
+	return
+}
+
+// Origin returns the metadata the Program was compiled with, see CompileWithOrigin().
+func (p *Program) Origin() *ScriptOrigin {
+	return p.src.origin
+}
+
+// compile parses and compiles the source. eval is set for the code of eval() and inFunction if it's a direct
+// eval() called from function code.
+func compile(name, src string, strict, eval, inFunction bool) (p *Program, err error) {
+	prg, err := parse(name, src)
+	if err != nil {
 		return
 	}
 
 	c := newCompiler()
 	c.scope.strict = strict
 	c.scope.eval = eval
+	c.p.funcCode = inFunction
 
-	defer func() {
-		if x := recover(); x != nil {
-			p = nil
-			switch x1 := x.(type) {
-			case *CompilerSyntaxError:
-				err = x1
-			default:
-				panic(x)
-			}
-		}
-	}()
-
-	c.compile(prg)
+	if err = c.compileProgram(prg); err != nil {
+		return
+	}
 	p = c.p
 	return
 }
 
-func (r *Runtime) compile(name, src string, strict, eval bool) (p *Program, err error) {
-	p, err = compile(name, src, strict, eval)
+func (r *Runtime) compile(name, src string, strict, eval, inFunction bool) (p *Program, err error) {
+	p, err = compile(name, src, strict, eval, inFunction)
 	if err != nil {
 		switch x1 := err.(type) {
 		case *CompilerSyntaxError:
@@ -744,6 +984,7 @@ func (r *Runtime) RunScript(name, src string) (Value, error) {
 
 // RunProgram executes a pre-compiled (see Compile()) code in the global context.
 func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
+	recursive := false
 	defer func() {
 		if x := recover(); x != nil {
 			if intr, ok := x.(*InterruptedError); ok {
@@ -752,8 +993,13 @@ func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
 				panic(x)
 			}
 		}
+		if recursive {
+			r.vm.popCtx()
+			r.vm.halt = false
+		} else {
+			r.vm.stack = nil
+		}
 	}()
-	recursive := false
 	if len(r.vm.callStack) > 0 {
 		recursive = true
 		r.vm.pushCtx()
@@ -766,21 +1012,85 @@ func (r *Runtime) RunProgram(p *Program) (result Value, err error) {
 	} else {
 		err = ex
 	}
-	if recursive {
-		r.vm.popCtx()
-		r.vm.halt = false
-	} else {
-		r.vm.stack = nil
+	return
+}
+
+// RunStringContext is like RunString but aborts the execution when ctx is cancelled or its deadline expires, see
+// RunProgramContext().
+func (r *Runtime) RunStringContext(ctx gocontext.Context, str string) (Value, error) {
+	p, err := Compile("", str, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunProgramContext(ctx, p)
+}
+
+// RunProgramContext is like RunProgram but aborts the execution when ctx is cancelled or its deadline expires. In
+// this case an *InterruptedError is returned, its Value() is ctx.Err(). It is built on Interrupt(): the interrupt is
+// cleared before returning, so the Runtime can be reused without calling ClearInterrupt(). An interrupt set by
+// anything else (a call to Interrupt() or the memory limit) is left in effect.
+// If ctx is already done, the program is not run at all.
+func (r *Runtime) RunProgramContext(ctx gocontext.Context, p *Program) (result Value, err error) {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, &InterruptedError{iface: ctxErr}
+	}
+	if ctx.Done() == nil {
+		// the context is never cancelled
+		return r.RunProgram(p)
+	}
+	done := make(chan struct{})
+	interrupted := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			r.vm.interruptIfClear(ctx.Err())
+			interrupted <- true
+		case <-done:
+			interrupted <- false
+		}
+	}()
+	result, err = r.RunProgram(p)
+	close(done)
+	if <-interrupted {
+		// the context may have been cancelled after the program has finished, the interrupt must not be left behind
+		r.vm.clearInterruptIf(ctx.Err())
 	}
 	return
 }
 
 // Interrupt a running JavaScript. The corresponding Go call will return an *InterruptedError containing v.
+// It is safe to call from another goroutine, e.g. to stop a script that runs for too long. The script is aborted
+// at the next instruction, the InterruptedError cannot be caught by the script (including in finally blocks).
 // Note, it only works while in JavaScript code, it does not interrupt native Go functions (which includes all built-ins).
+// The interrupt stays in effect until ClearInterrupt() is called: if the Runtime is not running a script, the next
+// Run*() call (or call of a function) is interrupted immediately.
 func (r *Runtime) Interrupt(v interface{}) {
 	r.vm.Interrupt(v)
 }
 
+// ClearInterrupt resets the interrupt set by Interrupt(), so that the Runtime can be reused.
+func (r *Runtime) ClearInterrupt() {
+	r.vm.ClearInterrupt()
+}
+
+// runWrapped runs f like vm.try() and returns the exception thrown by it or the *InterruptedError if the Runtime is
+// interrupted.
+func (r *Runtime) runWrapped(f func()) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			if intr, ok := x.(*InterruptedError); ok {
+				err = intr
+			} else {
+				panic(x)
+			}
+		}
+	}()
+	if ex := r.vm.try(f); ex != nil {
+		return ex
+	}
+	return nil
+}
+
 /*
 ToValue converts a Go value into JavaScript value.
 
@@ -822,7 +1132,7 @@ func (r *Runtime) ToValue(i interface{}) Value {
 			return valueFalse
 		}
 	case func(FunctionCall) Value:
-		return r.newNativeFunc(i, nil, "", nil, 0)
+		return r.newNativeFunc(r.wrapHostFunc(i), nil, "", nil, 0)
 	case int:
 		return intToValue(int64(i))
 	case int8:
@@ -851,6 +1161,8 @@ func (r *Runtime) ToValue(i interface{}) Value {
 		return intToValue(int64(i))
 	case uint32:
 		return intToValue(int64(i))
+	case *SharedData:
+		return r.sharedValue(i.root)
 	case map[string]interface{}:
 		obj := &Object{runtime: r}
 		m := &objectGoMapSimple{
@@ -953,8 +1265,31 @@ func (r *Runtime) ToValue(i interface{}) Value {
 	return obj
 }
 
+// wrapHostFunc returns a function that calls the host function f, throwing the errors it panics with as GoError.
+func (r *Runtime) wrapHostFunc(f func(FunctionCall) Value) func(FunctionCall) Value {
+	return func(call FunctionCall) Value {
+		defer r.throwHostError()
+		return f(call)
+	}
+}
+
+// throwHostError is deferred around the calls of the host functions. It throws an error the function has panicked
+// with as a GoError, like a returned one. The Go runtime errors (e.g. a nil map assignment), the exceptions and the
+// panics of the VM are propagated as they are.
+func (r *Runtime) throwHostError() {
+	if x := recover(); x != nil {
+		switch err := x.(type) {
+		case *Exception, *InterruptedError, *vmPanic, runtime.Error:
+		case error:
+			panic(r.NewGoError(err))
+		}
+		panic(x)
+	}
+}
+
 func (r *Runtime) wrapReflectFunc(value reflect.Value) func(FunctionCall) Value {
 	return func(call FunctionCall) Value {
+		defer r.throwHostError()
 		typ := value.Type()
 		nargs := typ.NumIn()
 		if len(call.Arguments) != nargs {
@@ -1042,6 +1377,23 @@ func (r *Runtime) wrapReflectFunc(value reflect.Value) func(FunctionCall) Value
 }
 
 func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, error) {
+	if r.strictNumberConversion {
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+			return r.toReflectIntStrict(v, typ)
+		case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+			return r.toReflectUintStrict(v, typ)
+		case reflect.Float32:
+			f := v.ToFloat()
+			ret := reflect.New(typ).Elem()
+			if !math.IsInf(f, 0) && ret.OverflowFloat(f) {
+				return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+			}
+			ret.SetFloat(f)
+			return ret, nil
+		}
+	}
+
 	switch typ.Kind() {
 	case reflect.String:
 		return reflect.ValueOf(v.String()).Convert(typ), nil
@@ -1079,6 +1431,13 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 		return reflect.ValueOf(uint8(i)).Convert(typ), nil
 	}
 
+	if v == _null || v == _undefined {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(typ), nil
+		}
+	}
+
 	t := reflect.TypeOf(v)
 	if t.AssignableTo(typ) {
 		return reflect.ValueOf(v), nil
@@ -1092,11 +1451,15 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 		}
 	}
 
+	if typ == typeTime {
+		return r.toReflectTime(v)
+	}
+
 	et := v.ExportType()
 
-	if et.AssignableTo(typ) {
+	if et != nil && et.AssignableTo(typ) {
 		return reflect.ValueOf(v.Export()), nil
-	} else if et.ConvertibleTo(typ) {
+	} else if et != nil && et.ConvertibleTo(typ) {
 		return reflect.ValueOf(v.Export()).Convert(typ), nil
 	}
 
@@ -1109,15 +1472,40 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 				elemTyp := typ.Elem()
 				for i := 0; i < l; i++ {
 					item := o.self.get(intToValue(int64(i)))
-					itemval, err := r.toReflectValue(item, elemTyp)
+					itemval, err := r.toReflectValue(nilSafe(item), elemTyp)
 					if err != nil {
-						return reflect.Value{}, fmt.Errorf("Could not convert array element %v to %v at %d", v, typ, i)
+						return reflect.Value{}, fmt.Errorf("Could not convert array element %v to %v at %d: %v", v, typ, i, err)
 					}
 					s.Index(i).Set(itemval)
 				}
 				return s, nil
 			}
 		}
+	case reflect.Array:
+		if o, ok := v.(*Object); ok && o.self.className() == classArray {
+			l := int(toLength(o.self.getStr("length")))
+			if l > typ.Len() {
+				return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: array is too long (%d)", v, typ, l)
+			}
+			s := reflect.New(typ).Elem()
+			for i := 0; i < l; i++ {
+				item := o.self.get(intToValue(int64(i)))
+				itemval, err := r.toReflectValue(nilSafe(item), typ.Elem())
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("Could not convert array element %v to %v at %d: %v", v, typ, i, err)
+				}
+				s.Index(i).Set(itemval)
+			}
+			return s, nil
+		}
+	case reflect.Ptr:
+		ev, err := r.toReflectValue(v, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(typ.Elem())
+		p.Elem().Set(ev)
+		return p, nil
 	case reflect.Map:
 		if o, ok := v.(*Object); ok {
 			m := reflect.MakeMap(typ)
@@ -1143,7 +1531,7 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 				if ival != nil {
 					vv, err := r.toReflectValue(ival, elemTyp)
 					if err != nil {
-						return reflect.Value{}, fmt.Errorf("Could not convert map value %v to %v at key %s", ival, typ, item.name)
+						return reflect.Value{}, fmt.Errorf("Could not convert map value %v to %v at key %s: %v", ival, typ, item.name, err)
 					}
 					m.SetMapIndex(kv, vv)
 				} else {
@@ -1155,19 +1543,8 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 	case reflect.Struct:
 		if o, ok := v.(*Object); ok {
 			s := reflect.New(typ).Elem()
-			for i := 0; i < typ.NumField(); i++ {
-				field := typ.Field(i)
-				if ast.IsExported(field.Name) {
-					v := o.self.getStr(field.Name)
-					if v != nil {
-						vv, err := r.toReflectValue(v, field.Type)
-						if err != nil {
-							return reflect.Value{}, fmt.Errorf("Could not convert struct value %v to %v for field %s", v, field.Type, field.Name)
-
-						}
-						s.Field(i).Set(vv)
-					}
-				}
+			if err := r.toReflectStruct(o, s); err != nil {
+				return reflect.Value{}, err
 			}
 			return s, nil
 		}
@@ -1180,24 +1557,153 @@ func (r *Runtime) toReflectValue(v Value, typ reflect.Type) (reflect.Value, erro
 	return reflect.Value{}, fmt.Errorf("Could not convert %v to %v", v, typ)
 }
 
+// toReflectStruct sets the fields of the struct s from the properties of o named after the fields (see
+// SetFieldNameMapper()). The fields of an embedded struct are taken from o itself unless o has a property named
+// after the embedded struct.
+func (r *Runtime) toReflectStruct(o *Object, s reflect.Value) error {
+	typ := s.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := field.Name
+		if r.fieldNameMapper != nil {
+			name = r.fieldNameMapper.FieldName(typ, field)
+			if name == "" {
+				continue
+			}
+		} else if !ast.IsExported(name) {
+			continue
+		}
+		v := o.self.getStr(name)
+		if v == nil {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := r.toReflectStruct(o, s.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		vv, err := r.toReflectValue(v, field.Type)
+		if err != nil {
+			return fmt.Errorf("Could not convert struct value %v to %v for field %s: %v", v, field.Type, field.Name, err)
+		}
+		s.Field(i).Set(vv)
+	}
+	return nil
+}
+
+// toReflectTime converts a Date, an ISO 8601 string or a number of milliseconds since the epoch to time.Time.
+func (r *Runtime) toReflectTime(v Value) (reflect.Value, error) {
+	if o, ok := v.(*Object); ok {
+		if d, ok := o.self.(*dateObject); ok {
+			if !d.isSet {
+				return reflect.Value{}, fmt.Errorf("Could not convert %v to time.Time: invalid date", v)
+			}
+			return reflect.ValueOf(d.time), nil
+		}
+		if t, ok := o.Export().(time.Time); ok {
+			return reflect.ValueOf(t), nil
+		}
+	}
+	if s, ok := v.assertString(); ok {
+		if t, ok := dateParse(s.String()); ok {
+			return reflect.ValueOf(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("Could not convert %q to time.Time", s.String())
+	}
+	switch v.(type) {
+	case valueInt, valueFloat:
+		if f := v.ToFloat(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return reflect.ValueOf(timeFromMsec(int64(f))), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("Could not convert %v to time.Time", v)
+}
+
+func (r *Runtime) toReflectIntStrict(v Value, typ reflect.Type) (reflect.Value, error) {
+	num := v.ToNumber()
+	ret := reflect.New(typ).Elem()
+	i, ok := num.assertInt()
+	if !ok {
+		f := num.ToFloat()
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: not an integer", v, typ)
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+		}
+		i = int64(f)
+	}
+	if ret.OverflowInt(i) {
+		return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+	}
+	ret.SetInt(i)
+	return ret, nil
+}
+
+func (r *Runtime) toReflectUintStrict(v Value, typ reflect.Type) (reflect.Value, error) {
+	num := v.ToNumber()
+	ret := reflect.New(typ).Elem()
+	var u uint64
+	if i, ok := num.assertInt(); ok {
+		if i < 0 {
+			return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+		}
+		u = uint64(i)
+	} else {
+		f := num.ToFloat()
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: not an integer", v, typ)
+		}
+		if f < 0 || f >= math.MaxUint64 {
+			return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+		}
+		u = uint64(f)
+	}
+	if ret.OverflowUint(u) {
+		return reflect.Value{}, fmt.Errorf("Could not convert %v to %v: value out of range", v, typ)
+	}
+	ret.SetUint(u)
+	return ret, nil
+}
+
+// wrapJSFunc returns the implementation of the Go func type typ that calls fn with the arguments converted with
+// ToValue() (the variadic arguments are passed individually). The result is converted to the first result of the func,
+// or, if there are several of them (not counting an error), the elements of the array returned by fn are converted to
+// them. If the last result is an error, the exceptions and the conversion errors are returned as it, otherwise they
+// cause a panic.
 func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.Value) (results []reflect.Value) {
+	numOut := typ.NumOut()
+	numValues := numOut
+	if numOut > 0 && typ.Out(numOut-1) == typeError {
+		numValues--
+	}
 	return func(args []reflect.Value) (results []reflect.Value) {
-		jsArgs := make([]Value, len(args))
+		jsArgs := make([]Value, 0, len(args))
 		for i, arg := range args {
-			jsArgs[i] = r.ToValue(arg.Interface())
+			if i == len(args)-1 && typ.IsVariadic() {
+				for j := 0; j < arg.Len(); j++ {
+					jsArgs = append(jsArgs, r.ToValue(arg.Index(j).Interface()))
+				}
+				break
+			}
+			jsArgs = append(jsArgs, r.ToValue(arg.Interface()))
 		}
 
-		results = make([]reflect.Value, typ.NumOut())
+		results = make([]reflect.Value, numOut)
 		res, err := fn(_undefined, jsArgs...)
 		if err == nil {
-			if typ.NumOut() > 0 {
+			switch numValues {
+			case 0:
+			case 1:
 				results[0], err = r.toReflectValue(res, typ.Out(0))
+			default:
+				err = r.toReflectResults(res, typ, results[:numValues])
 			}
 		}
 
 		if err != nil {
-			if typ.NumOut() == 2 && typ.Out(1).Name() == "error" {
-				results[1] = reflect.ValueOf(err).Convert(typ.Out(1))
+			if numValues < numOut {
+				results[numOut-1] = reflect.ValueOf(err).Convert(typeError)
 			} else {
 				panic(err)
 			}
@@ -1213,8 +1719,33 @@ func (r *Runtime) wrapJSFunc(fn Callable, typ reflect.Type) func(args []reflect.
 	}
 }
 
+// toReflectResults converts the elements of the array res to the results of the func type typ.
+func (r *Runtime) toReflectResults(res Value, typ reflect.Type, results []reflect.Value) error {
+	o, ok := res.(*Object)
+	if !ok || o.self.className() != classArray {
+		return fmt.Errorf("Could not convert %v to the results of %v: not an array", res, typ)
+	}
+	for i := range results {
+		v, err := r.toReflectValue(nilSafe(o.self.get(intToValue(int64(i)))), typ.Out(i))
+		if err != nil {
+			return err
+		}
+		results[i] = v
+	}
+	return nil
+}
+
 // ExportTo converts a JavaScript value into the specified Go value. The second parameter must be a non-nil pointer.
 // Returns error if conversion is not possible.
+// Objects are converted to structs (with the property names of the fields determined by the FieldNameMapper, see
+// SetFieldNameMapper(), the fields of embedded structs are taken from the object itself), maps and pointers to them,
+// arrays to slices and Go arrays, functions to Go funcs (see below). A time.Time can be set from a Date, an ISO 8601 string or
+// a number of milliseconds since the epoch. null and undefined set pointers, slices, maps and funcs to nil.
+// A Go func calls the JavaScript function (with 'this' undefined) passing the arguments converted with ToValue(),
+// the variadic ones individually, and converts the result to the func's result type. A func with several results
+// (not counting a trailing error) expects an array which is spread into them. If the last result of the func is
+// an error, an exception thrown by the function (or an interrupt, or a failed conversion of the result) is
+// returned as it, otherwise the func panics with it.
 func (r *Runtime) ExportTo(v Value, target interface{}) error {
 	tval := reflect.ValueOf(target)
 	if tval.Kind() != reflect.Ptr || tval.IsNil() {
@@ -1234,11 +1765,158 @@ func (r *Runtime) Set(name string, value interface{}) {
 	r.globalObject.self.putStr(name, r.ToValue(value), false)
 }
 
+// SetLazy defines a global variable whose value is created by init when the variable is accessed for the first
+// time. It can be used for host objects which are expensive to construct and are not used by most scripts.
+// Until then the variable is an accessor property of the global object (which can be seen with
+// Object.getOwnPropertyDescriptor()); once it's read or assigned it becomes a normal data property. Note that
+// init is called with the Runtime in the middle of running a script, so it must not call RunString() and alike.
+func (r *Runtime) SetLazy(name string, init func(*Runtime) Value) {
+	replace := func(v Value) {
+		r.globalObject.self._putProp(name, v, true, true, true)
+	}
+	getter := r.newNativeFunc(func(call FunctionCall) Value {
+		v := nilSafe(init(r))
+		replace(v)
+		return v
+	}, nil, "get "+name, nil, 0)
+	setter := r.newNativeFunc(func(call FunctionCall) Value {
+		replace(call.Argument(0))
+		return _undefined
+	}, nil, "set "+name, nil, 1)
+	r.globalObject.self.deleteStr(name, false)
+	// The property is stored as is because it's writable, enumerable and configurable
+	r.globalObject.self._putProp(name, &valueProperty{
+		accessor:     true,
+		configurable: true,
+		enumerable:   true,
+		getterFunc:   getter,
+		setterFunc:   setter,
+	}, true, true, true)
+}
+
 // Get the specified property of the global object.
 func (r *Runtime) Get(name string) Value {
 	return r.globalObject.self.getStr(name)
 }
 
+// JSONNonFiniteNumbers defines how JSON.stringify() serialises NaN, Infinity and -Infinity, see
+// Runtime.SetJSONNonFiniteNumbers().
+type JSONNonFiniteNumbers int
+
+const (
+	// JSONNonFiniteAsNull serialises non-finite numbers as null as required by the specification. This is the default.
+	JSONNonFiniteAsNull JSONNonFiniteNumbers = iota
+	// JSONNonFiniteAsString serialises non-finite numbers as strings, i.e. "NaN", "Infinity" or "-Infinity".
+	JSONNonFiniteAsString
+	// JSONNonFiniteError makes JSON.stringify() throw a TypeError naming the property.
+	JSONNonFiniteError
+)
+
+// SetJSONNonFiniteNumbers sets the way JSON.stringify() serialises NaN, Infinity and -Infinity.
+// The default (JSONNonFiniteAsNull) follows the specification, the other modes can be used to detect
+// invalid numbers which would otherwise be silently replaced with null.
+func (r *Runtime) SetJSONNonFiniteNumbers(mode JSONNonFiniteNumbers) {
+	r.jsonNonFiniteNumbers = mode
+}
+
+// JSONCircularReferences defines how JSON.stringify() handles circular structures, see
+// Runtime.SetJSONCircularReferences().
+type JSONCircularReferences int
+
+const (
+	// JSONCircularError makes JSON.stringify() throw a TypeError as required by the specification. This is the default.
+	JSONCircularError JSONCircularReferences = iota
+	// JSONCircularErrorWithPath makes JSON.stringify() throw a TypeError naming the path of the property that
+	// creates the cycle and the path of the object it refers to.
+	JSONCircularErrorWithPath
+	// JSONCircularAsMarker serialises circular references as the string "[Circular]".
+	JSONCircularAsMarker
+)
+
+// SetJSONCircularReferences sets the way JSON.stringify() handles circular structures.
+func (r *Runtime) SetJSONCircularReferences(mode JSONCircularReferences) {
+	r.jsonCircularReferences = mode
+}
+
+// SetJSONParseNullPrototype makes JSON.parse() create objects without a prototype (as if by Object.create(null)).
+// Such objects don't inherit any properties from Object.prototype and '__proto__' is an ordinary property for
+// them, so that untrusted JSON cannot be used to alter prototypes of objects built from the parsed data.
+func (r *Runtime) SetJSONParseNullPrototype(enable bool) {
+	r.jsonParseNullPrototype = enable
+}
+
+// SetStrictNumberConversion controls how numbers are converted into Go integer and float32 types by ExportTo() and
+// when calling wrapped Go functions. By default the conversion follows Go semantics, i.e. fractional values
+// are truncated (and non-integral values that cannot be represented as int64 become 0) and out of range values
+// wrap around. When strict mode is enabled the conversion fails instead: ExportTo() returns an error and a call
+// to a wrapped function throws a TypeError.
+func (r *Runtime) SetStrictNumberConversion(strict bool) {
+	r.strictNumberConversion = strict
+}
+
+// GoMapOrder defines the order in which the keys of wrapped Go maps are enumerated, see Runtime.SetGoMapOrder().
+type GoMapOrder int
+
+const (
+	// GoMapOrderRandom enumerates the keys in Go's map iteration order, which is randomised. This is the default.
+	GoMapOrderRandom GoMapOrder = iota
+	// GoMapOrderSorted enumerates the keys in ascending order (numerically for maps with numeric keys).
+	GoMapOrderSorted
+	// GoMapOrderInsertion enumerates the keys added by scripts in the order they were added, after the other keys,
+	// which are sorted. Note that the order is recorded by the wrapper object, so it's not retained if the map is
+	// wrapped again (e.g. when it's read from a field of a Go struct or set again with Set()).
+	GoMapOrderInsertion
+)
+
+// SetGoMapOrder sets the order in which the keys of the Go maps wrapped by ToValue() are enumerated by for-in,
+// Object.keys(), JSON.stringify() and alike. Deterministic orders come at the cost of sorting the keys on each
+// enumeration.
+func (r *Runtime) SetGoMapOrder(order GoMapOrder) {
+	r.goMapOrder = order
+}
+
+// CaptureCallStack returns the current call stack of the running script, the innermost frame first. When called
+// from a Go function invoked by the script, the first frame is the one of the Go function and the next one is the
+// script location it has been called from. At most limit frames are
+// returned, or all of them if limit is 0 or less. The StackFrameFilter is not applied.
+// Returns nil if no script is running.
+func (r *Runtime) CaptureCallStack(limit int) []StackFrame {
+	vm := r.vm
+	if vm.prg == nil && len(vm.callStack) == 0 {
+		return nil
+	}
+	stack := vm.captureStack(nil, 0)
+	if limit > 0 && len(stack) > limit {
+		stack = stack[:limit]
+	}
+	return stack
+}
+
+// SetStackFrameFilter sets the StackFrameFilter that is applied to the stack traces of the exceptions thrown in this
+// Runtime before they are exposed by Exception.Stack(), Exception.String() or Exception.SourceContext().
+func (r *Runtime) SetStackFrameFilter(filter StackFrameFilter) {
+	r.stackFrameFilter = filter
+}
+
+// SetGoPanicStacks enables the conversion of Go panics (e.g. in host functions) into JavaScript exceptions. This is
+// meant for debugging host bindings: by default such a panic propagates out of the Runtime, which aborts the script
+// and, unless recovered, the program.
+// When enabled, the panic is thrown as a GoError with the message "panic: <value>", the panic value in its 'value'
+// property and the Go stack trace of the panic in the non-enumerable 'goStack' property, which is also returned
+// by Exception.GoStack(). Note that this applies to all panics, including the ones caused by bugs in the Runtime
+// itself, and the Runtime may be left in an inconsistent state by the panicking function.
+func (r *Runtime) SetGoPanicStacks(enabled bool) {
+	r.goPanicStacks = enabled
+}
+
+// newGoPanicError creates the GoError thrown for a Go panic, see SetGoPanicStacks().
+func (r *Runtime) newGoPanicError(x interface{}, stack []byte) *Object {
+	e := r.newError(r.global.GoError, "panic: %v", x).(*Object)
+	e.Set("value", x)
+	e.self._putProp("goStack", newStringValue(string(stack)), true, false, true)
+	return e
+}
+
 // SetRandSource sets random source for this Runtime. If not called, the default math/rand is used.
 func (r *Runtime) SetRandSource(source RandSource) {
 	r.rand = source
@@ -1247,20 +1925,25 @@ func (r *Runtime) SetRandSource(source RandSource) {
 // Callable represents a JavaScript function that can be called from Go.
 type Callable func(this Value, args ...Value) (Value, error)
 
-// AssertFunction checks if the Value is a function and returns a Callable.
+// AssertFunction checks if the Value is a function and returns a Callable. The exceptions thrown by the function
+// are returned as *Exception and an interrupt as *InterruptedError.
 func AssertFunction(v Value) (Callable, bool) {
 	if obj, ok := v.(*Object); ok {
 		if f, ok := obj.self.assertCallable(); ok {
 			return func(this Value, args ...Value) (ret Value, err error) {
-				ex := obj.runtime.vm.try(func() {
+				// the limits of a host function don't apply to the scripts it calls
+				r := obj.runtime
+				saved := r.argLimits
+				r.argLimits = nil
+				defer func() {
+					r.argLimits = saved
+				}()
+				err = r.runWrapped(func() {
 					ret = f(FunctionCall{
 						This:      this,
 						Arguments: args,
 					})
 				})
-				if ex != nil {
-					err = ex
-				}
 				return
 			}, true
 		}
@@ -1268,6 +1951,191 @@ func AssertFunction(v Value) (Callable, bool) {
 	return nil, false
 }
 
+// Try calls f and returns the JavaScript exception thrown while it runs as *Exception: a value passed to panic()
+// (e.g. panic(r.NewTypeError(...))), an exception thrown by a conversion such as ToObject(), or one propagating from
+// a call back into the VM. This spares host functions their own recover() boilerplate. An interrupt is returned as
+// *InterruptedError, other panics are propagated. Try can be used both in host functions and outside of a script.
+func (r *Runtime) Try(f func()) error {
+	return r.runWrapped(f)
+}
+
+// Constructor represents a JavaScript constructor that can be invoked from Go as if by the 'new' operator.
+type Constructor func(args ...Value) (*Object, error)
+
+// AssertConstructor checks if the Value is a constructor and returns a Constructor. Exceptions thrown by the
+// constructor are returned as errors, as with a Callable.
+func AssertConstructor(v Value) (Constructor, bool) {
+	if obj, ok := v.(*Object); ok {
+		if ctor := obj.runtime.toConstructor(obj); ctor != nil {
+			return func(args ...Value) (ret *Object, err error) {
+				err = obj.runtime.runWrapped(func() {
+					ret = ctor(args)
+				})
+				return
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// toConstructor returns the function that constructs an object with the constructor obj, or nil if obj is not a
+// constructor.
+func (r *Runtime) toConstructor(obj *Object) func(args []Value) *Object {
+repeat:
+	switch f := obj.self.(type) {
+	case *funcObject:
+		return f.construct
+	case *nativeFuncObject:
+		return f.construct
+	case *boundFuncObject:
+		return f.construct
+	case *lazyObject:
+		obj.self = f.create(obj)
+		goto repeat
+	}
+	return nil
+}
+
+// CallBatch calls fn once for every element of argSets (with 'this' set to undefined) and returns the results in the
+// same order. The interrupt (see Interrupt()) is checked before each call, so a batch can be stopped even if fn is a
+// host function. If a call fails, the batch is aborted: the results of the calls that have completed so far are
+// returned along with the error (an *Exception if fn has thrown, an *InterruptedError if the Runtime has been
+// interrupted). To amortize the boundary crossing of a host function called by scripts, see NewBatchFunc().
+func (r *Runtime) CallBatch(fn Callable, argSets [][]Value) ([]Value, error) {
+	results := make([]Value, 0, len(argSets))
+	for _, args := range argSets {
+		if err := r.runWrapped(r.vm.checkInterrupt); err != nil {
+			return results, err
+		}
+		ret, err := fn(_undefined, args...)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, ret)
+	}
+	return results, nil
+}
+
+// BatchFunc is a host function that processes a batch of calls at once, see Runtime.NewBatchFunc().
+type BatchFunc func(argSets [][]Value) ([]Value, error)
+
+// NewBatchFunc creates a function which scripts call with an array of argument lists (e.g. f([[1, 2], [3, 4]]))
+// instead of calling a host function once per record, so that the boundary is crossed, and fn is invoked, only once
+// per batch. An element which is not an array is passed as the only argument. The results returned by fn are
+// returned as an array. A RangeError is thrown if the length of the batch or of an argument list is not a valid
+// array length. The interrupt (see Interrupt()) is checked while the lists are read.
+// An error returned by fn is thrown as GoError, or as is if it's an *Exception.
+func (r *Runtime) NewBatchFunc(name string, fn BatchFunc) *Object {
+	return r.newNativeFunc(func(call FunctionCall) Value {
+		list := r.toObject(call.Argument(0))
+		var argSets [][]Value
+		for i, l := int64(0), r.batchLength(list); i < l; i++ {
+			r.vm.checkInterrupt()
+			item := list.self.get(intToValue(i))
+			if item == nil {
+				item = _undefined
+			}
+			if obj, ok := item.(*Object); ok && obj.self.className() == classArray {
+				var args []Value
+				for j, n := int64(0), r.batchLength(obj); j < n; j++ {
+					r.vm.checkInterrupt()
+					arg := obj.self.get(intToValue(j))
+					if arg == nil {
+						arg = _undefined
+					}
+					args = append(args, arg)
+				}
+				argSets = append(argSets, args)
+			} else {
+				argSets = append(argSets, []Value{item})
+			}
+		}
+		results, err := fn(argSets)
+		if err != nil {
+			if ex, ok := err.(*Exception); ok {
+				panic(ex)
+			}
+			panic(r.NewGoError(err))
+		}
+		return r.newArrayValues(results)
+	}, nil, name, nil, 1)
+}
+
+// batchLength returns the length of an array-like object passed to a batch function. The lists are built as they
+// are read rather than allocated upfront, as the length is controlled by the script.
+func (r *Runtime) batchLength(o *Object) int64 {
+	l := toLength(o.self.getStr("length"))
+	if l > math.MaxUint32 {
+		panic(r.newError(r.global.RangeError, "Invalid array length"))
+	}
+	return l
+}
+
+// ExportIterator returns a function that yields the values of v one at a time, so that Go code can lazily consume
+// a sequence produced by a script without materialising it as a slice.
+// v may be an iterator, i.e. an object with a next() method returning {value, done} results, an array-like
+// object, whose elements are read as they are requested, or a string, which is iterated by code points (a surrogate
+// pair is yielded as a single string) like String.prototype[Symbol.iterator]. Note that Symbol.iterator is not
+// supported by this runtime, so other iterables have to be passed as the iterator returned by their iteration method.
+// The returned function reports ok == false once the sequence is exhausted. If getting a value throws, the exception
+// is returned as *Exception and the sequence ends.
+func (r *Runtime) ExportIterator(v Value) (next func() (Value, bool, error), err error) {
+	var iter func() (Value, bool)
+	ex := r.vm.try(func() {
+		if s, ok := v.assertString(); ok {
+			iter = stringCodePoints(s)
+			return
+		}
+		obj := r.toObject(v)
+		if nextFn, ok := obj.self.getStr("next").(*Object); ok {
+			if f, ok := nextFn.self.assertCallable(); ok {
+				iter = func() (Value, bool) {
+					res := r.toObject(f(FunctionCall{This: obj}))
+					if done := res.self.getStr("done"); done != nil && done.ToBoolean() {
+						return nil, false
+					}
+					if val := res.self.getStr("value"); val != nil {
+						return val, true
+					}
+					return _undefined, true
+				}
+				return
+			}
+		}
+		var idx uint32
+		iter = func() (Value, bool) {
+			if l := obj.self.getStr("length"); l == nil || idx >= toUInt32(l) {
+				return nil, false
+			}
+			val := obj.self.get(valueInt(idx))
+			idx++
+			if val == nil {
+				val = _undefined
+			}
+			return val, true
+		}
+	})
+	if ex != nil {
+		return nil, ex
+	}
+
+	done := false
+	next = func() (val Value, ok bool, err error) {
+		if done {
+			return nil, false, nil
+		}
+		if err := r.runWrapped(func() {
+			val, ok = iter()
+		}); err != nil {
+			done = true
+			return nil, false, err
+		}
+		done = !ok
+		return
+	}
+	return
+}
+
 // IsUndefined returns true if the supplied Value is undefined. Note, it checks against the real undefined, not
 // against the global object's 'undefined' property.
 func IsUndefined(v Value) bool {
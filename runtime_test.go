@@ -1,7 +1,11 @@
 package goja
 
 import (
+	gocontext "context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -355,6 +359,153 @@ func TestInterrupt(t *testing.T) {
 	}
 }
 
+func TestInterruptNotCatchable(t *testing.T) {
+	const SCRIPT = `
+	var caught = false, finalized = false;
+	try {
+		for (;;) {}
+	} catch (e) {
+		caught = true;
+	} finally {
+		finalized = true;
+	}
+	`
+
+	vm := New()
+	time.AfterFunc(100*time.Millisecond, func() {
+		vm.Interrupt("halt")
+	})
+
+	_, err := vm.RunString(SCRIPT)
+	intr, ok := err.(*InterruptedError)
+	if !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+	if v := intr.Value(); v != "halt" {
+		t.Fatalf("Unexpected value: %v", v)
+	}
+	if msg := intr.Error(); msg != "halt" {
+		t.Fatalf("Unexpected message: %q", msg)
+	}
+	if vm.Get("caught").ToBoolean() || vm.Get("finalized").ToBoolean() {
+		t.Fatal("The interrupt was caught by the script")
+	}
+}
+
+func TestClearInterrupt(t *testing.T) {
+	vm := New()
+	vm.Interrupt("halt")
+
+	_, err := vm.RunString("1")
+	if _, ok := err.(*InterruptedError); !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+
+	vm.ClearInterrupt()
+	res, err := vm.RunString("1 + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.StrictEquals(intToValue(2)) {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestInterruptCallable(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString("function loop() { for (;;) {} }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop, ok := AssertFunction(vm.Get("loop"))
+	if !ok {
+		t.Fatal("loop is not a function")
+	}
+	time.AfterFunc(100*time.Millisecond, func() {
+		vm.Interrupt("halt")
+	})
+
+	_, err = loop(_undefined)
+	if _, ok := err.(*InterruptedError); !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+
+	vm.ClearInterrupt()
+	res, err := vm.RunString("loop.name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != "loop" {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+}
+
+func TestRunStringContext(t *testing.T) {
+	vm := New()
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := vm.RunStringContext(ctx, "for (;;) {}")
+	intr, ok := err.(*InterruptedError)
+	if !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+	if v := intr.Value(); v != gocontext.DeadlineExceeded {
+		t.Fatalf("Unexpected value: %v", v)
+	}
+
+	res, err := vm.RunStringContext(gocontext.Background(), "1 + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.StrictEquals(intToValue(2)) {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+
+	_, err = vm.RunStringContext(ctx, "1")
+	if _, ok := err.(*InterruptedError); !ok {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+}
+
+func TestRunProgramContextCancel(t *testing.T) {
+	p, err := Compile("", "var i = 0; for (;;) { i++; }", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm := New()
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err = vm.RunProgramContext(ctx, p)
+	if intr, ok := err.(*InterruptedError); !ok || intr.Value() != gocontext.Canceled {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+	if vm.Get("i").ToInteger() == 0 {
+		t.Fatal("The program has not been run")
+	}
+}
+
+func TestRunProgramContextKeepsInterrupt(t *testing.T) {
+	vm := New()
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	vm.Set("stop", func() {
+		vm.Interrupt("stop")
+		cancel()
+	})
+
+	_, err := vm.RunStringContext(ctx, "stop(); for (;;) {}")
+	if intr, ok := err.(*InterruptedError); !ok || intr.Value() != "stop" {
+		t.Fatalf("Unexpected error: %v (%T)", err, err)
+	}
+	_, err = vm.RunString("1")
+	if intr, ok := err.(*InterruptedError); !ok || intr.Value() != "stop" {
+		t.Fatalf("The interrupt has been cleared: %v (%T)", err, err)
+	}
+	vm.ClearInterrupt()
+}
+
 func TestRuntime_ExportToSlice(t *testing.T) {
 	const SCRIPT = `
 	var a = [1, 2, 3];
@@ -542,6 +693,71 @@ func TestRuntime_ExportToFuncFail(t *testing.T) {
 	}
 }
 
+func TestRuntime_ExportToFuncVariadicAndError(t *testing.T) {
+	const SCRIPT = `
+	function sum() {
+		var s = 0;
+		for (var i = 0; i < arguments.length; i++) {
+			s += arguments[i];
+		}
+		return s;
+	}
+	function check(v) {
+		if (v < 0) {
+			throw new RangeError("negative");
+		}
+	}
+	function divmod(a, b) {
+		return [Math.floor(a / b), a % b];
+	}
+	`
+
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sum func(string, ...int) string
+	if err := vm.ExportTo(vm.Get("sum"), &sum); err != nil {
+		t.Fatal(err)
+	}
+	if res := sum("n=", 1, 2, 3); res != "0n=123" {
+		t.Fatalf("Unexpected sum: %q", res)
+	}
+
+	var check func(int) error
+	if err := vm.ExportTo(vm.Get("check"), &check); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(-1); err == nil || err.Error() != "RangeError: negative" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var divmod func(int, int) (int, int, error)
+	if err := vm.ExportTo(vm.Get("divmod"), &divmod); err != nil {
+		t.Fatal(err)
+	}
+	q, m, err := divmod(7, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != 3 || m != 1 {
+		t.Fatalf("Unexpected results: %d, %d", q, m)
+	}
+
+	var bad func() (int, int, error)
+	if err := vm.ExportTo(vm.Get("sum"), &bad); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bad(); err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
 func TestRuntime_ExportToCallable(t *testing.T) {
 	const SCRIPT = `
 	function f(param) {
@@ -590,146 +806,1163 @@ func TestRuntime_ExportToObject(t *testing.T) {
 	}
 }
 
-func TestGoFuncError(t *testing.T) {
-	const SCRIPT = `
-	try {
-		f();
-	} catch (e) {
-		if (!(e instanceof GoError)) {
-			throw(e);
-		}
-		if (e.value.Error() !== "Test") {
-			throw("Unexpected value: " + e.value.Error());
-		}
-	}
-	`
-
-	f := func() error {
-		return errors.New("Test")
+func TestRuntime_ExportToNested(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Person struct {
+		Base
+		Name      string            `json:"name"`
+		Tags      []string          `json:"tags"`
+		Scores    [2]float64        `json:"scores"`
+		Address   *Address          `json:"address"`
+		Manager   *Address          `json:"manager"`
+		Extra     map[string]int    `json:"extra"`
+		Born      time.Time         `json:"born"`
+		Updated   time.Time         `json:"updated"`
+		Seen      time.Time         `json:"seen"`
+		Format    func(int) string  `json:"format"`
+		Ignored   string            `json:"-"`
+		Untouched map[string]string `json:"untouched"`
 	}
-
 	vm := New()
-	vm.Set("f", f)
-	_, err := vm.RunString(SCRIPT)
+	vm.SetFieldNameMapper(TagFieldNameMapper("json", false))
+	v, err := vm.RunString(`({
+		id: 7,
+		name: "Ann",
+		tags: ["a", "b"],
+		scores: [1.5, 2],
+		address: {city: "Oslo"},
+		manager: null,
+		extra: {x: 1},
+		born: new Date(Date.UTC(2000, 0, 2)),
+		updated: "2020-05-06T07:08:09Z",
+		seen: 1000,
+		format: function(n) { return "#" + n; },
+		Ignored: "x"
+	})`)
 	if err != nil {
 		t.Fatal(err)
 	}
-}
-
-func TestToValueNil(t *testing.T) {
-	type T struct{}
-	var a *T
-	vm := New()
+	var p Person
+	if err := vm.ExportTo(v, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 7 || p.Name != "Ann" || len(p.Tags) != 2 || p.Tags[1] != "b" || p.Scores != [2]float64{1.5, 2} {
+		t.Fatalf("Unexpected value: %+v", p)
+	}
+	if p.Address == nil || p.Address.City != "Oslo" || p.Manager != nil || p.Extra["x"] != 1 {
+		t.Fatalf("Unexpected value: %+v", p)
+	}
+	if !p.Born.Equal(time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)) || !p.Updated.Equal(time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)) ||
+		!p.Seen.Equal(time.Unix(1, 0)) {
+		t.Fatalf("Unexpected times: %v, %v, %v", p.Born, p.Updated, p.Seen)
+	}
+	if s := p.Format(3); s != "#3" {
+		t.Fatalf("Unexpected format result: %q", s)
+	}
+	if p.Ignored != "" || p.Untouched != nil {
+		t.Fatalf("Unexpected value: %+v", p)
+	}
 
-	if v := vm.ToValue(a); !IsNull(v) {
-		t.Fatalf("Expected null, got: %v", v)
+	var arr [1]int
+	if err := vm.ExportTo(vm.ToValue([]interface{}{1, 2}), &arr); err == nil {
+		t.Fatal("Expected an error for a too long array")
+	}
+	var tm time.Time
+	if err := vm.ExportTo(vm.ToValue("not a date"), &tm); err == nil {
+		t.Fatal("Expected an error for an invalid date")
+	}
+	var tags []string
+	if err := vm.ExportTo(_null, &tags); err != nil || tags != nil {
+		t.Fatalf("Unexpected result: %v, %v", tags, err)
 	}
 }
 
-func TestJSONEscape(t *testing.T) {
-	const SCRIPT = `
-	var a = "\\+1";
-	JSON.stringify(a);
-	`
+func TestDynamicCodeNames(t *testing.T) {
+	vm := New()
+	test := func(src, expected string) {
+		_, err := vm.RunScript("test.js", src)
+		ex, ok := err.(*Exception)
+		if !ok {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(ex.String(), expected) {
+			t.Fatalf("Expected %q in the stack trace:\n%s", expected, ex.String())
+		}
+	}
 
-	testScript1(SCRIPT, asciiString(`"\\+1"`), t)
+	test(`eval("1;\n  throw new Error('test');")`, "at <eval:1>:2:9(")
+	test(`(0, eval)("\n\nthrow new Error('test');")`, "at <eval:2>:3:7(")
+	test(`new Function("a", "\nthrow new Error('test');")()`, "at anonymous (<Function:3>:2:7(")
+	test(`new Function("throw new Error('test');\n//# sourceURL=generated.js")()`, "at anonymous (generated.js:1:")
 }
 
-func TestJSONObjectInArray(t *testing.T) {
-	const SCRIPT = `
-	var a = "[{\"a\":1},{\"a\":2}]";
-	JSON.stringify(JSON.parse(a)) == a;
-	`
+type testSourceProvider map[string]string
 
-	testScript1(SCRIPT, valueTrue, t)
+func (p testSourceProvider) Source(filename string) (string, error) {
+	if src, exists := p[filename]; exists {
+		return src, nil
+	}
+	return "", errors.New("not found")
 }
 
-func TestJSONQuirkyNumbers(t *testing.T) {
-	const SCRIPT = `
-	var s;
-	s = JSON.stringify(NaN);
-	if (s != "null") {
-		throw new Error("NaN: " + s);
+func TestSourceContext(t *testing.T) {
+	vm := New()
+	_, err := vm.RunScript("test.js", "var a = 1;\n\tthrow new Error('test');")
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
 	}
-
-	s = JSON.stringify(Infinity);
-	if (s != "null") {
-		throw new Error("Infinity: " + s);
+	if res := ex.SourceContext(); res != "test.js:2\n\tthrow new Error('test');\n\t      ^\n" {
+		t.Fatalf("Unexpected context: %q", res)
 	}
 
-	s = JSON.stringify(-Infinity);
-	if (s != "null") {
-		throw new Error("-Infinity: " + s);
+	vm.SetSourceProvider(testSourceProvider{"test.ts": "let a: number = 1;\n\tthrow new Error('original');"})
+	_, err = vm.RunScript("test.ts", "var a = 1;\n\tthrow new Error('test');")
+	if res := err.(*Exception).SourceContext(); res != "test.ts:2\n\tthrow new Error('original');\n\t      ^\n" {
+		t.Fatalf("Unexpected context with provider: %q", res)
 	}
-
-	`
-
-	testScript1(SCRIPT, _undefined, t)
 }
 
-func TestJSONNil(t *testing.T) {
-	const SCRIPT = `
-	JSON.stringify(i);
-	`
-
+func TestStackFrameFilter(t *testing.T) {
 	vm := New()
-	var i interface{}
-	vm.Set("i", i)
-	ret, err := vm.RunString(SCRIPT)
+	vm.SetStackFrameFilter(func(frames []StackFrame) []StackFrame {
+		res := frames[:0]
+		for _, frame := range frames {
+			if frame.SrcName() != "harness.js" {
+				res = append(res, frame)
+			}
+		}
+		return res
+	})
+	_, err := vm.RunScript("harness.js", "function run(f) { return f(); }")
 	if err != nil {
 		t.Fatal(err)
 	}
+	_, err = vm.RunScript("test.js", "function test() {\n  throw new Error('test');\n}\nrun(test);")
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stack := ex.Stack()
+	if len(stack) != 2 {
+		t.Fatalf("Unexpected stack: %v", ex.String())
+	}
+	if stack[0].FuncName() != "test" || stack[0].Position() != (Position{Line: 2, Col: 9}) || stack[1].SrcName() != "test.js" {
+		t.Fatalf("Unexpected frames: %v", ex.String())
+	}
+	if strings.Contains(ex.String(), "harness.js") {
+		t.Fatalf("Frame is not filtered: %s", ex.String())
+	}
+}
 
-	if ret.String() != "null" {
-		t.Fatalf("Expected 'null', got: %v", ret)
+func TestCaptureCallStack(t *testing.T) {
+	vm := New()
+	if stack := vm.CaptureCallStack(0); stack != nil {
+		t.Fatalf("Unexpected stack: %v", stack)
+	}
+	var frames, limited []StackFrame
+	vm.Set("log", func(call FunctionCall) Value {
+		frames = vm.CaptureCallStack(0)
+		limited = vm.CaptureCallStack(2)
+		return _undefined
+	})
+	_, err := vm.RunScript("test.js", "function f() {\n  log('here');\n}\nf();")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 || len(limited) != 2 {
+		t.Fatalf("Unexpected stacks: %v, %v", frames, limited)
+	}
+	for i, expected := range []string{"native", "f (test.js:2:7)", "test.js:4:2"} {
+		if s := frames[i].String(); s != expected {
+			t.Fatalf("Frame %d: %q, expected %q", i, s, expected)
+		}
+	}
+	if pos := frames[1].Position(); frames[1].FuncName() != "f" || frames[1].SrcName() != "test.js" || pos.Line != 2 {
+		t.Fatalf("Unexpected frame: %v", frames[1])
 	}
 }
 
-type customJsonEncodable struct{}
+func TestScriptOrigin(t *testing.T) {
+	vm := New()
+	origin := &ScriptOrigin{URL: "https://example.com/plugin.js", Tenant: "tenant1", Integrity: "sha384-abc"}
+	p, err := CompileWithOrigin("plugin.js", "function f() {\n  throw new Error('test');\n}\neval('f()');", false, origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Origin() != origin {
+		t.Fatal("Origin is not retained")
+	}
+	_, err = vm.RunProgram(p)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stack := ex.Stack()
+	if len(stack) < 3 {
+		t.Fatalf("Unexpected stack: %v", ex.String())
+	}
+	for _, frame := range stack {
+		if frame.Origin() != origin {
+			t.Fatalf("Unexpected origin of %s: %v", frame.SrcName(), frame.Origin())
+		}
+	}
 
-func (*customJsonEncodable) JsonEncodable() interface{} {
-	return "Test"
+	_, err = vm.RunString("eval('f()')")
+	ex, ok = err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stack = ex.Stack()
+	if stack[0].Origin() != origin || stack[len(stack)-1].Origin() != nil {
+		t.Fatalf("Unexpected stack origins: %v", ex.String())
+	}
 }
 
-func TestJsonEncodable(t *testing.T) {
-	var s customJsonEncodable
-
+func TestCompileCache(t *testing.T) {
 	vm := New()
-	vm.Set("s", &s)
-
-	ret, err := vm.RunString("JSON.stringify(s)")
+	vm.SetCompileCacheSize(2)
+	_, err := vm.RunString(TESTLIB + `
+	var fns = [];
+	for (var i = 0; i < 3; i++) {
+		fns.push(new Function("a", "return a * 2"));
+		eval("var x = " + i % 2);
+	}
+	assert(fns[0] !== fns[1], "distinct functions");
+	assert.sameValue(fns[2](21), 42, "result");
+	assert.sameValue(x, 0, "x");
+	`)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !ret.StrictEquals(vm.ToValue("\"Test\"")) {
-		t.Fatalf("Expected \"Test\", got: %v", ret)
+	if l := vm.compileCache.order.Len(); l != 2 {
+		t.Fatalf("Unexpected cache length: %d", l)
+	}
+	// "var x = 0" is evicted by "var x = 1" and compiled again
+	if vm.dynamicCodeId != 4 {
+		t.Fatalf("Unexpected number of compiled programs: %d", vm.dynamicCodeId)
 	}
-}
 
-func TestSortComparatorReturnValues(t *testing.T) {
-	const SCRIPT = `
-	var a = [];
-	for (var i = 0; i < 12; i++) {
-	    a[i] = i;
+	vm.SetCompileCacheSize(0)
+	if l := vm.compileCache.order.Len(); l != 0 {
+		t.Fatalf("Unexpected cache length after resize: %d", l)
+	}
+	vm.RunString(`eval("1"); eval("1")`)
+	if vm.dynamicCodeId != 6 {
+		t.Fatalf("Cache must be disabled: %d", vm.dynamicCodeId)
 	}
 
-	a.sort(function(x, y) { return y - x });
+	vm.SetCompileCacheSize(2)
+	vm.RunString(`eval("1")`)
+	vm.SetCompileCacheSize(-1)
+	if l := vm.compileCache.order.Len(); l != 0 {
+		t.Fatalf("Unexpected cache length after negative resize: %d", l)
+	}
+	vm.RunString(`eval("1"); eval("1")`)
+	if vm.dynamicCodeId != 9 {
+		t.Fatalf("Cache must be disabled by a negative size: %d", vm.dynamicCodeId)
+	}
+}
 
-	for (var i = 0; i < 12; i++) {
-	    if (a[i] !== 11-i) {
-		throw new Error("Value at index " + i + " is incorrect: " + a[i]);
-	    }
+func TestRuntime_ExportToStrictNumbers(t *testing.T) {
+	vm := New()
+
+	var u8 uint8
+	if err := vm.ExportTo(vm.ToValue(300), &u8); err != nil || u8 != 44 {
+		t.Fatalf("Non-strict conversion: %d, %v", u8, err)
 	}
-	`
 
-	testScript1(SCRIPT, _undefined, t)
-}
+	vm.SetStrictNumberConversion(true)
 
-/*
-func TestArrayConcatSparse(t *testing.T) {
-function foo(a,b,c)
-  {
-    arguments[0] = 1; arguments[1] = 'str'; arguments[2] = 2.1;
+	if err := vm.ExportTo(vm.ToValue(255), &u8); err != nil || u8 != 255 {
+		t.Fatalf("Unexpected result: %d, %v", u8, err)
+	}
+	if err := vm.ExportTo(vm.ToValue(300), &u8); err == nil {
+		t.Fatal("Expected error for out of range value")
+	}
+	if err := vm.ExportTo(vm.ToValue(-1), &u8); err == nil {
+		t.Fatal("Expected error for negative value")
+	}
+	if err := vm.ExportTo(vm.ToValue(1.5), &u8); err == nil {
+		t.Fatal("Expected error for fractional value")
+	}
+
+	var i32 int32
+	if err := vm.ExportTo(vm.ToValue(-2147483648), &i32); err != nil || i32 != -2147483648 {
+		t.Fatalf("Unexpected result: %d, %v", i32, err)
+	}
+	if err := vm.ExportTo(vm.ToValue(1<<31), &i32); err == nil {
+		t.Fatal("Expected error for out of range value")
+	}
+
+	var u64 uint64
+	if err := vm.ExportTo(vm.ToValue(float64(1<<63)), &u64); err != nil || u64 != 1<<63 {
+		t.Fatalf("Unexpected result: %d, %v", u64, err)
+	}
+
+	var f32 float32
+	if err := vm.ExportTo(vm.ToValue(1e300), &f32); err == nil {
+		t.Fatal("Expected error for out of range float32")
+	}
+
+	vm.Set("f", func(v uint8) uint8 {
+		return v
+	})
+	_, err := vm.RunString(`
+	var thrown = false;
+	try {
+		f(256);
+	} catch (e) {
+		thrown = e instanceof TypeError;
+	}
+	if (!thrown) {
+		throw new Error("TypeError expected");
+	}
+	if (f(255) !== 255) {
+		throw new Error("Unexpected result");
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuntime_SetLazy(t *testing.T) {
+	vm := New()
+	calls := 0
+	vm.SetLazy("sdk", func(r *Runtime) Value {
+		calls++
+		o := r.NewObject()
+		o.Set("version", 2)
+		return o
+	})
+	vm.SetLazy("unused", func(r *Runtime) Value {
+		t.Fatal("unused is initialised")
+		return nil
+	})
+	vm.SetLazy("replaced", func(r *Runtime) Value {
+		t.Fatal("replaced is initialised")
+		return nil
+	})
+	res, err := vm.RunString(`
+	if (!("unused" in this)) {
+		throw new Error("unused is not defined");
+	}
+	replaced = 1;
+	sdk.version + sdk.version + replaced;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ToInteger() != 5 {
+		t.Fatalf("Unexpected result: %v", res)
+	}
+	if calls != 1 {
+		t.Fatalf("init is called %d times", calls)
+	}
+	if res, _ := vm.RunString(`typeof Object.getOwnPropertyDescriptor(this, "sdk").value`); res.String() != "object" {
+		t.Fatalf("sdk is not a data property: %v", res)
+	}
+}
+
+func goPanickingHostFunc(call FunctionCall) Value {
+	var m map[string]int
+	m["x"] = 1
+	return nil
+}
+
+func TestRuntime_SetGoPanicStacks(t *testing.T) {
+	vm := New()
+	vm.SetGoPanicStacks(true)
+	vm.Set("f", goPanickingHostFunc)
+	res, err := vm.RunString(`
+	var caught;
+	try {
+		f();
+	} catch (e) {
+		caught = e;
+	}
+	caught instanceof GoError && caught.message.indexOf("panic: assignment to entry in nil map") === 0 &&
+		Object.keys(caught).indexOf("goStack") === -1 && caught.goStack.indexOf("goPanickingHostFunc") !== -1;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != valueTrue {
+		t.Fatal("Unexpected exception")
+	}
+
+	_, err = vm.RunString("f()")
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(ex.GoStack(), "goPanickingHostFunc") {
+		t.Fatalf("Unexpected Go stack: %s", ex.GoStack())
+	}
+
+	vm.SetGoPanicStacks(false)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Panic is not propagated")
+		}
+	}()
+	vm.RunString("f()")
+}
+
+func TestRuntime_LimitArgs(t *testing.T) {
+	const SCRIPT = `
+	function throws(f, msg) {
+		try {
+			f();
+		} catch (e) {
+			if (e instanceof TypeError && e.message === msg) {
+				return;
+			}
+			throw e;
+		}
+		throw new Error("Expected TypeError: " + msg);
+	}
+	var big = "";
+	for (var i = 0; i < 11; i++) {
+		big += "x";
+	}
+	throws(function() { log("ok", big); }, "Argument 1 exceeds the maximum string length (10)");
+	throws(function() { log({a: [1, big]}); }, "Argument 0 exceeds the maximum string length (10)");
+	throws(function() { log([1, 2, 3, 4, 5, 6]); }, "Argument 0 exceeds the maximum array length (5)");
+	throws(function() { log({a: {b: {}}}); }, "Argument 0 exceeds the maximum nesting depth (2)");
+	var o = {};
+	o.self = o;
+	log(o);
+	log("short", [1, 2, 3], {a: {b: 1}});
+	assert.sameValue(count, 2, "count");
+	throws(function() { log(new String("0123456789abcdef")); }, "Argument 0 exceeds the maximum string length (10)");
+	var so = new String("short");
+	so.extra = big;
+	throws(function() { log(so); }, "Argument 0 exceeds the maximum string length (10)");
+	throws(function() { str({toString: function() { return "0123456789abcdef"; }}); }, "String conversion exceeds the maximum string length (10)");
+	assert.sameValue(str({toString: function() { return "fine"; }}), 4, "converted string");
+	assert.sameValue(log.name, "log", "name");
+	assert.sameValue(log.length, 1, "length");
+	assert.sameValue(sum([1, 2, 3]), 6, "reflect function");
+	`
+
+	vm := New()
+	count := 0
+	vm.Set("count", 0)
+	logFn := vm.ToValue(func(call FunctionCall) Value {
+		count++
+		vm.Set("count", count)
+		return _undefined
+	}).ToObject(vm)
+	logFn.self._putProp("name", asciiString("log"), false, false, true)
+	logFn.self._putProp("length", intToValue(1), false, false, true)
+	limits := HostArgLimits{
+		MaxStringLength: 10,
+		MaxArrayLength:  5,
+		MaxDepth:        2,
+	}
+	vm.Set("log", vm.LimitArgs(logFn, limits))
+	vm.Set("str", vm.LimitArgs(func(s string) int {
+		return len(s)
+	}, limits))
+	vm.Set("sum", vm.LimitArgs(func(a []int) int {
+		s := 0
+		for _, v := range a {
+			s += v
+		}
+		return s
+	}, limits))
+
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuntime_CallBatch(t *testing.T) {
+	const SCRIPT = `
+	function f(a, b) {
+		return a * b;
+	}
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argSets := make([][]Value, 100)
+	for i := range argSets {
+		argSets[i] = []Value{vm.ToValue(i), vm.ToValue(2)}
+	}
+
+	f, _ := AssertFunction(vm.Get("f"))
+	res, err := vm.CallBatch(f, argSets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(argSets) {
+		t.Fatalf("Unexpected number of results: %d", len(res))
+	}
+	for i, v := range res {
+		if !v.StrictEquals(vm.ToValue(i * 2)) {
+			t.Fatalf("Unexpected value at %d: %v", i, v)
+		}
+	}
+}
+
+func TestRuntime_CallBatchThrow(t *testing.T) {
+	const SCRIPT = `
+	function f(a) {
+		if (a === 2) {
+			throw new Error("testing");
+		}
+		return a;
+	}
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := AssertFunction(vm.Get("f"))
+	res, err := vm.CallBatch(f, [][]Value{{vm.ToValue(0)}, {vm.ToValue(1)}, {vm.ToValue(2)}, {vm.ToValue(3)}})
+	if ex, ok := err.(*Exception); !ok || ex.Error() != "Error: testing" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("Unexpected results: %v", res)
+	}
+}
+
+func TestRuntime_CallBatchInterrupt(t *testing.T) {
+	vm := New()
+	var calls int
+	f, _ := AssertFunction(vm.ToValue(func(call FunctionCall) Value {
+		calls++
+		if calls == 2 {
+			vm.Interrupt("halt")
+		}
+		return call.Argument(0)
+	}))
+
+	res, err := vm.CallBatch(f, [][]Value{{vm.ToValue(0)}, {vm.ToValue(1)}, {vm.ToValue(2)}, {vm.ToValue(3)}})
+	if intr, ok := err.(*InterruptedError); !ok || intr.Value() != "halt" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 || len(res) != 2 {
+		t.Fatalf("Unexpected results: %d, %v", calls, res)
+	}
+	vm.ClearInterrupt()
+}
+
+func TestRuntime_NewBatchFunc(t *testing.T) {
+	vm := New()
+	var calls, records int
+	vm.Set("mul", vm.NewBatchFunc("mul", func(argSets [][]Value) ([]Value, error) {
+		calls++
+		res := make([]Value, len(argSets))
+		for i, args := range argSets {
+			records++
+			if len(args) < 2 {
+				return nil, errors.New("two arguments expected")
+			}
+			res[i] = vm.ToValue(args[0].ToInteger() * args[1].ToInteger())
+		}
+		return res, nil
+	}))
+
+	v, err := vm.RunString(`
+	var rows = [];
+	for (var i = 0; i < 100; i++) {
+		rows.push([i, 3]);
+	}
+	var res = mul(rows);
+	var caught;
+	try {
+		mul([[1, 2], 5]);
+	} catch (e) {
+		caught = e instanceof GoError && e.message === "two arguments expected";
+	}
+	mul.name === "mul" && res.length === 100 && res[99] === 297 && caught;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.ToBoolean() {
+		t.Fatal("Unexpected result")
+	}
+	if calls != 2 || records != 102 {
+		t.Fatalf("Unexpected calls: %d, %d", calls, records)
+	}
+}
+
+func TestRuntime_NewBatchFuncLength(t *testing.T) {
+	vm := New()
+	vm.Set("count", vm.NewBatchFunc("count", func(argSets [][]Value) ([]Value, error) {
+		return []Value{vm.ToValue(len(argSets))}, nil
+	}))
+
+	v, err := vm.RunString(`
+	var caught;
+	try {
+		count({length: 1e15});
+	} catch (e) {
+		caught = e instanceof RangeError;
+	}
+	caught && count({length: 3})[0] === 3;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.ToBoolean() {
+		t.Fatal("Unexpected result")
+	}
+}
+
+func TestRuntime_NewBatchFuncInterrupt(t *testing.T) {
+	vm := New()
+	vm.Set("count", vm.NewBatchFunc("count", func(argSets [][]Value) ([]Value, error) {
+		return []Value{vm.ToValue(len(argSets))}, nil
+	}))
+	time.AfterFunc(100*time.Millisecond, func() {
+		vm.Interrupt("halt")
+	})
+
+	_, err := vm.RunString(`count({length: 4e9})`)
+	if intr, ok := err.(*InterruptedError); !ok || intr.Value() != "halt" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestRuntime_ExportIterator(t *testing.T) {
+	const SCRIPT = `
+	var calls = 0;
+	function range(n, fail) {
+		var i = 0;
+		return {
+			next: function() {
+				calls++;
+				if (i === 2 && fail) {
+					throw new Error("testing");
+				}
+				return i < n ? {value: i++, done: false} : {done: true};
+			}
+		};
+	}
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rangeFn, _ := AssertFunction(vm.Get("range"))
+	it, err := rangeFn(_undefined, vm.ToValue(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := vm.ExportIterator(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, ok, err := next()
+	if err != nil || !ok || v.ToInteger() != 0 {
+		t.Fatalf("Unexpected result: %v, %v, %v", v, ok, err)
+	}
+	if calls := vm.Get("calls").ToInteger(); calls != 1 {
+		t.Fatalf("Iterator is not lazy: %d calls", calls)
+	}
+	var values []int64
+	for {
+		v, ok, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		values = append(values, v.ToInteger())
+	}
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+	if _, ok, _ := next(); ok {
+		t.Fatal("Iterator is not done")
+	}
+
+	it, err = rangeFn(_undefined, vm.ToValue(3), valueTrue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err = vm.ExportIterator(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := next(); err == nil || err.Error() != "Error: testing" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	next, err = vm.ExportIterator(vm.ToValue([]interface{}{"a", "b"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, _ := next(); !ok || v.String() != "a" {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if v, ok, _ := next(); !ok || v.String() != "b" {
+		t.Fatalf("Unexpected result: %v", v)
+	}
+	if _, ok, _ := next(); ok {
+		t.Fatal("Iterator is not done")
+	}
+
+	if _, err := vm.ExportIterator(_undefined); err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestRuntime_ExportIteratorString(t *testing.T) {
+	vm := New()
+	v, err := vm.RunString(`String.fromCharCode(0xD83D, 0xDE00, 0x78, 0xD83D, 0x79)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := vm.ExportIterator(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for {
+		v, ok, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		values = append(values, v.String())
+	}
+	if len(values) != 4 || values[0] != "😀" || values[1] != "x" || values[2] == "y" || values[3] != "y" {
+		t.Fatalf("Unexpected values: %q", values)
+	}
+}
+
+func TestGoFuncError(t *testing.T) {
+	const SCRIPT = `
+	try {
+		f();
+	} catch (e) {
+		if (!(e instanceof GoError)) {
+			throw(e);
+		}
+		if (e.value.Error() !== "Test") {
+			throw("Unexpected value: " + e.value.Error());
+		}
+	}
+	`
+
+	f := func() error {
+		return errors.New("Test")
+	}
+
+	vm := New()
+	vm.Set("f", f)
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testUnwrapError struct {
+	code int
+}
+
+func (e *testUnwrapError) Error() string {
+	return "code " + strconv.Itoa(e.code)
+}
+
+func TestGoErrorUnwrap(t *testing.T) {
+	errTest := errors.New("test")
+	vm := New()
+	vm.Set("ret", func() error {
+		return fmt.Errorf("wrapped: %w", errTest)
+	})
+	vm.Set("pnc", func() {
+		panic(&testUnwrapError{code: 42})
+	})
+
+	_, err := vm.RunString("ret()")
+	if !errors.Is(err, errTest) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	v, err := vm.RunString(`
+	var res;
+	try {
+		pnc();
+	} catch (e) {
+		res = e instanceof GoError && e.message === "code 42";
+	}
+	res;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.ToBoolean() {
+		t.Fatal("The panic is not thrown as GoError")
+	}
+
+	_, err = vm.RunString("pnc()")
+	var target *testUnwrapError
+	if !errors.As(err, &target) || target.code != 42 {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = vm.RunString(`throw new Error("js")`)
+	if ex, ok := err.(*Exception); !ok || ex.Unwrap() != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	_, err = vm.RunStringContext(ctx, "1")
+	if !errors.Is(err, gocontext.Canceled) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestGoRuntimePanicNotCatchable(t *testing.T) {
+	vm := New()
+	vm.Set("boom", func(call FunctionCall) Value {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+	vm.Set("boomErr", func() {
+		panic(errors.New("host error"))
+	})
+	defer func() {
+		x := recover()
+		if x == nil {
+			t.Fatal("Panic is not propagated")
+		}
+		if err, ok := x.(error); !ok || !strings.Contains(err.Error(), "assignment to entry in nil map") {
+			t.Fatalf("Unexpected panic: %v", x)
+		}
+	}()
+
+	v, err := vm.RunString(`
+	var r;
+	try {
+		try {
+			boomErr();
+		} finally {
+		}
+	} catch (e) {
+		r = e instanceof GoError && e.message === "host error";
+	}
+	r;
+	`)
+	if err != nil || !v.ToBoolean() {
+		t.Fatalf("Host error is not catchable: %v, %v", v, err)
+	}
+
+	vm.RunString(`
+	var r;
+	try {
+		try {
+			boom();
+		} finally {
+		}
+	} catch (e) {
+		r = "caught " + e;
+	}
+	r;
+	`)
+}
+
+func TestToValueNil(t *testing.T) {
+	type T struct{}
+	var a *T
+	vm := New()
+
+	if v := vm.ToValue(a); !IsNull(v) {
+		t.Fatalf("Expected null, got: %v", v)
+	}
+}
+
+func TestJSONEscape(t *testing.T) {
+	const SCRIPT = `
+	var a = "\\+1";
+	JSON.stringify(a);
+	`
+
+	testScript1(SCRIPT, asciiString(`"\\+1"`), t)
+}
+
+func TestJSONObjectInArray(t *testing.T) {
+	const SCRIPT = `
+	var a = "[{\"a\":1},{\"a\":2}]";
+	JSON.stringify(JSON.parse(a)) == a;
+	`
+
+	testScript1(SCRIPT, valueTrue, t)
+}
+
+func TestJSONQuirkyNumbers(t *testing.T) {
+	const SCRIPT = `
+	var s;
+	s = JSON.stringify(NaN);
+	if (s != "null") {
+		throw new Error("NaN: " + s);
+	}
+
+	s = JSON.stringify(Infinity);
+	if (s != "null") {
+		throw new Error("Infinity: " + s);
+	}
+
+	s = JSON.stringify(-Infinity);
+	if (s != "null") {
+		throw new Error("-Infinity: " + s);
+	}
+
+	`
+
+	testScript1(SCRIPT, _undefined, t)
+}
+
+func TestJSONNonFiniteNumbers(t *testing.T) {
+	vm := New()
+	vm.SetJSONNonFiniteNumbers(JSONNonFiniteAsString)
+	ret, err := vm.RunString(`JSON.stringify({a: NaN, b: [Infinity, -Infinity], c: 1.5})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ret.String(); s != `{"a":"NaN","b":["Infinity","-Infinity"],"c":1.5}` {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+
+	vm.SetJSONNonFiniteNumbers(JSONNonFiniteError)
+	_, err = vm.RunString(`JSON.stringify({a: 1, b: 0/0})`)
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "TypeError: Cannot convert NaN to JSON (key 'b')" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestJSONCircularReferences(t *testing.T) {
+	const SCRIPT = `
+	var o = {a: {b: [1, {}]}, c: {}};
+	o.a.b[1].back = o.a;
+	o.c.d = o.c;
+	o.c.e = o;
+	`
+	vm := New()
+	_, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm.SetJSONCircularReferences(JSONCircularAsMarker)
+	ret, err := vm.RunString(`JSON.stringify(o)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := ret.String(); s != `{"a":{"b":[1,{"back":"[Circular]"}]},"c":{"d":"[Circular]","e":"[Circular]"}}` {
+		t.Fatalf("Unexpected result: %s", s)
+	}
+
+	vm.SetJSONCircularReferences(JSONCircularErrorWithPath)
+	_, err = vm.RunString(`JSON.stringify(o)`)
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "TypeError: Converting circular structure to JSON: property 'a.b[1].back' refers to 'a'" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = vm.RunString(`JSON.stringify(o.c)`)
+	if ex, ok := err.(*Exception); !ok || ex.Value().String() != "TypeError: Converting circular structure to JSON: property 'd' refers to '(root)'" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestJSONParseNullPrototype(t *testing.T) {
+	const SCRIPT = `
+	var o = JSON.parse('{"a": {"__proto__": {"polluted": true}}, "b": [{}]}');
+	assert.sameValue(Object.getPrototypeOf(o), null, "root");
+	assert.sameValue(Object.getPrototypeOf(o.b[0]), null, "nested");
+	assert.sameValue(o.toString, undefined, "toString");
+
+	var target = Object.create(null);
+	for (var key in o.a) {
+		target[key] = o.a[key];
+	}
+	assert.sameValue(Object.getPrototypeOf(target), null, "target prototype");
+	assert.sameValue(target.polluted, undefined, "polluted");
+	assert.sameValue(target.__proto__.polluted, true, "__proto__ is an own property");
+	`
+
+	vm := New()
+	vm.SetJSONParseNullPrototype(true)
+	_, err := vm.RunString(TESTLIB + SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONNil(t *testing.T) {
+	const SCRIPT = `
+	JSON.stringify(i);
+	`
+
+	vm := New()
+	var i interface{}
+	vm.Set("i", i)
+	ret, err := vm.RunString(SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.String() != "null" {
+		t.Fatalf("Expected 'null', got: %v", ret)
+	}
+}
+
+func TestJSONWellFormedStringify(t *testing.T) {
+	const SCRIPT = `
+	assert.sameValue(JSON.stringify("\uD800"), '"\\ud800"', "lone lead surrogate");
+	assert.sameValue(JSON.stringify("\uDC00a"), '"\\udc00a"', "lone trail surrogate");
+	assert.sameValue(JSON.stringify("😀"), '"😀"', "surrogate pair");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestJSONRawJSON(t *testing.T) {
+	const SCRIPT = `
+	var raw = JSON.rawJSON("12345678901234567890");
+	assert(JSON.isRawJSON(raw), "isRawJSON");
+	assert(!JSON.isRawJSON({rawJSON: "1"}), "!isRawJSON");
+	assert.sameValue(Object.getPrototypeOf(raw), null, "prototype");
+	assert(Object.isFrozen(raw), "frozen");
+	assert.sameValue(raw.rawJSON, "12345678901234567890", "rawJSON");
+	assert.sameValue(JSON.stringify({a: raw, b: [JSON.rawJSON('"x"'), JSON.rawJSON("null")]}), '{"a":12345678901234567890,"b":["x",null]}', "stringify");
+
+	["", " 1", "1\n", "{}", "[1]", "1 2", "x"].forEach(function(text) {
+		var thrown = false;
+		try {
+			JSON.rawJSON(text);
+		} catch (e) {
+			thrown = e instanceof SyntaxError;
+		}
+		assert(thrown, "SyntaxError for " + JSON.stringify(text));
+	});
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+func TestJSONParseSource(t *testing.T) {
+	const SCRIPT = `
+	var sources = {};
+	var res = JSON.parse('{"a": 12345678901234567890, "b" : [ 1.0, "x\\u0041", true, null ], "c": {"d": -0}}', function(key, value, context) {
+		if (typeof value !== "object" || value === null) {
+			sources[key] = context.source;
+		} else {
+			assert(!("source" in context), "no source for objects");
+		}
+		if (key === "a") {
+			return JSON.rawJSON(context.source);
+		}
+		return value;
+	});
+	assert.sameValue(sources.a, "12345678901234567890", "a");
+	assert.sameValue(sources[0], "1.0", "b[0]");
+	assert.sameValue(sources[1], '"x\\u0041"', "b[1]");
+	assert.sameValue(sources[2], "true", "b[2]");
+	assert.sameValue(sources[3], "null", "b[3]");
+	assert.sameValue(sources.d, "-0", "d");
+	assert.sameValue(JSON.stringify(res), '{"a":12345678901234567890,"b":[1,"xA",true,null],"c":{"d":0}}', "stringify");
+
+	var modified;
+	JSON.parse('[1, 2]', function(key, value, context) {
+		if (key === "0") {
+			this[1] = 3;
+		}
+		if (key === "1") {
+			modified = context.source;
+		}
+		return value;
+	});
+	assert.sameValue(modified, undefined, "modified value");
+
+	var root;
+	JSON.parse(' "s" ', function(key, value, context) {
+		root = context.source;
+		return value;
+	});
+	assert.sameValue(root, '"s"', "root");
+	`
+
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
+
+type customJsonEncodable struct{}
+
+func (*customJsonEncodable) JsonEncodable() interface{} {
+	return "Test"
+}
+
+func TestJsonEncodable(t *testing.T) {
+	var s customJsonEncodable
+
+	vm := New()
+	vm.Set("s", &s)
+
+	ret, err := vm.RunString("JSON.stringify(s)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ret.StrictEquals(vm.ToValue("\"Test\"")) {
+		t.Fatalf("Expected \"Test\", got: %v", ret)
+	}
+}
+
+func TestSortComparatorReturnValues(t *testing.T) {
+	const SCRIPT = `
+	var a = [];
+	for (var i = 0; i < 12; i++) {
+	    a[i] = i;
+	}
+
+	a.sort(function(x, y) { return y - x });
+
+	for (var i = 0; i < 12; i++) {
+	    if (a[i] !== 11-i) {
+		throw new Error("Value at index " + i + " is incorrect: " + a[i]);
+	    }
+	}
+	`
+
+	testScript1(SCRIPT, _undefined, t)
+}
+
+/*
+func TestArrayConcatSparse(t *testing.T) {
+function foo(a,b,c)
+  {
+    arguments[0] = 1; arguments[1] = 'str'; arguments[2] = 2.1;
     if(1 === a && 'str' === b && 2.1 === c)
       return true;
   }
@@ -747,3 +1980,116 @@ function foo(a,b,c)
 	testScript1(SCRIPT, valueTrue, t)
 }
 */
+
+func TestAssertConstructor(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	function Point(x, y) {
+		if (x < 0) {
+			throw new RangeError("negative");
+		}
+		this.x = x;
+		this.y = y;
+	}
+	Point.prototype.sum = function() {
+		return this.x + this.y;
+	};
+	var Bound = Point.bind(null, 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctor, ok := AssertConstructor(vm.Get("Point"))
+	if !ok {
+		t.Fatal("Point is not a constructor")
+	}
+	p, err := ctor(vm.ToValue(1), vm.ToValue(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, _ := AssertFunction(p.Get("sum"))
+	if res, err := sum(p); err != nil || res.ToInteger() != 3 {
+		t.Fatalf("Unexpected result: %v, %v", res, err)
+	}
+	if _, err := ctor(vm.ToValue(-1)); err == nil || err.Error() != "RangeError: negative" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	bound, ok := AssertConstructor(vm.Get("Bound"))
+	if !ok {
+		t.Fatal("Bound function is not a constructor")
+	}
+	if p, err := bound(vm.ToValue(5)); err != nil || p.Get("x").ToInteger() != 10 || p.Get("y").ToInteger() != 5 {
+		t.Fatalf("Unexpected result: %v, %v", p, err)
+	}
+
+	date, ok := AssertConstructor(vm.Get("Date"))
+	if !ok {
+		t.Fatal("Date is not a constructor")
+	}
+	if d, err := date(vm.ToValue(0)); err != nil || d.self.className() != classDate {
+		t.Fatalf("Unexpected result: %v, %v", d, err)
+	}
+
+	for _, v := range []Value{vm.Get("Math").ToObject(vm).Get("max"), vm.ToValue(1), vm.NewObject()} {
+		if _, ok := AssertConstructor(v); ok {
+			t.Fatalf("%v is a constructor", v)
+		}
+	}
+}
+
+func TestRuntimeTry(t *testing.T) {
+	vm := New()
+	_, err := vm.RunString(`
+	var o = {};
+	Object.defineProperty(o, "ro", {value: 1});
+	function thrower() {
+		throw new RangeError("from js");
+	}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = vm.Try(func() {
+		_undefined.ToObject(vm)
+	})
+	if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Error(), "TypeError") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = vm.Try(func() {
+		panic(vm.NewTypeError("custom %d", 1))
+	})
+	if err == nil || err.Error() != "TypeError: custom 1" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := vm.Try(func() {}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = vm.Get("o").ToObject(vm).Set("ro", 2)
+	if ex, ok := err.(*Exception); !ok || !strings.HasPrefix(ex.Error(), "TypeError") {
+		t.Fatalf("Unexpected Set error: %v", err)
+	}
+
+	// inside a host function calling back into the VM
+	vm.Set("host", func(call FunctionCall) Value {
+		thrower, _ := AssertFunction(vm.Get("thrower"))
+		err := vm.Try(func() {
+			if _, err := thrower(nil); err != nil {
+				panic(err)
+			}
+		})
+		return vm.ToValue(err.Error())
+	})
+	v, err := vm.RunString(`host()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != "RangeError: from js" {
+		t.Fatalf("Unexpected result: %q", s)
+	}
+}
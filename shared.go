@@ -0,0 +1,359 @@
+package goja
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SharedData is an immutable graph of values (e.g. configuration or lookup tables) which is built once and can be
+// used by any number of Runtimes, including concurrently, without being copied into each of them. See
+// NewSharedData().
+//
+// ToValue() (and therefore Set()) turns it into a frozen object (or array) of the Runtime. The nested objects are
+// wrapped as they are accessed and the wrappers are cached, so the same node is always the same object within a
+// Runtime, while the data itself is shared. Any attempt to modify the objects fails as if they were frozen with
+// Object.freeze(): the assignment is ignored, or throws a TypeError in strict mode.
+type SharedData struct {
+	root interface{}
+}
+
+// sharedNode is an object or an array of a SharedData. The items are primitive Values (which don't belong to a
+// Runtime) or *sharedNode.
+type sharedNode struct {
+	isArray bool
+	names   []string
+	index   map[string]int
+	items   []interface{}
+}
+
+// NewSharedData builds a SharedData from a Go value: nil, booleans, numbers and strings, maps with string keys
+// and slices or arrays of such values, or primitive Values. Other types (including structs, functions and *Object)
+// and cyclic structures are rejected with an error. The value is copied, so it can be modified afterwards.
+// The properties of the objects are ordered as in JavaScript: array indexes first, then the other keys (sorted).
+func NewSharedData(v interface{}) (*SharedData, error) {
+	root, err := newSharedItem(reflect.ValueOf(v), map[uintptr]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return &SharedData{root: root}, nil
+}
+
+func newSharedItem(v reflect.Value, path map[uintptr]bool) (interface{}, error) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return _null, nil
+		}
+		if val, ok := v.Interface().(Value); ok {
+			if _, isObj := val.(*Object); !isObj {
+				return val, nil
+			}
+			return nil, fmt.Errorf("shared data cannot contain objects of a Runtime")
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return _null, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return valueTrue, nil
+		}
+		return valueFalse, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intToValue(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= maxInt {
+			return intToValue(int64(u)), nil
+		} else {
+			return floatToValue(float64(u)), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return floatToValue(v.Float()), nil
+	case reflect.String:
+		return newStringValue(v.String()), nil
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return _null, nil
+		}
+		ptr := v.Pointer()
+		if path[ptr] {
+			return nil, fmt.Errorf("shared data cannot be cyclic")
+		}
+		path[ptr] = true
+		defer delete(path, ptr)
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type in shared data: %s", v.Type().Key())
+		}
+		node := &sharedNode{
+			names: make([]string, 0, v.Len()),
+			index: make(map[string]int, v.Len()),
+			items: make([]interface{}, v.Len()),
+		}
+		for _, key := range v.MapKeys() {
+			node.names = append(node.names, key.String())
+		}
+		sort.Slice(node.names, func(i, j int) bool {
+			a, b := strToIdx(node.names[i]), strToIdx(node.names[j])
+			if a >= 0 || b >= 0 {
+				return b < 0 || a >= 0 && a < b
+			}
+			return node.names[i] < node.names[j]
+		})
+		for i, name := range node.names {
+			item, err := newSharedItem(v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())), path)
+			if err != nil {
+				return nil, err
+			}
+			node.index[name] = i
+			node.items[i] = item
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		node := &sharedNode{
+			isArray: true,
+			items:   make([]interface{}, v.Len()),
+		}
+		for i := range node.items {
+			item, err := newSharedItem(v.Index(i), path)
+			if err != nil {
+				return nil, err
+			}
+			node.items[i] = item
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("unsupported type in shared data: %s", v.Type())
+}
+
+// sharedValue returns the Value of an item of a SharedData, wrapping it into an objectShared if it's a node.
+func (r *Runtime) sharedValue(item interface{}) Value {
+	node, ok := item.(*sharedNode)
+	if !ok {
+		return item.(Value)
+	}
+	if obj := r.sharedObjects[node]; obj != nil {
+		return obj
+	}
+	obj := &Object{runtime: r}
+	o := &objectShared{
+		baseObject: baseObject{
+			val: obj,
+		},
+		node: node,
+	}
+	obj.self = o
+	o.init()
+	if r.sharedObjects == nil {
+		r.sharedObjects = make(map[*sharedNode]*Object)
+	}
+	r.sharedObjects[node] = obj
+	return obj
+}
+
+// objectShared is a frozen object or array backed by a node of a SharedData.
+type objectShared struct {
+	baseObject
+	node *sharedNode
+}
+
+func (o *objectShared) init() {
+	o.baseObject.init()
+	if o.node.isArray {
+		o.class = classArray
+		o.prototype = o.val.runtime.global.ArrayPrototype
+		o.baseObject._putProp("length", intToValue(int64(len(o.node.items))), false, false, false)
+	} else {
+		o.class = classObject
+		o.prototype = o.val.runtime.global.ObjectPrototype
+	}
+	o.extensible = false
+}
+
+func (o *objectShared) _getStr(name string) Value {
+	if o.node.isArray {
+		if idx := strToIdx(name); idx >= 0 && idx < int64(len(o.node.items)) {
+			return o.val.runtime.sharedValue(o.node.items[idx])
+		}
+		return nil
+	}
+	if i, exists := o.node.index[name]; exists {
+		return o.val.runtime.sharedValue(o.node.items[i])
+	}
+	return nil
+}
+
+func (o *objectShared) _hasStr(name string) bool {
+	if o.node.isArray {
+		idx := strToIdx(name)
+		return idx >= 0 && idx < int64(len(o.node.items))
+	}
+	_, exists := o.node.index[name]
+	return exists
+}
+
+func (o *objectShared) get(n Value) Value {
+	return o.getStr(n.String())
+}
+
+func (o *objectShared) getStr(name string) Value {
+	if v := o._getStr(name); v != nil {
+		return v
+	}
+	return o.baseObject.getStr(name)
+}
+
+func (o *objectShared) getProp(n Value) Value {
+	return o.getPropStr(n.String())
+}
+
+func (o *objectShared) getPropStr(name string) Value {
+	if v := o._getStr(name); v != nil {
+		return v
+	}
+	return o.baseObject.getPropStr(name)
+}
+
+func (o *objectShared) getOwnProp(name string) Value {
+	if v := o._getStr(name); v != nil {
+		return &valueProperty{
+			value:      v,
+			enumerable: true,
+		}
+	}
+	return o.baseObject.getOwnProp(name)
+}
+
+func (o *objectShared) put(n Value, val Value, throw bool) {
+	o.putStr(n.String(), val, throw)
+}
+
+func (o *objectShared) putStr(name string, val Value, throw bool) {
+	if o._hasStr(name) {
+		o.val.runtime.typeErrorResult(throw, "Cannot assign to read only property '%s'", name)
+		return
+	}
+	o.baseObject.putStr(name, val, throw)
+}
+
+func (o *objectShared) hasProperty(n Value) bool {
+	return o.hasPropertyStr(n.String())
+}
+
+func (o *objectShared) hasPropertyStr(name string) bool {
+	return o._hasStr(name) || o.baseObject.hasPropertyStr(name)
+}
+
+func (o *objectShared) hasOwnProperty(n Value) bool {
+	return o.hasOwnPropertyStr(n.String())
+}
+
+func (o *objectShared) hasOwnPropertyStr(name string) bool {
+	return o._hasStr(name) || o.baseObject.hasOwnPropertyStr(name)
+}
+
+func (o *objectShared) _putProp(name string, value Value, writable, enumerable, configurable bool) Value {
+	o.putStr(name, value, false)
+	return value
+}
+
+func (o *objectShared) defineOwnProperty(n Value, descr objectImpl, throw bool) bool {
+	name := n.String()
+	if o._hasStr(name) {
+		// only the descriptors which don't change anything are allowed
+		_, ok := o.baseObject._defineOwnProperty(n, o.getOwnProp(name), descr, throw)
+		return ok
+	}
+	return o.baseObject.defineOwnProperty(n, descr, throw)
+}
+
+func (o *objectShared) deleteStr(name string, throw bool) bool {
+	if o._hasStr(name) {
+		o.val.runtime.typeErrorResult(throw, "Cannot delete property '%s' of %s", name, o.val.ToString())
+		return false
+	}
+	return o.baseObject.deleteStr(name, throw)
+}
+
+func (o *objectShared) delete(n Value, throw bool) bool {
+	return o.deleteStr(n.String(), throw)
+}
+
+type sharedPropIter struct {
+	o         *objectShared
+	recursive bool
+	idx       int
+}
+
+func (i *sharedPropIter) next() (propIterItem, iterNextFunc) {
+	if i.idx < len(i.o.node.items) {
+		var name string
+		if i.o.node.isArray {
+			name = strconv.Itoa(i.idx)
+		} else {
+			name = i.o.node.names[i.idx]
+		}
+		i.idx++
+		return propIterItem{name: name, enumerable: _ENUM_TRUE}, i.next
+	}
+	return i.o.baseObject._enumerate(i.recursive)()
+}
+
+func (o *objectShared) enumerate(all, recursive bool) iterNextFunc {
+	return (&propFilterIter{
+		wrapped: o._enumerate(recursive),
+		all:     all,
+		seen:    make(map[string]bool),
+	}).next
+}
+
+func (o *objectShared) _enumerate(recursive bool) iterNextFunc {
+	return (&sharedPropIter{
+		o:         o,
+		recursive: recursive,
+	}).next
+}
+
+func (o *objectShared) export() interface{} {
+	return exportSharedItem(o.node)
+}
+
+func exportSharedItem(item interface{}) interface{} {
+	node, ok := item.(*sharedNode)
+	if !ok {
+		return item.(Value).Export()
+	}
+	if node.isArray {
+		a := make([]interface{}, len(node.items))
+		for i, item := range node.items {
+			a[i] = exportSharedItem(item)
+		}
+		return a
+	}
+	m := make(map[string]interface{}, len(node.items))
+	for i, item := range node.items {
+		m[node.names[i]] = exportSharedItem(item)
+	}
+	return m
+}
+
+func (o *objectShared) exportType() reflect.Type {
+	if o.node.isArray {
+		return reflectTypeArray
+	}
+	return reflectTypeMap
+}
+
+func (o *objectShared) equal(other objectImpl) bool {
+	if other, ok := other.(*objectShared); ok {
+		return o.node == other.node
+	}
+	return false
+}
+
+func (o *objectShared) preventExtensions() {
+}
@@ -0,0 +1,89 @@
+package goja
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSharedData(t *testing.T) {
+	data, err := NewSharedData(map[string]interface{}{
+		"name":  "config",
+		"limit": 10,
+		"tags":  []string{"a", "b"},
+		"nested": map[string]interface{}{
+			"2":  "two",
+			"10": "ten",
+			"x":  nil,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const SCRIPT = `
+	assert.sameValue(shared.name, "config", "string");
+	assert.sameValue(shared.limit, 10, "number");
+	assert(Array.isArray(shared.tags), "isArray");
+	assert.sameValue(shared.tags.map(function(s) { return s.toUpperCase(); }).join(), "A,B", "Array.prototype");
+	assert.sameValue(shared.tags.length, 2, "length");
+	assert.sameValue(shared.nested, shared.nested, "identity");
+	assert.sameValue(Object.keys(shared.nested).join(), "2,10,x", "order");
+	assert.sameValue(shared.nested.x, null, "null");
+	assert.sameValue(JSON.stringify(shared.tags), '["a","b"]', "JSON");
+	assert(Object.isFrozen(shared) && Object.isFrozen(shared.tags), "isFrozen");
+
+	shared.name = "changed";
+	shared.added = 1;
+	delete shared.limit;
+	shared.tags.push;
+	assert.sameValue(shared.name, "config", "assignment is ignored");
+	assert.sameValue(shared.added, undefined, "not extensible");
+	assert.sameValue(shared.limit, 10, "delete is ignored");
+	Object.freeze(shared);
+
+	function throwsTypeError(f) {
+		"use strict";
+		try {
+			f();
+		} catch (e) {
+			return e instanceof TypeError;
+		}
+		return false;
+	}
+	assert(throwsTypeError(function() { "use strict"; shared.name = "x"; }), "strict assignment");
+	assert(throwsTypeError(function() { "use strict"; delete shared.name; }), "strict delete");
+	assert(throwsTypeError(function() { shared.tags.push("c"); }), "push");
+	assert(throwsTypeError(function() { Object.defineProperty(shared, "name", {value: "x"}); }), "defineProperty");
+	Object.defineProperty(shared, "name", {value: "config"});
+	`
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vm := New()
+			vm.Set("shared", data)
+			_, err := vm.RunString(TESTLIB + SCRIPT)
+			if err != nil {
+				t.Error(err)
+			}
+			exported := vm.Get("shared").Export().(map[string]interface{})
+			if exported["tags"].([]interface{})[1] != "b" {
+				t.Errorf("Unexpected export: %v", exported)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSharedDataErrors(t *testing.T) {
+	cyclic := map[string]interface{}{}
+	cyclic["self"] = cyclic
+	vm := New()
+	for _, v := range []interface{}{cyclic, struct{}{}, map[int]string{}, vm.NewObject()} {
+		if _, err := NewSharedData(v); err == nil {
+			t.Errorf("No error for %#v", v)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package goja
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja/parser"
+)
+
+// PositionMapper maps a position in the source a Program has been compiled from (e.g. the output of a transpiler or
+// a bundler) to the name of the original source and the position in it. It returns false if the position cannot be
+// mapped, in which case the generated position is used. See Program.SetPositionMapper().
+type PositionMapper func(pos Position) (srcName string, mapped Position, ok bool)
+
+// SourceMap is a parsed source map (revision 3), its MapPosition method can be used as a PositionMapper.
+type SourceMap struct {
+	sources []string
+	// lines holds the mapping segments of each generated line, sorted by the generated column.
+	lines [][]sourceMapSegment
+}
+
+type sourceMapSegment struct {
+	genCol, src, srcLine, srcCol int
+}
+
+type sourceMapJSON struct {
+	Version    int      `json:"version"`
+	SourceRoot string   `json:"sourceRoot"`
+	Sources    []string `json:"sources"`
+	Mappings   string   `json:"mappings"`
+}
+
+const sourceMapBase64 = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+var errSourceMapMappings = errors.New("invalid source map mappings")
+
+// ParseSourceMap parses a source map in the JSON format of the revision 3 of the specification. The index maps
+// (with "sections") are not supported.
+func ParseSourceMap(data []byte) (*SourceMap, error) {
+	var sm sourceMapJSON
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return nil, err
+	}
+	if sm.Version != 3 {
+		return nil, fmt.Errorf("unsupported source map version: %d", sm.Version)
+	}
+	m := &SourceMap{
+		sources: make([]string, len(sm.Sources)),
+	}
+	for i, s := range sm.Sources {
+		if sm.SourceRoot != "" && !strings.HasSuffix(sm.SourceRoot, "/") {
+			s = sm.SourceRoot + "/" + s
+		} else {
+			s = sm.SourceRoot + s
+		}
+		m.sources[i] = s
+	}
+	if err := m.parseMappings(sm.Mappings); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *SourceMap) parseMappings(mappings string) error {
+	var src, srcLine, srcCol, name int
+	for _, line := range strings.Split(mappings, ";") {
+		var segments []sourceMapSegment
+		genCol := 0
+		for _, s := range strings.Split(line, ",") {
+			if s == "" {
+				continue
+			}
+			var fields [5]int
+			n := 0
+			for i := 0; i < len(s); {
+				if n == len(fields) {
+					return errSourceMapMappings
+				}
+				v, l := decodeVLQ(s[i:])
+				if l == 0 {
+					return errSourceMapMappings
+				}
+				fields[n] = v
+				n++
+				i += l
+			}
+			genCol += fields[0]
+			if n == 1 {
+				// no source
+				segments = append(segments, sourceMapSegment{genCol: genCol, src: -1})
+				continue
+			}
+			if n < 4 {
+				return errSourceMapMappings
+			}
+			src += fields[1]
+			srcLine += fields[2]
+			srcCol += fields[3]
+			name += fields[4]
+			if src < 0 || src >= len(m.sources) {
+				return errSourceMapMappings
+			}
+			segments = append(segments, sourceMapSegment{
+				genCol:  genCol,
+				src:     src,
+				srcLine: srcLine,
+				srcCol:  srcCol,
+			})
+		}
+		sort.SliceStable(segments, func(i, j int) bool {
+			return segments[i].genCol < segments[j].genCol
+		})
+		m.lines = append(m.lines, segments)
+	}
+	return nil
+}
+
+// decodeVLQ decodes a Base64 VLQ value at the start of s and returns it with the number of characters it takes (0
+// if it's invalid).
+func decodeVLQ(s string) (int, int) {
+	var v, shift int
+	for i := 0; i < len(s) && shift < 32; i++ {
+		digit := strings.IndexByte(sourceMapBase64, s[i])
+		if digit < 0 {
+			return 0, 0
+		}
+		v |= (digit & 31) << shift
+		if digit&32 == 0 {
+			if v&1 != 0 {
+				return -(v >> 1), i + 1
+			}
+			return v >> 1, i + 1
+		}
+		shift += 5
+	}
+	return 0, 0
+}
+
+// MapPosition returns the original position of the generated position pos.
+func (m *SourceMap) MapPosition(pos Position) (string, Position, bool) {
+	line := pos.Line - 1
+	if line < 0 || line >= len(m.lines) {
+		return "", Position{}, false
+	}
+	segments := m.lines[line]
+	col := pos.Col - 1
+	i := sort.Search(len(segments), func(i int) bool {
+		return segments[i].genCol > col
+	}) - 1
+	if i < 0 || segments[i].src < 0 {
+		return "", Position{}, false
+	}
+	seg := segments[i]
+	return m.sources[seg.src], Position{
+		Line: seg.srcLine + 1,
+		Col:  seg.srcCol + 1,
+	}, true
+}
+
+// SetPositionMapper sets the function that maps the positions in the source of the program to the original source
+// for the stack traces (StackFrame.SrcName() and StackFrame.Position()), including those of the functions defined
+// in it.
+func (p *Program) SetPositionMapper(mapper PositionMapper) {
+	p.src.mapper = mapper
+}
+
+// CompileWithPositionMapper is like Compile, but also maps the position of a syntax error to the original source, and
+// sets the mapper on the resulting Program, see Program.SetPositionMapper().
+func CompileWithPositionMapper(name, src string, strict bool, mapper PositionMapper) (*Program, error) {
+	p, err := compile(name, src, strict, false, false)
+	if err != nil {
+		if e, ok := err.(*CompilerSyntaxError); ok {
+			mapSyntaxError(e, name, src, mapper)
+		}
+		return nil, err
+	}
+	p.SetPositionMapper(mapper)
+	return p, nil
+}
+
+// mapSyntaxError rewrites the positions of a syntax error to the original source.
+func mapSyntaxError(e *CompilerSyntaxError, name, src string, mapper PositionMapper) {
+	if e.File != nil {
+		e.File.mapper = mapper
+		return
+	}
+	// the parser's error only survives in the message, so parse again to get the positions
+	_, err := parser.ParseFile(nil, name, src, 0)
+	list, ok := err.(parser.ErrorList)
+	if !ok {
+		return
+	}
+	for _, pe := range list {
+		if srcName, pos, ok := mapper(Position{Line: pe.Position.Line, Col: pe.Position.Column}); ok {
+			pe.Position.Filename = srcName
+			pe.Position.Line = pos.Line
+			pe.Position.Column = pos.Col
+		}
+	}
+	e.Message = list.Error()
+}
+
+// mappedPosition returns the source name and the position of the offset in the original source, if the file has a
+// mapper and it can map the position.
+func (f *SrcFile) mappedPosition(offset int) (string, Position, bool) {
+	if f == nil || f.mapper == nil {
+		return "", Position{}, false
+	}
+	return f.mapper(f.Position(offset))
+}
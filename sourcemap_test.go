@@ -0,0 +1,95 @@
+package goja
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeVLQ(t *testing.T) {
+	for _, test := range []struct {
+		s      string
+		v, len int
+	}{
+		{"A", 0, 1},
+		{"C", 1, 1},
+		{"D", -1, 1},
+		{"gB", 16, 2},
+		{"hBC", -16, 2},
+		{"g", 0, 0},
+		{"!", 0, 0},
+	} {
+		v, l := decodeVLQ(test.s)
+		if v != test.v || l != test.len {
+			t.Fatalf("%q: %d, %d", test.s, v, l)
+		}
+	}
+}
+
+func TestSourceMapStackTrace(t *testing.T) {
+	const SCRIPT = "function f() {\n  throw new Error(\"x\");\n}\nf();\n"
+	sm, err := ParseSourceMap([]byte(`{
+		"version": 3,
+		"sourceRoot": "src",
+		"sources": ["app.ts"],
+		"names": [],
+		"mappings": "AAAA;EASI;;AAUJ"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := CompileWithPositionMapper("bundle.js", SCRIPT, false, sm.MapPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = New().RunProgram(p)
+	ex, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stack := ex.Stack()
+	if len(stack) != 2 {
+		t.Fatalf("Unexpected stack: %v", stack)
+	}
+	if n, pos := stack[0].SrcName(), stack[0].Position(); n != "src/app.ts" || pos.Line != 10 || pos.Col != 5 {
+		t.Fatalf("Unexpected frame 0: %s:%s", n, pos)
+	}
+	if pos := stack[0].GeneratedPosition(); pos.Line != 2 {
+		t.Fatalf("Unexpected generated position: %s", pos)
+	}
+	if n, pos := stack[1].SrcName(), stack[1].Position(); n != "src/app.ts" || pos.Line != 20 || pos.Col != 1 {
+		t.Fatalf("Unexpected frame 1: %s:%s", n, pos)
+	}
+	if s := ex.String(); !strings.Contains(s, "at f (src/app.ts:10:5(") {
+		t.Fatalf("Unexpected string: %s", s)
+	}
+}
+
+func TestPositionMapperSyntaxError(t *testing.T) {
+	mapper := func(pos Position) (string, Position, bool) {
+		return "orig.js", Position{Line: pos.Line + 100, Col: pos.Col}, true
+	}
+
+	_, err := CompileWithPositionMapper("gen.js", "var x = ;", false, mapper)
+	if err == nil || !strings.Contains(err.Error(), "orig.js: Line 101:") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = CompileWithPositionMapper("gen.js", "\nvar re = /(/;", false, mapper)
+	if err == nil || !strings.Contains(err.Error(), "at orig.js:102:") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseSourceMapInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"version": 2, "sources": [], "mappings": ""}`,
+		`{"version": 3, "sources": [], "mappings": "AAAA"}`,
+		`{"version": 3, "sources": ["a.js"], "mappings": "AA"}`,
+		`{"version": 3, "sources": ["a.js"], "mappings": "A!AA"}`,
+		`not json`,
+	} {
+		if _, err := ParseSourceMap([]byte(data)); err == nil {
+			t.Fatalf("No error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package goja
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// SourceProvider is used to retrieve the source code of a script by its file name when it's needed to render a
+// stack trace, so that the host does not have to keep the source text around (it may be loaded from a database,
+// an embedded file system, etc.). If the file is not available, Source() should return an error.
+type SourceProvider interface {
+	Source(filename string) (string, error)
+}
+
+// SetSourceProvider sets the SourceProvider that is used by Exception.SourceContext() for the exceptions thrown in
+// this Runtime. If not set (or if it fails) the source the program was compiled from is used.
+func (r *Runtime) SetSourceProvider(p SourceProvider) {
+	r.sourceProvider = p
+}
+
+// SourceContext returns the location where the exception was thrown followed by the source line and a caret
+// pointing to the column, in the same format as Node does it:
+//
+//	test.js:2
+//	    throw new Error("test");
+//	          ^
+//
+// Returns an empty string if the exception was not thrown by JavaScript code.
+func (e *Exception) SourceContext() string {
+	for _, frame := range e.Stack() {
+		if frame.prg == nil {
+			continue
+		}
+		name := frame.SrcName()
+		pos := frame.Position()
+
+		var src string
+		if _, _, mapped := frame.prg.src.mappedPosition(frame.prg.sourceOffset(frame.pc)); !mapped {
+			// the original source of a mapped position is only available from the provider
+			src = frame.prg.src.src
+		}
+		if e.sources != nil {
+			if s, err := e.sources.Source(name); err == nil {
+				src = s
+			}
+		}
+		line := sourceLine(src, pos.Line)
+
+		var b bytes.Buffer
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(pos.Line))
+		b.WriteByte('\n')
+		b.WriteString(line)
+		b.WriteByte('\n')
+		col := pos.Col - 1
+		if col > len(line) {
+			col = len(line)
+		}
+		for _, c := range line[:col] {
+			// keep tabs so that the caret lines up
+			if c == '\t' {
+				b.WriteByte('\t')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString("^\n")
+		return b.String()
+	}
+	return ""
+}
+
+// sourceLine returns the line with the given (1-based) number without the line terminator.
+func sourceLine(src string, n int) string {
+	for ; n > 1; n-- {
+		p := strings.IndexByte(src, '\n')
+		if p == -1 {
+			return ""
+		}
+		src = src[p+1:]
+	}
+	if p := strings.IndexByte(src, '\n'); p != -1 {
+		src = src[:p]
+	}
+	return strings.TrimSuffix(src, "\r")
+}
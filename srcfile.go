@@ -11,8 +11,10 @@ type Position struct {
 }
 
 type SrcFile struct {
-	name string
-	src  string
+	name   string
+	src    string
+	origin *ScriptOrigin
+	mapper PositionMapper
 
 	lineOffsets       []int
 	lastScannedOffset int
@@ -39,7 +41,7 @@ func (f *SrcFile) Position(offset int) Position {
 	}
 
 	if line >= 0 {
-		if f.lineOffsets[line] > offset {
+		if line == len(f.lineOffsets) || f.lineOffsets[line] > offset {
 			line--
 		}
 	}
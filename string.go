@@ -31,6 +31,7 @@ var (
 	stringRangeError     valueString = asciiString("RangeError")
 	stringEvalError      valueString = asciiString("EvalError")
 	stringURIError       valueString = asciiString("URIError")
+	stringAggregateError valueString = asciiString("AggregateError")
 	stringGoError        valueString = asciiString("GoError")
 
 	stringObjectNull      valueString = asciiString("[object Null]")
@@ -63,7 +64,31 @@ type stringObject struct {
 }
 
 func newUnicodeString(s string) valueString {
-	return unicodeString(utf16.Encode([]rune(s)))
+	b := make([]uint16, 0, len(s))
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 && len(s) >= 3 && s[0] == 0xED && s[1]&0xE0 == 0xA0 && s[2]&0xC0 == 0x80 {
+			// An unpaired surrogate encoded by unicodeString.String()
+			r, size = 0xD000|rune(s[1]&0x3F)<<6|rune(s[2]&0x3F), 3
+		}
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			b = append(b, uint16(r1), uint16(r2))
+		} else {
+			b = append(b, uint16(r))
+		}
+		s = s[size:]
+	}
+	return unicodeString(b)
+}
+
+// appendWTF8 appends the UTF-8 encoding of r to b, encoding a surrogate as a 3-byte sequence (as in WTF-8) instead
+// of U+FFFD.
+func appendWTF8(b []byte, r rune) []byte {
+	if utf16.IsSurrogate(r) {
+		return append(b, byte(0xE0|r>>12), byte(0x80|(r>>6)&0x3F), byte(0x80|r&0x3F))
+	}
+	return utf8.AppendRune(b, r)
 }
 
 func newStringValue(s string) valueString {
@@ -152,8 +177,9 @@ func (s *stringObject) putStr(name string, val Value, throw bool) {
 
 func (s *stringObject) defineOwnProperty(n Value, descr objectImpl, throw bool) bool {
 	if i := toIdx(n); i >= 0 && i < s.length {
-		s.val.runtime.typeErrorResult(throw, "Cannot redefine property: %d", i)
-		return false
+		// The index properties are non-writable and non-configurable, so only a compatible definition is accepted
+		_, ok := s.baseObject._defineOwnProperty(n, s.getOwnProp(n.String()), descr, throw)
+		return ok
 	}
 
 	return s.baseObject.defineOwnProperty(n, descr, throw)
@@ -224,3 +250,24 @@ func (s *stringObject) hasOwnPropertyStr(name string) bool {
 	}
 	return s.baseObject.hasOwnPropertyStr(name)
 }
+
+// stringCodePoints returns a function that yields the code points of s as strings (a surrogate pair is yielded as
+// a single string, a lone surrogate as is), like String.prototype[Symbol.iterator].
+func stringCodePoints(s valueString) func() (Value, bool) {
+	var idx int64
+	return func() (Value, bool) {
+		l := s.length()
+		if idx >= l {
+			return nil, false
+		}
+		end := idx + 1
+		if c := s.charAt(idx); isUTF16FirstSurrogate(c) && end < l {
+			if isUTF16SecondSurrogate(s.charAt(end)) {
+				end++
+			}
+		}
+		val := s.substring(idx, end)
+		idx = end
+		return val, true
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"math"
 	"reflect"
 	"regexp"
-	"strings"
 	"unicode/utf16"
 	"unicode/utf8"
 )
@@ -46,30 +45,35 @@ func (rr runeReaderReplace) ReadRune() (r rune, size int, err error) {
 func (rr *unicodeRuneReader) ReadRune() (r rune, size int, err error) {
 	if rr.pos < len(rr.s) {
 		r = rune(rr.s[rr.pos])
-		if r != utf8.RuneError {
-			if utf16.IsSurrogate(r) {
-				if rr.pos+1 < len(rr.s) {
-					r1 := utf16.DecodeRune(r, rune(rr.s[rr.pos+1]))
+		size++
+		rr.pos++
+		if isUTF16FirstSurrogate(r) {
+			if rr.pos < len(rr.s) {
+				if second := rune(rr.s[rr.pos]); isUTF16SecondSurrogate(second) {
+					r = utf16.DecodeRune(r, second)
 					size++
 					rr.pos++
-					if r1 == utf8.RuneError {
-						err = InvalidRuneError
-					} else {
-						r = r1
-					}
-				} else {
-					err = InvalidRuneError
+					return
 				}
 			}
+			err = InvalidRuneError
+		} else if isUTF16SecondSurrogate(r) {
+			err = InvalidRuneError
 		}
-		size++
-		rr.pos++
 	} else {
 		err = io.EOF
 	}
 	return
 }
 
+func isUTF16FirstSurrogate(r rune) bool {
+	return r >= 0xD800 && r <= 0xDBFF
+}
+
+func isUTF16SecondSurrogate(r rune) bool {
+	return r >= 0xDC00 && r <= 0xDFFF
+}
+
 func (s unicodeString) reader(start int) io.RuneReader {
 	return &unicodeRuneReader{
 		s: s[start:],
@@ -212,12 +216,44 @@ func (s unicodeString) substring(start, end int64) valueString {
 	return asciiString(as)
 }
 
+// String returns the string in UTF-8, except that the unpaired surrogates are kept as 3-byte sequences (as in
+// WTF-8), so that the strings which differ in them stay distinct as property names and the parser accepts them in
+// the source code. newStringValue() decodes them back.
 func (s unicodeString) String() string {
-	return string(utf16.Decode(s))
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		r := rune(s[i])
+		if isUTF16FirstSurrogate(r) && i+1 < len(s) && isUTF16SecondSurrogate(rune(s[i+1])) {
+			r = utf16.DecodeRune(r, rune(s[i+1]))
+			i++
+		}
+		b = appendWTF8(b, r)
+	}
+	return string(b)
 }
 
 func (s unicodeString) compareTo(other valueString) int {
-	return strings.Compare(s.String(), other.String())
+	// Strings are compared by UTF-16 code units, which for the supplementary characters is not the same as
+	// comparing the code points.
+	l := s.length()
+	if ol := other.length(); ol < l {
+		l = ol
+	}
+	for i := int64(0); i < l; i++ {
+		if c, oc := s.charAt(i), other.charAt(i); c != oc {
+			if c < oc {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case s.length() < other.length():
+		return -1
+	case s.length() > other.length():
+		return 1
+	}
+	return 0
 }
 
 func (s unicodeString) index(substr valueString, start int64) int64 {
@@ -236,7 +272,7 @@ func (s unicodeString) index(substr valueString, start int64) int64 {
 
 	// TODO: optimise
 	end := int64(len(s) - len(ss))
-	for start < end {
+	for start <= end {
 		for i := int64(0); i < int64(len(ss)); i++ {
 			if s[start+i] != ss[i] {
 				goto nomatch
@@ -264,6 +300,10 @@ func (s unicodeString) lastIndex(substr valueString, start int64) int64 {
 		panic(fmt.Errorf("Unknown string type: %T", substr))
 	}
 
+	if maxStart := int64(len(s) - len(ss)); start > maxStart {
+		start = maxStart
+	}
+
 	// TODO: optimise
 	for start >= 0 {
 		for i := int64(0); i < int64(len(ss)); i++ {
@@ -308,7 +348,8 @@ func (s unicodeString) toUpper() valueString {
 }
 
 func (s unicodeString) Export() interface{} {
-	return s.String()
+	// Go strings are expected to be valid UTF-8, the unpaired surrogates become U+FFFD
+	return string(utf16.Decode(s))
 }
 
 func (s unicodeString) ExportType() reflect.Type {
@@ -0,0 +1,136 @@
+package goja
+
+// structuredCloner implements the structured clone algorithm of HTML.
+type structuredCloner struct {
+	r *Runtime
+	// memory maps the objects already cloned to their clones, so that the cycles and the shared references are
+	// preserved.
+	memory map[*Object]*Object
+	// descr is the descriptor used to define the properties of the clones.
+	descr objectImpl
+}
+
+func (r *Runtime) structuredClone(v Value) Value {
+	c := &structuredCloner{
+		r:      r,
+		memory: make(map[*Object]*Object),
+	}
+	return c.clone(v)
+}
+
+func (c *structuredCloner) dataCloneError(obj *Object) {
+	panic(obj.runtime.NewTypeError("%s could not be cloned", obj.String()))
+}
+
+func (c *structuredCloner) clone(v Value) Value {
+	obj, ok := v.(*Object)
+	if !ok {
+		return v
+	}
+	if res, exists := c.memory[obj]; exists {
+		return res
+	}
+	if _, ok := obj.self.assertCallable(); ok {
+		c.dataCloneError(obj)
+	}
+
+	r := c.r
+	var res *Object
+	switch obj.self.className() {
+	case classBoolean, classNumber:
+		if p, ok := obj.self.(*primitiveValueObject); ok {
+			res = p.pValue.ToObject(r)
+		}
+	case classString:
+		if s, ok := obj.self.(*stringObject); ok {
+			res = s.value.ToObject(r)
+		}
+	case classDate:
+		if d, ok := obj.self.(*dateObject); ok {
+			res = r.newDateObject(d.time, d.isSet)
+		}
+	case classRegExp:
+		if re, ok := obj.self.(*regexpObject); ok {
+			res = r.newRegExp(re.source, re.flags(), r.global.RegExpPrototype)
+		}
+	case classError:
+		res = c.cloneError(obj)
+	case classArray:
+		res = r.newArrayLength(toLength(obj.self.getStr("length")))
+		c.memory[obj] = res
+		c.cloneProperties(obj, res)
+	case classObject:
+		if _, ok := obj.self.(*baseObject); ok {
+			res = r.NewObject()
+			c.memory[obj] = res
+			c.cloneProperties(obj, res)
+		}
+	}
+	if res == nil {
+		// host objects and built-in objects with internal state that cannot be cloned
+		c.dataCloneError(obj)
+	}
+	c.memory[obj] = res
+	return res
+}
+
+// cloneProperties clones the enumerable own properties of obj into res. The values are read with [[Get]], so the
+// getters are invoked, and the properties of the clone are plain writable, enumerable and configurable data
+// properties.
+func (c *structuredCloner) cloneProperties(obj, res *Object) {
+	if c.descr == nil {
+		c.descr = c.r.NewObject().self
+		c.descr.putStr("writable", valueTrue, false)
+		c.descr.putStr("enumerable", valueTrue, false)
+		c.descr.putStr("configurable", valueTrue, false)
+	}
+	for item, f := obj.self.enumerate(false, false)(); f != nil; item, f = f() {
+		v := obj.self.getStr(item.name)
+		if v == nil {
+			v = _undefined
+		}
+		c.descr.putStr("value", c.clone(v), false)
+		res.self.defineOwnProperty(newStringValue(item.name), c.descr, true)
+	}
+}
+
+// cloneError clones an Error as an instance of the standard error type it's named after (or Error), with the same
+// message.
+func (c *structuredCloner) cloneError(obj *Object) *Object {
+	r := c.r
+	proto := r.global.ErrorPrototype
+	if name := obj.self.getStr("name"); name != nil {
+		switch name.String() {
+		case "EvalError":
+			proto = r.global.EvalErrorPrototype
+		case "RangeError":
+			proto = r.global.RangeErrorPrototype
+		case "ReferenceError":
+			proto = r.global.ReferenceErrorPrototype
+		case "SyntaxError":
+			proto = r.global.SyntaxErrorPrototype
+		case "TypeError":
+			proto = r.global.TypeErrorPrototype
+		case "URIError":
+			proto = r.global.URIErrorPrototype
+		}
+	}
+	res := r.newBaseObject(proto, classError)
+	if obj.self.getOwnProp("message") != nil {
+		res._putProp("message", obj.self.getStr("message").ToString(), true, false, true)
+	}
+	return res.val
+}
+
+// builtin_structuredClone implements the structuredClone() global function. As there are no transferable objects,
+// the transfer option must be empty.
+func (r *Runtime) builtin_structuredClone(call FunctionCall) Value {
+	if options := call.Argument(1); options != _undefined && options != _null {
+		if transfer := r.toObject(options).self.getStr("transfer"); transfer != nil && transfer != _undefined {
+			if len(r.toValueArray(transfer)) > 0 {
+				panic(r.NewTypeError("Value could not be transferred"))
+			}
+		}
+	}
+	return r.structuredClone(call.Argument(0))
+}
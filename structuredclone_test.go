@@ -0,0 +1,53 @@
+package goja
+
+import "testing"
+
+func TestStructuredClone(t *testing.T) {
+	const SCRIPT = `
+	var shared = {x: 1};
+	var o = {a: [1, "s", shared], b: shared, d: new Date(0), re: /ab+/gi, n: new Number(5), e: new RangeError("bad")};
+	o.self = o;
+	Object.defineProperty(o, "hidden", {value: 1, enumerable: false});
+	var c = structuredClone(o);
+
+	assert(c !== o, "new object");
+	assert.sameValue(c.self, c, "cycle");
+	assert.sameValue(c.a[2], c.b, "shared reference");
+	assert(c.b !== shared, "deep");
+	assert.sameValue(c.a.length, 3, "array");
+	assert(Array.isArray(c.a), "isArray");
+	assert.sameValue(c.d.getTime(), 0, "date");
+	assert.sameValue(c.re.source, "ab+", "regexp source");
+	assert.sameValue(c.re.flags, "gi", "regexp flags");
+	assert.sameValue(typeof c.n, "object", "wrapper");
+	assert.sameValue(c.n.valueOf(), 5, "wrapper value");
+	assert(c.e instanceof RangeError, "error type");
+	assert.sameValue(c.e.message, "bad", "error message");
+	assert.sameValue(c.hidden, undefined, "non-enumerable");
+	assert.sameValue(structuredClone(-0), -0, "primitive");
+
+	var n = 0;
+	var src = {get g() { return ++n; }};
+	Object.defineProperty(src, "ro", {value: 1, writable: false, enumerable: true});
+	var c1 = structuredClone(src);
+	assert.sameValue(c1.g, 1, "getter value");
+	assert.sameValue(c1.g, 1, "getter is not cloned");
+	c1.ro = 2;
+	assert.sameValue(c1.ro, 2, "writable");
+	var d = Object.getOwnPropertyDescriptor(c1, "g");
+	assert(d.writable && d.enumerable && d.configurable, "data property");
+
+	function throws(f) {
+		try {
+			f();
+		} catch (e) {
+			return e instanceof TypeError;
+		}
+		return false;
+	}
+	assert(throws(function() { structuredClone({f: function() {}}); }), "function");
+	assert(throws(function() { structuredClone(new Intl.Collator()); }), "internal state");
+	assert(throws(function() { structuredClone({}, {transfer: [{}]}); }), "transfer");
+	`
+	testScript1(TESTLIB+SCRIPT, _undefined, t)
+}
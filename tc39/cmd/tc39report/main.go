@@ -0,0 +1,77 @@
+// Command tc39report runs the test262 suite against goja and writes a conformance report (see tc39.Report), and
+// optionally compares it with a previous report:
+//
+//	tc39report -base testdata/test262 -o report.json -prev old-report.json test/built-ins test/language
+//
+// The changes are printed to stderr, and the exit status is 1 if any test that passed in the previous report
+// doesn't pass anymore. The directories default to "test".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dop251/goja/tc39"
+)
+
+func main() {
+	base := flag.String("base", "testdata/test262", "path to the test262 checkout")
+	out := flag.String("o", "", "output file (default stdout)")
+	prev := flag.String("prev", "", "previous report to compare with")
+	flag.Parse()
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"test"}
+	}
+
+	h := &tc39.Harness{
+		Base: *base,
+	}
+	report, err := h.Report(dirs...)
+	if err != nil {
+		fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := report.Write(w); err != nil {
+		fatal(err)
+	}
+
+	if *prev != "" {
+		f, err := os.Open(*prev)
+		if err != nil {
+			fatal(err)
+		}
+		prevReport, err := tc39.ReadReport(f)
+		f.Close()
+		if err != nil {
+			fatal(err)
+		}
+		regressed := false
+		for _, c := range report.Diff(prevReport) {
+			fmt.Fprintln(os.Stderr, c)
+			if c.Regression() {
+				regressed = true
+			}
+		}
+		if regressed {
+			os.Exit(1)
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
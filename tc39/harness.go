@@ -0,0 +1,287 @@
+// Package tc39 runs the ECMAScript conformance test suite (https://github.com/tc39/test262) against goja.
+//
+// Embedders can use it to verify that their configuration of the Runtime (globals, field name mappers, limits and
+// so on) doesn't break the language semantics:
+//
+//	func TestConformance(t *testing.T) {
+//		h := &tc39.Harness{
+//			Base: "testdata/test262",
+//			NewRuntime: func() *goja.Runtime {
+//				vm := goja.New()
+//				configure(vm)
+//				return vm
+//			},
+//		}
+//		h.RunDir(t, "test/built-ins")
+//	}
+//
+// Only the ES5.1 tests (the ones with an es5id) are run.
+package tc39
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	invalidFormatError = errors.New("Invalid file format")
+)
+
+// Harness runs the test262 tests.
+type Harness struct {
+	// Base is the path to the test262 checkout.
+	Base string
+
+	// NewRuntime creates the Runtime for each test. If nil, goja.New is used.
+	NewRuntime func() *goja.Runtime
+
+	// SkipList contains the names (relative to Base) of the tests that are not run.
+	SkipList map[string]bool
+
+	prgCache map[string]*goja.Program
+}
+
+type metaNegative struct {
+	Phase, Type string
+}
+
+type meta struct {
+	Negative metaNegative
+	Includes []string
+	Flags    []string
+	Es5id    string
+	Es6id    string
+	Esid     string
+}
+
+func (m *meta) hasFlag(flag string) bool {
+	for _, f := range m.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFile(name string) (*meta, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	str := string(b)
+	metaStart := strings.Index(str, "/*---")
+	if metaStart == -1 {
+		return nil, "", invalidFormatError
+	} else {
+		metaStart += 5
+	}
+	metaEnd := strings.Index(str, "---*/")
+	if metaEnd == -1 || metaEnd <= metaStart {
+		return nil, "", invalidFormatError
+	}
+
+	var m meta
+	err = yaml.Unmarshal([]byte(str[metaStart:metaEnd]), &m)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if m.Negative.Type != "" && m.Negative.Phase == "" {
+		return nil, "", errors.New("negative type is set, but phase isn't")
+	}
+
+	return &m, str, nil
+}
+
+func (h *Harness) newRuntime() *goja.Runtime {
+	if h.NewRuntime != nil {
+		return h.NewRuntime()
+	}
+	return goja.New()
+}
+
+func (h *Harness) runTest(name, src string, m *meta, t testing.TB) {
+	if err := h.execTest(name, src, m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// execTest runs a test in a new Runtime and returns an error if it fails.
+func (h *Harness) execTest(name, src string, m *meta) error {
+	vm := h.newRuntime()
+	err, early := h.runScript(name, src, m.Includes, vm)
+
+	if err != nil {
+		if m.Negative.Type == "" {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if m.Negative.Phase == "early" && !early || m.Negative.Phase == "runtime" && early {
+			return fmt.Errorf("%s: error %v happened at the wrong phase (expected %s)", name, err, m.Negative.Phase)
+		}
+		var errType string
+
+		switch err := err.(type) {
+		case *goja.Exception:
+			if o, ok := err.Value().(*goja.Object); ok {
+				if c := o.Get("constructor"); c != nil {
+					if c, ok := c.(*goja.Object); ok {
+						errType = c.Get("name").String()
+					} else {
+						return fmt.Errorf("%s: error constructor is not an object (%v)", name, o)
+					}
+				} else {
+					return fmt.Errorf("%s: error does not have a constructor (%v)", name, o)
+				}
+			} else {
+				return fmt.Errorf("%s: error is not an object (%v)", name, err.Value())
+			}
+		case *goja.CompilerSyntaxError:
+			errType = "SyntaxError"
+		case *goja.CompilerReferenceError:
+			errType = "ReferenceError"
+		default:
+			return fmt.Errorf("%s: error is not a JS error: %v", name, err)
+		}
+
+		if errType != m.Negative.Type {
+			return fmt.Errorf("%s: unexpected error type (%s), expected (%s)", name, errType, m.Negative.Type)
+		}
+	} else if m.Negative.Type != "" {
+		return fmt.Errorf("%s: Expected error: %v", name, err)
+	}
+	return nil
+}
+
+// RunFile runs a single test, name is relative to Base. The test is run both in non-strict and strict mode unless
+// its flags say otherwise.
+func (h *Harness) RunFile(t testing.TB, name string) {
+	if h.SkipList[name] {
+		t.Logf("Skipped %s", name)
+		return
+	}
+	p := path.Join(h.Base, name)
+	m, src, err := parseFile(p)
+	if err != nil {
+		t.Errorf("Could not parse %s: %v", name, err)
+		return
+	}
+	if m.Es5id == "" {
+		return
+	}
+
+	for _, src := range testSources(m, src) {
+		h.runTest(name, src, m, t)
+	}
+}
+
+// testSources returns the sources of the test for each mode it is run in (non-strict and/or strict).
+func testSources(m *meta, src string) []string {
+	var sources []string
+	hasRaw := m.hasFlag("raw")
+
+	if hasRaw || !m.hasFlag("onlyStrict") {
+		sources = append(sources, src)
+	}
+
+	if !hasRaw && !m.hasFlag("noStrict") {
+		sources = append(sources, "'use strict';\n"+src)
+	}
+	return sources
+}
+
+func (h *Harness) runHarnessFile(name string, vm *goja.Runtime) error {
+	if h.prgCache == nil {
+		h.prgCache = make(map[string]*goja.Program)
+	}
+	prg := h.prgCache[name]
+	if prg == nil {
+		fname := path.Join(h.Base, name)
+		f, err := os.Open(fname)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		b, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		str := string(b)
+		prg, err = goja.Compile(name, str, false)
+		if err != nil {
+			return err
+		}
+		h.prgCache[name] = prg
+	}
+	_, err := vm.RunProgram(prg)
+	return err
+}
+
+func (h *Harness) runScript(name, src string, includes []string, vm *goja.Runtime) (err error, early bool) {
+	early = true
+	err = h.runHarnessFile(path.Join("harness", "assert.js"), vm)
+	if err != nil {
+		return
+	}
+
+	err = h.runHarnessFile(path.Join("harness", "sta.js"), vm)
+	if err != nil {
+		return
+	}
+
+	for _, include := range includes {
+		err = h.runHarnessFile(path.Join("harness", include), vm)
+		if err != nil {
+			return
+		}
+	}
+
+	var p *goja.Program
+	p, err = goja.Compile(name, src, false)
+
+	if err != nil {
+		return
+	}
+
+	early = false
+	_, err = vm.RunProgram(p)
+
+	return
+}
+
+// RunDir runs the tests in the directory (relative to Base) and its subdirectories.
+func (h *Harness) RunDir(t testing.TB, name string) {
+	files, err := ioutil.ReadDir(path.Join(h.Base, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		if file.Name()[0] == '.' {
+			continue
+		}
+		if file.IsDir() {
+			h.RunDir(t, path.Join(name, file.Name()))
+		} else {
+			if strings.HasSuffix(file.Name(), ".js") {
+				h.RunFile(t, path.Join(name, file.Name()))
+			}
+		}
+	}
+}
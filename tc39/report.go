@@ -0,0 +1,180 @@
+package tc39
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Status is the outcome of a test in a Report.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	// StatusSkip is the status of the tests in the SkipList and the tests which are not ES5.1 tests.
+	StatusSkip Status = "skip"
+)
+
+// DirSummary counts the outcomes of the tests in a directory, not including its subdirectories.
+type DirSummary struct {
+	Pass int `json:"pass"`
+	Fail int `json:"fail"`
+	Skip int `json:"skip"`
+}
+
+// Report is a machine-readable conformance report created by Harness.Report(). It is written and read as JSON,
+// so that a report can be compared with the one of a previous version using Diff().
+type Report struct {
+	// Dirs has the summary of each directory (relative to Base) that contains tests.
+	Dirs map[string]*DirSummary `json:"dirs"`
+	// Tests has the status of each test, by name.
+	Tests map[string]Status `json:"tests"`
+	// Errors has the error of each failed test.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Change is a test which has a different status in two reports. The status is empty if the test is missing
+// from the report.
+type Change struct {
+	Name     string
+	Old, New Status
+}
+
+// Regression returns true if the test passed before and doesn't pass anymore.
+func (c Change) Regression() bool {
+	return c.Old == StatusPass && c.New != StatusPass
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Name, statusString(c.Old), statusString(c.New))
+}
+
+func statusString(s Status) string {
+	if s == "" {
+		return "missing"
+	}
+	return string(s)
+}
+
+// Report runs the tests in the directories (relative to Base) and their subdirectories, like RunDir, and collects
+// the outcomes into a Report instead of failing a test. A test passes if it passes in all the modes it is run in.
+func (h *Harness) Report(dirs ...string) (*Report, error) {
+	r := &Report{
+		Dirs:   make(map[string]*DirSummary),
+		Tests:  make(map[string]Status),
+		Errors: make(map[string]string),
+	}
+	for _, dir := range dirs {
+		if err := h.reportDir(r, dir); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (h *Harness) reportDir(r *Report, name string) error {
+	files, err := ioutil.ReadDir(path.Join(h.Base, name))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.Name()[0] == '.' {
+			continue
+		}
+		if file.IsDir() {
+			if err := h.reportDir(r, path.Join(name, file.Name())); err != nil {
+				return err
+			}
+		} else if strings.HasSuffix(file.Name(), ".js") {
+			h.reportFile(r, path.Join(name, file.Name()))
+		}
+	}
+	return nil
+}
+
+func (h *Harness) reportFile(r *Report, name string) {
+	status, err := h.fileStatus(name)
+	r.Tests[name] = status
+	if err != nil {
+		r.Errors[name] = err.Error()
+	}
+	dir := r.Dirs[path.Dir(name)]
+	if dir == nil {
+		dir = &DirSummary{}
+		r.Dirs[path.Dir(name)] = dir
+	}
+	switch status {
+	case StatusPass:
+		dir.Pass++
+	case StatusFail:
+		dir.Fail++
+	default:
+		dir.Skip++
+	}
+}
+
+func (h *Harness) fileStatus(name string) (status Status, err error) {
+	if h.SkipList[name] {
+		return StatusSkip, nil
+	}
+	m, src, err := parseFile(path.Join(h.Base, name))
+	if err != nil {
+		return StatusFail, fmt.Errorf("Could not parse %s: %v", name, err)
+	}
+	if m.Es5id == "" {
+		return StatusSkip, nil
+	}
+
+	defer func() {
+		if x := recover(); x != nil {
+			status, err = StatusFail, fmt.Errorf("%s: panic: %v", name, x)
+		}
+	}()
+	for _, src := range testSources(m, src) {
+		if err := h.execTest(name, src, m); err != nil {
+			return StatusFail, err
+		}
+	}
+	return StatusPass, nil
+}
+
+// Write writes the report as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadReport reads a report written by Report.Write().
+func ReadReport(rd io.Reader) (*Report, error) {
+	var r Report
+	if err := json.NewDecoder(rd).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Diff returns the tests which have a different status in the previous report, sorted by name.
+func (r *Report) Diff(prev *Report) []Change {
+	var changes []Change
+	for name, status := range r.Tests {
+		if old := prev.Tests[name]; old != status {
+			changes = append(changes, Change{Name: name, Old: old, New: status})
+		}
+	}
+	for name, old := range prev.Tests {
+		if _, exists := r.Tests[name]; !exists {
+			changes = append(changes, Change{Name: name, Old: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
@@ -0,0 +1,163 @@
+package tc39
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	tc39BASE = "../testdata/test262"
+)
+
+var (
+	skipList = map[string]bool{
+		"test/built-ins/Date/prototype/toISOString/15.9.5.43-0-9.js":  true, // timezone
+		"test/built-ins/Date/prototype/toISOString/15.9.5.43-0-10.js": true, // timezone
+	}
+)
+
+func TestTC39(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	if _, err := os.Stat(tc39BASE); err != nil {
+		t.Skipf("If you want to run tc39 tests, download them from https://github.com/tc39/test262 and put into %s. (%v)", tc39BASE, err)
+	}
+
+	h := &Harness{
+		Base:     tc39BASE,
+		SkipList: skipList,
+	}
+
+	//_ = "breakpoint"
+	//h.RunFile(t, "test/language/types/number/8.5.1.js")
+	//h.RunDir(t, "test/language")
+	h.RunDir(t, "test/language/expressions")
+	h.RunDir(t, "test/language/arguments-object")
+	h.RunDir(t, "test/language/asi")
+	h.RunDir(t, "test/language/directive-prologue")
+	h.RunDir(t, "test/language/function-code")
+	h.RunDir(t, "test/language/eval-code")
+	h.RunDir(t, "test/language/global-code")
+	h.RunDir(t, "test/language/identifier-resolution")
+	h.RunDir(t, "test/language/identifiers")
+	//h.RunDir(t, "test/language/literals") // octal sequences in strict mode
+	h.RunDir(t, "test/language/punctuators")
+	h.RunDir(t, "test/language/reserved-words")
+	h.RunDir(t, "test/language/source-text")
+	h.RunDir(t, "test/language/statements")
+	h.RunDir(t, "test/language/types")
+	h.RunDir(t, "test/language/white-space")
+	h.RunDir(t, "test/built-ins")
+	h.RunDir(t, "test/annexB/built-ins/String/prototype/substr")
+}
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	base, err := ioutil.TempDir("", "test262")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		p := path.Join(base, name)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return base
+}
+
+func TestHarnessRuntimeFactory(t *testing.T) {
+	base := writeTestFiles(t, map[string]string{
+		"harness/assert.js": "function assert(v, msg) { if (v !== true) { throw new Test262Error(msg); } }",
+		"harness/sta.js":    "function Test262Error(msg) { this.message = msg; }",
+		"test/host.js":      "/*---\nes5id: 1\n---*/\nassert(host.ready === true, 'host is not configured');",
+		"test/negative.js":  "/*---\nes5id: 2\nnegative:\n  phase: runtime\n  type: TypeError\n---*/\nnull.x;",
+		"test/es6.js":       "/*---\nesid: 3\n---*/\nthrow new Error('ES6 tests are not run');",
+	})
+	defer os.RemoveAll(base)
+
+	runtimes := 0
+	h := &Harness{
+		Base: base,
+		NewRuntime: func() *goja.Runtime {
+			runtimes++
+			vm := goja.New()
+			vm.Set("host", map[string]interface{}{"ready": true})
+			return vm
+		},
+	}
+	h.RunDir(t, "test")
+	// host.js and negative.js are run in both modes
+	if runtimes != 4 {
+		t.Fatalf("Unexpected number of runtimes: %d", runtimes)
+	}
+}
+
+func TestHarnessReport(t *testing.T) {
+	base := writeTestFiles(t, map[string]string{
+		"harness/assert.js":     "function assert(v, msg) { if (v !== true) { throw new Test262Error(msg); } }",
+		"harness/sta.js":        "function Test262Error(msg) { this.message = msg; }",
+		"test/a/pass.js":        "/*---\nes5id: 1\n---*/\nassert(true);",
+		"test/a/strict-fail.js": "/*---\nes5id: 2\n---*/\nassert((function() { return this; })() !== undefined, 'strict');",
+		"test/a/es6.js":         "/*---\nesid: 3\n---*/\nthrow new Error('ES6 tests are not run');",
+		"test/b/skipped.js":     "/*---\nes5id: 4\n---*/\nassert(false);",
+		"test/b/fail.js":        "/*---\nes5id: 5\n---*/\nassert(false, 'fail');",
+	})
+	defer os.RemoveAll(base)
+
+	h := &Harness{
+		Base:     base,
+		SkipList: map[string]bool{"test/b/skipped.js": true},
+	}
+	report, err := h.Report("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := report.Dirs["test/a"]; s == nil || *s != (DirSummary{Pass: 1, Fail: 1, Skip: 1}) {
+		t.Fatalf("Unexpected summary: %v", s)
+	}
+	if s := report.Dirs["test/b"]; s == nil || *s != (DirSummary{Fail: 1, Skip: 1}) {
+		t.Fatalf("Unexpected summary: %v", s)
+	}
+	if report.Errors["test/b/fail.js"] == "" {
+		t.Fatal("Missing error")
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prev, err := ReadReport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := report.Diff(prev); len(changes) != 0 {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+
+	prev.Tests["test/a/strict-fail.js"] = StatusPass
+	prev.Tests["test/a/pass.js"] = StatusFail
+	prev.Tests["test/removed.js"] = StatusPass
+	changes := report.Diff(prev)
+	if len(changes) != 3 {
+		t.Fatalf("Unexpected changes: %v", changes)
+	}
+	if c := changes[0]; c.Name != "test/a/pass.js" || c.Regression() {
+		t.Fatalf("Unexpected change: %v", c)
+	}
+	if c := changes[1]; c.Name != "test/a/strict-fail.js" || !c.Regression() {
+		t.Fatalf("Unexpected change: %v", c)
+	}
+	if c := changes[2]; c.String() != "test/removed.js: pass -> missing" || !c.Regression() {
+		t.Fatalf("Unexpected change: %v", c)
+	}
+}
@@ -3,7 +3,6 @@ package goja
 import (
 	"math"
 	"reflect"
-	"regexp"
 	"strconv"
 )
 
@@ -80,6 +79,14 @@ type valueProperty struct {
 	setterFunc   *Object
 }
 
+// nilSafe converts a missing property value (nil) into undefined.
+func nilSafe(v Value) Value {
+	if v != nil {
+		return v
+	}
+	return _undefined
+}
+
 func propGetter(o Value, v Value, r *Runtime) *Object {
 	if v == _undefined {
 		return nil
@@ -498,25 +505,8 @@ func (f valueFloat) ToString() valueString {
 	return asciiString(f.String())
 }
 
-var matchLeading0Exponent = regexp.MustCompile(`([eE][\+\-])0+([1-9])`) // 1e-07 => 1e-7
-
 func (f valueFloat) String() string {
-	value := float64(f)
-	if math.IsNaN(value) {
-		return "NaN"
-	} else if math.IsInf(value, 0) {
-		if math.Signbit(value) {
-			return "-Infinity"
-		}
-		return "Infinity"
-	} else if f == _negativeZero {
-		return "0"
-	}
-	exponent := math.Log10(math.Abs(value))
-	if exponent >= 21 || exponent < -6 {
-		return matchLeading0Exponent.ReplaceAllString(strconv.FormatFloat(value, 'g', -1, 64), "$1$2")
-	}
-	return strconv.FormatFloat(value, 'f', -1, 64)
+	return numberToString(float64(f))
 }
 
 func (f valueFloat) ToFloat() float64 {
@@ -624,7 +614,11 @@ func (o *Object) ToString() valueString {
 }
 
 func (o *Object) String() string {
-	return o.self.toPrimitiveString().String()
+	s := o.self.toPrimitiveString().ToString()
+	if o.runtime != nil && o.runtime.argLimits != nil {
+		o.runtime.argLimits.checkConvertedString(o.runtime, s)
+	}
+	return s.String()
 }
 
 func (o *Object) ToFloat() float64 {
@@ -708,19 +702,65 @@ func (o *Object) Get(name string) Value {
 	return o.self.getStr(name)
 }
 
-func (o *Object) Set(name string, value interface{}) (err error) {
-	defer func() {
-		if x := recover(); x != nil {
-			if ex, ok := x.(*Exception); ok {
-				err = ex
-			} else {
-				panic(x)
-			}
+// Set sets the property like an assignment in strict mode code. The exceptions (e.g. a TypeError for a read-only
+// property or one thrown by a setter) are returned as *Exception.
+func (o *Object) Set(name string, value interface{}) error {
+	return o.runtime.Try(func() {
+		o.self.putStr(name, o.runtime.ToValue(value), true)
+	})
+}
+
+// Flag is the value of a property attribute passed to DefineDataProperty() and DefineAccessorProperty().
+// FLAG_NOT_SET leaves the attribute unchanged (or false if the property is created), as if it was omitted from the
+// descriptor passed to Object.defineProperty().
+type Flag int
+
+const (
+	FLAG_NOT_SET Flag = iota
+	FLAG_FALSE
+	FLAG_TRUE
+)
+
+func (f Flag) putTo(descr objectImpl, name string) {
+	switch f {
+	case FLAG_FALSE:
+		descr.putStr(name, valueFalse, false)
+	case FLAG_TRUE:
+		descr.putStr(name, valueTrue, false)
+	}
+}
+
+// DefineDataProperty defines or modifies a data property like Object.defineProperty() does. The TypeError thrown
+// if it cannot be done (e.g. the property is not configurable) is returned as *Exception.
+func (o *Object) DefineDataProperty(name string, value Value, writable, configurable, enumerable Flag) error {
+	return o.runtime.runWrapped(func() {
+		descr := o.runtime.NewObject().self
+		if value != nil {
+			descr.putStr("value", value, false)
 		}
-	}()
+		writable.putTo(descr, "writable")
+		configurable.putTo(descr, "configurable")
+		enumerable.putTo(descr, "enumerable")
+		o.self.defineOwnProperty(newStringValue(name), descr, true)
+	})
+}
 
-	o.self.putStr(name, o.runtime.ToValue(value), true)
-	return
+// DefineAccessorProperty defines or modifies an accessor property like Object.defineProperty() does. A nil getter
+// or setter is left unchanged (or undefined if the property is created), otherwise it must be a function or
+// undefined. The TypeError thrown if the property cannot be defined is returned as *Exception.
+func (o *Object) DefineAccessorProperty(name string, getter, setter Value, configurable, enumerable Flag) error {
+	return o.runtime.runWrapped(func() {
+		descr := o.runtime.NewObject()
+		if getter != nil {
+			descr.self.putStr("get", getter, false)
+		}
+		if setter != nil {
+			descr.self.putStr("set", setter, false)
+		}
+		configurable.putTo(descr.self, "configurable")
+		enumerable.putTo(descr.self, "enumerable")
+		o.self.defineOwnProperty(newStringValue(name), o.runtime.toPropertyDescriptor(descr), true)
+	})
 }
 
 func (o valueUnresolved) throw() {
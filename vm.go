@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"runtime/debug"
 	"strconv"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -29,6 +31,8 @@ type context struct {
 	stash    *stash
 	pc, sb   int
 	args     int
+
+	newTarget Value
 }
 
 type iterStackItem struct {
@@ -102,6 +106,7 @@ type vm struct {
 	pc           int
 	stack        valueStack
 	sp, sb, args int
+	newTarget    Value // the constructor if the current function was called with new, nil otherwise
 
 	stash     *stash
 	callStack []context
@@ -111,7 +116,7 @@ type vm struct {
 	stashAllocs int
 	halt        bool
 
-	interrupt     bool
+	interrupted   uint32 // accessed atomically, as it's set from other goroutines
 	interruptVal  interface{}
 	interruptLock sync.Mutex
 }
@@ -277,40 +282,93 @@ func (vm *vm) init() {
 
 func (vm *vm) run() {
 	vm.halt = false
-	for !vm.halt && !vm.interrupt {
+	interrupted := false
+	for !vm.halt {
+		if interrupted = atomic.LoadUint32(&vm.interrupted) != 0; interrupted {
+			break
+		}
 		vm.prg.code[vm.pc].exec(vm)
 	}
 
-	if vm.interrupt {
-		vm.interruptLock.Lock()
-		v := &InterruptedError{
-			iface: vm.interruptVal,
-		}
-		vm.interrupt = false
-		vm.interruptVal = nil
-		vm.interruptLock.Unlock()
-		panic(v)
+	if interrupted {
+		vm.throwInterrupted()
+	}
+}
+
+// checkInterrupt is used by the loops in Go code which would not notice an interrupt otherwise, as it's only checked
+// between the instructions.
+func (vm *vm) checkInterrupt() {
+	if atomic.LoadUint32(&vm.interrupted) != 0 {
+		vm.throwInterrupted()
 	}
 }
 
+func (vm *vm) throwInterrupted() {
+	vm.interruptLock.Lock()
+	v := &InterruptedError{
+		iface: vm.interruptVal,
+	}
+	vm.interruptLock.Unlock()
+	panic(v)
+}
+
 func (vm *vm) Interrupt(v interface{}) {
 	vm.interruptLock.Lock()
 	vm.interruptVal = v
-	vm.interrupt = true
+	atomic.StoreUint32(&vm.interrupted, 1)
 	vm.interruptLock.Unlock()
 }
 
-func (vm *vm) captureStack(stack []stackFrame, ctxOffset int) []stackFrame {
+func (vm *vm) ClearInterrupt() {
+	vm.interruptLock.Lock()
+	vm.interruptVal = nil
+	atomic.StoreUint32(&vm.interrupted, 0)
+	vm.interruptLock.Unlock()
+}
+
+// interruptIfClear interrupts with v unless an interrupt is already in effect.
+func (vm *vm) interruptIfClear(v interface{}) {
+	vm.interruptLock.Lock()
+	if atomic.LoadUint32(&vm.interrupted) == 0 {
+		vm.interruptVal = v
+		atomic.StoreUint32(&vm.interrupted, 1)
+	}
+	vm.interruptLock.Unlock()
+}
+
+// clearInterruptIf clears the interrupt only if its value is v, so that an interrupt set concurrently by someone
+// else is left in effect.
+func (vm *vm) clearInterruptIf(v interface{}) {
+	vm.interruptLock.Lock()
+	if atomic.LoadUint32(&vm.interrupted) != 0 && vm.interruptVal == v {
+		vm.interruptVal = nil
+		atomic.StoreUint32(&vm.interrupted, 0)
+	}
+	vm.interruptLock.Unlock()
+}
+
+func (vm *vm) captureStack(stack []StackFrame, ctxOffset int) []StackFrame {
 	// Unroll the context stack
-	stack = append(stack, stackFrame{prg: vm.prg, pc: vm.pc, funcName: vm.funcName})
+	stack = append(stack, StackFrame{prg: vm.prg, pc: vm.pc, funcName: vm.funcName})
 	for i := len(vm.callStack) - 1; i > ctxOffset-1; i-- {
 		if vm.callStack[i].pc != -1 {
-			stack = append(stack, stackFrame{prg: vm.callStack[i].prg, pc: vm.callStack[i].pc - 1, funcName: vm.callStack[i].funcName})
+			stack = append(stack, StackFrame{prg: vm.callStack[i].prg, pc: vm.callStack[i].pc - 1, funcName: vm.callStack[i].funcName})
 		}
 	}
 	return stack
 }
 
+// vmPanic is an unexpected Go panic that occurred while running the VM. It's propagated out of the Runtime and is
+// never converted into a JavaScript exception, not even by the enclosing try blocks.
+type vmPanic struct {
+	pc    int
+	value interface{}
+}
+
+func (p *vmPanic) Error() string {
+	return fmt.Sprintf("Panic at %d: %v", p.pc, p.value)
+}
+
 func (vm *vm) try(f func()) (ex *Exception) {
 	var ctx context
 	vm.saveCtx(&ctx)
@@ -346,17 +404,34 @@ func (vm *vm) try(f func()) (ex *Exception) {
 				}
 			case *InterruptedError:
 				x1.stack = vm.captureStack(x1.stack, ctxOffset)
+				x1.sources = vm.r.sourceProvider
+				x1.filter = vm.r.stackFrameFilter
 				panic(x1)
 			case *Exception:
 				ex = x1
+			case *vmPanic:
+				// already reported by a nested try
+				panic(x1)
 			default:
+				if vm.r.goPanicStacks {
+					ex = &Exception{
+						val: vm.r.newGoPanicError(x, debug.Stack()),
+					}
+					break
+				}
 				if vm.prg != nil {
 					vm.prg.dumpCode(log.Printf)
 				}
 				//log.Print("Stack: ", string(debug.Stack()))
-				panic(fmt.Errorf("Panic at %d: %v", vm.pc, x))
+				panic(&vmPanic{pc: vm.pc, value: x})
 			}
 			ex.stack = vm.captureStack(ex.stack, ctxOffset)
+			if ex.sources == nil {
+				ex.sources = vm.r.sourceProvider
+			}
+			if ex.filter == nil {
+				ex.filter = vm.r.stackFrameFilter
+			}
 		}
 	}()
 
@@ -390,6 +465,7 @@ func (vm *vm) saveCtx(ctx *context) {
 	ctx.pc = vm.pc
 	ctx.sb = vm.sb
 	ctx.args = vm.args
+	ctx.newTarget = vm.newTarget
 }
 
 func (vm *vm) pushCtx() {
@@ -412,6 +488,7 @@ func (vm *vm) restoreCtx(ctx *context) {
 	vm.stash = ctx.stash
 	vm.sb = ctx.sb
 	vm.args = ctx.args
+	vm.newTarget = ctx.newTarget
 }
 
 func (vm *vm) popCtx() {
@@ -424,6 +501,8 @@ func (vm *vm) popCtx() {
 	vm.callStack[l].stash = nil
 	vm.sb = vm.callStack[l].sb
 	vm.args = vm.callStack[l].args
+	vm.newTarget = vm.callStack[l].newTarget
+	vm.callStack[l].newTarget = nil
 
 	vm.callStack = vm.callStack[:l]
 }
@@ -515,6 +594,19 @@ func (_loadGlobalObject) exec(vm *vm) {
 	vm.pc++
 }
 
+type _loadNewTarget struct{}
+
+var loadNewTarget _loadNewTarget
+
+func (_loadNewTarget) exec(vm *vm) {
+	if vm.newTarget != nil {
+		vm.push(vm.newTarget)
+	} else {
+		vm.push(_undefined)
+	}
+	vm.pc++
+}
+
 type loadStack int
 
 func (l loadStack) exec(vm *vm) {
@@ -591,6 +683,13 @@ func (_add) exec(vm *vm) {
 	right := vm.stack[vm.sp-1]
 	left := vm.stack[vm.sp-2]
 
+	if res := operate("+", left, right); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	if o, ok := left.(*Object); ok {
 		left = o.self.toPrimitive()
 	}
@@ -611,7 +710,12 @@ func (_add) exec(vm *vm) {
 		if !isRightString {
 			rightString = right.ToString()
 		}
-		ret = leftString.concat(rightString)
+		res := leftString.concat(rightString)
+		vm.r.trackMemory(stringMemorySize(res, res.length()))
+		if vm.r.perfWarnings != nil {
+			vm.r.checkConcatPerf(res)
+		}
+		ret = res
 	} else {
 		if leftInt, ok := left.assertInt(); ok {
 			if rightInt, ok := right.assertInt(); ok {
@@ -646,6 +750,10 @@ func (_sub) exec(vm *vm) {
 		}
 	}
 
+	if result = operate("-", left, right); result != nil {
+		goto end
+	}
+
 	result = floatToValue(left.ToFloat() - right.ToFloat())
 end:
 	vm.sp--
@@ -663,6 +771,10 @@ func (_mul) exec(vm *vm) {
 
 	var result Value
 
+	if result = operate("*", left, right); result != nil {
+		goto end
+	}
+
 	if left, ok := toInt(left); ok {
 		if right, ok := toInt(right); ok {
 			if left == 0 && right == -1 || left == -1 && right == 0 {
@@ -692,6 +804,13 @@ type _div struct{}
 var div _div
 
 func (_div) exec(vm *vm) {
+	if res := operate("/", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.sp--
+		vm.stack[vm.sp-1] = res
+		vm.pc++
+		return
+	}
+
 	left := vm.stack[vm.sp-2].ToFloat()
 	right := vm.stack[vm.sp-1].ToFloat()
 
@@ -756,6 +875,10 @@ func (_mod) exec(vm *vm) {
 
 	var result Value
 
+	if result = operate("%", left, right); result != nil {
+		goto end
+	}
+
 	if leftInt, ok := toInt(left); ok {
 		if rightInt, ok := toInt(right); ok {
 			if rightInt == 0 {
@@ -788,6 +911,14 @@ func (_neg) exec(vm *vm) {
 
 	var result Value
 
+	if op := toOperand(operand); op != nil {
+		if result = op.Operate("neg", _undefined, false); result != nil {
+			vm.stack[vm.sp-1] = result
+			vm.pc++
+			return
+		}
+	}
+
 	if i, ok := toInt(operand); ok {
 		if i == 0 {
 			result = _negativeZero
@@ -822,6 +953,11 @@ var inc _inc
 func (_inc) exec(vm *vm) {
 	v := vm.stack[vm.sp-1]
 
+	if res := operate("+", v, intToValue(1)); res != nil {
+		v = res
+		goto end
+	}
+
 	if i, ok := toInt(v); ok {
 		v = intToValue(i + 1)
 		goto end
@@ -841,6 +977,11 @@ var dec _dec
 func (_dec) exec(vm *vm) {
 	v := vm.stack[vm.sp-1]
 
+	if res := operate("-", v, intToValue(1)); res != nil {
+		v = res
+		goto end
+	}
+
 	if i, ok := toInt(v); ok {
 		v = intToValue(i - 1)
 		goto end
@@ -1255,14 +1396,12 @@ func (l newArray) exec(vm *vm) {
 }
 
 type newRegexp struct {
-	pattern regexpPattern
-	src     valueString
-
-	global, ignoreCase, multiline bool
+	re  *compiledRegexp
+	src valueString
 }
 
 func (n *newRegexp) exec(vm *vm) {
-	vm.push(vm.r.newRegExpp(n.pattern, n.src, n.global, n.ignoreCase, n.multiline, vm.r.global.RegExpPrototype))
+	vm.push(vm.r.newRegExpp(n.re, n.src, vm.r.global.RegExpPrototype))
 	vm.pc++
 }
 
@@ -1733,7 +1872,7 @@ func (vm *vm) callEval(n int, strict bool) {
 				} else {
 					this = vm.r.globalObject
 				}
-				ret := vm.r.eval(src.String(), true, strict, this)
+				ret := vm.r.eval("eval", src.String(), true, strict, this)
 				vm.stack[vm.sp-n-2] = ret
 			} else {
 				vm.stack[vm.sp-n-2] = srcVal
@@ -1792,6 +1931,7 @@ repeat:
 		vm.pc++
 		vm.pushCtx()
 		vm.args = n
+		vm.newTarget = nil
 		vm.prg = f.prg
 		vm.stash = f.stash
 		vm.pc = 0
@@ -1801,6 +1941,8 @@ repeat:
 		vm._nativeCall(f, n)
 	case *boundFuncObject:
 		vm._nativeCall(&f.nativeFuncObject, n)
+	case *objectProxy:
+		vm._proxyCall(f, n)
 	case *lazyObject:
 		obj.self = f.create(obj)
 		goto repeat
@@ -1809,6 +1951,39 @@ repeat:
 	}
 }
 
+type tailCall uint32
+
+func (numargs tailCall) exec(vm *vm) {
+	// Same as call, but if the callee is a JavaScript function its frame replaces the current one, so
+	// that it returns directly to the caller of the current function.
+	n := int(numargs)
+	v := vm.stack[vm.sp-n-1] // callee
+	obj := vm.r.toCallee(v)
+repeat:
+	switch f := obj.self.(type) {
+	case *funcObject:
+		// The current frame starts at the callee slot which precedes this (at sb)
+		base := vm.sb - 1
+		sp := vm.sp
+		copy(vm.stack[base:], vm.stack[sp-n-2:sp])
+		vm.sp = base + n + 2
+		for i := vm.sp; i < sp; i++ {
+			vm.stack[i] = nil
+		}
+		vm.args = n
+		vm.newTarget = nil
+		vm.prg = f.prg
+		vm.stash = f.stash
+		vm.pc = 0
+		vm.stack[vm.sp-n-1], vm.stack[vm.sp-n-2] = vm.stack[vm.sp-n-2], vm.stack[vm.sp-n-1]
+	case *lazyObject:
+		obj.self = f.create(obj)
+		goto repeat
+	default:
+		call(numargs).exec(vm)
+	}
+}
+
 func (vm *vm) _nativeCall(f *nativeFuncObject, n int) {
 	if f.f != nil {
 		vm.pushCtx()
@@ -1830,6 +2005,27 @@ func (vm *vm) _nativeCall(f *nativeFuncObject, n int) {
 	vm.pc++
 }
 
+func (vm *vm) _proxyCall(p *objectProxy, n int) {
+	call, ok := p.assertCallable()
+	if !ok {
+		vm.r.typeErrorResult(true, "Not a function: %s", p.val.ToString())
+	}
+	vm.pushCtx()
+	vm.prg = nil
+	vm.funcName = nilSafe(p.target.self.getStr("name")).String()
+	ret := call(FunctionCall{
+		Arguments: vm.stack[vm.sp-n : vm.sp],
+		This:      vm.stack[vm.sp-n-2],
+	})
+	if ret == nil {
+		ret = _undefined
+	}
+	vm.stack[vm.sp-n-2] = ret
+	vm.popCtx()
+	vm.sp -= n + 1
+	vm.pc++
+}
+
 type enterFunc uint32
 
 func (e enterFunc) exec(vm *vm) {
@@ -2055,6 +2251,13 @@ type _op_lt struct{}
 var op_lt _op_lt
 
 func (_op_lt) exec(vm *vm) {
+	if res := compareOperands("<", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -2073,6 +2276,13 @@ type _op_lte struct{}
 var op_lte _op_lte
 
 func (_op_lte) exec(vm *vm) {
+	if res := compareOperands("<=", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -2092,6 +2302,13 @@ type _op_gt struct{}
 var op_gt _op_gt
 
 func (_op_gt) exec(vm *vm) {
+	if res := compareOperands(">", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -2110,6 +2327,13 @@ type _op_gte struct{}
 var op_gte _op_gte
 
 func (_op_gte) exec(vm *vm) {
+	if res := compareOperands(">=", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	left := toPrimitiveNumber(vm.stack[vm.sp-2])
 	right := toPrimitiveNumber(vm.stack[vm.sp-1])
 
@@ -2129,6 +2353,13 @@ type _op_eq struct{}
 var op_eq _op_eq
 
 func (_op_eq) exec(vm *vm) {
+	if res := compareOperands("==", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	if vm.stack[vm.sp-2].Equals(vm.stack[vm.sp-1]) {
 		vm.stack[vm.sp-2] = valueTrue
 	} else {
@@ -2143,6 +2374,13 @@ type _op_neq struct{}
 var op_neq _op_neq
 
 func (_op_neq) exec(vm *vm) {
+	if res := compareOperands("!=", vm.stack[vm.sp-2], vm.stack[vm.sp-1]); res != nil {
+		vm.stack[vm.sp-2] = res
+		vm.sp--
+		vm.pc++
+		return
+	}
+
 	if vm.stack[vm.sp-2].Equals(vm.stack[vm.sp-1]) {
 		vm.stack[vm.sp-2] = valueFalse
 	} else {
@@ -2339,6 +2577,12 @@ func (_typeof) exec(vm *vm) {
 		switch s := v.self.(type) {
 		case *funcObject, *nativeFuncObject, *boundFuncObject:
 			r = stringFunction
+		case *objectProxy:
+			if _, ok := s.assertCallable(); ok {
+				r = stringFunction
+			} else {
+				r = stringObjectC
+			}
 		case *lazyObject:
 			v.self = s.create(v)
 			goto repeat